@@ -0,0 +1,211 @@
+package sqllexer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+type anonymizeConfig struct {
+	// LexerOptions are the options of the lexer of the query, e.g. WithDBMS
+	LexerOptions []lexerOption `json:"-"`
+
+	// Key derives the pseudonyms from an HMAC of the identifiers, so they are the same across queries,
+	// instead of numbering the identifiers of each query
+	Key []byte `json:"-"`
+}
+
+type anonymizeOption func(*anonymizeConfig)
+
+func WithAnonymizeLexerOptions(lexerOpts ...lexerOption) anonymizeOption {
+	return func(c *anonymizeConfig) {
+		c.LexerOptions = append(c.LexerOptions, lexerOpts...)
+	}
+}
+
+func WithAnonymizeKey(key []byte) anonymizeOption {
+	return func(c *anonymizeConfig) {
+		c.Key = key
+	}
+}
+
+// identifierKind is the kind of object an identifier names, which prefixes its pseudonym
+type identifierKind byte
+
+const (
+	identifierColumn identifierKind = 'c'
+	identifierTable  identifierKind = 't'
+	identifierSchema identifierKind = 's'
+	identifierAlias  identifierKind = 'a'
+)
+
+// anonymizeKeepWords are SQL words lexed as identifiers, e.g. types and date parts, which are kept as is
+var anonymizeKeepWords = map[string]bool{
+	"WHEN": true, "THEN": true, "NULLS": true, "FIRST": true, "LAST": true, "ESCAPE": true, "ROWS": true, "ROW": true,
+	"ONLY": true, "NEXT": true, "CONFLICT": true, "DO": true, "NOTHING": true, "EXCLUDED": true, "PARTITION": true,
+	"OVER": true, "WITHIN": true, "FILTER": true, "PRECEDING": true, "FOLLOWING": true, "UNBOUNDED": true, "CURRENT": true,
+	"CURRENT_DATE": true, "CURRENT_TIME": true, "CURRENT_TIMESTAMP": true, "CURRENT_USER": true, "LOCALTIMESTAMP": true,
+	"INTERVAL": true, "YEAR": true, "MONTH": true, "WEEK": true, "DAY": true, "HOUR": true, "MINUTE": true, "SECOND": true,
+	"INT": true, "INTEGER": true, "SMALLINT": true, "BIGINT": true, "TINYINT": true, "NUMERIC": true, "DECIMAL": true,
+	"FLOAT": true, "REAL": true, "DOUBLE": true, "PRECISION": true, "BOOL": true, "BOOLEAN": true, "CHAR": true,
+	"VARCHAR": true, "NVARCHAR": true, "TEXT": true, "DATE": true, "TIME": true, "TIMESTAMP": true, "TIMESTAMPTZ": true,
+	"JSON": true, "JSONB": true, "UUID": true, "BYTEA": true, "BLOB": true,
+}
+
+// anonymizedName is a name of the query and the kind of object it names, if known before reading the whole query
+type anonymizedName struct {
+	start, end int
+	parts      []identifierPart
+	kind       identifierKind // 0 if the name is a column unless it names another object elsewhere in the query
+}
+
+// anonymizer assigns the pseudonyms of the identifiers of a query
+type anonymizer struct {
+	key        []byte
+	pseudonyms map[string]string
+	counts     map[identifierKind]int
+}
+
+// Anonymize returns the query with its literals obfuscated by the obfuscator, and its tables, schemas, aliases
+// and columns replaced by pseudonyms, e.g. t1, s1, a1 and c1, so queries can be shared without revealing the schema.
+// The same identifier is replaced by the same pseudonym in the whole query, and with WithAnonymizeKey,
+// in every query anonymized with the key, e.g. t_5e3a9c1f. Unquoted identifiers are matched case-insensitively.
+// Functions, parameters and SQL words lexed as identifiers, e.g. INT or YEAR, are kept as is,
+// so columns named as SQL words are not anonymized. Comments and optimizer hints are removed.
+func Anonymize(query string, obfuscator *Obfuscator, opts ...anonymizeOption) string {
+	config := &anonymizeConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	// comments and hints may name tables and columns, e.g. /*+ INDEX(users idx) */
+	query = StripComments(obfuscator.Obfuscate(query, config.LexerOptions...), WithStripCommentsLexerOptions(config.LexerOptions...))
+
+	names := collectAnonymizedNames(query, config.LexerOptions)
+	a := &anonymizer{key: config.Key, pseudonyms: make(map[string]string), counts: make(map[identifierKind]int)}
+	// the tables, schemas and aliases are numbered first, so their uses before their definition are known
+	for _, name := range names {
+		if name.kind == 0 {
+			continue
+		}
+		last := len(name.parts) - 1
+		for _, part := range name.parts[:last] {
+			a.pseudonym(part, identifierSchema)
+		}
+		a.pseudonym(name.parts[last], name.kind)
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(query))
+	end := 0
+	for _, name := range names {
+		builder.WriteString(query[end:name.start])
+		for i, part := range name.parts {
+			if i > 0 {
+				builder.WriteByte('.')
+			}
+			builder.WriteString(a.pseudonym(part, identifierColumn))
+		}
+		end = name.end
+	}
+	builder.WriteString(query[end:])
+	return builder.String()
+}
+
+// collectAnonymizedNames returns the names of the query to anonymize
+func collectAnonymizedNames(query string, lexerOpts []lexerOption) []anonymizedName {
+	tokens := make([]Token, 0, estimateTokens(len(query)))
+	lexer := New(query, lexerOpts...)
+	for {
+		token := lexer.Scan()
+		if token.Type == EOF {
+			break
+		}
+		tokens = append(tokens, *token)
+	}
+
+	var names []anonymizedName
+	state := &metadataState{ctes: make(map[string]bool)}
+	var lastValueToken *LastValueToken
+	var functionParentheses []bool // whether each enclosing parenthesis holds the arguments of a function
+	lastTable := false             // true if the last value token is a table, which the next identifier may alias
+	for i := 0; i < len(tokens); i++ {
+		token := &tokens[i]
+		state.trackClause(token, lastValueToken)
+		state.trackCTEs(token)
+		switch {
+		case token.Type == PUNCTUATION && token.Value == "(":
+			functionParentheses = append(functionParentheses, lastValueToken != nil && lastValueToken.Type == FUNCTION)
+		case token.Type == PUNCTUATION && token.Value == ")" && len(functionParentheses) > 0:
+			functionParentheses = functionParentheses[:len(functionParentheses)-1]
+		}
+
+		if isNamePart(token) && token.Type != PUNCTUATION {
+			end := nameEnd(tokens, i)
+			text := query[token.Start:tokens[end].End]
+			parts := splitIdentifier(text)
+			inFunction := len(functionParentheses) > 0 && functionParentheses[len(functionParentheses)-1]
+			function := tokens[end].Type == FUNCTION && !isColumnListTable(lastValueToken)
+			table := false
+			if !function && (len(parts) > 1 || (len(parts) == 1 && !isAnonymizeKeepWord(parts[0]))) {
+				name := anonymizedName{start: token.Start, end: tokens[end].End, parts: parts}
+				if strings.HasSuffix(text, ".") {
+					// the dot of e.g. u.* is kept
+					name.end--
+				}
+				switch {
+				case isCTEName(state, lastValueToken):
+					name.kind = identifierTable
+				case isTablePosition(state.clause, lastValueToken) && !isNonTableWord(text) && !inFunction:
+					name.kind, table = identifierTable, true
+				case lastValueToken != nil && lastValueToken.Type == ALIAS_INDICATOR:
+					name.kind = identifierAlias
+				case lastTable && len(parts) == 1:
+					name.kind = identifierAlias
+				}
+				names = append(names, name)
+			}
+			lastTable = table
+			lastValueToken = tokens[end].getLastValueToken()
+			i = end
+			continue
+		}
+
+		if isValueToken(token) {
+			lastTable = lastTable && token.Type == ALIAS_INDICATOR
+			lastValueToken = token.getLastValueToken()
+		}
+	}
+	return names
+}
+
+// isAnonymizeKeepWord returns true if the part is an unquoted SQL word kept as is
+func isAnonymizeKeepWord(part identifierPart) bool {
+	return part.quote == 0 && (anonymizeKeepWords[ToUpperASCII(part.name)] || isNonTableWord(part.name))
+}
+
+// pseudonym returns the pseudonym of the identifier part, assigning it a pseudonym of the kind if it has none
+func (a *anonymizer) pseudonym(part identifierPart, kind identifierKind) string {
+	if isAnonymizeKeepWord(part) {
+		return part.name
+	}
+	key := part.name
+	if part.quote == 0 {
+		key = ToLowerASCII(key)
+	}
+	if pseudonym, ok := a.pseudonyms[key]; ok {
+		return pseudonym
+	}
+	var pseudonym string
+	if a.key != nil {
+		mac := hmac.New(sha256.New, a.key)
+		mac.Write([]byte(key))
+		pseudonym = string(kind) + "_" + hex.EncodeToString(mac.Sum(nil)[:4])
+	} else {
+		a.counts[kind]++
+		pseudonym = string(kind) + strconv.Itoa(a.counts[kind])
+	}
+	a.pseudonyms[key] = pseudonym
+	return pseudonym
+}
@@ -0,0 +1,95 @@
+package sqllexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnonymize(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     []anonymizeOption
+		expected string
+	}{
+		{
+			name:     "tables, aliases and columns",
+			input:    "SELECT u.name, count(*) AS total FROM users u JOIN orders AS o ON o.user_id = u.id WHERE u.email = 'a@b.c' GROUP BY u.name ORDER BY total DESC",
+			expected: "SELECT a2.c1, count(*) AS a1 FROM t1 a2 JOIN t2 AS a3 ON a3.c2 = a2.c3 WHERE a2.c4 = ? GROUP BY a2.c1 ORDER BY a1 DESC",
+		},
+		{
+			name:     "schema",
+			input:    "SELECT price FROM sales.orders WHERE sales.orders.id = 1",
+			expected: "SELECT c1 FROM s1.t1 WHERE s1.t1.c2 = ?",
+		},
+		{
+			name:     "SQL words lexed as identifiers",
+			input:    "SELECT CAST(price AS INT), CASE WHEN status = 1 THEN 'x' END FROM orders WHERE created_at > CURRENT_TIMESTAMP - INTERVAL '1' DAY ORDER BY 1 NULLS LAST",
+			opts:     []anonymizeOption{WithAnonymizeLexerOptions(WithDBMS(DBMSPostgres))},
+			expected: "SELECT CAST(c1 AS INT), CASE WHEN c2 = ? THEN ? END FROM t1 WHERE c3 > CURRENT_TIMESTAMP - INTERVAL ? DAY ORDER BY ? NULLS LAST",
+		},
+		{
+			name:     "insert with columns",
+			input:    "INSERT INTO t(a, b) VALUES (1, 2) ON CONFLICT (a) DO UPDATE SET b = EXCLUDED.b RETURNING a",
+			opts:     []anonymizeOption{WithAnonymizeLexerOptions(WithDBMS(DBMSPostgres))},
+			expected: "INSERT INTO t1(c1, c2) VALUES (?, ?) ON CONFLICT (c1) DO UPDATE SET c2 = EXCLUDED.c2 RETURNING c1",
+		},
+		{
+			name:     "CTE and quoted identifiers",
+			input:    `WITH recent AS (SELECT id FROM "Users") SELECT * FROM recent r, "Users" x WHERE EXTRACT(YEAR FROM x.born) = 2000`,
+			opts:     []anonymizeOption{WithAnonymizeLexerOptions(WithDBMS(DBMSPostgres))},
+			expected: "WITH t1 AS (SELECT c1 FROM t2) SELECT * FROM t1 a1, t2 a2 WHERE EXTRACT(YEAR FROM a2.c2) = ?",
+		},
+		{
+			name:     "case-insensitive unquoted identifiers",
+			input:    "DELETE FROM Sessions WHERE sessions.expires < now()",
+			expected: "DELETE FROM t1 WHERE t1.c1 < now()",
+		},
+		{
+			name:     "SQL Server brackets",
+			input:    "SELECT [id] FROM [dbo].[users] WHERE id = @id",
+			opts:     []anonymizeOption{WithAnonymizeLexerOptions(WithDBMS(DBMSSQLServer))},
+			expected: "SELECT c1 FROM s1.t1 WHERE c1 = @id",
+		},
+		{
+			name:     "mixed quotes",
+			input:    `SELECT o.id, o.* FROM public."Orders" o JOIN a."b" ON true`,
+			opts:     []anonymizeOption{WithAnonymizeLexerOptions(WithDBMS(DBMSPostgres))},
+			expected: "SELECT a1.c1, a1.* FROM s1.t1 a1 JOIN s2.t2 ON true",
+		},
+		{
+			name:     "comments and hints",
+			input:    "SELECT /*+ INDEX(users users_email) */ email FROM users -- users by email\nWHERE id = 1 /* secret_table */",
+			expected: "SELECT  c1 FROM t1 \nWHERE c2 = ? ",
+		},
+		{
+			name:     "keyed pseudonyms",
+			input:    "SELECT id FROM users",
+			opts:     []anonymizeOption{WithAnonymizeKey([]byte("secret"))},
+			expected: "SELECT c_0da5b405 FROM t_3c7d3558",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Anonymize(tt.input, NewObfuscator(), tt.opts...))
+		})
+	}
+}
+
+func TestAnonymizeKeyIsStable(t *testing.T) {
+	key := WithAnonymizeKey([]byte("secret"))
+	first := Anonymize("SELECT email FROM users", NewObfuscator(), key)
+	second := Anonymize("SELECT name, email FROM accounts JOIN users ON true", NewObfuscator(), key)
+	// users and email have the same pseudonyms in both queries
+	assert.Contains(t, second, first[len("SELECT "):len("SELECT ")+10])
+	assert.Contains(t, second, first[len(first)-10:])
+	assert.NotEqual(t, first, Anonymize("SELECT email FROM users", NewObfuscator(), WithAnonymizeKey([]byte("other"))))
+}
+
+func ExampleAnonymize() {
+	fmt.Println(Anonymize("SELECT p.name FROM patients p WHERE p.ssn = '123-45-6789'", NewObfuscator()))
+	// Output: SELECT a1.c1 FROM t1 a1 WHERE a1.c2 = ?
+}
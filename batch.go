@@ -0,0 +1,126 @@
+package sqllexer
+
+import "strings"
+
+// BatchStatement is a statement of a batch, as analyzed by AnalyzeBatch
+type BatchStatement struct {
+	Statement
+	// Obfuscated is the text of the statement with its literals obfuscated
+	Obfuscated string        `json:"obfuscated"`
+	Kind       StatementKind `json:"kind"`
+	// Tables are the tables the statement references, without their quotes and CTE names excluded
+	Tables []string `json:"tables"`
+	// TransactionControl is true if the statement begins, commits or rolls back a transaction, e.g. BEGIN or SAVEPOINT
+	TransactionControl bool `json:"transaction_control"`
+}
+
+// batchEdit is the replacement of the obfuscated value of a token
+type batchEdit struct {
+	start, end int
+	value      string
+}
+
+// batchAnalyzer accumulates the analysis of the statement being read
+type batchAnalyzer struct {
+	obfuscator     *Obfuscator
+	lexerOpts      []lexerOption
+	classifier     statementClassifier
+	classified     bool
+	state          metadataState
+	lastValueToken *LastValueToken
+	tables         []string
+	tablesSet      map[string]bool
+	edits          []batchEdit
+}
+
+// AnalyzeBatch splits the batch into its statements, as Splitter does, and returns for each of them its kind,
+// its text obfuscated by the obfuscator, the tables it references and whether it is a transaction control statement.
+// The batch is lexed once, instead of once to split it and once per statement to obfuscate and classify them.
+func AnalyzeBatch(batch string, obfuscator *Obfuscator, lexerOpts ...lexerOption) []BatchStatement {
+	splitter := NewSplitter(lexerOpts...)
+	a := &batchAnalyzer{
+		obfuscator: obfuscator,
+		lexerOpts:  lexerOpts,
+		classifier: statementClassifier{dbms: splitter.dbms},
+		state:      metadataState{ctes: make(map[string]bool)},
+		tablesSet:  make(map[string]bool),
+	}
+	var statements []BatchStatement
+	splitter.split(batch, a.visit, func(statement Statement, ok bool) {
+		if ok {
+			statements = append(statements, a.result(batch, statement))
+		}
+		a.reset()
+	})
+	return statements
+}
+
+// visit analyzes the next token of the statement, at the byte offsets of the batch
+func (a *batchAnalyzer) visit(token *Token, start, end int) {
+	if !a.classified {
+		a.classified = a.classifier.classify(token)
+	}
+
+	a.state.trackClause(token, a.lastValueToken)
+	a.state.trackCTEs(token)
+	if token.Type == IDENT || token.Type == QUOTED_IDENT || token.Type == FUNCTION {
+		name := token.Value
+		if token.Type == QUOTED_IDENT {
+			name = trimQuotes(token)
+		}
+		if isCTEName(&a.state, a.lastValueToken) {
+			a.state.ctes[name] = true
+		} else if isTablePosition(a.state.clause, a.lastValueToken) && !isNonTableWord(name) &&
+			!a.state.ctes[name] && !a.tablesSet[name] {
+			a.tablesSet[name] = true
+			a.tables = append(a.tables, name)
+		}
+	}
+
+	obfuscated := *token
+	a.obfuscator.ObfuscateTokenValue(&obfuscated, a.lastValueToken, a.lexerOpts...)
+	if obfuscated.Value != token.Value {
+		a.edits = append(a.edits, batchEdit{start: start, end: end, value: obfuscated.Value})
+	}
+	if isValueToken(token) {
+		a.lastValueToken = token.getLastValueToken()
+	}
+}
+
+// result returns the analysis of the statement of the batch
+func (a *batchAnalyzer) result(batch string, statement Statement) BatchStatement {
+	var obfuscated strings.Builder
+	obfuscated.Grow(statement.End - statement.Start)
+	pos := statement.Start
+	for _, edit := range a.edits {
+		// the trailing spaces of a token, e.g. an unterminated string, are trimmed from the statement
+		start, end := max(edit.start, pos), min(edit.end, statement.End)
+		if start > statement.End {
+			break
+		}
+		obfuscated.WriteString(batch[pos:start])
+		obfuscated.WriteString(edit.value)
+		pos = end
+	}
+	obfuscated.WriteString(batch[pos:statement.End])
+
+	kind := a.classifier.result()
+	return BatchStatement{
+		Statement:          statement,
+		Obfuscated:         obfuscated.String(),
+		Kind:               kind,
+		Tables:             a.tables,
+		TransactionControl: kind == StatementTCL,
+	}
+}
+
+// reset empties the analyzer for the next statement
+func (a *batchAnalyzer) reset() {
+	a.classifier = statementClassifier{dbms: a.classifier.dbms}
+	a.classified = false
+	a.state.reset()
+	a.lastValueToken = nil
+	a.tables = nil
+	clear(a.tablesSet)
+	a.edits = a.edits[:0]
+}
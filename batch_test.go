@@ -0,0 +1,122 @@
+package sqllexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzeBatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		lexerOpts []lexerOption
+		expected  []BatchStatement
+	}{
+		{
+			name:  "transaction",
+			input: "BEGIN; UPDATE users SET name = 'x' WHERE id = 42; COMMIT;",
+			expected: []BatchStatement{
+				{Statement: Statement{Text: "BEGIN", Start: 0, End: 5}, Obfuscated: "BEGIN", Kind: StatementTCL, TransactionControl: true},
+				{
+					Statement:  Statement{Text: "UPDATE users SET name = 'x' WHERE id = 42", Start: 7, End: 48},
+					Obfuscated: "UPDATE users SET name = ? WHERE id = ?",
+					Kind:       StatementUpdate,
+					Tables:     []string{"users"},
+				},
+				{Statement: Statement{Text: "COMMIT", Start: 50, End: 56}, Obfuscated: "COMMIT", Kind: StatementTCL, TransactionControl: true},
+			},
+		},
+		{
+			name:  "trailing unterminated string",
+			input: "SELECT 'abc ",
+			expected: []BatchStatement{
+				{Statement: Statement{Text: "SELECT 'abc", Start: 0, End: 11}, Obfuscated: "SELECT ?", Kind: StatementSelect},
+			},
+		},
+		{
+			name:  "unterminated string only",
+			input: "' ",
+			expected: []BatchStatement{
+				{Statement: Statement{Text: "'", Start: 0, End: 1}, Obfuscated: "?", Kind: StatementUnknown},
+			},
+		},
+		{
+			name:  "comments and CTEs",
+			input: "/* report */ WITH t AS (SELECT 1) SELECT * FROM t, orders JOIN \"Orders\" o ON o.id = t.id;\n-- trailing",
+			expected: []BatchStatement{
+				{
+					Statement:  Statement{Text: "/* report */ WITH t AS (SELECT 1) SELECT * FROM t, orders JOIN \"Orders\" o ON o.id = t.id", Start: 0, End: 88},
+					Obfuscated: "/* report */ WITH t AS (SELECT ?) SELECT * FROM t, orders JOIN \"Orders\" o ON o.id = t.id",
+					Kind:       StatementSelect,
+					Tables:     []string{"orders", "Orders"},
+				},
+			},
+		},
+		{
+			name:      "MySQL delimiter",
+			input:     "DELIMITER //\nCREATE PROCEDURE p() BEGIN SELECT 1 FROM a; END//\nDELIMITER ;\nCALL p()",
+			lexerOpts: []lexerOption{WithDBMS(DBMSMySQL)},
+			expected: []BatchStatement{
+				{
+					Statement:  Statement{Text: "CREATE PROCEDURE p() BEGIN SELECT 1 FROM a; END", Start: 13, End: 60},
+					Obfuscated: "CREATE PROCEDURE p() BEGIN SELECT ? FROM a; END",
+					Kind:       StatementDDL,
+					Tables:     []string{"a"},
+				},
+				{Statement: Statement{Text: "CALL p()", Start: 75, End: 83}, Obfuscated: "CALL p()", Kind: StatementUtility},
+			},
+		},
+		{
+			name:      "SQL Server batches",
+			input:     "BEGIN TRAN\nINSERT INTO logs VALUES ('a')\nGO\nCOMMIT TRAN",
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+			expected: []BatchStatement{
+				{
+					Statement:          Statement{Text: "BEGIN TRAN\nINSERT INTO logs VALUES ('a')", Start: 0, End: 40},
+					Obfuscated:         "BEGIN TRAN\nINSERT INTO logs VALUES (?)",
+					Kind:               StatementTCL,
+					Tables:             []string{"logs"},
+					TransactionControl: true,
+				},
+				{Statement: Statement{Text: "COMMIT TRAN", Start: 44, End: 55}, Obfuscated: "COMMIT TRAN", Kind: StatementTCL, TransactionControl: true},
+			},
+		},
+		{
+			name:  "empty",
+			input: " ; -- nothing\n;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statements := AnalyzeBatch(tt.input, NewObfuscator(), tt.lexerOpts...)
+			assert.Equal(t, tt.expected, statements)
+		})
+	}
+}
+
+func TestAnalyzeBatchMatchesSeparatePasses(t *testing.T) {
+	input := "SELECT * FROM users WHERE id = 1; INSERT INTO t (a) VALUES ($$x$$, 2.5); DELETE FROM s.t USING u WHERE s.t.id = u.id"
+	obfuscator := NewObfuscator()
+	statements := NewSplitter().Split(input)
+	analyzed := AnalyzeBatch(input, obfuscator)
+	assert.Len(t, analyzed, len(statements))
+	for i, statement := range statements {
+		assert.Equal(t, statement, analyzed[i].Statement)
+		assert.Equal(t, obfuscator.Obfuscate(statement.Text), analyzed[i].Obfuscated)
+		assert.Equal(t, Command(statement.Text), analyzed[i].Kind)
+	}
+}
+
+func ExampleAnalyzeBatch() {
+	batch := "BEGIN; UPDATE accounts SET balance = 100 WHERE id = 7; COMMIT;"
+	for _, statement := range AnalyzeBatch(batch, NewObfuscator()) {
+		fmt.Println(statement.Kind, statement.TransactionControl, statement.Tables, statement.Obfuscated)
+	}
+	// Output:
+	// TCL true [] BEGIN
+	// UPDATE false [accounts] UPDATE accounts SET balance = ? WHERE id = ?
+	// TCL true [] COMMIT
+}
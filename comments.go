@@ -0,0 +1,81 @@
+package sqllexer
+
+import "strings"
+
+// CommentKind is the kind of a SQL comment
+type CommentKind string
+
+const (
+	CommentLine  CommentKind = "line"  // -- comment, or # comment in MySQL
+	CommentBlock CommentKind = "block" // /* comment */
+	CommentHint  CommentKind = "hint"  // /*+ optimizer hint */
+)
+
+// CommentPlacement is the placement of a comment relative to the SQL statement
+type CommentPlacement string
+
+const (
+	CommentLeading  CommentPlacement = "leading"  // before the first token of the statement
+	CommentTrailing CommentPlacement = "trailing" // after the last token of the statement
+	CommentInline   CommentPlacement = "inline"   // between two tokens of the statement
+)
+
+// Comment is a comment extracted from a SQL query
+type Comment struct {
+	Text      string           `json:"text"`
+	Kind      CommentKind      `json:"kind"`
+	Start     int              `json:"start"` // byte offset of the comment in the query
+	End       int              `json:"end"`   // byte offset following the comment in the query
+	Placement CommentPlacement `json:"placement"`
+}
+
+// ExtractComments returns the comments of the query in order of appearance,
+// along with their kind, byte range and placement relative to the statement.
+func ExtractComments(query string, lexerOpts ...lexerOption) []Comment {
+	lexer := New(query, lexerOpts...)
+	var comments []Comment
+	seenStatement := false
+	trailingFrom := 0 // index of the first comment following the last statement token
+
+	for {
+		token := lexer.Scan()
+		if token.Type == EOF {
+			break
+		}
+		switch token.Type {
+		case SPACE:
+		case COMMENT, MULTILINE_COMMENT:
+			comment := Comment{
+				Text:      token.Value,
+				Kind:      commentKind(token),
+				Start:     token.Start,
+				End:       token.End,
+				Placement: CommentLeading,
+			}
+			if seenStatement {
+				comment.Placement = CommentInline
+			}
+			comments = append(comments, comment)
+		default:
+			seenStatement = true
+			trailingFrom = len(comments)
+		}
+	}
+
+	if seenStatement {
+		for i := trailingFrom; i < len(comments); i++ {
+			comments[i].Placement = CommentTrailing
+		}
+	}
+	return comments
+}
+
+func commentKind(token *Token) CommentKind {
+	if token.Type == COMMENT {
+		return CommentLine
+	}
+	if strings.HasPrefix(token.Value, "/*+") {
+		return CommentHint
+	}
+	return CommentBlock
+}
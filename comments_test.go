@@ -0,0 +1,74 @@
+package sqllexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractComments(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  []Comment
+		lexerOpts []lexerOption
+	}{
+		{
+			input:    "SELECT * FROM users",
+			expected: nil,
+		},
+		{
+			input: "/* leading */ SELECT /*+ INDEX(users idx) */ * FROM users -- trailing\n",
+			expected: []Comment{
+				{Text: "/* leading */", Kind: CommentBlock, Start: 0, End: 13, Placement: CommentLeading},
+				{Text: "/*+ INDEX(users idx) */", Kind: CommentHint, Start: 21, End: 44, Placement: CommentInline},
+				{Text: "-- trailing", Kind: CommentLine, Start: 58, End: 69, Placement: CommentTrailing},
+			},
+		},
+		{
+			input: "-- first\n-- second\nSELECT 1",
+			expected: []Comment{
+				{Text: "-- first", Kind: CommentLine, Start: 0, End: 8, Placement: CommentLeading},
+				{Text: "-- second", Kind: CommentLine, Start: 9, End: 18, Placement: CommentLeading},
+			},
+		},
+		{
+			input: "SELECT 1 # mysql comment",
+			expected: []Comment{
+				{Text: "# mysql comment", Kind: CommentLine, Start: 9, End: 24, Placement: CommentTrailing},
+			},
+			lexerOpts: []lexerOption{WithDBMS(DBMSMySQL)},
+		},
+		{
+			input: "/* only a comment */",
+			expected: []Comment{
+				{Text: "/* only a comment */", Kind: CommentBlock, Start: 0, End: 20, Placement: CommentLeading},
+			},
+		},
+		{
+			input: "SELECT 'a -- not a comment' /* é */",
+			expected: []Comment{
+				{Text: "/* é */", Kind: CommentBlock, Start: 28, End: 36, Placement: CommentTrailing},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			got := ExtractComments(test.input, test.lexerOpts...)
+			assert.Equal(t, test.expected, got)
+			for _, comment := range got {
+				assert.Equal(t, comment.Text, test.input[comment.Start:comment.End])
+			}
+		})
+	}
+}
+
+func ExampleExtractComments() {
+	for _, comment := range ExtractComments("/* app=api */ SELECT * FROM users -- slow") {
+		fmt.Println(comment.Placement, comment.Kind, comment.Start, comment.End, comment.Text)
+	}
+	// Output:
+	// leading block 0 13 /* app=api */
+	// trailing line 34 41 -- slow
+}
@@ -0,0 +1,108 @@
+package sqllexer
+
+import "strings"
+
+// CompletionKind is what a completion engine should suggest at a cursor
+type CompletionKind string
+
+const (
+	CompletionNone    CompletionKind = "NONE"    // nothing, e.g. inside a string, a comment or a number, or after AS
+	CompletionKeyword CompletionKind = "KEYWORD" // keywords, e.g. at the start of a statement or after a column
+	CompletionTable   CompletionKind = "TABLE"   // tables, e.g. after FROM, JOIN or INTO
+	CompletionColumn  CompletionKind = "COLUMN"  // columns, e.g. after SELECT, WHERE or an operator
+)
+
+// CompletionContext is the lexical context of a cursor in a query
+type CompletionContext struct {
+	Kind CompletionKind
+	// Clause is the clause of the statement at the cursor, one of SELECT, FROM, WHERE, GROUP BY and ORDER BY,
+	// or empty if it is none of them. ON and HAVING conditions are in the WHERE clause.
+	Clause string
+	// Prefix is the partial word before the cursor, which the completion replaces, e.g. "na" in "SELECT u.na"
+	Prefix string
+	// Start is the byte offset of the prefix, the cursor if the prefix is empty
+	Start int
+	// Qualifier is the qualifier of the prefix, e.g. "u" in "SELECT u.na", the table or schema
+	// to complete the columns or tables of
+	Qualifier string
+}
+
+var clauseNames = map[sqlClause]string{
+	clauseSelect:  "SELECT",
+	clauseFrom:    "FROM",
+	clauseWhere:   "WHERE",
+	clauseGroupBy: "GROUP BY",
+	clauseOrderBy: "ORDER BY",
+}
+
+// CompleteAt returns the lexical context of the cursor at the byte offset of the query, so completion engines
+// can decide whether to suggest keywords, tables, columns or nothing. Only the tokens before the cursor are read,
+// so the query may be incomplete, as it is while being typed.
+func CompleteAt(query string, cursor int, lexerOpts ...lexerOption) CompletionContext {
+	cursor = max(0, min(cursor, len(query)))
+
+	tokens := make([]Token, 0, estimateTokens(cursor))
+	lexer := New(query, lexerOpts...)
+	for {
+		token := lexer.Scan()
+		if token.Type == EOF || token.Start >= cursor {
+			break
+		}
+		tokens = append(tokens, *token)
+	}
+
+	context := CompletionContext{Start: cursor}
+	inside := false // true if the cursor is inside a token which cannot be completed
+	if len(tokens) > 0 {
+		last := &tokens[len(tokens)-1]
+		switch last.Type {
+		case STRING, DOLLAR_QUOTED_STRING, DOLLAR_QUOTED_FUNCTION, MULTILINE_COMMENT:
+			inside = cursor < last.End
+		case INCOMPLETE_STRING, INCOMPLETE_COMMENT, INCOMPLETE_QUOTED_IDENT, COMMENT, NUMBER, ERROR:
+			// the token continues at the cursor, e.g. unterminated strings, quoted identifiers and comments
+			inside = true
+		case IDENT, QUOTED_IDENT, FUNCTION, KEYWORD, COMMAND, BOOLEAN, NULL, PROC_INDICATOR, CTE_INDICATOR, ALIAS_INDICATOR:
+			// the word being typed
+			context.Prefix, context.Start = query[last.Start:cursor], last.Start
+			tokens = tokens[:len(tokens)-1]
+			if dot := strings.LastIndexByte(context.Prefix, '.'); dot >= 0 && last.Type != QUOTED_IDENT {
+				context.Qualifier, context.Prefix = context.Prefix[:dot], context.Prefix[dot+1:]
+				context.Start += dot + 1
+			}
+		}
+	}
+
+	state := &metadataState{ctes: make(map[string]bool)}
+	var lastValueToken *LastValueToken
+	for i := range tokens {
+		token := &tokens[i]
+		if token.Type == PUNCTUATION && token.Value == ";" {
+			state.reset()
+			lastValueToken = nil
+			continue
+		}
+		state.trackClause(token, lastValueToken)
+		if isValueToken(token) {
+			lastValueToken = token.getLastValueToken()
+		}
+	}
+
+	context.Clause = clauseNames[state.clause]
+	switch {
+	case inside:
+		context.Kind = CompletionNone
+	case lastValueToken == nil:
+		context.Kind = CompletionKeyword
+	case isTablePosition(state.clause, lastValueToken):
+		context.Kind = CompletionTable
+	case context.Qualifier != "":
+		context.Kind = CompletionColumn
+	case lastValueToken.Type == ALIAS_INDICATOR:
+		context.Kind = CompletionNone
+	case isColumnPosition(state.clause, lastValueToken):
+		context.Kind = CompletionColumn
+	default:
+		context.Kind = CompletionKeyword
+	}
+	return context
+}
@@ -0,0 +1,156 @@
+package sqllexer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompleteAt(t *testing.T) {
+	// the cursor is at the "|" of the input
+	tests := []struct {
+		name      string
+		input     string
+		lexerOpts []lexerOption
+		expected  CompletionContext
+	}{
+		{
+			name:     "empty query",
+			input:    "|",
+			expected: CompletionContext{Kind: CompletionKeyword},
+		},
+		{
+			name:     "partial command",
+			input:    "SEL|",
+			expected: CompletionContext{Kind: CompletionKeyword, Prefix: "SEL"},
+		},
+		{
+			name:     "after SELECT",
+			input:    "SELECT |",
+			expected: CompletionContext{Kind: CompletionColumn, Clause: "SELECT", Start: 7},
+		},
+		{
+			name:     "partial column",
+			input:    "SELECT id, na| FROM users",
+			expected: CompletionContext{Kind: CompletionColumn, Clause: "SELECT", Prefix: "na", Start: 11},
+		},
+		{
+			name:     "qualified column",
+			input:    "SELECT u.na| FROM users u",
+			expected: CompletionContext{Kind: CompletionColumn, Clause: "SELECT", Prefix: "na", Start: 9, Qualifier: "u"},
+		},
+		{
+			name:     "after FROM",
+			input:    "SELECT * FROM |",
+			expected: CompletionContext{Kind: CompletionTable, Clause: "FROM", Start: 14},
+		},
+		{
+			name:     "table of a schema",
+			input:    "SELECT * FROM sales.or|",
+			expected: CompletionContext{Kind: CompletionTable, Clause: "FROM", Prefix: "or", Start: 20, Qualifier: "sales"},
+		},
+		{
+			name:     "after a table",
+			input:    "SELECT * FROM users |",
+			expected: CompletionContext{Kind: CompletionKeyword, Clause: "FROM", Start: 20},
+		},
+		{
+			name:     "after JOIN",
+			input:    "SELECT * FROM users u JOIN |",
+			expected: CompletionContext{Kind: CompletionTable, Clause: "FROM", Start: 27},
+		},
+		{
+			name:     "join condition",
+			input:    "SELECT * FROM users u JOIN orders o ON o.|",
+			expected: CompletionContext{Kind: CompletionColumn, Clause: "WHERE", Start: 41, Qualifier: "o"},
+		},
+		{
+			name:     "after an operator",
+			input:    "SELECT a FROM t WHERE a = |",
+			expected: CompletionContext{Kind: CompletionColumn, Clause: "WHERE", Start: 26},
+		},
+		{
+			name:     "after ORDER BY",
+			input:    "SELECT a FROM t ORDER BY |",
+			expected: CompletionContext{Kind: CompletionColumn, Clause: "ORDER BY", Start: 25},
+		},
+		{
+			name:     "after a subquery",
+			input:    "SELECT * FROM (SELECT a FROM t) WHERE |",
+			expected: CompletionContext{Kind: CompletionColumn, Clause: "WHERE", Start: 38},
+		},
+		{
+			name:     "after INTO",
+			input:    "INSERT INTO |",
+			expected: CompletionContext{Kind: CompletionTable, Start: 12},
+		},
+		{
+			name:     "second statement",
+			input:    "SELECT 1; DELETE FROM |",
+			expected: CompletionContext{Kind: CompletionTable, Clause: "FROM", Start: 22},
+		},
+		{
+			name:     "after AS",
+			input:    "SELECT a AS |",
+			expected: CompletionContext{Kind: CompletionNone, Clause: "SELECT", Start: 12},
+		},
+		{
+			name:     "inside a string",
+			input:    "SELECT * FROM t WHERE a = 'ab|c'",
+			expected: CompletionContext{Kind: CompletionNone, Clause: "WHERE", Start: 29},
+		},
+		{
+			name:     "after a string",
+			input:    "SELECT 'abc'|",
+			expected: CompletionContext{Kind: CompletionKeyword, Clause: "SELECT", Start: 12},
+		},
+		{
+			name:     "unterminated string",
+			input:    "SELECT 'ab|",
+			expected: CompletionContext{Kind: CompletionNone, Clause: "SELECT", Start: 10},
+		},
+		{
+			name:     "number",
+			input:    "SELECT 1| FROM t",
+			expected: CompletionContext{Kind: CompletionNone, Clause: "SELECT", Start: 8},
+		},
+		{
+			name:     "single line comment",
+			input:    "SELECT a -- x|",
+			expected: CompletionContext{Kind: CompletionNone, Clause: "SELECT", Start: 13},
+		},
+		{
+			name:     "multiline comment",
+			input:    "SELECT /* x| */ 1",
+			expected: CompletionContext{Kind: CompletionNone, Clause: "SELECT", Start: 11},
+		},
+		{
+			name:      "dollar quoted string",
+			input:     "SELECT $$a|b$$",
+			lexerOpts: []lexerOption{WithDBMS(DBMSPostgres)},
+			expected:  CompletionContext{Kind: CompletionNone, Clause: "SELECT", Start: 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor := strings.Index(tt.input, "|")
+			query := tt.input[:cursor] + tt.input[cursor+1:]
+			assert.Equal(t, tt.expected, CompleteAt(query, cursor, tt.lexerOpts...))
+		})
+	}
+}
+
+func TestCompleteAtOutOfRange(t *testing.T) {
+	assert.Equal(t, CompletionContext{Kind: CompletionKeyword}, CompleteAt("SELECT", -1))
+	assert.Equal(t, CompletionContext{Kind: CompletionKeyword, Prefix: "SELECT"}, CompleteAt("SELECT", 100))
+}
+
+func ExampleCompleteAt() {
+	query := "SELECT * FROM users u JOIN ord"
+	context := CompleteAt(query, len(query))
+	fmt.Println(context.Kind, context.Clause, context.Prefix)
+	// Output: TABLE FROM ord
+}
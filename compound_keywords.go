@@ -0,0 +1,102 @@
+package sqllexer
+
+// compoundKeywords are the phrases of keywords scanned as single tokens WithCompoundKeywords
+var compoundKeywords = []string{
+	"GROUP BY",
+	"ORDER BY",
+	"INSERT INTO",
+	"DELETE FROM",
+	"INNER JOIN",
+	"LEFT JOIN",
+	"LEFT OUTER JOIN",
+	"RIGHT JOIN",
+	"RIGHT OUTER JOIN",
+	"IS NULL",
+	"IS NOT NULL",
+	"NOT IN",
+	"NOT LIKE",
+	"NOT EXISTS",
+	"UNION ALL",
+	"PRIMARY KEY",
+}
+
+// compoundNode is a node of the trie of the compound keywords, whose edges are keywords
+type compoundNode struct {
+	children map[string]*compoundNode // keyed by the uppercase keyword
+	// the phrase ending at the node, if any
+	terminal         bool
+	tokenType        TokenType
+	isTableIndicator bool
+}
+
+// compoundTrie is the trie of the compound keywords, its root being the empty phrase
+var compoundTrie = buildCompoundTrie(compoundKeywords)
+
+func buildCompoundTrie(phrases []string) *compoundNode {
+	root := &compoundNode{}
+	for _, phrase := range phrases {
+		node := root
+		tokenType := KEYWORD
+		var kw *keyword
+		for start, end := 0, 0; start < len(phrase); start = end + 1 {
+			end = start
+			for end < len(phrase) && phrase[end] != ' ' {
+				end++
+			}
+			if kw = lookupKeyword(phrase[start:end]); kw == nil {
+				panic("compound keyword " + phrase + " is not made of keywords")
+			}
+			if kw.tokenType == COMMAND {
+				// e.g. INSERT INTO or LEFT JOIN
+				tokenType = COMMAND
+			}
+			if node.children == nil {
+				node.children = make(map[string]*compoundNode)
+			}
+			child := node.children[kw.value]
+			if child == nil {
+				child = &compoundNode{}
+				node.children[kw.value] = child
+			}
+			node = child
+		}
+		// the phrase is a command if one of its keywords is, and indicates a table if its last keyword does
+		node.terminal, node.tokenType, node.isTableIndicator = true, tokenType, kw.isTableIndicator
+	}
+	return root
+}
+
+// scanCompoundKeyword extends the keyword ending at the cursor to the longest compound keyword it starts,
+// its words being separated by whitespace, and returns the node of the compound keyword, or nil if there is none
+func (s *Lexer) scanCompoundKeyword(kw *keyword) *compoundNode {
+	node := compoundTrie.children[kw.value]
+	var match *compoundNode
+	matchEnd := s.cursor
+	for cursor := s.cursor; node != nil; {
+		// the next word follows whitespace and is followed by a punctuation, a space or the end of the input
+		wordStart := cursor
+		for wordStart < len(s.src) && isSpace(rune(s.src[wordStart])) {
+			wordStart++
+		}
+		if wordStart == cursor {
+			break
+		}
+		wordEnd := wordStart
+		for wordEnd < len(s.src) && isAsciiLetter(rune(s.src[wordEnd])) {
+			wordEnd++
+		}
+		if wordEnd < len(s.src) && !isPunctuation(rune(s.src[wordEnd])) && !isSpace(rune(s.src[wordEnd])) && s.src[wordEnd] != 0 {
+			break
+		}
+		next := lookupKeyword(s.src[wordStart:wordEnd])
+		if next == nil {
+			break
+		}
+		if node = node.children[next.value]; node != nil && node.terminal {
+			match, matchEnd = node, wordEnd
+		}
+		cursor = wordEnd
+	}
+	s.cursor = matchEnd
+	return match
+}
@@ -0,0 +1,88 @@
+package sqllexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLexerCompoundKeywords(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []TokenSpec
+	}{
+		{
+			name:  "clauses",
+			input: "SELECT a FROM t GROUP BY a ORDER\n  by b",
+			expected: []TokenSpec{
+				{COMMAND, "SELECT"}, {IDENT, "a"}, {KEYWORD, "FROM"}, {IDENT, "t"},
+				{KEYWORD, "GROUP BY"}, {IDENT, "a"}, {KEYWORD, "ORDER\n  by"}, {IDENT, "b"},
+			},
+		},
+		{
+			name:  "longest phrase",
+			input: "SELECT * FROM a LEFT OUTER JOIN b ON a.x IS NOT NULL",
+			expected: []TokenSpec{
+				{COMMAND, "SELECT"}, {WILDCARD, "*"}, {KEYWORD, "FROM"}, {IDENT, "a"},
+				{COMMAND, "LEFT OUTER JOIN"}, {IDENT, "b"}, {KEYWORD, "ON"}, {IDENT, "a.x"}, {KEYWORD, "IS NOT NULL"},
+			},
+		},
+		{
+			name:  "phrase prefix",
+			input: "SELECT a IS NOT TRUE, b LEFT OUTER",
+			expected: []TokenSpec{
+				{COMMAND, "SELECT"}, {IDENT, "a"}, {KEYWORD, "IS"}, {KEYWORD, "NOT"}, {BOOLEAN, "TRUE"}, {PUNCTUATION, ","},
+				{IDENT, "b"}, {KEYWORD, "LEFT"}, {KEYWORD, "OUTER"},
+			},
+		},
+		{
+			name:  "words separated by a comment",
+			input: "INSERT /* c */ INTO t SELECT a FROM t GROUP\n-- c\nBY a",
+			expected: []TokenSpec{
+				{COMMAND, "INSERT"}, {MULTILINE_COMMENT, "/* c */"}, {KEYWORD, "INTO"}, {IDENT, "t"},
+				{COMMAND, "SELECT"}, {IDENT, "a"}, {KEYWORD, "FROM"}, {IDENT, "t"},
+				{KEYWORD, "GROUP"}, {COMMENT, "-- c"}, {KEYWORD, "BY"}, {IDENT, "a"},
+			},
+		},
+		{
+			name:  "identifier starting with a keyword",
+			input: "DELETE FROM t WHERE a NOT INside",
+			expected: []TokenSpec{
+				{COMMAND, "DELETE FROM"}, {IDENT, "t"}, {KEYWORD, "WHERE"}, {IDENT, "a"}, {KEYWORD, "NOT"}, {IDENT, "INside"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lexer := New(tt.input, WithCompoundKeywords(true), WithSkipWhitespace(true))
+			var tokens []TokenSpec
+			for token := lexer.Scan(); token.Type != EOF; token = lexer.Scan() {
+				tokens = append(tokens, TokenSpec{token.Type, token.Value})
+			}
+			assert.Equal(t, tt.expected, tokens)
+		})
+	}
+
+	// the phrases which end with a table indicator are table indicators
+	lexer := New("INSERT INTO t", WithCompoundKeywords(true))
+	token := lexer.Scan()
+	assert.Equal(t, "INSERT INTO", token.Value)
+	assert.True(t, token.isTableIndicator)
+}
+
+func TestCompoundKeywordsObfuscatorAndNormalizer(t *testing.T) {
+	// the obfuscator and the normalizer scan the keywords one by one
+	query := "SELECT a FROM t WHERE b IS NULL GROUP BY a"
+	obfuscator := NewObfuscator(WithReplaceNull(true))
+	assert.Equal(t, obfuscator.Obfuscate(query), obfuscator.Obfuscate(query, WithCompoundKeywords(true)))
+
+	normalizer := NewNormalizer(WithCollectTables(true), WithCollectCommands(true))
+	expected, expectedMetadata, err := normalizer.Normalize(query)
+	assert.NoError(t, err)
+	normalized, metadata, err := normalizer.Normalize(query, WithCompoundKeywords(true))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, normalized)
+	assert.Equal(t, expectedMetadata, metadata)
+}
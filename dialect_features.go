@@ -0,0 +1,55 @@
+// Code generated by gen_dialects.go; DO NOT EDIT.
+
+package sqllexer
+
+// dialectFeature is a set of the behaviors of the lexer which depend on the DBMS
+type dialectFeature uint16
+
+const (
+	// dialectBacktickQuotes: ` quotes identifiers
+	dialectBacktickQuotes dialectFeature = 1 << iota
+	// dialectBracketQuotes: [ and ] quote identifiers
+	dialectBracketQuotes
+	// dialectHashComments: # starts a single line comment
+	dialectHashComments
+	// dialectHashIdentifiers: # starts an identifier, e.g. a temporary table
+	dialectHashIdentifiers
+	// dialectDollarIdentifiers: $ followed by a letter starts an identifier
+	dialectDollarIdentifiers
+	// dialectColonBindParameters: :name is a bind parameter
+	dialectColonBindParameters
+	// dialectAtIdentifiers: @name is an identifier, e.g. a stage, instead of a bind parameter
+	dialectAtIdentifiers
+	// dialectNullSafeEquals: <=> is the null-safe equality operator
+	dialectNullSafeEquals
+	// dialectPatternOperators: ~*, !~, !~*, ~~, ~~*, !~~ and !~~* match patterns
+	dialectPatternOperators
+	// dialectAssignmentOperators: +=, -=, *=, /=, %=, &= and |= are compound assignments
+	dialectAssignmentOperators
+	// dialectNegatedComparisons: !< and !> are comparison operators
+	dialectNegatedComparisons
+	// dialectOctalLiterals: 0o starts an octal number
+	dialectOctalLiterals
+	// dialectBinaryLiterals: 0b starts a binary number
+	dialectBinaryLiterals
+	// dialectIfFunction: IF( calls the IF function instead of starting a condition
+	dialectIfFunction
+)
+
+// dialectFeatures returns the features of the lexer for the DBMS, resolved once per input
+// instead of comparing the DBMS for each token
+func dialectFeatures(dbms DBMSType) dialectFeature {
+	switch dbms {
+	case DBMSSQLServer:
+		return dialectBracketQuotes | dialectHashIdentifiers | dialectDollarIdentifiers | dialectAssignmentOperators | dialectNegatedComparisons
+	case DBMSMySQL:
+		return dialectBacktickQuotes | dialectHashComments | dialectNullSafeEquals | dialectBinaryLiterals | dialectIfFunction
+	case DBMSPostgres:
+		return dialectPatternOperators | dialectOctalLiterals | dialectBinaryLiterals
+	case DBMSOracle:
+		return dialectColonBindParameters
+	case DBMSSnowflake:
+		return dialectAtIdentifiers
+	}
+	return dialectNullSafeEquals | dialectPatternOperators | dialectAssignmentOperators | dialectNegatedComparisons | dialectOctalLiterals | dialectBinaryLiterals
+}
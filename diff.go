@@ -0,0 +1,267 @@
+package sqllexer
+
+type diffConfig struct {
+	// LexerOptions are the options of the lexers of the compared queries, e.g. WithDBMS
+	LexerOptions []lexerOption `json:"-"`
+
+	// IgnoreWhitespace specifies whether spaces and newlines between tokens should be ignored
+	IgnoreWhitespace bool `json:"ignore_whitespace"`
+
+	// IgnoreComments specifies whether comments should be ignored
+	IgnoreComments bool `json:"ignore_comments"`
+
+	// IgnoreKeywordCase specifies whether keywords differing only by their case are equal, e.g. SELECT and select
+	IgnoreKeywordCase bool `json:"ignore_keyword_case"`
+
+	// IgnoreLiterals specifies whether literals are equal whatever their values and types, e.g. 1 and 'a'
+	IgnoreLiterals bool `json:"ignore_literals"`
+}
+
+type diffOption func(*diffConfig)
+
+func WithDiffLexerOptions(lexerOpts ...lexerOption) diffOption {
+	return func(c *diffConfig) {
+		c.LexerOptions = append(c.LexerOptions, lexerOpts...)
+	}
+}
+
+func WithDiffIgnoreWhitespace(ignoreWhitespace bool) diffOption {
+	return func(c *diffConfig) {
+		c.IgnoreWhitespace = ignoreWhitespace
+	}
+}
+
+func WithDiffIgnoreComments(ignoreComments bool) diffOption {
+	return func(c *diffConfig) {
+		c.IgnoreComments = ignoreComments
+	}
+}
+
+func WithDiffIgnoreKeywordCase(ignoreKeywordCase bool) diffOption {
+	return func(c *diffConfig) {
+		c.IgnoreKeywordCase = ignoreKeywordCase
+	}
+}
+
+func WithDiffIgnoreLiterals(ignoreLiterals bool) diffOption {
+	return func(c *diffConfig) {
+		c.IgnoreLiterals = ignoreLiterals
+	}
+}
+
+// DiffOperation is the kind of a difference between two queries
+type DiffOperation string
+
+const (
+	DiffInsert DiffOperation = "insert" // tokens only in the new query
+	DiffDelete DiffOperation = "delete" // tokens only in the old query
+	DiffChange DiffOperation = "change" // tokens of the old query replaced by tokens of the new query
+)
+
+// TokenDiff is a run of tokens that differ between two queries.
+// The byte ranges span from the first to the last differing token, and are empty for an insertion
+// in the old query or a deletion in the new query, at the position where the tokens were inserted or deleted.
+type TokenDiff struct {
+	Operation DiffOperation `json:"operation"`
+	Old       string        `json:"old,omitempty"`
+	New       string        `json:"new,omitempty"`
+	OldStart  int           `json:"old_start"`
+	OldEnd    int           `json:"old_end"`
+	NewStart  int           `json:"new_start"`
+	NewEnd    int           `json:"new_end"`
+}
+
+// diffToken is a compared token of a query
+type diffToken struct {
+	tokenType TokenType
+	value     string
+	start     int
+	end       int
+}
+
+// DiffTokens aligns the tokens of the old and new queries and returns the runs of tokens that differ, in order.
+// Tokens are equal if they have the same type and value. Queries with the same tokens have no differences.
+func DiffTokens(old, new string, opts ...diffOption) []TokenDiff {
+	config := &diffConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	oldTokens := config.tokens(old)
+	newTokens := config.tokens(new)
+
+	var diffs []TokenDiff
+	for _, edit := range diffEdits(oldTokens, newTokens, config.equal) {
+		diff := TokenDiff{
+			OldStart: diffPosition(oldTokens, edit.oldStart, len(old)),
+			NewStart: diffPosition(newTokens, edit.newStart, len(new)),
+		}
+		diff.OldEnd, diff.NewEnd = diff.OldStart, diff.NewStart
+		if edit.oldEnd > edit.oldStart {
+			diff.OldEnd = oldTokens[edit.oldEnd-1].end
+		}
+		if edit.newEnd > edit.newStart {
+			diff.NewEnd = newTokens[edit.newEnd-1].end
+		}
+		switch {
+		case edit.oldEnd == edit.oldStart:
+			diff.Operation = DiffInsert
+		case edit.newEnd == edit.newStart:
+			diff.Operation = DiffDelete
+		default:
+			diff.Operation = DiffChange
+		}
+		diff.Old, diff.New = old[diff.OldStart:diff.OldEnd], new[diff.NewStart:diff.NewEnd]
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+// EqualSQL returns true if the queries have the same tokens, ignoring the whitespace and the comments,
+// e.g. to assert that two queries are the same modulo formatting. The case of the keywords and the values
+// of the literals are also ignored WithDiffIgnoreKeywordCase and WithDiffIgnoreLiterals.
+func EqualSQL(a, b string, opts ...diffOption) bool {
+	config := &diffConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	config.IgnoreWhitespace, config.IgnoreComments = true, true
+	lexerA, lexerB := New(a, config.LexerOptions...), New(b, config.LexerOptions...)
+	for {
+		tokenA, tokenB := config.next(lexerA), config.next(lexerB)
+		if tokenA.Type == EOF || tokenB.Type == EOF {
+			return tokenA.Type == tokenB.Type
+		}
+		if !config.equal(diffToken{tokenType: tokenA.Type, value: tokenA.Value}, diffToken{tokenType: tokenB.Type, value: tokenB.Value}) {
+			return false
+		}
+	}
+}
+
+// tokens returns the compared tokens of the query
+func (c *diffConfig) tokens(query string) []diffToken {
+	var tokens []diffToken
+	lexer := New(query, c.LexerOptions...)
+	for token := c.next(lexer); token.Type != EOF; token = c.next(lexer) {
+		tokens = append(tokens, diffToken{tokenType: token.Type, value: token.Value, start: token.Start, end: token.End})
+	}
+	return tokens
+}
+
+// next returns the next compared token of the lexer, or its EOF token
+func (c *diffConfig) next(lexer *Lexer) *Token {
+	for {
+		token := lexer.Scan()
+		switch {
+		case token.Type == SPACE && c.IgnoreWhitespace:
+		case token.IsComment() && c.IgnoreComments:
+		default:
+			return token
+		}
+	}
+}
+
+// equal returns true if the tokens are equal: they have the same type and value, the case of the keywords
+// and the literals being ignored if configured
+func (c *diffConfig) equal(a, b diffToken) bool {
+	if c.IgnoreLiterals && isDiffLiteral(a.tokenType) && isDiffLiteral(b.tokenType) {
+		return true
+	}
+	if a.tokenType != b.tokenType {
+		return false
+	}
+	if c.IgnoreKeywordCase {
+		switch a.tokenType {
+		case KEYWORD, COMMAND, BOOLEAN, NULL, PROC_INDICATOR, CTE_INDICATOR, ALIAS_INDICATOR:
+			return equalFoldASCII(a.value, b.value)
+		}
+	}
+	return a.value == b.value
+}
+
+// isDiffLiteral returns true if the token type is a literal ignored WithDiffIgnoreLiterals
+func isDiffLiteral(tokenType TokenType) bool {
+	switch tokenType {
+	case NUMBER, STRING, INCOMPLETE_STRING, DOLLAR_QUOTED_STRING:
+		return true
+	}
+	return false
+}
+
+// diffPosition returns the byte offset of the i-th token, or the length of the query past the last token
+func diffPosition(tokens []diffToken, i int, length int) int {
+	if i < len(tokens) {
+		return tokens[i].start
+	}
+	return length
+}
+
+// diffEdit is a run of differing tokens, tokens[oldStart:oldEnd] of the old query being replaced by
+// tokens[newStart:newEnd] of the new query
+type diffEdit struct {
+	oldStart, oldEnd int
+	newStart, newEnd int
+}
+
+// diffEdits returns the runs of differing tokens of a shortest edit script, computed with Myers' algorithm
+func diffEdits(a, b []diffToken, equal func(a, b diffToken) bool) []diffEdit {
+	n, m := len(a), len(b)
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	var trace [][]int
+
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // insertion
+			} else {
+				x = v[offset+k-1] + 1 // deletion
+			}
+			y := x - k
+			for x < n && y < m && equal(a[x], b[y]) {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// backtrack the edit script from the end of both queries, trace[d] being the furthest points before step d
+	var edits []diffEdit
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		previous := trace[d]
+		k := x - y
+		var previousK int
+		if k == -d || (k != d && previous[offset+k-1] < previous[offset+k+1]) {
+			previousK = k + 1
+		} else {
+			previousK = k - 1
+		}
+		previousX := previous[offset+previousK]
+		previousY := previousX - previousK
+		for x > previousX && y > previousY {
+			// snake of equal tokens
+			x--
+			y--
+		}
+		edit := diffEdit{oldStart: previousX, oldEnd: x, newStart: previousY, newEnd: y}
+		if len(edits) > 0 && edits[len(edits)-1].oldStart == edit.oldEnd && edits[len(edits)-1].newStart == edit.newEnd {
+			// merge adjacent edits into a single run
+			edits[len(edits)-1].oldStart, edits[len(edits)-1].newStart = edit.oldStart, edit.newStart
+		} else {
+			edits = append(edits, edit)
+		}
+		x, y = previousX, previousY
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
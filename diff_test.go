@@ -0,0 +1,155 @@
+package sqllexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		opts     []diffOption
+		expected []TokenDiff
+	}{
+		{
+			name: "identical",
+			old:  "SELECT * FROM users",
+			new:  "SELECT * FROM users",
+		},
+		{
+			name: "change",
+			old:  "SELECT * FROM users WHERE id = 1",
+			new:  "SELECT * FROM accounts WHERE id = 1",
+			expected: []TokenDiff{
+				{Operation: DiffChange, Old: "users", New: "accounts", OldStart: 14, OldEnd: 19, NewStart: 14, NewEnd: 22},
+			},
+		},
+		{
+			name: "insert and delete",
+			old:  "SELECT id, name FROM users ORDER BY id",
+			new:  "SELECT id FROM users WHERE active = true ORDER BY id",
+			opts: []diffOption{WithDiffIgnoreWhitespace(true)},
+			expected: []TokenDiff{
+				{Operation: DiffDelete, Old: ", name", OldStart: 9, OldEnd: 15, NewStart: 10, NewEnd: 10},
+				{Operation: DiffInsert, New: "WHERE active = true", OldStart: 27, OldEnd: 27, NewStart: 21, NewEnd: 40},
+			},
+		},
+		{
+			name: "insert at the end",
+			old:  "SELECT 1",
+			new:  "SELECT 1 LIMIT 1",
+			expected: []TokenDiff{
+				{Operation: DiffInsert, New: " LIMIT 1", OldStart: 8, OldEnd: 8, NewStart: 8, NewEnd: 16},
+			},
+		},
+		{
+			name: "whitespace",
+			old:  "SELECT  a\nFROM t",
+			new:  "SELECT a FROM t",
+			expected: []TokenDiff{
+				{Operation: DiffChange, Old: "  ", New: " ", OldStart: 6, OldEnd: 8, NewStart: 6, NewEnd: 7},
+				{Operation: DiffChange, Old: "\n", New: " ", OldStart: 9, OldEnd: 10, NewStart: 8, NewEnd: 9},
+			},
+		},
+		{
+			name: "ignored whitespace and comments",
+			old:  "SELECT a /* old */\nFROM t",
+			new:  "SELECT a FROM t -- new",
+			opts: []diffOption{WithDiffIgnoreWhitespace(true), WithDiffIgnoreComments(true)},
+		},
+		{
+			name: "comments",
+			old:  "SELECT a /* old */ FROM t",
+			new:  "SELECT a /* new */ FROM t",
+			opts: []diffOption{WithDiffIgnoreWhitespace(true)},
+			expected: []TokenDiff{
+				{Operation: DiffChange, Old: "/* old */", New: "/* new */", OldStart: 9, OldEnd: 18, NewStart: 9, NewEnd: 18},
+			},
+		},
+		{
+			name: "dialect",
+			old:  "SELECT a FROM t # old",
+			new:  "SELECT a FROM t # new",
+			opts: []diffOption{WithDiffLexerOptions(WithDBMS(DBMSMySQL)), WithDiffIgnoreComments(true)},
+		},
+		{
+			name: "keyword case and literals",
+			old:  "SELECT a FROM t WHERE b = 1",
+			new:  "select a from t where b = 'x'",
+			opts: []diffOption{WithDiffIgnoreKeywordCase(true), WithDiffIgnoreLiterals(true)},
+		},
+		{
+			name: "empty",
+			old:  "",
+			new:  "SELECT 1",
+			expected: []TokenDiff{
+				{Operation: DiffInsert, New: "SELECT 1", OldStart: 0, OldEnd: 0, NewStart: 0, NewEnd: 8},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diffs := DiffTokens(test.old, test.new, test.opts...)
+			assert.Equal(t, test.expected, diffs)
+		})
+	}
+}
+
+func TestDiffTokensMinimal(t *testing.T) {
+	old := "SELECT a, b, c, d FROM t WHERE x = 1 AND y = 2"
+	new := "SELECT a, c, d, e FROM t WHERE x = 1 AND z = 2"
+	diffs := DiffTokens(old, new, WithDiffIgnoreWhitespace(true))
+	var changes []string
+	for _, diff := range diffs {
+		assert.Equal(t, diff.Old, old[diff.OldStart:diff.OldEnd])
+		assert.Equal(t, diff.New, new[diff.NewStart:diff.NewEnd])
+		changes = append(changes, fmt.Sprintf("%s %q %q", diff.Operation, diff.Old, diff.New))
+	}
+	assert.Equal(t, []string{`delete "b," ""`, `insert "" ", e"`, `change "y" "z"`}, changes)
+}
+
+func TestEqualSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		opts     []diffOption
+		expected bool
+	}{
+		{"formatting", "SELECT a,b FROM t", "SELECT a, b\n  FROM t", nil, true},
+		{"comments", "SELECT a /* c */ FROM t -- d", "SELECT a FROM t", nil, true},
+		{"keyword case", "SELECT a FROM t", "select a from t", nil, false},
+		{"ignored keyword case", "SELECT a FROM t", "select a from t", []diffOption{WithDiffIgnoreKeywordCase(true)}, true},
+		{"identifier case", "SELECT a FROM t", "SELECT A FROM t", []diffOption{WithDiffIgnoreKeywordCase(true)}, false},
+		{"literals", "SELECT a FROM t WHERE b = 1", "SELECT a FROM t WHERE b = 'x'", nil, false},
+		{"ignored literals", "SELECT a FROM t WHERE b = 1", "SELECT a FROM t WHERE b = 'x'", []diffOption{WithDiffIgnoreLiterals(true)}, true},
+		{"prefix", "SELECT a FROM t", "SELECT a FROM t WHERE b = 1", nil, false},
+		{"dialect", "SELECT a FROM t # c", "SELECT a FROM t", []diffOption{WithDiffLexerOptions(WithDBMS(DBMSMySQL))}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, EqualSQL(test.a, test.b, test.opts...))
+			assert.Equal(t, test.expected, EqualSQL(test.b, test.a, test.opts...))
+		})
+	}
+}
+
+func ExampleDiffTokens() {
+	diffs := DiffTokens(
+		"SELECT * FROM orders WHERE status = 'open'",
+		"SELECT id FROM orders WHERE status = 'open' LIMIT 10",
+		WithDiffIgnoreWhitespace(true),
+	)
+	for _, diff := range diffs {
+		fmt.Printf("%s %q -> %q\n", diff.Operation, diff.Old, diff.New)
+	}
+	// Output:
+	// change "*" -> "id"
+	// insert "" -> "LIMIT 10"
+}
@@ -0,0 +1,731 @@
+package sqllexer
+
+import "strings"
+
+// ExpressionKind is the kind of a node of an expression tree
+type ExpressionKind string
+
+const (
+	ExpressionAnd        ExpressionKind = "AND"
+	ExpressionOr         ExpressionKind = "OR"
+	ExpressionNot        ExpressionKind = "NOT"
+	ExpressionComparison ExpressionKind = "COMPARISON" // e.g. =, <>, <=
+	ExpressionIn         ExpressionKind = "IN"         // IN and NOT IN
+	ExpressionBetween    ExpressionKind = "BETWEEN"    // BETWEEN and NOT BETWEEN
+	ExpressionLike       ExpressionKind = "LIKE"       // e.g. LIKE, NOT ILIKE, SIMILAR TO
+	ExpressionIs         ExpressionKind = "IS"         // e.g. IS NULL, IS NOT TRUE, IS DISTINCT FROM
+	ExpressionExists     ExpressionKind = "EXISTS"
+	ExpressionOperation  ExpressionKind = "OPERATION" // arithmetic, string, JSON, cast and other operators
+	ExpressionFunction   ExpressionKind = "FUNCTION"
+	ExpressionCase       ExpressionKind = "CASE"
+	ExpressionColumn     ExpressionKind = "COLUMN"
+	ExpressionLiteral    ExpressionKind = "LITERAL"
+	ExpressionParameter  ExpressionKind = "PARAMETER"
+	ExpressionSubquery   ExpressionKind = "SUBQUERY"
+	ExpressionList       ExpressionKind = "LIST"  // e.g. the tuples of (a, b) IN ((1, 2))
+	ExpressionOther      ExpressionKind = "OTHER" // e.g. function arguments with a special syntax, as in CAST(x AS int)
+)
+
+// ExpressionNode is a node of an expression tree.
+// The operands of a node depend on its kind:
+//   - AND, OR: the combined predicates
+//   - NOT: the negated predicate
+//   - COMPARISON, OPERATION: the left and right operands, or the only operand of a unary or cast operator
+//   - IN: the tested expression, followed by the items of the list or by the subquery
+//   - BETWEEN: the tested expression, the lower bound and the upper bound
+//   - LIKE: the tested expression, the pattern and the escape character, if any
+//   - IS: the tested expression and what it is compared to, e.g. NULL
+//   - EXISTS: the subquery
+//   - FUNCTION: the arguments
+//   - CASE: the case operand, if any, followed by the conditions and results, and the ELSE result, if any
+//   - LIST: the items
+type ExpressionNode struct {
+	Kind     ExpressionKind    `json:"kind"`
+	Operator string            `json:"operator,omitempty"` // e.g. =, NOT IN, IS NOT, ::, +
+	Name     string            `json:"name,omitempty"`     // column or function name, or type of a cast
+	Operands []*ExpressionNode `json:"operands,omitempty"`
+	Subquery *SelectStatement  `json:"subquery,omitempty"`
+	Expression
+}
+
+// ParseExpression parses an expression, e.g. the condition of a WHERE clause or of a join
+func ParseExpression(expression string, lexerOpts ...lexerOption) (*ExpressionNode, error) {
+	return parseExpressionAt(expression, 0, lexerOpts...)
+}
+
+// Parse parses the expression into a tree, whose byte ranges are relative to the query the expression belongs to
+func (e Expression) Parse(lexerOpts ...lexerOption) (*ExpressionNode, error) {
+	return parseExpressionAt(e.Text, e.Start, lexerOpts...)
+}
+
+func parseExpressionAt(expression string, offset int, lexerOpts ...lexerOption) (*ExpressionNode, error) {
+	p, err := newParser(expression, offset, lexerOpts...)
+	if err != nil {
+		return nil, err
+	}
+	node, err := p.parseExpressionNode(precedenceLowest)
+	if err != nil {
+		return nil, err
+	}
+	if token := p.peek(); token.Type != EOF {
+		return nil, p.errorf("unexpected %q", token.Value)
+	}
+	return node, nil
+}
+
+// Walk calls fn for the node and its operands, depth first, as long as fn returns true.
+// Subqueries are not walked.
+func (n *ExpressionNode) Walk(fn func(*ExpressionNode) bool) bool {
+	if !fn(n) {
+		return false
+	}
+	for _, operand := range n.Operands {
+		if !operand.Walk(fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// NonSargable returns the predicates of the expression that prevent the use of an index on their column:
+// predicates testing a column wrapped in a function or an operation, e.g. lower(name) = 'x' or id + 1 = 2,
+// and LIKE predicates whose pattern starts with a wildcard, e.g. name LIKE '%x'.
+func (n *ExpressionNode) NonSargable() []*ExpressionNode {
+	var predicates []*ExpressionNode
+	n.Walk(func(node *ExpressionNode) bool {
+		if isNonSargable(node) {
+			predicates = append(predicates, node)
+		}
+		return true
+	})
+	return predicates
+}
+
+func isNonSargable(node *ExpressionNode) bool {
+	switch node.Kind {
+	case ExpressionComparison:
+		// a comparison of a plain column can use an index on that column, e.g. a.id = lower(b.name)
+		for _, operand := range node.Operands {
+			if operand.Kind == ExpressionColumn {
+				return false
+			}
+		}
+		for _, operand := range node.Operands {
+			if isWrappedColumn(operand) {
+				return true
+			}
+		}
+	case ExpressionIn, ExpressionBetween, ExpressionIs:
+		return isWrappedColumn(node.Operands[0])
+	case ExpressionLike:
+		if isWrappedColumn(node.Operands[0]) {
+			return true
+		}
+		pattern := node.Operands[1]
+		return pattern.Kind == ExpressionLiteral && len(pattern.Text) > 1 && (pattern.Text[1] == '%' || pattern.Text[1] == '_')
+	}
+	return false
+}
+
+// isWrappedColumn returns true if the node is a function, an operation or a CASE applied to a column
+func isWrappedColumn(node *ExpressionNode) bool {
+	if node.Kind != ExpressionFunction && node.Kind != ExpressionOperation && node.Kind != ExpressionCase {
+		return false
+	}
+	hasColumn := false
+	node.Walk(func(n *ExpressionNode) bool {
+		hasColumn = n.Kind == ExpressionColumn
+		return !hasColumn
+	})
+	return hasColumn
+}
+
+const (
+	precedenceLowest = iota
+	precedenceOr
+	precedenceAnd
+	precedenceNot
+	precedenceComparison
+	precedenceAdditive
+	precedenceMultiplicative
+	precedenceUnary
+	precedencePostfix
+)
+
+// comparisonOperators are the operators of comparison predicates
+var comparisonOperators = map[string]bool{
+	"=":   true,
+	"==":  true,
+	"<>":  true,
+	"!=":  true,
+	"<":   true,
+	">":   true,
+	"<=":  true,
+	">=":  true,
+	"<=>": true, // MySQL null-safe equality
+}
+
+// likeOperators are the words of pattern matching predicates
+var likeOperators = map[string]bool{
+	"LIKE":    true,
+	"ILIKE":   true,
+	"RLIKE":   true,
+	"REGEXP":  true,
+	"GLOB":    true,
+	"SIMILAR": true, // SIMILAR TO
+}
+
+// reservedExpressionWords are the keywords that cannot start an operand, even when followed by a parenthesis
+var reservedExpressionWords = map[string]bool{
+	"AND":     true,
+	"OR":      true,
+	"NOT":     true,
+	"IN":      true,
+	"IS":      true,
+	"BETWEEN": true,
+	"LIKE":    true,
+	"ILIKE":   true,
+	"EXISTS":  true,
+	"CASE":    true,
+	"WHEN":    true,
+	"THEN":    true,
+	"ELSE":    true,
+	"END":     true,
+}
+
+// node returns a node of the given kind, spanning the tokens from first to the current one, excluded
+func (p *parser) node(kind ExpressionKind, first int, operands ...*ExpressionNode) *ExpressionNode {
+	return &ExpressionNode{Kind: kind, Operands: operands, Expression: p.expression(first)}
+}
+
+// parseExpressionNode parses an expression whose operators have a precedence higher than the given one
+func (p *parser) parseExpressionNode(precedence int) (*ExpressionNode, error) {
+	first := p.pos
+	left, err := p.parsePrefix()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		token := p.peek()
+		negated := isWord(token, "NOT")
+		if negated {
+			token = p.peekAt(1)
+		}
+		word := upperWord(token)
+		predicate := precedence < precedenceComparison
+
+		switch {
+		case word == "OR" && !negated && precedence < precedenceOr,
+			word == "AND" && !negated && precedence < precedenceAnd:
+			kind := ExpressionKind(word)
+			operatorPrecedence := precedenceOr
+			if kind == ExpressionAnd {
+				operatorPrecedence = precedenceAnd
+			}
+			p.next()
+			right, err := p.parseExpressionNode(operatorPrecedence)
+			if err != nil {
+				return nil, err
+			}
+			if left.Kind == kind && left.Start == p.tokens[first].Start {
+				// a AND b AND c is a single node
+				left.Operands = append(left.Operands, right)
+				left.Expression = p.expression(first)
+			} else {
+				left = p.node(kind, first, left, right)
+			}
+		case predicate && token.Type == OPERATOR && comparisonOperators[token.Value] && !negated:
+			p.next()
+			right, err := p.parseExpressionNode(precedenceComparison)
+			if err != nil {
+				return nil, err
+			}
+			left = p.node(ExpressionComparison, first, left, right)
+			left.Operator = token.Value
+		case predicate && word == "IS" && !negated:
+			if left, err = p.parseIs(first, left); err != nil {
+				return nil, err
+			}
+		case predicate && word == "IN":
+			if left, err = p.parseIn(first, left, negated); err != nil {
+				return nil, err
+			}
+		case predicate && word == "BETWEEN":
+			if left, err = p.parseBetween(first, left, negated); err != nil {
+				return nil, err
+			}
+		case predicate && likeOperators[word]:
+			if left, err = p.parseLike(first, left, negated); err != nil {
+				return nil, err
+			}
+		default:
+			operation, err := p.parseOperation(first, left, precedence)
+			if err != nil {
+				return nil, err
+			}
+			if operation == nil {
+				return left, nil
+			}
+			left = operation
+		}
+	}
+}
+
+// parseOperation parses the operators binding tighter than comparisons, and returns nil if there is none
+func (p *parser) parseOperation(first int, left *ExpressionNode, precedence int) (*ExpressionNode, error) {
+	token := p.peek()
+	switch {
+	case token.Type == OPERATOR && token.Value == "::" && precedence < precedencePostfix:
+		p.next()
+		typeName, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().Type == PUNCTUATION && p.peek().Value == "(" {
+			// e.g. varchar(10)
+			if _, err = p.parseParenthesized(); err != nil {
+				return nil, err
+			}
+		}
+		node := p.node(ExpressionOperation, first, left)
+		node.Operator, node.Name = "::", typeName
+		return node, nil
+	case isWord(token, "COLLATE") && precedence < precedencePostfix:
+		p.next()
+		collation, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		node := p.node(ExpressionOperation, first, left)
+		node.Operator, node.Name = "COLLATE", collation
+		return node, nil
+	case token.Type == PUNCTUATION && token.Value == "[" && precedence < precedencePostfix:
+		// e.g. PostgreSQL array subscripts
+		p.next()
+		index, err := p.parseExpressionNode(precedenceLowest)
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expectPunctuation("]"); err != nil {
+			return nil, err
+		}
+		node := p.node(ExpressionOperation, first, left, index)
+		node.Operator = "[]"
+		return node, nil
+	}
+
+	operatorPrecedence := precedenceAdditive
+	switch {
+	case token.Type == OPERATOR && (token.Value == "*" || token.Value == "/" || token.Value == "%"):
+		operatorPrecedence = precedenceMultiplicative
+	case isWord(token, "DIV"), isWord(token, "MOD"):
+		operatorPrecedence = precedenceMultiplicative
+	case token.Type == OPERATOR && !comparisonOperators[token.Value] && token.Value != "?" && token.Value != ":",
+		token.Type == JSON_OP:
+	default:
+		return nil, nil
+	}
+	if precedence >= operatorPrecedence {
+		return nil, nil
+	}
+	p.next()
+	right, err := p.parseExpressionNode(operatorPrecedence)
+	if err != nil {
+		return nil, err
+	}
+	node := p.node(ExpressionOperation, first, left, right)
+	node.Operator = ToUpperASCII(token.Value)
+	return node, nil
+}
+
+// parseIs parses IS [NOT] NULL, TRUE, FALSE, UNKNOWN or DISTINCT FROM ...
+func (p *parser) parseIs(first int, left *ExpressionNode) (*ExpressionNode, error) {
+	p.next()
+	operator := "IS"
+	if p.acceptWords("NOT") {
+		operator += " NOT"
+	}
+	if p.acceptWords("DISTINCT", "FROM") {
+		operator += " DISTINCT FROM"
+	}
+	right, err := p.parseExpressionNode(precedenceComparison)
+	if err != nil {
+		return nil, err
+	}
+	node := p.node(ExpressionIs, first, left, right)
+	node.Operator = operator
+	return node, nil
+}
+
+// parseIn parses [NOT] IN (list) or [NOT] IN (subquery)
+func (p *parser) parseIn(first int, left *ExpressionNode, negated bool) (*ExpressionNode, error) {
+	operator := p.negation(negated) + "IN"
+	p.next()
+	operands := []*ExpressionNode{left}
+	if p.peek().Type != PUNCTUATION || p.peek().Value != "(" {
+		// e.g. PostgreSQL IN UNNEST(...) or a bind parameter holding the list
+		right, err := p.parsePrefix()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, right)
+	} else if isWord(p.peekAt(1), "SELECT") || isWord(p.peekAt(1), "WITH") {
+		subquery, err := p.parseSubquery()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, subquery)
+	} else {
+		p.next()
+		for {
+			item, err := p.parseExpressionNode(precedenceLowest)
+			if err != nil {
+				return nil, err
+			}
+			operands = append(operands, item)
+			if !p.acceptPunctuation(",") {
+				break
+			}
+		}
+		if err := p.expectPunctuation(")"); err != nil {
+			return nil, err
+		}
+	}
+	node := p.node(ExpressionIn, first, operands...)
+	node.Operator = operator
+	return node, nil
+}
+
+// parseBetween parses [NOT] BETWEEN [SYMMETRIC] low AND high
+func (p *parser) parseBetween(first int, left *ExpressionNode, negated bool) (*ExpressionNode, error) {
+	operator := p.negation(negated) + "BETWEEN"
+	p.next()
+	if p.acceptWords("SYMMETRIC") {
+		operator += " SYMMETRIC"
+	}
+	low, err := p.parseExpressionNode(precedenceComparison)
+	if err != nil {
+		return nil, err
+	}
+	if err = p.expectWords("AND"); err != nil {
+		return nil, err
+	}
+	high, err := p.parseExpressionNode(precedenceComparison)
+	if err != nil {
+		return nil, err
+	}
+	node := p.node(ExpressionBetween, first, left, low, high)
+	node.Operator = operator
+	return node, nil
+}
+
+// parseLike parses [NOT] LIKE pattern [ESCAPE character], and the other pattern matching predicates
+func (p *parser) parseLike(first int, left *ExpressionNode, negated bool) (*ExpressionNode, error) {
+	operator := p.negation(negated)
+	word := ToUpperASCII(p.next().Value)
+	operator += word
+	if word == "SIMILAR" {
+		if err := p.expectWords("TO"); err != nil {
+			return nil, err
+		}
+		operator += " TO"
+	}
+	pattern, err := p.parseExpressionNode(precedenceComparison)
+	if err != nil {
+		return nil, err
+	}
+	operands := []*ExpressionNode{left, pattern}
+	if p.acceptWords("ESCAPE") {
+		escape, err := p.parseExpressionNode(precedenceComparison)
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, escape)
+	}
+	node := p.node(ExpressionLike, first, operands...)
+	node.Operator = operator
+	return node, nil
+}
+
+// upperWord returns the upper case value of the token if it is an unquoted word, or an empty string
+func upperWord(token *Token) string {
+	if isWord(token, token.Value) {
+		return ToUpperASCII(token.Value)
+	}
+	return ""
+}
+
+// negation consumes the NOT preceding a predicate operator, if any, and returns its prefix
+func (p *parser) negation(negated bool) string {
+	if negated {
+		p.next()
+		return "NOT "
+	}
+	return ""
+}
+
+// parsePrefix parses an operand, possibly preceded by a unary operator
+func (p *parser) parsePrefix() (*ExpressionNode, error) {
+	first := p.pos
+	token := p.peek()
+	switch {
+	case token.Type == EOF:
+		return nil, p.errorf("expected an expression")
+	case isWord(token, "NOT"):
+		p.next()
+		operand, err := p.parseExpressionNode(precedenceNot)
+		if err != nil {
+			return nil, err
+		}
+		return p.node(ExpressionNot, first, operand), nil
+	case isWord(token, "EXISTS"):
+		p.next()
+		subquery, err := p.parseSubquery()
+		if err != nil {
+			return nil, err
+		}
+		node := p.node(ExpressionExists, first, subquery)
+		node.Subquery = subquery.Subquery
+		return node, nil
+	case isWord(token, "CASE"):
+		return p.parseCase()
+	case token.Type == OPERATOR && (token.Value == "-" || token.Value == "+" || token.Value == "~" || token.Value == "!"):
+		p.next()
+		operand, err := p.parseExpressionNode(precedenceUnary)
+		if err != nil {
+			return nil, err
+		}
+		node := p.node(ExpressionOperation, first, operand)
+		node.Operator = token.Value
+		return node, nil
+	case token.Type == PUNCTUATION && token.Value == "(":
+		if isWord(p.peekAt(1), "SELECT") || isWord(p.peekAt(1), "WITH") {
+			return p.parseSubquery()
+		}
+		p.next()
+		var items []*ExpressionNode
+		for {
+			item, err := p.parseExpressionNode(precedenceLowest)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			if !p.acceptPunctuation(",") {
+				break
+			}
+		}
+		if err := p.expectPunctuation(")"); err != nil {
+			return nil, err
+		}
+		if len(items) == 1 {
+			return items[0], nil
+		}
+		return p.node(ExpressionList, first, items...), nil
+	}
+	return p.parseOperandNode()
+}
+
+// parseOperandNode parses a column, a literal, a parameter or a function call
+func (p *parser) parseOperandNode() (*ExpressionNode, error) {
+	first := p.pos
+	token := p.next()
+	next := p.peek()
+	switch token.Type {
+	case NUMBER, STRING, INCOMPLETE_STRING, DOLLAR_QUOTED_STRING, BOOLEAN, NULL:
+		return p.node(ExpressionLiteral, first), nil
+	case POSITIONAL_PARAMETER, BIND_PARAMETER, PLACEHOLDER, SYSTEM_VARIABLE:
+		return p.node(ExpressionParameter, first), nil
+	case WILDCARD:
+		node := p.node(ExpressionColumn, first)
+		node.Name = token.Value
+		return node, nil
+	case OPERATOR:
+		switch {
+		case token.Value == "?":
+			return p.node(ExpressionParameter, first), nil
+		case (token.Value == ":" || token.Value == "%") && next.Start == token.End && next.Type == IDENT:
+			// e.g. :name or %s
+			p.next()
+			return p.node(ExpressionParameter, first), nil
+		}
+	case FUNCTION, IDENT, QUOTED_IDENT, KEYWORD, COMMAND:
+		word := ToUpperASCII(token.Value)
+		if next.Type == PUNCTUATION && next.Value == "(" && token.Type != QUOTED_IDENT && !reservedExpressionWords[word] {
+			return p.parseFunction(first, token.Value)
+		}
+		if token.Type == IDENT && (next.Type == STRING || next.Type == INCOMPLETE_STRING) {
+			// typed literals, e.g. DATE '2024-01-01' or INTERVAL '1 day'
+			p.next()
+			node := p.node(ExpressionLiteral, first)
+			node.Name = token.Value
+			return node, nil
+		}
+		if token.Type == IDENT || token.Type == QUOTED_IDENT {
+			p.pos = first
+			name, err := p.parseName()
+			if err != nil {
+				return nil, err
+			}
+			node := p.node(ExpressionColumn, first)
+			node.Name = name
+			return node, nil
+		}
+	}
+	p.pos = first
+	return nil, p.errorf("unexpected %q", token.Value)
+}
+
+// parseFunction parses the arguments of a function call, and its FILTER and OVER clauses
+func (p *parser) parseFunction(first int, name string) (*ExpressionNode, error) {
+	p.next()
+	var arguments []*ExpressionNode
+	if !p.acceptPunctuation(")") {
+		for {
+			argument := p.parseArgument()
+			arguments = append(arguments, argument)
+			if !p.acceptPunctuation(",") {
+				break
+			}
+		}
+		if err := p.expectPunctuation(")"); err != nil {
+			return nil, err
+		}
+	}
+	for _, clause := range []string{"WITHIN", "FILTER", "OVER"} {
+		if !isWord(p.peek(), clause) {
+			continue
+		}
+		p.next()
+		p.acceptWords("GROUP") // WITHIN GROUP
+		if p.acceptPunctuation("(") {
+			p.parseUntil()
+			if err := p.expectPunctuation(")"); err != nil {
+				return nil, err
+			}
+		} else if _, err := p.parseName(); err != nil {
+			// e.g. OVER w
+			return nil, err
+		}
+	}
+	node := p.node(ExpressionFunction, first, arguments...)
+	node.Name = name
+	return node, nil
+}
+
+// parseArgument parses a function argument. Arguments with a special syntax, e.g. CAST(x AS int)
+// or EXTRACT(YEAR FROM x), are returned as OTHER nodes.
+func (p *parser) parseArgument() *ExpressionNode {
+	first := p.pos
+	p.acceptWords("DISTINCT")
+	argument, err := p.parseExpressionNode(precedenceLowest)
+	if next := p.peek(); err == nil && next.Type == PUNCTUATION && (next.Value == "," || next.Value == ")") {
+		return argument
+	}
+	p.pos = first
+	depth := 0
+	for {
+		token := p.peek()
+		if token.Type == EOF || (token.Type == PUNCTUATION && depth == 0 && (token.Value == "," || token.Value == ")")) {
+			break
+		}
+		if token.Type == PUNCTUATION && token.Value == "(" {
+			depth++
+		} else if token.Type == PUNCTUATION && token.Value == ")" {
+			depth--
+		}
+		p.next()
+	}
+	if p.pos == first {
+		return &ExpressionNode{Kind: ExpressionOther, Expression: Expression{Start: p.peek().Start, End: p.peek().Start}}
+	}
+	return p.node(ExpressionOther, first)
+}
+
+// parseSubquery parses a parenthesized SELECT
+func (p *parser) parseSubquery() (*ExpressionNode, error) {
+	first := p.pos
+	if err := p.expectPunctuation("("); err != nil {
+		return nil, err
+	}
+	subquery, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+	if err = p.expectPunctuation(")"); err != nil {
+		return nil, err
+	}
+	node := p.node(ExpressionSubquery, first)
+	node.Subquery = subquery
+	return node, nil
+}
+
+// parseCase parses CASE [operand] WHEN condition THEN result ... [ELSE result] END
+func (p *parser) parseCase() (*ExpressionNode, error) {
+	first := p.pos
+	p.next()
+	var operands []*ExpressionNode
+	if !isWord(p.peek(), "WHEN") {
+		operand, err := p.parseExpressionNode(precedenceLowest)
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+	for p.acceptWords("WHEN") {
+		condition, err := p.parseExpressionNode(precedenceLowest)
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expectWords("THEN"); err != nil {
+			return nil, err
+		}
+		result, err := p.parseExpressionNode(precedenceLowest)
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, condition, result)
+	}
+	if p.acceptWords("ELSE") {
+		result, err := p.parseExpressionNode(precedenceLowest)
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, result)
+	}
+	if err := p.expectWords("END"); err != nil {
+		return nil, err
+	}
+	return p.node(ExpressionCase, first, operands...), nil
+}
+
+// String returns the expression tree in a prefix notation, e.g. (AND (= a 1) (IN b 1 2)), mostly for debugging
+func (n *ExpressionNode) String() string {
+	var builder strings.Builder
+	n.writeTo(&builder)
+	return builder.String()
+}
+
+func (n *ExpressionNode) writeTo(builder *strings.Builder) {
+	switch n.Kind {
+	case ExpressionColumn, ExpressionLiteral, ExpressionParameter, ExpressionSubquery, ExpressionOther:
+		builder.WriteString(n.Text)
+		return
+	}
+	builder.WriteString("(")
+	switch {
+	case n.Operator != "":
+		builder.WriteString(n.Operator)
+	case n.Kind == ExpressionFunction:
+		builder.WriteString(n.Name)
+	default:
+		builder.WriteString(string(n.Kind))
+	}
+	if n.Kind == ExpressionOperation && n.Name != "" {
+		builder.WriteString(" " + n.Name)
+	}
+	for _, operand := range n.Operands {
+		builder.WriteString(" ")
+		operand.writeTo(builder)
+	}
+	builder.WriteString(")")
+}
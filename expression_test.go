@@ -0,0 +1,154 @@
+package sqllexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    "a = 1 AND b IN (1, 2) AND NOT c LIKE '%x' OR d BETWEEN 1 AND 10",
+			expected: "(OR (AND (= a 1) (IN b 1 2) (NOT (LIKE c '%x'))) (BETWEEN d 1 10))",
+		},
+		{
+			input:    "a = 1 AND (b = 2 AND c = 3)",
+			expected: "(AND (= a 1) (AND (= b 2) (= c 3)))",
+		},
+		{
+			input:    "lower(name) = 'x' AND created_at::date = $1 AND x IS NOT NULL AND y IS DISTINCT FROM z",
+			expected: "(AND (= (lower name) 'x') (= (:: date created_at) $1) (IS NOT x NULL) (IS DISTINCT FROM y z))",
+		},
+		{
+			input:    "a NOT BETWEEN SYMMETRIC 1 AND 2 AND b NOT IN (?) AND c NOT ILIKE '_a' ESCAPE '!' AND d SIMILAR TO 'x'",
+			expected: "(AND (NOT BETWEEN SYMMETRIC a 1 2) (NOT IN b ?) (NOT ILIKE c '_a' '!') (SIMILAR TO d 'x'))",
+		},
+		{
+			input:    "EXISTS (SELECT 1 FROM t WHERE t.id = u.id) AND u.id NOT IN (SELECT id FROM b)",
+			expected: "(AND (EXISTS (SELECT 1 FROM t WHERE t.id = u.id)) (NOT IN u.id (SELECT id FROM b)))",
+		},
+		{
+			input:    "CAST(x AS int) > 3 AND count(*) OVER (PARTITION BY z) > 1 AND coalesce(a, b, now()) < c",
+			expected: "(AND (> (CAST x AS int) 3) (> (count *) 1) (< (coalesce a b (now)) c))",
+		},
+		{
+			input:    "CASE WHEN a > 1 THEN 'x' ELSE 'y' END = 'x' AND (a, b) IN ((1, 2), (3, 4))",
+			expected: "(AND (= (CASE (> a 1) 'x' 'y') 'x') (IN (LIST a b) (LIST 1 2) (LIST 3 4)))",
+		},
+		{
+			input:    "a = :name AND b = %s AND c * 2 / 3 + 1 > -d AND DATE '2024-01-01' < e",
+			expected: "(AND (= a :name) (= b %s) (> (+ (/ (* c 2) 3) 1) (- d)) (< DATE '2024-01-01' e))",
+		},
+		{
+			input:    "j->>'k' = 'v' AND tags[1] = 'a' AND name COLLATE \"C\" = 'b' AND x DIV 2 = 1",
+			expected: "(AND (= (->> j 'k') 'v') (= ([] tags 1) 'a') (= (COLLATE \"C\" name) 'b') (= (DIV x 2) 1))",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			node, err := ParseExpression(test.input)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, node.String())
+			node.Walk(func(n *ExpressionNode) bool {
+				assert.Equal(t, n.Text, test.input[n.Start:n.End])
+				return true
+			})
+		})
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		message string
+		offset  int
+	}{
+		{input: "", message: "expected an expression", offset: 0},
+		{input: "a =", message: "expected an expression", offset: 3},
+		{input: "a b", message: `unexpected "b"`, offset: 2},
+		{input: "a BETWEEN 1 OR 2", message: "expected AND", offset: 12},
+		{input: "a IN (1, 2", message: `expected ")"`, offset: 10},
+		{input: "CASE WHEN a THEN b", message: "expected END", offset: 18},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			node, err := ParseExpression(test.input)
+			assert.Nil(t, node)
+			assert.Equal(t, &ParseError{Message: test.message, Offset: test.offset}, err)
+		})
+	}
+}
+
+func TestExpressionNodeNonSargable(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{input: "id = 1 AND name LIKE 'a%' AND a.id = lower(b.name)", expected: nil},
+		{input: "lower(name) = 'x'", expected: []string{"lower(name) = 'x'"}},
+		{input: "id + 1 = ? OR created_at::date = $1", expected: []string{"id + 1 = ?", "created_at::date = $1"}},
+		{input: "name LIKE '%x' AND code LIKE '_1'", expected: []string{"name LIKE '%x'", "code LIKE '_1'"}},
+		{input: "year(d) BETWEEN 1 AND 2 AND upper(c) IN ('A') AND trim(n) IS NULL", expected: []string{"year(d) BETWEEN 1 AND 2", "upper(c) IN ('A')", "trim(n) IS NULL"}},
+		{input: "CASE WHEN a THEN b END = 1", expected: []string{"CASE WHEN a THEN b END = 1"}},
+		{input: "now() - interval '1 day' < created_at", expected: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			node, err := ParseExpression(test.input)
+			assert.NoError(t, err)
+			var texts []string
+			for _, predicate := range node.NonSargable() {
+				texts = append(texts, predicate.Text)
+			}
+			assert.Equal(t, test.expected, texts)
+		})
+	}
+}
+
+func TestExpressionParse(t *testing.T) {
+	query := "SELECT * FROM users u JOIN orders o ON o.user_id = u.id WHERE lower(u.email) = ? AND o.total > 10"
+	statement, err := Parse(query)
+	assert.NoError(t, err)
+	selectStatement := statement.(*SelectStatement)
+
+	where, err := selectStatement.Where.Parse()
+	assert.NoError(t, err)
+	assert.Equal(t, ExpressionAnd, where.Kind)
+	where.Walk(func(n *ExpressionNode) bool {
+		assert.Equal(t, n.Text, query[n.Start:n.End])
+		return true
+	})
+	nonSargable := where.NonSargable()
+	assert.Len(t, nonSargable, 1)
+	assert.Equal(t, "lower(u.email) = ?", query[nonSargable[0].Start:nonSargable[0].End])
+
+	on, err := selectStatement.Joins[0].On.Parse()
+	assert.NoError(t, err)
+	assert.Equal(t, "(= o.user_id u.id)", on.String())
+
+	_, err = Expression{Text: "a = ", Start: 10, End: 14}.Parse()
+	assert.Equal(t, &ParseError{Message: "expected an expression", Offset: 14}, err)
+}
+
+func ExampleExpressionNode_NonSargable() {
+	predicate, err := ParseExpression("status = 'active' AND lower(email) = ? AND name LIKE '%smith'")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(predicate)
+	for _, nonSargable := range predicate.NonSargable() {
+		fmt.Println(nonSargable.Text)
+	}
+	// Output:
+	// (AND (= status 'active') (= (lower email) ?) (LIKE name '%smith'))
+	// lower(email) = ?
+	// name LIKE '%smith'
+}
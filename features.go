@@ -0,0 +1,120 @@
+package sqllexer
+
+// QueryFeatures are the features a query uses, detected from its tokens, e.g. for routing layers
+// to send locking reads to primaries. Features of subqueries are features of the query.
+type QueryFeatures struct {
+	Distinct bool `json:"distinct"`
+	GroupBy  bool `json:"group_by"`
+	// OrderBy is true if the query is sorted, ORDER BY of window definitions excluded
+	OrderBy bool `json:"order_by"`
+	Having  bool `json:"having"`
+	// Limit is true if the rows are limited by LIMIT, TOP or FETCH FIRST|NEXT
+	Limit bool `json:"limit"`
+	// Window is true if the query uses window functions, with OVER
+	Window bool `json:"window"`
+	// CTE is true if the query defines common table expressions, with WITH
+	CTE bool `json:"cte"`
+	// ForUpdate is true if the query locks rows for update, with FOR UPDATE, FOR NO KEY UPDATE,
+	// or the UPDLOCK and XLOCK table hints of SQL Server
+	ForUpdate bool `json:"for_update"`
+	// ForShare is true if the query locks rows for reading, with FOR SHARE, FOR KEY SHARE, LOCK IN SHARE MODE,
+	// or the HOLDLOCK table hint of SQL Server
+	ForShare bool `json:"for_share"`
+	// SkipLocked is true if locked rows are skipped, with SKIP LOCKED or the READPAST table hint of SQL Server
+	SkipLocked bool `json:"skip_locked"`
+	// NoWait is true if the query fails instead of waiting for locked rows, with NOWAIT
+	NoWait bool `json:"no_wait"`
+}
+
+// Features returns the features of the query
+func Features(query string, lexerOpts ...lexerOption) QueryFeatures {
+	tokens := make([]Token, 0, estimateValueTokens(len(query)))
+	lexer := New(query, lexerOpts...)
+	for {
+		token := lexer.Scan()
+		if token.Type == EOF {
+			break
+		}
+		if isValueToken(token) {
+			tokens = append(tokens, *token)
+		}
+	}
+
+	var features QueryFeatures
+	var windowParentheses []bool // whether each enclosing parenthesis holds a window definition
+	windowClause := false        // true while reading the window definitions of a WINDOW clause
+	inWindow := func() bool {
+		for _, window := range windowParentheses {
+			if window {
+				return true
+			}
+		}
+		return false
+	}
+	// is returns true if the value of the token at index i is the word
+	is := func(i int, word string) bool {
+		return i < len(tokens) && equalFoldASCII(tokens[i].Value, word)
+	}
+
+	for i := range tokens {
+		token := &tokens[i]
+		switch token.Type {
+		case PUNCTUATION:
+			switch token.Value {
+			case "(":
+				window := i > 0 && (is(i-1, "OVER") || (windowClause && tokens[i-1].Type == ALIAS_INDICATOR))
+				windowParentheses = append(windowParentheses, window)
+			case ")":
+				if len(windowParentheses) > 0 {
+					windowParentheses = windowParentheses[:len(windowParentheses)-1]
+				}
+			case ";":
+				windowParentheses, windowClause = windowParentheses[:0], false
+			}
+			continue
+		case CTE_INDICATOR:
+			// WITH also introduces the table hints of SQL Server
+			if i == 0 || (tokens[i-1].Type == PUNCTUATION && (tokens[i-1].Value == ";" || tokens[i-1].Value == "(")) {
+				features.CTE = true
+			}
+			continue
+		case KEYWORD:
+			if len(windowParentheses) == 0 && !is(i, "WINDOW") {
+				windowClause = false
+			}
+		}
+
+		switch {
+		case is(i, "DISTINCT"):
+			features.Distinct = true
+		case is(i, "GROUP") && is(i+1, "BY"):
+			features.GroupBy = true
+		case is(i, "ORDER") && is(i+1, "BY") && !inWindow():
+			features.OrderBy = true
+		case is(i, "HAVING"):
+			features.Having = true
+		case is(i, "LIMIT"), is(i, "TOP"), is(i, "FETCH") && (is(i+1, "FIRST") || is(i+1, "NEXT")):
+			features.Limit = true
+		case is(i, "OVER") && i+1 < len(tokens) && (tokens[i+1].Value == "(" || tokens[i+1].Type == IDENT):
+			features.Window = true
+		case is(i, "WINDOW"):
+			windowClause = true
+		case is(i, "FOR") && (is(i+1, "UPDATE") || (is(i+1, "NO") && is(i+2, "KEY") && is(i+3, "UPDATE"))):
+			features.ForUpdate = true
+		case is(i, "FOR") && (is(i+1, "SHARE") || (is(i+1, "KEY") && is(i+2, "SHARE"))),
+			is(i, "LOCK") && is(i+1, "IN") && is(i+2, "SHARE") && is(i+3, "MODE"):
+			features.ForShare = true
+		case is(i, "SKIP") && is(i+1, "LOCKED"):
+			features.SkipLocked = true
+		case is(i, "NOWAIT"):
+			features.NoWait = true
+		case token.Type == IDENT && (is(i, "UPDLOCK") || is(i, "XLOCK")):
+			features.ForUpdate = true
+		case token.Type == IDENT && is(i, "HOLDLOCK"):
+			features.ForShare = true
+		case token.Type == IDENT && is(i, "READPAST"):
+			features.SkipLocked = true
+		}
+	}
+	return features
+}
@@ -0,0 +1,97 @@
+package sqllexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatures(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		lexerOpts []lexerOption
+		expected  QueryFeatures
+	}{
+		{
+			name:     "plain select",
+			input:    "SELECT a FROM t WHERE b = 'ORDER BY c FOR UPDATE' -- LIMIT 1",
+			expected: QueryFeatures{},
+		},
+		{
+			name:     "aggregation",
+			input:    "SELECT DISTINCT a, count(*) FROM t GROUP BY a HAVING count(*) > 1 ORDER BY a LIMIT 10",
+			expected: QueryFeatures{Distinct: true, GroupBy: true, Having: true, OrderBy: true, Limit: true},
+		},
+		{
+			name:     "window function",
+			input:    "SELECT row_number() OVER (PARTITION BY b ORDER BY c) FROM t",
+			expected: QueryFeatures{Window: true},
+		},
+		{
+			name:     "named window",
+			input:    "SELECT sum(a) OVER w FROM t WINDOW w AS (ORDER BY a), v AS (ORDER BY b) ORDER BY a",
+			expected: QueryFeatures{Window: true, OrderBy: true},
+		},
+		{
+			name:     "column named over",
+			input:    "SELECT over FROM t",
+			expected: QueryFeatures{},
+		},
+		{
+			name:     "CTE",
+			input:    "WITH x AS (SELECT 1) SELECT * FROM x",
+			expected: QueryFeatures{CTE: true},
+		},
+		{
+			name:     "CTE in a subquery",
+			input:    "SELECT * FROM (WITH x AS (SELECT 1) SELECT * FROM x) y",
+			expected: QueryFeatures{CTE: true},
+		},
+		{
+			name:     "locking read skipping locked rows",
+			input:    "SELECT id FROM jobs WHERE state = 'ready' ORDER BY id LIMIT 1 FOR UPDATE SKIP LOCKED",
+			expected: QueryFeatures{OrderBy: true, Limit: true, ForUpdate: true, SkipLocked: true},
+		},
+		{
+			name:     "for no key update",
+			input:    "SELECT * FROM t FOR NO KEY UPDATE OF t NOWAIT",
+			expected: QueryFeatures{ForUpdate: true, NoWait: true},
+		},
+		{
+			name:     "for share",
+			input:    "SELECT * FROM t FOR KEY SHARE",
+			expected: QueryFeatures{ForShare: true},
+		},
+		{
+			name:      "lock in share mode",
+			input:     "SELECT * FROM t LOCK IN SHARE MODE",
+			lexerOpts: []lexerOption{WithDBMS(DBMSMySQL)},
+			expected:  QueryFeatures{ForShare: true},
+		},
+		{
+			name:      "SQL Server table hints",
+			input:     "SELECT TOP 5 * FROM t WITH (UPDLOCK, READPAST) ORDER BY id",
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+			expected:  QueryFeatures{Limit: true, ForUpdate: true, SkipLocked: true, OrderBy: true},
+		},
+		{
+			name:     "fetch first",
+			input:    "SELECT * FROM t ORDER BY a OFFSET 5 ROWS FETCH NEXT 5 ROWS ONLY",
+			expected: QueryFeatures{OrderBy: true, Limit: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Features(tt.input, tt.lexerOpts...))
+		})
+	}
+}
+
+func ExampleFeatures() {
+	features := Features("SELECT * FROM accounts WHERE id = 1 FOR UPDATE")
+	fmt.Println(features.ForUpdate || features.ForShare)
+	// Output: true
+}
@@ -0,0 +1,47 @@
+package sqllexer
+
+const (
+	// fnv64Offset and fnv64Prime are the 64-bit FNV-1a parameters
+	fnv64Offset uint64 = 14695981039346656037
+	fnv64Prime  uint64 = 1099511628211
+)
+
+var (
+	// fingerprintObfuscator and fingerprintNormalizer define the canonical form hashed by Fingerprint.
+	// Changing their options changes every fingerprint, so they must be kept stable.
+	fingerprintObfuscator = NewObfuscator(
+		WithReplaceDigits(true),
+		WithReplacePositionalParameter(true),
+		WithReplaceBoolean(true),
+		WithReplaceNull(true),
+		WithReplaceBindParameter(true),
+	)
+	fingerprintNormalizer = NewNormalizer(
+		WithUppercaseKeywords(true),
+		WithCanonicalizeInList(true),
+	)
+)
+
+// Fingerprint returns a stable 64-bit fingerprint of the query.
+// The query is obfuscated and normalized (literals, digits in identifiers, parameters, booleans and nulls
+// are replaced with placeholders, keywords are uppercased, aliases, comments and the trailing semicolon
+// are removed and literal-only IN lists are collapsed), then the normalized SQL is hashed with 64-bit FNV-1a.
+// Queries differing only in literal values, formatting or keyword case share the same fingerprint.
+func Fingerprint(query string, lexerOpts ...lexerOption) uint64 {
+	normalizedSQL, _, err := ObfuscateAndNormalize(query, fingerprintObfuscator, fingerprintNormalizer, lexerOpts...)
+	if err != nil {
+		// fall back to the obfuscated query so that literals are never part of the fingerprint input
+		normalizedSQL = fingerprintObfuscator.Obfuscate(query, lexerOpts...)
+	}
+	return FingerprintNormalizedSQL(normalizedSQL)
+}
+
+// FingerprintNormalizedSQL returns the 64-bit FNV-1a hash of the bytes of an already normalized SQL statement.
+func FingerprintNormalizedSQL(normalizedSQL string) uint64 {
+	hash := fnv64Offset
+	for i := 0; i < len(normalizedSQL); i++ {
+		hash ^= uint64(normalizedSQL[i])
+		hash *= fnv64Prime
+	}
+	return hash
+}
@@ -0,0 +1,102 @@
+package sqllexer
+
+import (
+	"fmt"
+	"hash/fnv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		queries   []string
+		lexerOpts []lexerOption
+	}{
+		{
+			queries: []string{
+				"SELECT * FROM users WHERE id = 1",
+				"select * from users where id = 42",
+				"SELECT *\n  FROM users\n WHERE id = '42';",
+				"/* comment */ SELECT * FROM users AS u WHERE id = ?",
+			},
+		},
+		{
+			queries: []string{
+				"SELECT * FROM users WHERE id IN (1, 2, 3)",
+				"SELECT * FROM users WHERE id IN (3, 1, 2)",
+				"SELECT * FROM users WHERE id IN (?)",
+			},
+		},
+		{
+			queries: []string{
+				"SELECT * FROM users WHERE id = $1 AND active = true",
+				"SELECT * FROM users WHERE id = $2 AND active = false",
+			},
+			lexerOpts: []lexerOption{WithDBMS(DBMSPostgres)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			expected := Fingerprint(test.queries[0], test.lexerOpts...)
+			for _, query := range test.queries[1:] {
+				assert.Equal(t, expected, Fingerprint(query, test.lexerOpts...), query)
+			}
+		})
+	}
+
+	assert.NotEqual(t, Fingerprint("SELECT * FROM users WHERE id = 1"), Fingerprint("SELECT * FROM orders WHERE id = 1"))
+}
+
+// TestFingerprintGolden pins the fingerprints of representative queries, which must not change across releases
+// as they are stored and compared by consumers
+func TestFingerprintGolden(t *testing.T) {
+	tests := []struct {
+		query     string
+		lexerOpts []lexerOption
+		expected  uint64
+	}{
+		{
+			query:    "SELECT * FROM users WHERE id = 1",
+			expected: 10010606031982395717,
+		},
+		{
+			query:    "SELECT u.name, count(*) FROM users u JOIN orders o ON o.user_id = u.id WHERE o.status IN ('paid', 'shipped') GROUP BY u.name",
+			expected: 6060826963609073295,
+		},
+		{
+			query:    "INSERT INTO logs (level, message) VALUES ('error', 'disk full'), ('info', 'started')",
+			expected: 11668926015938344027,
+		},
+		{
+			query:     "UPDATE accounts SET balance = balance - $1 WHERE id = $2 RETURNING balance",
+			lexerOpts: []lexerOption{WithDBMS(DBMSPostgres)},
+			expected:  7529766092015356671,
+		},
+		{
+			query:     "SELECT TOP 10 [Name] FROM [dbo].[Users] WHERE [Id] = @id",
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+			expected:  10963421748346673695,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			assert.Equal(t, test.expected, Fingerprint(test.query, test.lexerOpts...), test.query)
+		})
+	}
+}
+
+func TestFingerprintNormalizedSQL(t *testing.T) {
+	for _, input := range []string{"", "a", "SELECT * FROM users WHERE id = ?", "SELECT * FROM 世界"} {
+		h := fnv.New64a()
+		h.Write([]byte(input))
+		assert.Equal(t, h.Sum64(), FingerprintNormalizedSQL(input))
+	}
+}
+
+func ExampleFingerprint() {
+	fmt.Println(Fingerprint("SELECT * FROM users WHERE id = 1") == Fingerprint("select * from users where id = 2"))
+	// Output: true
+}
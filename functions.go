@@ -0,0 +1,76 @@
+package sqllexer
+
+// functionKeywordSpec is a keyword which is also the name of a function, and the feature of the dialects
+// in which it is one
+type functionKeywordSpec struct {
+	value   string
+	feature dialectFeature // 0 if the keyword is a function in every dialect
+}
+
+// functionKeywords are the keywords followed by a parenthesis which call a function, e.g. LEFT(name, 2).
+// The other keywords are reserved: followed by a parenthesis, e.g. VALUES(1) or IN (1, 2), they are keywords.
+var functionKeywords = []functionKeywordSpec{
+	{"LEFT", 0},
+	{"RIGHT", 0},
+	{"REPLACE", 0},
+	{"IF", dialectIfFunction},
+}
+
+// isFunctionKeyword reports whether the keyword is the name of a function in the dialect
+func (s *Lexer) isFunctionKeyword(kw *keyword) bool {
+	for _, fn := range functionKeywords {
+		if fn.value == kw.value {
+			return fn.feature == 0 || s.dialect&fn.feature != 0
+		}
+	}
+	return false
+}
+
+// builtinFunctions are the functions common to the dialects, whose calls may have whitespace before
+// the parenthesis, e.g. count (*). Other names followed by whitespace and a parenthesis are identifiers,
+// e.g. the table of INSERT INTO t (a) or the alias of AS d (id).
+var builtinFunctions = []string{
+	"COUNT",
+	"SUM",
+	"AVG",
+	"MIN",
+	"MAX",
+	"COALESCE",
+	"NULLIF",
+	"CAST",
+	"CONCAT",
+	"SUBSTRING",
+	"UPPER",
+	"LOWER",
+	"TRIM",
+	"LENGTH",
+	"ROUND",
+	"ABS",
+	"NOW",
+	"ROW_NUMBER",
+	"RANK",
+	"DENSE_RANK",
+}
+
+// isBuiltinFunction reports whether the name is one of the builtinFunctions
+func isBuiltinFunction(name string) bool {
+	for _, fn := range builtinFunctions {
+		if equalFoldASCII(name, fn) {
+			return true
+		}
+	}
+	return false
+}
+
+// isFunctionCall reports whether the name ending at the cursor is followed by a parenthesis,
+// with whitespace in between if allowSpace, e.g. count (*)
+func (s *Lexer) isFunctionCall(allowSpace bool) bool {
+	if s.config.NoFunctions {
+		return false
+	}
+	i := s.cursor
+	for allowSpace && i < len(s.src) && isSpace(rune(s.src[i])) {
+		i++
+	}
+	return i < len(s.src) && s.src[i] == '('
+}
@@ -0,0 +1,48 @@
+package sqllexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLexerFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		dbms     DBMSType
+		opts     []lexerOption
+		expected []TokenSpec
+	}{
+		{"IF(a,1)", DBMSMySQL, nil, []TokenSpec{{FUNCTION, "IF"}, {PUNCTUATION, "("}, {IDENT, "a"}, {PUNCTUATION, ","}, {NUMBER, "1"}, {PUNCTUATION, ")"}}},
+		{"IF(a)", DBMSSQLServer, nil, []TokenSpec{{KEYWORD, "IF"}, {PUNCTUATION, "("}, {IDENT, "a"}, {PUNCTUATION, ")"}}},
+		{"IF (a)", DBMSMySQL, nil, []TokenSpec{{KEYWORD, "IF"}, {SPACE, " "}, {PUNCTUATION, "("}, {IDENT, "a"}, {PUNCTUATION, ")"}}},
+		{"left(a,2)", "", nil, []TokenSpec{{FUNCTION, "left"}, {PUNCTUATION, "("}, {IDENT, "a"}, {PUNCTUATION, ","}, {NUMBER, "2"}, {PUNCTUATION, ")"}}},
+		{"VALUES(1)", "", nil, []TokenSpec{{KEYWORD, "VALUES"}, {PUNCTUATION, "("}, {NUMBER, "1"}, {PUNCTUATION, ")"}}},
+		{"count (*)", "", nil, []TokenSpec{{FUNCTION, "count"}, {SPACE, " "}, {PUNCTUATION, "("}, {WILDCARD, "*"}, {PUNCTUATION, ")"}}},
+		{"t (a)", "", nil, []TokenSpec{{IDENT, "t"}, {SPACE, " "}, {PUNCTUATION, "("}, {IDENT, "a"}, {PUNCTUATION, ")"}}},
+		{"f(a)", "", nil, []TokenSpec{{FUNCTION, "f"}, {PUNCTUATION, "("}, {IDENT, "a"}, {PUNCTUATION, ")"}}},
+		{"f(a)", "", []lexerOption{WithNoFunctions(true)}, []TokenSpec{{IDENT, "f"}, {PUNCTUATION, "("}, {IDENT, "a"}, {PUNCTUATION, ")"}}},
+		{"LEFT(a,2)", "", []lexerOption{WithNoFunctions(true)}, []TokenSpec{{KEYWORD, "LEFT"}, {PUNCTUATION, "("}, {IDENT, "a"}, {PUNCTUATION, ","}, {NUMBER, "2"}, {PUNCTUATION, ")"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			lexer := New(tt.input, append(tt.opts, WithDBMS(tt.dbms))...)
+			var tokens []TokenSpec
+			for token := lexer.Scan(); token.Type != EOF; token = lexer.Scan() {
+				tokens = append(tokens, TokenSpec{token.Type, token.Value})
+			}
+			assert.Equal(t, tt.expected, tokens)
+		})
+	}
+}
+
+func TestFunctionKeywords(t *testing.T) {
+	// the function keywords are keywords, the built-in functions are not
+	for _, fn := range functionKeywords {
+		assert.NotNil(t, lookupKeyword(fn.value), fn.value)
+	}
+	for _, fn := range builtinFunctions {
+		assert.Nil(t, lookupKeyword(fn), fn)
+	}
+}
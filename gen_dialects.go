@@ -0,0 +1,111 @@
+//go:build ignore
+
+// gen_dialects generates dialect_features.go, the features of the lexer for each DBMS, from the specs below.
+// Run it with go generate after changing them: a dialect is added by listing its features.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+)
+
+// features are the behaviors of the lexer which depend on the DBMS
+var features = []struct {
+	name string
+	doc  string
+}{
+	{"BacktickQuotes", "` quotes identifiers"},
+	{"BracketQuotes", "[ and ] quote identifiers"},
+	{"HashComments", "# starts a single line comment"},
+	{"HashIdentifiers", "# starts an identifier, e.g. a temporary table"},
+	{"DollarIdentifiers", "$ followed by a letter starts an identifier"},
+	{"ColonBindParameters", ":name is a bind parameter"},
+	{"AtIdentifiers", "@name is an identifier, e.g. a stage, instead of a bind parameter"},
+	{"NullSafeEquals", "<=> is the null-safe equality operator"},
+	{"PatternOperators", "~*, !~, !~*, ~~, ~~*, !~~ and !~~* match patterns"},
+	{"AssignmentOperators", "+=, -=, *=, /=, %=, &= and |= are compound assignments"},
+	{"NegatedComparisons", "!< and !> are comparison operators"},
+	{"OctalLiterals", "0o starts an octal number"},
+	{"BinaryLiterals", "0b starts a binary number"},
+	{"IfFunction", "IF( calls the IF function instead of starting a condition"},
+}
+
+// dialects are the features of each DBMS, the DBMS not listed having the features of the default dialect
+var dialects = []struct {
+	dbms     string
+	features []string
+}{
+	{"DBMSSQLServer", []string{"BracketQuotes", "HashIdentifiers", "DollarIdentifiers", "AssignmentOperators", "NegatedComparisons"}},
+	{"DBMSMySQL", []string{"BacktickQuotes", "HashComments", "NullSafeEquals", "BinaryLiterals", "IfFunction"}},
+	{"DBMSPostgres", []string{"PatternOperators", "OctalLiterals", "BinaryLiterals"}},
+	{"DBMSOracle", []string{"ColonBindParameters"}},
+	{"DBMSSnowflake", []string{"AtIdentifiers"}},
+}
+
+// defaultDialect is the features of the DBMS not listed, e.g. when the DBMS is not set:
+// the operators and the numbers of every dialect are scanned
+var defaultDialect = []string{
+	"NullSafeEquals", "PatternOperators", "AssignmentOperators", "NegatedComparisons", "OctalLiterals", "BinaryLiterals",
+}
+
+func main() {
+	if len(features) > 16 {
+		log.Fatal("the features do not fit in a dialectFeature")
+	}
+	flags := make(map[string]bool)
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by gen_dialects.go; DO NOT EDIT.\n\npackage sqllexer\n\n")
+	fmt.Fprintf(&out, "// dialectFeature is a set of the behaviors of the lexer which depend on the DBMS\n")
+	fmt.Fprintf(&out, "type dialectFeature uint16\n\nconst (\n")
+	for i, feature := range features {
+		fmt.Fprintf(&out, "\t// dialect%s: %s\n", feature.name, feature.doc)
+		if i == 0 {
+			fmt.Fprintf(&out, "\tdialect%s dialectFeature = 1 << iota\n", feature.name)
+		} else {
+			fmt.Fprintf(&out, "\tdialect%s\n", feature.name)
+		}
+		flags[feature.name] = true
+	}
+	fmt.Fprintf(&out, ")\n\n")
+
+	fmt.Fprintf(&out, "// dialectFeatures returns the features of the lexer for the DBMS, resolved once per input\n")
+	fmt.Fprintf(&out, "// instead of comparing the DBMS for each token\n")
+	fmt.Fprintf(&out, "func dialectFeatures(dbms DBMSType) dialectFeature {\n\tswitch dbms {\n")
+	for _, dialect := range dialects {
+		fmt.Fprintf(&out, "\tcase %s:\n\t\treturn ", dialect.dbms)
+		writeFeatures(&out, dialect.dbms, dialect.features, flags)
+	}
+	fmt.Fprintf(&out, "\t}\n\treturn ")
+	writeFeatures(&out, "default dialect", defaultDialect, flags)
+	fmt.Fprintf(&out, "}\n")
+
+	source, err := format.Source(out.Bytes())
+	if err != nil {
+		log.Fatalf("formatting the features: %v", err)
+	}
+	if err := os.WriteFile("dialect_features.go", source, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// writeFeatures writes the union of the features of the dialect
+func writeFeatures(out *bytes.Buffer, dialect string, features []string, flags map[string]bool) {
+	if len(features) == 0 {
+		fmt.Fprintf(out, "0\n")
+		return
+	}
+	for i, feature := range features {
+		if !flags[feature] {
+			log.Fatalf("%s: unknown feature %s", dialect, feature)
+		}
+		if i > 0 {
+			fmt.Fprintf(out, " | ")
+		}
+		fmt.Fprintf(out, "dialect%s", feature)
+	}
+	fmt.Fprintf(out, "\n")
+}
@@ -0,0 +1,171 @@
+//go:build ignore
+
+// gen_keywords generates keywords_table.go, the keywords of the lexer, and keywords_lookup.go, their lookup,
+// from the word lists of sqllexer_utils.go. Run it with go generate after changing the lists.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// wordLists are the lists of words, with the type of their tokens, in increasing precedence:
+// a word of several lists has the type of the last one
+var wordLists = []struct {
+	name             string
+	tokenType        string
+	isTableIndicator bool
+}{
+	{"commands", "COMMAND", false},
+	{"keywords", "KEYWORD", false},
+	{"tableIndicatorCommands", "COMMAND", true},
+	{"tableIndicatorKeywords", "KEYWORD", true},
+	{"booleanValues", "BOOLEAN", false},
+	{"nullValues", "NULL", false},
+	{"procedureNames", "PROC_INDICATOR", false},
+	{"ctes", "CTE_INDICATOR", false},
+	{"alias", "ALIAS_INDICATOR", false},
+}
+
+type keyword struct {
+	value            string
+	tokenType        string
+	isTableIndicator bool
+}
+
+func main() {
+	lists := parseWordLists("sqllexer_utils.go")
+
+	keywords := make(map[string]keyword)
+	for _, list := range wordLists {
+		words, ok := lists[list.name]
+		if !ok {
+			log.Fatalf("word list %s not found", list.name)
+		}
+		for _, word := range words {
+			word = strings.ToUpper(word)
+			keywords[word] = keyword{value: word, tokenType: list.tokenType, isTableIndicator: list.isTableIndicator}
+		}
+	}
+
+	// the keywords are sorted by length, then by first letter, the cases of the lookup
+	sorted := make([]keyword, 0, len(keywords))
+	for _, kw := range keywords {
+		sorted = append(sorted, kw)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i].value, sorted[j].value
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return a < b
+	})
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by gen_keywords.go; DO NOT EDIT.\n\npackage sqllexer\n\n")
+	fmt.Fprintf(&out, "// keywordTable are the keywords of the lexer, sorted by length and value\n")
+	fmt.Fprintf(&out, "var keywordTable = [...]keyword{\n")
+	for _, kw := range sorted {
+		fmt.Fprintf(&out, "\t{value: %q, lower: %q, tokenType: %s, isTableIndicator: %t},\n",
+			kw.value, strings.ToLower(kw.value), kw.tokenType, kw.isTableIndicator)
+	}
+	fmt.Fprintf(&out, "}\n")
+	writeSource("keywords_table.go", out.Bytes())
+
+	// TinyGo builds search the table instead, see keywords_lookup_tinygo.go
+	out.Reset()
+	fmt.Fprintf(&out, "// Code generated by gen_keywords.go; DO NOT EDIT.\n\n//go:build !tinygo\n\npackage sqllexer\n\n")
+	fmt.Fprintf(&out, "// lookupKeyword returns the keyword the ASCII word is, case-insensitively, or nil if it is not one.\n")
+	fmt.Fprintf(&out, "// The candidates are found by the length and the first letter of the word, then compared.\n")
+	fmt.Fprintf(&out, "func lookupKeyword(word string) *keyword {\n")
+	fmt.Fprintf(&out, "\tswitch len(word) {\n")
+	for i := 0; i < len(sorted); {
+		length := len(sorted[i].value)
+		fmt.Fprintf(&out, "\tcase %d:\n\t\tswitch word[0] | 0x20 {\n", length)
+		for i < len(sorted) && len(sorted[i].value) == length {
+			first := sorted[i].value[0]
+			fmt.Fprintf(&out, "\t\tcase %q:\n", first|0x20)
+			for ; i < len(sorted) && len(sorted[i].value) == length && sorted[i].value[0] == first; i++ {
+				fmt.Fprintf(&out, "\t\t\tif equalFoldASCII(word, %q) {\n\t\t\t\treturn &keywordTable[%d]\n\t\t\t}\n", sorted[i].value, i)
+			}
+		}
+		fmt.Fprintf(&out, "\t\t}\n")
+	}
+	fmt.Fprintf(&out, "\t}\n\treturn nil\n}\n")
+	writeSource("keywords_lookup.go", out.Bytes())
+}
+
+// writeSource formats the generated source and writes it to the file
+func writeSource(filename string, source []byte) {
+	source, err := format.Source(source)
+	if err != nil {
+		log.Fatalf("formatting %s: %v", filename, err)
+	}
+	if err := os.WriteFile(filename, source, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parseWordLists returns the string slices declared by the variables of the file
+func parseWordLists(filename string) map[string][]string {
+	file, err := parser.ParseFile(token.NewFileSet(), filename, nil, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	lists := make(map[string][]string)
+	ast.Inspect(file, func(node ast.Node) bool {
+		spec, ok := node.(*ast.ValueSpec)
+		if !ok {
+			return true
+		}
+		for i, name := range spec.Names {
+			if i >= len(spec.Values) {
+				break
+			}
+			if !isWordList(name.Name) {
+				continue
+			}
+			literal, ok := spec.Values[i].(*ast.CompositeLit)
+			if !ok {
+				continue
+			}
+			if array, ok := literal.Type.(*ast.ArrayType); !ok || array.Len != nil || fmt.Sprint(array.Elt) != "string" {
+				continue
+			}
+			var words []string
+			for _, element := range literal.Elts {
+				basic, ok := element.(*ast.BasicLit)
+				if !ok || basic.Kind != token.STRING {
+					log.Fatalf("%s: the words must be string literals", name.Name)
+				}
+				word, err := strconv.Unquote(basic.Value)
+				if err != nil {
+					log.Fatal(err)
+				}
+				words = append(words, word)
+			}
+			lists[name.Name] = words
+		}
+		return true
+	})
+	return lists
+}
+
+// isWordList checks if the variable is one of the word lists
+func isWordList(name string) bool {
+	for _, list := range wordLists {
+		if list.name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+package sqllexer
+
+import (
+	"html"
+	"strings"
+)
+
+// highlightClass is the highlighting class of a token
+type highlightClass int
+
+const (
+	highlightNone highlightClass = iota
+	highlightKeyword
+	highlightString
+	highlightNumber
+	highlightComment
+	highlightParameter
+	highlightFunction
+	highlightIdentifier
+	highlightOperator
+	highlightError
+)
+
+// highlightClassNames are the CSS class names of the highlighting classes
+var highlightClassNames = map[highlightClass]string{
+	highlightKeyword:    "keyword",
+	highlightString:     "string",
+	highlightNumber:     "number",
+	highlightComment:    "comment",
+	highlightParameter:  "parameter",
+	highlightFunction:   "function",
+	highlightIdentifier: "identifier",
+	highlightOperator:   "operator",
+	highlightError:      "error",
+}
+
+// tokenTypeClassNames are the CSS class names of the token types, refining the highlighting classes
+var tokenTypeClassNames = map[TokenType]string{
+	ERROR:                   "error",
+	STRING:                  "string",
+	INCOMPLETE_STRING:       "incomplete-string",
+	NUMBER:                  "number",
+	IDENT:                   "ident",
+	QUOTED_IDENT:            "quoted-ident",
+	OPERATOR:                "operator",
+	WILDCARD:                "wildcard",
+	COMMENT:                 "comment",
+	MULTILINE_COMMENT:       "multiline-comment",
+	PUNCTUATION:             "punctuation",
+	DOLLAR_QUOTED_FUNCTION:  "dollar-quoted-function",
+	DOLLAR_QUOTED_STRING:    "dollar-quoted-string",
+	POSITIONAL_PARAMETER:    "positional-parameter",
+	BIND_PARAMETER:          "bind-parameter",
+	FUNCTION:                "function",
+	SYSTEM_VARIABLE:         "system-variable",
+	UNKNOWN:                 "unknown",
+	COMMAND:                 "command",
+	KEYWORD:                 "keyword",
+	JSON_OP:                 "json-op",
+	BOOLEAN:                 "boolean",
+	NULL:                    "null",
+	PROC_INDICATOR:          "proc-indicator",
+	CTE_INDICATOR:           "cte-indicator",
+	ALIAS_INDICATOR:         "alias-indicator",
+	TEMPLATE:                "template",
+	INCOMPLETE_COMMENT:      "incomplete-comment",
+	INCOMPLETE_QUOTED_IDENT: "incomplete-quoted-ident",
+	PLACEHOLDER:             "placeholder",
+}
+
+// Theme is the ANSI escape sequence, e.g. "\x1b[1;34m", written before each class of token by Highlight.
+// Tokens whose class has an empty sequence are written as is.
+type Theme struct {
+	Keyword    string `json:"keyword,omitempty"`    // commands, keywords, booleans and NULL
+	String     string `json:"string,omitempty"`     // string literals and dollar quoted strings
+	Number     string `json:"number,omitempty"`     // number literals
+	Comment    string `json:"comment,omitempty"`    // single line and multiline comments
+	Parameter  string `json:"parameter,omitempty"`  // positional and bind parameters, system variables and templates
+	Function   string `json:"function,omitempty"`   // function names
+	Identifier string `json:"identifier,omitempty"` // identifiers and quoted identifiers
+	Operator   string `json:"operator,omitempty"`   // operators and wildcards
+	Error      string `json:"error,omitempty"`      // unterminated and unknown tokens
+}
+
+const ansiReset = "\x1b[0m"
+
+// DefaultTheme highlights queries with the basic ANSI colors, readable on dark and light terminals
+var DefaultTheme = Theme{
+	Keyword:   "\x1b[1;34m", // bold blue
+	String:    "\x1b[32m",   // green
+	Number:    "\x1b[36m",   // cyan
+	Comment:   "\x1b[2;37m", // dim gray
+	Parameter: "\x1b[35m",   // magenta
+	Function:  "\x1b[33m",   // yellow
+	Error:     "\x1b[1;31m", // bold red
+}
+
+// Highlight returns the query with ANSI escape sequences around its tokens, as configured by the theme,
+// for use in terminals. The bytes of the query are preserved, so stripping the escape sequences returns the query.
+func Highlight(query string, theme Theme, lexerOpts ...lexerOption) string {
+	var builder strings.Builder
+	builder.Grow(len(query) * 2)
+	lexer := New(query, lexerOpts...)
+	for {
+		token := lexer.Scan()
+		if token.Type == EOF {
+			return builder.String()
+		}
+		text := query[token.Start:token.End]
+		if sequence := theme.sequence(classifyHighlight(token)); sequence != "" {
+			builder.WriteString(sequence)
+			builder.WriteString(text)
+			builder.WriteString(ansiReset)
+		} else {
+			builder.WriteString(text)
+		}
+	}
+}
+
+// HighlightHTML returns the query as HTML, each token but spaces being wrapped in a span, e.g.
+// <span class="sql-keyword sql-command">SELECT</span>. The first class is the highlighting class of the token,
+// one of keyword, string, number, comment, parameter, function, identifier, operator and error,
+// and the second one is its token type, e.g. command, keyword, boolean or null for keywords,
+// so stylesheets can style tokens coarsely or finely. The text of the query is HTML escaped.
+// Tokens are the ones of the lexer for the DBMS given in the lexer options, so highlighting matches the Go lexer.
+func HighlightHTML(query string, lexerOpts ...lexerOption) string {
+	var builder strings.Builder
+	builder.Grow(len(query) * 4)
+	lexer := New(query, lexerOpts...)
+	for {
+		token := lexer.Scan()
+		if token.Type == EOF {
+			return builder.String()
+		}
+		text := html.EscapeString(query[token.Start:token.End])
+		typeName, ok := tokenTypeClassNames[token.Type]
+		if !ok {
+			builder.WriteString(text)
+			continue
+		}
+		builder.WriteString(`<span class="`)
+		if className, ok := highlightClassNames[classifyHighlight(token)]; ok && className != typeName {
+			builder.WriteString("sql-")
+			builder.WriteString(className)
+			builder.WriteString(" ")
+		}
+		builder.WriteString("sql-")
+		builder.WriteString(typeName)
+		builder.WriteString(`">`)
+		builder.WriteString(text)
+		builder.WriteString("</span>")
+	}
+}
+
+// sequence returns the escape sequence of the class
+func (t *Theme) sequence(class highlightClass) string {
+	switch class {
+	case highlightKeyword:
+		return t.Keyword
+	case highlightString:
+		return t.String
+	case highlightNumber:
+		return t.Number
+	case highlightComment:
+		return t.Comment
+	case highlightParameter:
+		return t.Parameter
+	case highlightFunction:
+		return t.Function
+	case highlightIdentifier:
+		return t.Identifier
+	case highlightOperator:
+		return t.Operator
+	case highlightError:
+		return t.Error
+	}
+	return ""
+}
+
+// classifyHighlight returns the highlighting class of the token
+func classifyHighlight(token *Token) highlightClass {
+	switch token.Type {
+	case COMMAND, KEYWORD, BOOLEAN, NULL, PROC_INDICATOR, CTE_INDICATOR, ALIAS_INDICATOR:
+		return highlightKeyword
+	case STRING, DOLLAR_QUOTED_STRING, DOLLAR_QUOTED_FUNCTION:
+		return highlightString
+	case NUMBER:
+		return highlightNumber
+	case COMMENT, MULTILINE_COMMENT:
+		return highlightComment
+	case POSITIONAL_PARAMETER, BIND_PARAMETER, PLACEHOLDER, SYSTEM_VARIABLE, TEMPLATE:
+		return highlightParameter
+	case FUNCTION:
+		return highlightFunction
+	case IDENT, QUOTED_IDENT:
+		return highlightIdentifier
+	case OPERATOR, JSON_OP, WILDCARD:
+		if token.Value == "?" {
+			return highlightParameter
+		}
+		return highlightOperator
+	case ERROR, UNKNOWN, INCOMPLETE_STRING, INCOMPLETE_COMMENT, INCOMPLETE_QUOTED_IDENT:
+		return highlightError
+	}
+	return highlightNone
+}
@@ -0,0 +1,105 @@
+package sqllexer
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var ansiSequence = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func TestHighlight(t *testing.T) {
+	theme := Theme{Keyword: "<k>", String: "<s>", Number: "<n>", Comment: "<c>", Parameter: "<p>", Function: "<f>", Identifier: "<i>", Operator: "<o>", Error: "<e>"}
+	tests := []struct {
+		input     string
+		expected  string
+		lexerOpts []lexerOption
+	}{
+		{
+			input:    "SELECT id, count(*) FROM users WHERE name = 'x' AND age > 42 -- adults",
+			expected: "<k>SELECT\x1b[0m <i>id\x1b[0m, <f>count\x1b[0m(<o>*\x1b[0m) <k>FROM\x1b[0m <i>users\x1b[0m <k>WHERE\x1b[0m <i>name\x1b[0m <o>=\x1b[0m <s>'x'\x1b[0m <k>AND\x1b[0m <i>age\x1b[0m <o>>\x1b[0m <n>42\x1b[0m <c>-- adults\x1b[0m",
+		},
+		{
+			input:     "SELECT * FROM t WHERE a = $1 AND b IS NULL /* multi\nline */",
+			expected:  "<k>SELECT\x1b[0m <o>*\x1b[0m <k>FROM\x1b[0m <i>t\x1b[0m <k>WHERE\x1b[0m <i>a\x1b[0m <o>=\x1b[0m <p>$1\x1b[0m <k>AND\x1b[0m <i>b\x1b[0m <k>IS\x1b[0m <k>NULL\x1b[0m <c>/* multi\nline */\x1b[0m",
+			lexerOpts: []lexerOption{WithDBMS(DBMSPostgres)},
+		},
+		{
+			input:    "SELECT ? FROM t WHERE s = 'unterminated",
+			expected: "<k>SELECT\x1b[0m <p>?\x1b[0m <k>FROM\x1b[0m <i>t\x1b[0m <k>WHERE\x1b[0m <i>s\x1b[0m <o>=\x1b[0m <e>'unterminated\x1b[0m",
+		},
+		{
+			input:    "SELECT a /* unterminated",
+			expected: "<k>SELECT\x1b[0m <i>a\x1b[0m <e>/* unterminated\x1b[0m",
+		},
+		{
+			input:     "SELECT * FROM {{ .Table }} WHERE id = ${id}",
+			expected:  "<k>SELECT\x1b[0m <o>*\x1b[0m <k>FROM\x1b[0m <p>{{ .Table }}\x1b[0m <k>WHERE\x1b[0m <i>id\x1b[0m <o>=\x1b[0m <p>${id}\x1b[0m",
+			lexerOpts: []lexerOption{WithTemplateDelimiters(DefaultTemplateDelimiters...)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			assert.Equal(t, test.expected, Highlight(test.input, theme, test.lexerOpts...))
+		})
+	}
+}
+
+func TestHighlightPreservesQuery(t *testing.T) {
+	for _, query := range []string{
+		"SELECT * FROM users WHERE id = 1",
+		"  select  \"Name\"\n\tfrom [dbo].[users] -- comment\n",
+		"INSERT INTO t VALUES ('é', 'ü', $$body$$)",
+		"",
+	} {
+		highlighted := Highlight(query, DefaultTheme)
+		assert.Equal(t, query, ansiSequence.ReplaceAllString(highlighted, ""))
+	}
+	assert.Equal(t, "SELECT 1", Highlight("SELECT 1", Theme{}))
+}
+
+func TestHighlightHTML(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  string
+		lexerOpts []lexerOption
+	}{
+		{
+			input: "SELECT count(*) FROM users WHERE name = '<b>' AND active IS TRUE",
+			expected: `<span class="sql-keyword sql-command">SELECT</span> <span class="sql-function">count</span><span class="sql-punctuation">(</span>` +
+				`<span class="sql-operator sql-wildcard">*</span><span class="sql-punctuation">)</span> <span class="sql-keyword">FROM</span> ` +
+				`<span class="sql-identifier sql-ident">users</span> <span class="sql-keyword">WHERE</span> <span class="sql-identifier sql-ident">name</span> ` +
+				`<span class="sql-operator">=</span> <span class="sql-string">&#39;&lt;b&gt;&#39;</span> <span class="sql-keyword">AND</span> ` +
+				`<span class="sql-identifier sql-ident">active</span> <span class="sql-keyword">IS</span> <span class="sql-keyword sql-boolean">TRUE</span>`,
+		},
+		{
+			input: "SELECT \"a&b\" FROM t WHERE x = $1 -- note",
+			expected: `<span class="sql-keyword sql-command">SELECT</span> <span class="sql-identifier sql-quoted-ident">&#34;a&amp;b&#34;</span> ` +
+				`<span class="sql-keyword">FROM</span> <span class="sql-identifier sql-ident">t</span> <span class="sql-keyword">WHERE</span> ` +
+				`<span class="sql-identifier sql-ident">x</span> <span class="sql-operator">=</span> <span class="sql-parameter sql-positional-parameter">$1</span> ` +
+				`<span class="sql-comment">-- note</span>`,
+			lexerOpts: []lexerOption{WithDBMS(DBMSPostgres)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			assert.Equal(t, test.expected, HighlightHTML(test.input, test.lexerOpts...))
+		})
+	}
+}
+
+func ExampleHighlight() {
+	fmt.Printf("%q\n", Highlight("SELECT name FROM users WHERE id = 42", DefaultTheme))
+	// Output:
+	// "\x1b[1;34mSELECT\x1b[0m name \x1b[1;34mFROM\x1b[0m users \x1b[1;34mWHERE\x1b[0m id = \x1b[36m42\x1b[0m"
+}
+
+func ExampleHighlightHTML() {
+	fmt.Println(HighlightHTML("SELECT * FROM users"))
+	// Output:
+	// <span class="sql-keyword sql-command">SELECT</span> <span class="sql-operator sql-wildcard">*</span> <span class="sql-keyword">FROM</span> <span class="sql-identifier sql-ident">users</span>
+}
@@ -0,0 +1,87 @@
+package sqllexer
+
+import "strconv"
+
+// isInListStart returns true if the token opens the list of an IN predicate, e.g. the parenthesis of IN (1, 2)
+func isInListStart(token *Token, lastValueToken *LastValueToken) bool {
+	return token.Type == PUNCTUATION && token.Value == "(" &&
+		lastValueToken != nil && lastValueToken.Type == KEYWORD && equalFoldASCII(lastValueToken.Value, "IN")
+}
+
+// scanLiteralList scans the literals of an IN list whose opening parenthesis was just scanned, if they are
+// all numbers or all strings, e.g. the 1, 2, 3 of IN (1, 2, 3), as a single token of their type, and returns it
+// along with the number of literals. The closing parenthesis is the next token scanned.
+// It returns nil, the list being scanned again token by token, if the list is not made of such literals.
+func (s *Lexer) scanLiteralList() (*Token, int) {
+	mark := s.Mark()
+	listType, count, end := ERROR, 0, 0
+	for {
+		token := s.scanNonSpace()
+		if token.Type != NUMBER && token.Type != STRING || count > 0 && token.Type != listType {
+			break
+		}
+		listType, count, end = token.Type, count+1, s.cursor
+		separator := s.scanNonSpace()
+		if separator.Type == PUNCTUATION && separator.Value == ")" {
+			s.ResetTo(mark)
+			s.skipSpaces()
+			s.start, s.cursor = s.cursor, end
+			return s.emit(listType), count
+		}
+		if separator.Type != PUNCTUATION || separator.Value != "," {
+			break
+		}
+	}
+	s.ResetTo(mark)
+	return nil, 0
+}
+
+// appendObfuscatedLiteralList appends the list of literals scanned by scanLiteralList to dst,
+// each literal being replaced with its placeholder as the obfuscator replaces it
+func (s *Lexer) appendObfuscatedLiteralList(dst []byte, list string) []byte {
+	lexer := Lexer{src: list, config: s.config, dialect: s.dialect}
+	lexer.config.StartPosition, lexer.config.SkipWhitespace = nil, false
+	for token := lexer.Scan(); token.Type != EOF; token = lexer.Scan() {
+		switch token.Type {
+		case NUMBER:
+			dst = append(dst, NumberPlaceholder...)
+		case STRING:
+			dst = append(dst, StringPlaceholder...)
+		default:
+			dst = append(dst, list[token.Start:token.End]...)
+		}
+	}
+	return dst
+}
+
+// scanNonSpace scans the next token which is not a SPACE
+func (s *Lexer) scanNonSpace() *Token {
+	token := s.Scan()
+	for token.Type == SPACE {
+		token = s.Scan()
+	}
+	return token
+}
+
+// summarizesInLists returns true if the literals of IN lists are collapsed into a single placeholder,
+// when they are obfuscated or CanonicalizeInList is set, so that a list of literals of a single type
+// can be scanned as a single token, whatever its length
+func (n *Normalizer) summarizesInLists(obfuscate bool) bool {
+	return (obfuscate || n.config.CanonicalizeInList) && !n.config.KeepLiterals
+}
+
+// collectInList collects the summary of the IN list of count literals scanned as a single token, if any,
+// within the metadata limits
+func (n *Normalizer) collectInList(state *normalizerState, statementMetadata *StatementMetadata, count int) {
+	if count > 0 && n.config.CollectInLists {
+		state.meta.addMetadata(inListSummary(count), state.meta.inListsSet, &statementMetadata.InLists)
+	}
+}
+
+// inListSummary returns the summary of an IN list of count literals, as collected in the metadata
+func inListSummary(count int) string {
+	if count == 1 {
+		return "IN list of 1 literal"
+	}
+	return "IN list of " + strconv.Itoa(count) + " literals"
+}
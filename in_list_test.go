@@ -0,0 +1,121 @@
+package sqllexer
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizerInLists(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		obfuscate  bool
+		opts       []normalizerOption
+		normalized string
+		inLists    []string
+	}{
+		{
+			name:       "numbers",
+			input:      "SELECT * FROM t WHERE id IN (1, 2, 3)",
+			obfuscate:  true,
+			normalized: "SELECT * FROM t WHERE id IN ( ? )",
+			inLists:    []string{"IN list of 3 literals"},
+		},
+		{
+			name:       "strings and a single literal",
+			input:      "SELECT * FROM t WHERE a NOT IN ('x, y',\n'z') AND b in (-1)",
+			obfuscate:  true,
+			normalized: "SELECT * FROM t WHERE a NOT IN ( ? ) AND b in ( ? )",
+			inLists:    []string{"IN list of 2 literals", "IN list of 1 literal"},
+		},
+		{
+			name:       "literals of different types",
+			input:      "SELECT * FROM t WHERE id IN (1, 'a')",
+			obfuscate:  true,
+			normalized: "SELECT * FROM t WHERE id IN ( ? )",
+		},
+		{
+			name:       "not only literals",
+			input:      "SELECT * FROM t WHERE id IN (1, x)",
+			obfuscate:  true,
+			normalized: "SELECT * FROM t WHERE id IN ( ?, x )",
+		},
+		{
+			name:       "comment",
+			input:      "SELECT * FROM t WHERE id IN (1 /* one */, 2)",
+			obfuscate:  true,
+			normalized: "SELECT * FROM t WHERE id IN ( ? )",
+		},
+		{
+			name:       "subquery",
+			input:      "SELECT * FROM t WHERE id IN (SELECT id FROM u WHERE v IN (1, 2))",
+			obfuscate:  true,
+			normalized: "SELECT * FROM t WHERE id IN ( SELECT id FROM u WHERE v IN ( ? ) )",
+			inLists:    []string{"IN list of 2 literals"},
+		},
+		{
+			name:       "canonicalized",
+			input:      "SELECT * FROM t WHERE id IN (1, 2)",
+			opts:       []normalizerOption{WithCanonicalizeInList(true)},
+			normalized: "SELECT * FROM t WHERE id IN ( ? )",
+			inLists:    []string{"IN list of 2 literals"},
+		},
+		{
+			name:       "literals kept",
+			input:      "SELECT * FROM t WHERE id IN (1, 2)",
+			normalized: "SELECT * FROM t WHERE id IN ( 1, 2 )",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalizer := NewNormalizer(append(tt.opts, WithCollectInLists(true))...)
+			var normalized string
+			var metadata *StatementMetadata
+			var err error
+			if tt.obfuscate {
+				normalized, metadata, err = ObfuscateAndNormalize(tt.input, NewObfuscator(), normalizer)
+			} else {
+				normalized, metadata, err = normalizer.Normalize(tt.input)
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.normalized, normalized)
+			assert.Equal(t, tt.inLists, metadata.InLists)
+		})
+	}
+}
+
+func TestNormalizerInListsLimits(t *testing.T) {
+	query := "SELECT * FROM t WHERE a IN (1) AND b IN (1, 2) AND c IN ('x', 'y') AND d IN (1, 2, 3)"
+	normalizer := NewNormalizer(WithCollectInLists(true), WithMaxMetadataEntries(1))
+	_, metadata, err := ObfuscateAndNormalize(query, NewObfuscator(), normalizer)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"IN list of 1 literal"}, metadata.InLists)
+	assert.True(t, metadata.Truncated)
+
+	// the summaries are distinct and counted in the size of the metadata
+	normalizer = NewNormalizer(WithCollectInLists(true))
+	_, metadata, err = ObfuscateAndNormalize(query, NewObfuscator(), normalizer)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"IN list of 1 literal", "IN list of 2 literals", "IN list of 3 literals"}, metadata.InLists)
+	assert.Equal(t, len("IN list of 1 literal")+2*len("IN list of 2 literals"), metadata.Size)
+	assert.False(t, metadata.Truncated)
+}
+
+func TestNormalizerLargeInList(t *testing.T) {
+	values := make([]string, 50000)
+	for i := range values {
+		values[i] = strconv.Itoa(i)
+	}
+	query := "SELECT * FROM t WHERE id IN (" + strings.Join(values, ", ") + ")"
+
+	normalized, metadata, err := ObfuscateAndNormalize(query, NewObfuscator(), NewNormalizer(WithCollectInLists(true), WithCollectOffsets(true)))
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE id IN ( ? )", normalized)
+	assert.Equal(t, []string{"IN list of 50000 literals"}, metadata.InLists)
+	// the placeholder maps to the whole list
+	assert.Contains(t, metadata.Offsets, OffsetMapping{NormalizedStart: 30, NormalizedEnd: 31, OriginalStart: 29, OriginalEnd: len(query) - 1})
+}
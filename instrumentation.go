@@ -0,0 +1,141 @@
+package sqllexer
+
+import (
+	"context"
+	"runtime/pprof"
+	"time"
+)
+
+// Operation is an operation processing a query, as labeled by the Context variants of the operations, e.g. ProcessContext,
+// and reported WithPhaseTimings
+type Operation string
+
+const (
+	OperationObfuscate             Operation = "obfuscate"
+	OperationNormalize             Operation = "normalize"
+	OperationObfuscateAndNormalize Operation = "obfuscate_and_normalize"
+	OperationProcess               Operation = "process"
+)
+
+// Phase is a phase of the processing of a query, whose duration is reported WithPhaseTimings
+type Phase int
+
+const (
+	PhaseLex Phase = iota
+	PhaseObfuscate
+	PhaseNormalize
+	phaseCount
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseLex:
+		return "lex"
+	case PhaseObfuscate:
+		return "obfuscate"
+	case PhaseNormalize:
+		return "normalize"
+	}
+	return "unknown"
+}
+
+// PhaseTimings is called once a query is processed with the time spent in each phase of the operation
+type PhaseTimings func(operation Operation, phase Phase, duration time.Duration)
+
+// WithPhaseTimings reports the time spent lexing, obfuscating and normalizing each query to timings.
+// The phases are timed for each token, which slows the processing down.
+func WithPhaseTimings(timings PhaseTimings) lexerOption {
+	return func(c *LexerConfig) {
+		c.PhaseTimings = timings
+	}
+}
+
+// ProcessContext is Process labeling the goroutine with the pprof labels dbms and operation, added to the labels
+// of ctx, so the CPU spent processing the query is attributed in profiles. The goroutine gets the labels of ctx back
+// once the query is processed.
+func ProcessContext(ctx context.Context, query string, obfuscator *Obfuscator, normalizer *Normalizer, lexerOpts ...lexerOption) (result ProcessResult, err error) {
+	doLabeled(ctx, OperationProcess, lexerOpts, func() {
+		result, err = Process(query, obfuscator, normalizer, lexerOpts...)
+	})
+	return result, err
+}
+
+// ProcessBatchContext is ProcessBatch labeling its workers as ProcessContext labels the goroutine,
+// with the operation process
+func ProcessBatchContext(ctx context.Context, queries []string, workers int, obfuscator *Obfuscator, normalizer *Normalizer, lexerOpts ...lexerOption) (results []ProcessResult, err error) {
+	// the workers inherit the labels of the goroutine starting them
+	doLabeled(ctx, OperationProcess, lexerOpts, func() {
+		results, err = ProcessBatch(queries, workers, obfuscator, normalizer, lexerOpts...)
+	})
+	return results, err
+}
+
+// ObfuscateContext is Obfuscate labeling the goroutine as ProcessContext does, with the operation obfuscate
+func (o *Obfuscator) ObfuscateContext(ctx context.Context, input string, lexerOpts ...lexerOption) (obfuscated string) {
+	doLabeled(ctx, OperationObfuscate, lexerOpts, func() {
+		obfuscated = o.Obfuscate(input, lexerOpts...)
+	})
+	return obfuscated
+}
+
+// NormalizeContext is Normalize labeling the goroutine as ProcessContext does, with the operation normalize
+func (n *Normalizer) NormalizeContext(ctx context.Context, input string, lexerOpts ...lexerOption) (normalizedSQL string, statementMetadata *StatementMetadata, err error) {
+	doLabeled(ctx, OperationNormalize, lexerOpts, func() {
+		normalizedSQL, statementMetadata, err = n.Normalize(input, lexerOpts...)
+	})
+	return normalizedSQL, statementMetadata, err
+}
+
+// ObfuscateAndNormalizeContext is ObfuscateAndNormalize labeling the goroutine as ProcessContext does,
+// with the operation obfuscate_and_normalize
+func ObfuscateAndNormalizeContext(ctx context.Context, input string, obfuscator *Obfuscator, normalizer *Normalizer, lexerOpts ...lexerOption) (normalizedSQL string, statementMetadata *StatementMetadata, err error) {
+	doLabeled(ctx, OperationObfuscateAndNormalize, lexerOpts, func() {
+		normalizedSQL, statementMetadata, err = ObfuscateAndNormalize(input, obfuscator, normalizer, lexerOpts...)
+	})
+	return normalizedSQL, statementMetadata, err
+}
+
+// doLabeled calls fn with the goroutine labeled with the DBMS of the lexer options and the operation
+func doLabeled(ctx context.Context, operation Operation, lexerOpts []lexerOption, fn func()) {
+	config := &LexerConfig{}
+	for _, opt := range lexerOpts {
+		opt(config)
+	}
+	labels := pprof.Labels("dbms", string(config.DBMS), "operation", string(operation))
+	pprof.Do(ctx, labels, func(context.Context) {
+		fn()
+	})
+}
+
+// phaseTimer accumulates the durations of the phases of an operation, when they are reported.
+// The operations decide whether the phases are timed once, before their loop over the tokens,
+// so the timer is only called for each token when WithPhaseTimings is set.
+type phaseTimer struct {
+	timings   PhaseTimings
+	last      time.Time
+	durations [phaseCount]time.Duration
+}
+
+// newPhaseTimer returns the timer of the phases reported to timings, and whether the phases are timed
+func newPhaseTimer(timings PhaseTimings) (phaseTimer, bool) {
+	if timings == nil {
+		return phaseTimer{}, false
+	}
+	return phaseTimer{timings: timings, last: time.Now()}, true
+}
+
+// end ends the phase, the next phase starting now
+func (t *phaseTimer) end(phase Phase) {
+	now := time.Now()
+	t.durations[phase] += now.Sub(t.last)
+	t.last = now
+}
+
+// report reports the durations of the phases of the operation
+func (t *phaseTimer) report(operation Operation) {
+	for phase, duration := range t.durations {
+		if duration > 0 {
+			t.timings(operation, Phase(phase), duration)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package sqllexer
+
+import (
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPhaseTimings(t *testing.T) {
+	query := "SELECT * FROM users WHERE id = 1 AND name = 'alice'"
+	obfuscator := NewObfuscator()
+	normalizer := NewNormalizer(WithCollectTables(true))
+
+	tests := []struct {
+		operation Operation
+		phases    []Phase
+		process   func(lexerOpts ...lexerOption)
+	}{
+		{
+			operation: OperationObfuscate,
+			phases:    []Phase{PhaseLex, PhaseObfuscate},
+			process: func(lexerOpts ...lexerOption) {
+				assert.Equal(t, obfuscator.Obfuscate(query), obfuscator.Obfuscate(query, lexerOpts...))
+			},
+		},
+		{
+			operation: OperationNormalize,
+			phases:    []Phase{PhaseLex, PhaseNormalize},
+			process: func(lexerOpts ...lexerOption) {
+				expected, _, _ := normalizer.Normalize(query)
+				normalized, _, err := normalizer.Normalize(query, lexerOpts...)
+				assert.NoError(t, err)
+				assert.Equal(t, expected, normalized)
+			},
+		},
+		{
+			operation: OperationObfuscateAndNormalize,
+			phases:    []Phase{PhaseLex, PhaseObfuscate, PhaseNormalize},
+			process: func(lexerOpts ...lexerOption) {
+				expected, _, _ := ObfuscateAndNormalize(query, obfuscator, normalizer)
+				normalized, _, err := ObfuscateAndNormalize(query, obfuscator, normalizer, lexerOpts...)
+				assert.NoError(t, err)
+				assert.Equal(t, expected, normalized)
+			},
+		},
+		{
+			operation: OperationProcess,
+			phases:    []Phase{PhaseLex, PhaseObfuscate, PhaseNormalize},
+			process: func(lexerOpts ...lexerOption) {
+				expected, _ := Process(query, obfuscator, normalizer)
+				result, err := Process(query, obfuscator, normalizer, lexerOpts...)
+				assert.NoError(t, err)
+				assert.Equal(t, expected, result)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.operation), func(t *testing.T) {
+			durations := make(map[Phase]time.Duration)
+			timings := func(operation Operation, phase Phase, duration time.Duration) {
+				assert.Equal(t, tt.operation, operation)
+				durations[phase] += duration
+			}
+			tt.process(WithPhaseTimings(timings), WithDBMS(DBMSPostgres))
+
+			assert.Len(t, durations, len(tt.phases))
+			for _, phase := range tt.phases {
+				assert.Positive(t, durations[phase], phase.String())
+			}
+		})
+	}
+}
+
+func TestProcessContext(t *testing.T) {
+	query := "SELECT * FROM users WHERE id = 1"
+	obfuscator := NewObfuscator()
+	normalizer := NewNormalizer()
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("caller", "test"))
+
+	tests := []struct {
+		operation Operation
+		process   func(lexerOpts ...lexerOption) (any, error)
+		expected  func() (any, error)
+	}{
+		{
+			operation: OperationProcess,
+			process: func(lexerOpts ...lexerOption) (any, error) {
+				return ProcessContext(ctx, query, obfuscator, normalizer, lexerOpts...)
+			},
+			expected: func() (any, error) {
+				return Process(query, obfuscator, normalizer, WithDBMS(DBMSPostgres))
+			},
+		},
+		{
+			operation: OperationProcess,
+			process: func(lexerOpts ...lexerOption) (any, error) {
+				return ProcessBatchContext(ctx, []string{query, query}, 2, obfuscator, normalizer, lexerOpts...)
+			},
+			expected: func() (any, error) {
+				return ProcessBatch([]string{query, query}, 2, obfuscator, normalizer, WithDBMS(DBMSPostgres))
+			},
+		},
+		{
+			operation: OperationObfuscate,
+			process: func(lexerOpts ...lexerOption) (any, error) {
+				return obfuscator.ObfuscateContext(ctx, query, lexerOpts...), nil
+			},
+			expected: func() (any, error) {
+				return obfuscator.Obfuscate(query, WithDBMS(DBMSPostgres)), nil
+			},
+		},
+		{
+			operation: OperationNormalize,
+			process: func(lexerOpts ...lexerOption) (any, error) {
+				normalized, _, err := normalizer.NormalizeContext(ctx, query, lexerOpts...)
+				return normalized, err
+			},
+			expected: func() (any, error) {
+				normalized, _, err := normalizer.Normalize(query, WithDBMS(DBMSPostgres))
+				return normalized, err
+			},
+		},
+		{
+			operation: OperationObfuscateAndNormalize,
+			process: func(lexerOpts ...lexerOption) (any, error) {
+				normalized, _, err := ObfuscateAndNormalizeContext(ctx, query, obfuscator, normalizer, lexerOpts...)
+				return normalized, err
+			},
+			expected: func() (any, error) {
+				normalized, _, err := ObfuscateAndNormalize(query, obfuscator, normalizer, WithDBMS(DBMSPostgres))
+				return normalized, err
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.operation), func(t *testing.T) {
+			// the goroutine profile lists the labels of the goroutines while the query is processed
+			var mu sync.Mutex
+			var profile strings.Builder
+			timings := func(operation Operation, phase Phase, duration time.Duration) {
+				mu.Lock()
+				defer mu.Unlock()
+				if profile.Len() == 0 {
+					assert.NoError(t, pprof.Lookup("goroutine").WriteTo(&profile, 1))
+				}
+			}
+			expected, _ := test.expected()
+			result, err := test.process(WithDBMS(DBMSPostgres), WithPhaseTimings(timings))
+			assert.NoError(t, err)
+			assert.Equal(t, expected, result)
+			assert.Contains(t, profile.String(),
+				`labels: {"caller":"test", "dbms":"postgresql", "operation":"`+string(test.operation)+`"}`)
+		})
+	}
+}
+
+func ExampleWithPhaseTimings() {
+	phases := make(map[Phase]bool)
+	timings := func(operation Operation, phase Phase, duration time.Duration) {
+		phases[phase] = true
+	}
+	obfuscator := NewObfuscator()
+	normalizer := NewNormalizer()
+	normalized, _, _ := ObfuscateAndNormalize("SELECT * FROM users WHERE id = 1", obfuscator, normalizer,
+		WithPhaseTimings(timings))
+	fmt.Println(normalized)
+	fmt.Println(phases[PhaseLex], phases[PhaseObfuscate], phases[PhaseNormalize])
+	// Output:
+	// SELECT * FROM users WHERE id = ?
+	// true true true
+}
@@ -0,0 +1,184 @@
+package sqllexer
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Interpolate returns the query with its bind parameter placeholders replaced by the SQL literals of the args,
+// for debug logging of the query as executed. It must not be used to build queries to execute.
+// The DBMS of the lexer options determines the placeholders recognized by the lexer and the quoting
+// of the literals, e.g. backslashes are escaped in MySQL strings.
+//
+// Anonymous placeholders, ? and %s, take the args in order, and numbered placeholders, e.g. $2 or :2,
+// take the arg of their number. A ? is not a placeholder for PostgreSQL or in a query with $N placeholders,
+// where it is the JSON operator, e.g. data ? 'key'. Named placeholders, e.g. :name or @name, take the sql.NamedArg of their name,
+// or else the args in order of first appearance of their names, as positional drivers bind them.
+// Placeholders without an arg are left as is. Args implementing driver.Valuer are replaced by their value.
+func Interpolate(query string, args []any, lexerOpts ...lexerOption) string {
+	config := &LexerConfig{}
+	for _, opt := range lexerOpts {
+		opt(config)
+	}
+	dbms := config.DBMS
+
+	named := make(map[string]any)
+	var positional []any
+	for _, arg := range args {
+		if namedArg, ok := arg.(sql.NamedArg); ok && namedArg.Name != "" {
+			named[namedArg.Name] = namedArg.Value
+		} else {
+			positional = append(positional, arg)
+		}
+	}
+	names := make(map[string]int) // position of the named placeholders without a sql.NamedArg
+
+	parameters := ExtractParameters(query, lexerOpts...)
+	questionMarks := dbms != DBMSPostgres
+	for _, parameter := range parameters {
+		if parameter.Style == ParameterDollar {
+			questionMarks = false
+		}
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(query))
+	last := 0
+	for _, parameter := range parameters {
+		var arg any
+		var ok bool
+		if parameter.Style == ParameterQuestionMark && !questionMarks {
+			continue
+		}
+		if parameter.Name != "" {
+			if arg, ok = named[parameter.Name]; !ok {
+				position, seen := names[parameter.Name]
+				if !seen {
+					position = len(names)
+					names[parameter.Name] = position
+				}
+				arg, ok = argAt(positional, position)
+			}
+		} else {
+			arg, ok = argAt(positional, parameter.Ordinal-1)
+		}
+		if !ok {
+			continue
+		}
+		builder.WriteString(query[last:parameter.Start])
+		builder.WriteString(formatLiteral(arg, dbms))
+		last = parameter.End
+	}
+	builder.WriteString(query[last:])
+	return builder.String()
+}
+
+func argAt(args []any, i int) (any, bool) {
+	if i < 0 || i >= len(args) {
+		return nil, false
+	}
+	return args[i], true
+}
+
+// formatLiteral returns the SQL literal of the value for the DBMS
+func formatLiteral(value any, dbms DBMSType) string {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return quoteString(fmt.Sprintf("%%!(%v)", err), dbms)
+		}
+		value = v
+	}
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		switch {
+		case dbms == DBMSSQLServer || dbms == DBMSOracle:
+			if v {
+				return "1"
+			}
+			return "0"
+		case v:
+			return "TRUE"
+		}
+		return "FALSE"
+	case int:
+		return strconv.FormatInt(int64(v), 10)
+	case int8:
+		return strconv.FormatInt(int64(v), 10)
+	case int16:
+		return strconv.FormatInt(int64(v), 10)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float32:
+		return formatFloat(float64(v), 32, dbms)
+	case float64:
+		return formatFloat(v, 64, dbms)
+	case []byte:
+		return formatBytes(v, dbms)
+	case time.Time:
+		return quoteString(v.Format("2006-01-02 15:04:05.999999999Z07:00"), dbms)
+	case string:
+		return quoteString(v, dbms)
+	}
+	return quoteString(fmt.Sprint(value), dbms)
+}
+
+func formatFloat(f float64, bitSize int, dbms DBMSType) string {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		// e.g. 'NaN' and 'Infinity' are cast to floats by PostgreSQL
+		return quoteString(strconv.FormatFloat(f, 'g', -1, bitSize), dbms)
+	}
+	return strconv.FormatFloat(f, 'g', -1, bitSize)
+}
+
+func formatBytes(b []byte, dbms DBMSType) string {
+	encoded := hex.EncodeToString(b)
+	switch dbms {
+	case DBMSPostgres:
+		return `'\x` + encoded + `'`
+	case DBMSSQLServer:
+		return "0x" + encoded
+	case DBMSOracle:
+		return "HEXTORAW('" + encoded + "')"
+	}
+	return "X'" + encoded + "'"
+}
+
+// quoteString returns the string literal of s, doubling its quotes, and its backslashes for MySQL
+func quoteString(s string, dbms DBMSType) string {
+	var builder strings.Builder
+	builder.Grow(len(s) + 2)
+	builder.WriteByte('\'')
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\'':
+			builder.WriteString("''")
+		case s[i] == '\\' && dbms == DBMSMySQL:
+			builder.WriteString(`\\`)
+		default:
+			builder.WriteByte(s[i])
+		}
+	}
+	builder.WriteByte('\'')
+	return builder.String()
+}
@@ -0,0 +1,125 @@
+package sqllexer
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpolate(t *testing.T) {
+	tests := []struct {
+		name      string
+		lexerOpts []lexerOption
+		input     string
+		args      []any
+		expected  string
+	}{
+		{
+			name:     "question marks",
+			input:    "SELECT * FROM t WHERE a = ? AND b IN (?, ?) AND c = '?'",
+			args:     []any{1, "it's", nil},
+			expected: "SELECT * FROM t WHERE a = 1 AND b IN ('it''s', NULL) AND c = '?'",
+		},
+		{
+			name:     "missing args",
+			input:    "SELECT * FROM t WHERE a = ? AND b = ?",
+			args:     []any{true},
+			expected: "SELECT * FROM t WHERE a = TRUE AND b = ?",
+		},
+		{
+			name:      "dollar",
+			lexerOpts: []lexerOption{WithDBMS(DBMSPostgres)},
+			input:     "SELECT * FROM t WHERE a = $2 OR b = $1 OR c = $2",
+			args:      []any{1.5, int64(-3)},
+			expected:  "SELECT * FROM t WHERE a = -3 OR b = 1.5 OR c = -3",
+		},
+		{
+			name:      "named args",
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+			input:     "SELECT * FROM t WHERE a = @a AND b = @b AND c = @a",
+			args:      []any{sql.Named("b", false), sql.Named("a", "x")},
+			expected:  "SELECT * FROM t WHERE a = 'x' AND b = 0 AND c = 'x'",
+		},
+		{
+			name:      "named placeholders bound by position",
+			lexerOpts: []lexerOption{WithDBMS(DBMSOracle)},
+			input:     "UPDATE t SET a = :a, b = :b WHERE c = :a",
+			args:      []any{uint8(1), 2},
+			expected:  "UPDATE t SET a = 1, b = 2 WHERE c = 1",
+		},
+		{
+			name:     "format",
+			input:    "SELECT a % 2 FROM t WHERE b = %s AND c = %(c)s",
+			args:     []any{"v", sql.Named("c", float32(0.5))},
+			expected: "SELECT a % 2 FROM t WHERE b = 'v' AND c = 0.5",
+		},
+		{
+			name:      "mysql backslash",
+			lexerOpts: []lexerOption{WithDBMS(DBMSMySQL)},
+			input:     "SELECT * FROM t WHERE path = ?",
+			args:      []any{`C:\tmp\'x`},
+			expected:  `SELECT * FROM t WHERE path = 'C:\\tmp\\''x'`,
+		},
+		{
+			name:      "postgres backslash",
+			lexerOpts: []lexerOption{WithDBMS(DBMSPostgres)},
+			input:     "SELECT * FROM t WHERE path = $1",
+			args:      []any{`C:\tmp`},
+			expected:  `SELECT * FROM t WHERE path = 'C:\tmp'`,
+		},
+		{
+			name:     "json operator with dollar placeholders",
+			input:    "SELECT * FROM t WHERE data ? 'k' AND id = $1",
+			args:     []any{42},
+			expected: "SELECT * FROM t WHERE data ? 'k' AND id = 42",
+		},
+		{
+			name:      "postgres json operators",
+			lexerOpts: []lexerOption{WithDBMS(DBMSPostgres)},
+			input:     "SELECT * FROM t WHERE data ? 'k' AND tags ?| array['a'] AND tags ?& array['b'] AND id = $1 AND a = $2",
+			args:      []any{42, "x"},
+			expected:  "SELECT * FROM t WHERE data ? 'k' AND tags ?| array['a'] AND tags ?& array['b'] AND id = 42 AND a = 'x'",
+		},
+		{
+			name:      "bytes",
+			lexerOpts: []lexerOption{WithDBMS(DBMSPostgres)},
+			input:     "INSERT INTO t VALUES ($1)",
+			args:      []any{[]byte{0xde, 0xad}},
+			expected:  `INSERT INTO t VALUES ('\xdead')`,
+		},
+		{
+			name:      "bytes sql server",
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+			input:     "INSERT INTO t VALUES (@p1)",
+			args:      []any{[]byte{0xde, 0xad}},
+			expected:  "INSERT INTO t VALUES (0xdead)",
+		},
+		{
+			name:     "time and valuer",
+			input:    "INSERT INTO t VALUES (?, ?, ?, ?)",
+			args:     []any{time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), sql.NullString{}, sql.NullInt64{Int64: 7, Valid: true}, math.Inf(1)},
+			expected: "INSERT INTO t VALUES ('2024-01-02 03:04:05Z', NULL, 7, '+Inf')",
+		},
+		{
+			name:     "other types",
+			input:    "SELECT ?",
+			args:     []any{time.Second},
+			expected: "SELECT '1s'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Interpolate(tt.input, tt.args, tt.lexerOpts...))
+		})
+	}
+}
+
+func ExampleInterpolate() {
+	fmt.Println(Interpolate("SELECT * FROM users WHERE name = ? AND age > ?", []any{"O'Brien", 30}))
+	// Output: SELECT * FROM users WHERE name = 'O''Brien' AND age > 30
+}
@@ -0,0 +1,422 @@
+// Code generated by gen_keywords.go; DO NOT EDIT.
+
+//go:build !tinygo
+
+package sqllexer
+
+// lookupKeyword returns the keyword the ASCII word is, case-insensitively, or nil if it is not one.
+// The candidates are found by the length and the first letter of the word, then compared.
+func lookupKeyword(word string) *keyword {
+	switch len(word) {
+	case 2:
+		switch word[0] | 0x20 {
+		case 'a':
+			if equalFoldASCII(word, "AS") {
+				return &keywordTable[0]
+			}
+		case 'b':
+			if equalFoldASCII(word, "BY") {
+				return &keywordTable[1]
+			}
+		case 'i':
+			if equalFoldASCII(word, "IF") {
+				return &keywordTable[2]
+			}
+			if equalFoldASCII(word, "IN") {
+				return &keywordTable[3]
+			}
+			if equalFoldASCII(word, "IS") {
+				return &keywordTable[4]
+			}
+		case 'o':
+			if equalFoldASCII(word, "OF") {
+				return &keywordTable[5]
+			}
+			if equalFoldASCII(word, "ON") {
+				return &keywordTable[6]
+			}
+			if equalFoldASCII(word, "OR") {
+				return &keywordTable[7]
+			}
+		}
+	case 3:
+		switch word[0] | 0x20 {
+		case 'a':
+			if equalFoldASCII(word, "ADD") {
+				return &keywordTable[8]
+			}
+			if equalFoldASCII(word, "ALL") {
+				return &keywordTable[9]
+			}
+			if equalFoldASCII(word, "AND") {
+				return &keywordTable[10]
+			}
+			if equalFoldASCII(word, "ANY") {
+				return &keywordTable[11]
+			}
+			if equalFoldASCII(word, "ASC") {
+				return &keywordTable[12]
+			}
+		case 'e':
+			if equalFoldASCII(word, "END") {
+				return &keywordTable[13]
+			}
+		case 'k':
+			if equalFoldASCII(word, "KEY") {
+				return &keywordTable[14]
+			}
+		case 'n':
+			if equalFoldASCII(word, "NOT") {
+				return &keywordTable[15]
+			}
+		case 'o':
+			if equalFoldASCII(word, "OUT") {
+				return &keywordTable[16]
+			}
+		case 's':
+			if equalFoldASCII(word, "SET") {
+				return &keywordTable[17]
+			}
+		case 't':
+			if equalFoldASCII(word, "TOP") {
+				return &keywordTable[18]
+			}
+		case 'u':
+			if equalFoldASCII(word, "USE") {
+				return &keywordTable[19]
+			}
+		}
+	case 4:
+		switch word[0] | 0x20 {
+		case 'c':
+			if equalFoldASCII(word, "CASE") {
+				return &keywordTable[20]
+			}
+			if equalFoldASCII(word, "COPY") {
+				return &keywordTable[21]
+			}
+			if equalFoldASCII(word, "CUBE") {
+				return &keywordTable[22]
+			}
+		case 'd':
+			if equalFoldASCII(word, "DESC") {
+				return &keywordTable[23]
+			}
+			if equalFoldASCII(word, "DROP") {
+				return &keywordTable[24]
+			}
+		case 'e':
+			if equalFoldASCII(word, "ELSE") {
+				return &keywordTable[25]
+			}
+			if equalFoldASCII(word, "EXEC") {
+				return &keywordTable[26]
+			}
+		case 'f':
+			if equalFoldASCII(word, "FROM") {
+				return &keywordTable[27]
+			}
+		case 'i':
+			if equalFoldASCII(word, "INTO") {
+				return &keywordTable[28]
+			}
+		case 'j':
+			if equalFoldASCII(word, "JOIN") {
+				return &keywordTable[29]
+			}
+		case 'l':
+			if equalFoldASCII(word, "LEFT") {
+				return &keywordTable[30]
+			}
+			if equalFoldASCII(word, "LIKE") {
+				return &keywordTable[31]
+			}
+		case 'n':
+			if equalFoldASCII(word, "NULL") {
+				return &keywordTable[32]
+			}
+		case 'o':
+			if equalFoldASCII(word, "ONLY") {
+				return &keywordTable[33]
+			}
+		case 'p':
+			if equalFoldASCII(word, "PROC") {
+				return &keywordTable[34]
+			}
+		case 's':
+			if equalFoldASCII(word, "SKIP") {
+				return &keywordTable[35]
+			}
+			if equalFoldASCII(word, "SOME") {
+				return &keywordTable[36]
+			}
+		case 't':
+			if equalFoldASCII(word, "TRUE") {
+				return &keywordTable[37]
+			}
+		case 'v':
+			if equalFoldASCII(word, "VIEW") {
+				return &keywordTable[38]
+			}
+		case 'w':
+			if equalFoldASCII(word, "WITH") {
+				return &keywordTable[39]
+			}
+		}
+	case 5:
+		switch word[0] | 0x20 {
+		case 'a':
+			if equalFoldASCII(word, "ALTER") {
+				return &keywordTable[40]
+			}
+		case 'b':
+			if equalFoldASCII(word, "BEGIN") {
+				return &keywordTable[41]
+			}
+		case 'c':
+			if equalFoldASCII(word, "CHECK") {
+				return &keywordTable[42]
+			}
+			if equalFoldASCII(word, "CLONE") {
+				return &keywordTable[43]
+			}
+		case 'f':
+			if equalFoldASCII(word, "FALSE") {
+				return &keywordTable[44]
+			}
+		case 'g':
+			if equalFoldASCII(word, "GRANT") {
+				return &keywordTable[45]
+			}
+			if equalFoldASCII(word, "GROUP") {
+				return &keywordTable[46]
+			}
+		case 'i':
+			if equalFoldASCII(word, "ILIKE") {
+				return &keywordTable[47]
+			}
+			if equalFoldASCII(word, "INDEX") {
+				return &keywordTable[48]
+			}
+			if equalFoldASCII(word, "INNER") {
+				return &keywordTable[49]
+			}
+		case 'l':
+			if equalFoldASCII(word, "LIMIT") {
+				return &keywordTable[50]
+			}
+		case 'm':
+			if equalFoldASCII(word, "MERGE") {
+				return &keywordTable[51]
+			}
+		case 'o':
+			if equalFoldASCII(word, "ORDER") {
+				return &keywordTable[52]
+			}
+			if equalFoldASCII(word, "OUTER") {
+				return &keywordTable[53]
+			}
+		case 'r':
+			if equalFoldASCII(word, "RIGHT") {
+				return &keywordTable[54]
+			}
+		case 't':
+			if equalFoldASCII(word, "TABLE") {
+				return &keywordTable[55]
+			}
+		case 'u':
+			if equalFoldASCII(word, "UNION") {
+				return &keywordTable[56]
+			}
+			if equalFoldASCII(word, "USING") {
+				return &keywordTable[57]
+			}
+		case 'w':
+			if equalFoldASCII(word, "WHERE") {
+				return &keywordTable[58]
+			}
+		}
+	case 6:
+		switch word[0] | 0x20 {
+		case 'c':
+			if equalFoldASCII(word, "COLUMN") {
+				return &keywordTable[59]
+			}
+			if equalFoldASCII(word, "COMMIT") {
+				return &keywordTable[60]
+			}
+			if equalFoldASCII(word, "CREATE") {
+				return &keywordTable[61]
+			}
+		case 'd':
+			if equalFoldASCII(word, "DELETE") {
+				return &keywordTable[62]
+			}
+			if equalFoldASCII(word, "DOMAIN") {
+				return &keywordTable[63]
+			}
+		case 'e':
+			if equalFoldASCII(word, "EXISTS") {
+				return &keywordTable[64]
+			}
+		case 'h':
+			if equalFoldASCII(word, "HAVING") {
+				return &keywordTable[65]
+			}
+		case 'i':
+			if equalFoldASCII(word, "INSERT") {
+				return &keywordTable[66]
+			}
+		case 'o':
+			if equalFoldASCII(word, "OFFSET") {
+				return &keywordTable[67]
+			}
+		case 'r':
+			if equalFoldASCII(word, "REVOKE") {
+				return &keywordTable[68]
+			}
+			if equalFoldASCII(word, "ROLLUP") {
+				return &keywordTable[69]
+			}
+			if equalFoldASCII(word, "ROWNUM") {
+				return &keywordTable[70]
+			}
+		case 's':
+			if equalFoldASCII(word, "SELECT") {
+				return &keywordTable[71]
+			}
+		case 'u':
+			if equalFoldASCII(word, "UNIQUE") {
+				return &keywordTable[72]
+			}
+			if equalFoldASCII(word, "UPDATE") {
+				return &keywordTable[73]
+			}
+		case 'v':
+			if equalFoldASCII(word, "VACCUM") {
+				return &keywordTable[74]
+			}
+			if equalFoldASCII(word, "VALUES") {
+				return &keywordTable[75]
+			}
+		case 'w':
+			if equalFoldASCII(word, "WINDOW") {
+				return &keywordTable[76]
+			}
+		}
+	case 7:
+		switch word[0] | 0x20 {
+		case 'a':
+			if equalFoldASCII(word, "ANALYZE") {
+				return &keywordTable[77]
+			}
+		case 'b':
+			if equalFoldASCII(word, "BETWEEN") {
+				return &keywordTable[78]
+			}
+		case 'c':
+			if equalFoldASCII(word, "CLUSTER") {
+				return &keywordTable[79]
+			}
+		case 'd':
+			if equalFoldASCII(word, "DECLARE") {
+				return &keywordTable[80]
+			}
+			if equalFoldASCII(word, "DEFAULT") {
+				return &keywordTable[81]
+			}
+		case 'e':
+			if equalFoldASCII(word, "EXECUTE") {
+				return &keywordTable[82]
+			}
+			if equalFoldASCII(word, "EXPLAIN") {
+				return &keywordTable[83]
+			}
+		case 'f':
+			if equalFoldASCII(word, "FOREIGN") {
+				return &keywordTable[84]
+			}
+		case 'l':
+			if equalFoldASCII(word, "LITERAL") {
+				return &keywordTable[85]
+			}
+		case 'p':
+			if equalFoldASCII(word, "PLPGSQL") {
+				return &keywordTable[86]
+			}
+			if equalFoldASCII(word, "PRIMARY") {
+				return &keywordTable[87]
+			}
+		case 'r':
+			if equalFoldASCII(word, "REPLACE") {
+				return &keywordTable[88]
+			}
+			if equalFoldASCII(word, "RETURNS") {
+				return &keywordTable[89]
+			}
+		case 't':
+			if equalFoldASCII(word, "TRIGGER") {
+				return &keywordTable[90]
+			}
+		}
+	case 8:
+		switch word[0] | 0x20 {
+		case 'd':
+			if equalFoldASCII(word, "DATABASE") {
+				return &keywordTable[91]
+			}
+			if equalFoldASCII(word, "DISTINCT") {
+				return &keywordTable[92]
+			}
+		case 'r':
+			if equalFoldASCII(word, "ROLLBACK") {
+				return &keywordTable[93]
+			}
+		case 't':
+			if equalFoldASCII(word, "TRUNCATE") {
+				return &keywordTable[94]
+			}
+		case 'u':
+			if equalFoldASCII(word, "UNLOGGED") {
+				return &keywordTable[95]
+			}
+		}
+	case 9:
+		switch word[0] | 0x20 {
+		case 'a':
+			if equalFoldASCII(word, "ASSERTION") {
+				return &keywordTable[96]
+			}
+		case 'p':
+			if equalFoldASCII(word, "PROCEDURE") {
+				return &keywordTable[97]
+			}
+		case 'r':
+			if equalFoldASCII(word, "RECURSIVE") {
+				return &keywordTable[98]
+			}
+			if equalFoldASCII(word, "RETURNING") {
+				return &keywordTable[99]
+			}
+		case 't':
+			if equalFoldASCII(word, "TEMPORARY") {
+				return &keywordTable[100]
+			}
+		}
+	case 10:
+		switch word[0] | 0x20 {
+		case 'c':
+			if equalFoldASCII(word, "CONSTRAINT") {
+				return &keywordTable[101]
+			}
+		}
+	case 13:
+		switch word[0] | 0x20 {
+		case 's':
+			if equalFoldASCII(word, "STRAIGHT_JOIN") {
+				return &keywordTable[102]
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+//go:build tinygo
+
+package sqllexer
+
+// lookupKeyword returns the keyword the ASCII word is, case-insensitively, or nil if it is not one.
+// TinyGo builds, e.g. WebAssembly filters, binary search the sorted keyword table rather than compiling
+// the generated switch, which is much smaller in the binary for a few more comparisons per word.
+func lookupKeyword(word string) *keyword {
+	low, high := 0, len(keywordTable)
+	for low < high {
+		mid := int(uint(low+high) >> 1)
+		if compareKeyword(keywordTable[mid].value, word) < 0 {
+			low = mid + 1
+		} else {
+			high = mid
+		}
+	}
+	if low < len(keywordTable) && compareKeyword(keywordTable[low].value, word) == 0 {
+		return &keywordTable[low]
+	}
+	return nil
+}
+
+// compareKeyword compares an uppercase keyword to an ASCII word, case-insensitively,
+// in the order of the keyword table: by length, then by value
+func compareKeyword(value, word string) int {
+	if len(value) != len(word) {
+		if len(value) < len(word) {
+			return -1
+		}
+		return 1
+	}
+	for i := 0; i < len(value); i++ {
+		c := word[i]
+		if 'a' <= c && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		if value[i] != c {
+			if value[i] < c {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,110 @@
+// Code generated by gen_keywords.go; DO NOT EDIT.
+
+package sqllexer
+
+// keywordTable are the keywords of the lexer, sorted by length and value
+var keywordTable = [...]keyword{
+	{value: "AS", lower: "as", tokenType: ALIAS_INDICATOR, isTableIndicator: false},
+	{value: "BY", lower: "by", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "IF", lower: "if", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "IN", lower: "in", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "IS", lower: "is", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "OF", lower: "of", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "ON", lower: "on", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "OR", lower: "or", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "ADD", lower: "add", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "ALL", lower: "all", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "AND", lower: "and", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "ANY", lower: "any", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "ASC", lower: "asc", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "END", lower: "end", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "KEY", lower: "key", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "NOT", lower: "not", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "OUT", lower: "out", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "SET", lower: "set", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "TOP", lower: "top", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "USE", lower: "use", tokenType: COMMAND, isTableIndicator: false},
+	{value: "CASE", lower: "case", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "COPY", lower: "copy", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "CUBE", lower: "cube", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "DESC", lower: "desc", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "DROP", lower: "drop", tokenType: COMMAND, isTableIndicator: false},
+	{value: "ELSE", lower: "else", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "EXEC", lower: "exec", tokenType: COMMAND, isTableIndicator: false},
+	{value: "FROM", lower: "from", tokenType: KEYWORD, isTableIndicator: true},
+	{value: "INTO", lower: "into", tokenType: KEYWORD, isTableIndicator: true},
+	{value: "JOIN", lower: "join", tokenType: COMMAND, isTableIndicator: true},
+	{value: "LEFT", lower: "left", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "LIKE", lower: "like", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "NULL", lower: "null", tokenType: NULL, isTableIndicator: false},
+	{value: "ONLY", lower: "only", tokenType: KEYWORD, isTableIndicator: true},
+	{value: "PROC", lower: "proc", tokenType: PROC_INDICATOR, isTableIndicator: false},
+	{value: "SKIP", lower: "skip", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "SOME", lower: "some", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "TRUE", lower: "true", tokenType: BOOLEAN, isTableIndicator: false},
+	{value: "VIEW", lower: "view", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "WITH", lower: "with", tokenType: CTE_INDICATOR, isTableIndicator: false},
+	{value: "ALTER", lower: "alter", tokenType: COMMAND, isTableIndicator: false},
+	{value: "BEGIN", lower: "begin", tokenType: COMMAND, isTableIndicator: false},
+	{value: "CHECK", lower: "check", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "CLONE", lower: "clone", tokenType: COMMAND, isTableIndicator: true},
+	{value: "FALSE", lower: "false", tokenType: BOOLEAN, isTableIndicator: false},
+	{value: "GRANT", lower: "grant", tokenType: COMMAND, isTableIndicator: false},
+	{value: "GROUP", lower: "group", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "ILIKE", lower: "ilike", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "INDEX", lower: "index", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "INNER", lower: "inner", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "LIMIT", lower: "limit", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "MERGE", lower: "merge", tokenType: COMMAND, isTableIndicator: false},
+	{value: "ORDER", lower: "order", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "OUTER", lower: "outer", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "RIGHT", lower: "right", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "TABLE", lower: "table", tokenType: KEYWORD, isTableIndicator: true},
+	{value: "UNION", lower: "union", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "USING", lower: "using", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "WHERE", lower: "where", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "COLUMN", lower: "column", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "COMMIT", lower: "commit", tokenType: COMMAND, isTableIndicator: false},
+	{value: "CREATE", lower: "create", tokenType: COMMAND, isTableIndicator: false},
+	{value: "DELETE", lower: "delete", tokenType: COMMAND, isTableIndicator: false},
+	{value: "DOMAIN", lower: "domain", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "EXISTS", lower: "exists", tokenType: KEYWORD, isTableIndicator: true},
+	{value: "HAVING", lower: "having", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "INSERT", lower: "insert", tokenType: COMMAND, isTableIndicator: false},
+	{value: "OFFSET", lower: "offset", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "REVOKE", lower: "revoke", tokenType: COMMAND, isTableIndicator: false},
+	{value: "ROLLUP", lower: "rollup", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "ROWNUM", lower: "rownum", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "SELECT", lower: "select", tokenType: COMMAND, isTableIndicator: false},
+	{value: "UNIQUE", lower: "unique", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "UPDATE", lower: "update", tokenType: COMMAND, isTableIndicator: true},
+	{value: "VACCUM", lower: "vaccum", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "VALUES", lower: "values", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "WINDOW", lower: "window", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "ANALYZE", lower: "analyze", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "BETWEEN", lower: "between", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "CLUSTER", lower: "cluster", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "DECLARE", lower: "declare", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "DEFAULT", lower: "default", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "EXECUTE", lower: "execute", tokenType: COMMAND, isTableIndicator: false},
+	{value: "EXPLAIN", lower: "explain", tokenType: COMMAND, isTableIndicator: false},
+	{value: "FOREIGN", lower: "foreign", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "LITERAL", lower: "literal", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "PLPGSQL", lower: "plpgsql", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "PRIMARY", lower: "primary", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "REPLACE", lower: "replace", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "RETURNS", lower: "returns", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "TRIGGER", lower: "trigger", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "DATABASE", lower: "database", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "DISTINCT", lower: "distinct", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "ROLLBACK", lower: "rollback", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "TRUNCATE", lower: "truncate", tokenType: COMMAND, isTableIndicator: false},
+	{value: "UNLOGGED", lower: "unlogged", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "ASSERTION", lower: "assertion", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "PROCEDURE", lower: "procedure", tokenType: PROC_INDICATOR, isTableIndicator: false},
+	{value: "RECURSIVE", lower: "recursive", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "RETURNING", lower: "returning", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "TEMPORARY", lower: "temporary", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "CONSTRAINT", lower: "constraint", tokenType: KEYWORD, isTableIndicator: false},
+	{value: "STRAIGHT_JOIN", lower: "straight_join", tokenType: COMMAND, isTableIndicator: true},
+}
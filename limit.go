@@ -0,0 +1,179 @@
+package sqllexer
+
+import (
+	"strconv"
+	"strings"
+)
+
+// limitToken is a value token of the query and its parentheses depth
+type limitToken struct {
+	Token
+	depth int
+}
+
+// EnsureLimit returns the SELECT query capped to n rows, to protect ad-hoc query frontends from unbounded results.
+// A query without row cap gets one, LIMIT n by default, TOP n for SQL Server, or FETCH NEXT n ROWS ONLY for
+// SQL Server queries with OFFSET, and FETCH FIRST n ROWS ONLY for Oracle, the DBMS being the one of the lexer options.
+// A query with a LIMIT, TOP or FETCH clause greater than n gets it lowered to n.
+// Caps that are not numbers of rows, e.g. LIMIT $1 or TOP 10 PERCENT, are kept, and so are queries other than SELECT,
+// SQL Server set operations and parenthesized queries, which TOP cannot cap, and Oracle queries locking their rows,
+// e.g. with FOR UPDATE, which cannot be combined with FETCH FIRST. Only the first statement of the query is capped.
+func EnsureLimit(query string, n int, lexerOpts ...lexerOption) string {
+	lexer := New(query, lexerOpts...)
+	dbms := lexer.config.DBMS
+
+	var tokens []limitToken
+	depth := 0
+	for {
+		token := lexer.Scan()
+		if token.Type == EOF || (depth == 0 && token.Type == PUNCTUATION && token.Value == ";") {
+			break
+		}
+		if !isValueToken(token) {
+			continue
+		}
+		if token.Type == PUNCTUATION && token.Value == ")" && depth > 0 {
+			depth--
+		}
+		tokens = append(tokens, limitToken{Token: *token, depth: depth})
+		if token.Type == PUNCTUATION && token.Value == "(" {
+			depth++
+		}
+	}
+	if !isSelectQuery(tokens) {
+		return query
+	}
+
+	limit := strconv.Itoa(n)
+	var edits []queryEdit
+	capped, hasOffset, hasSetOperation := false, false, false
+	selectIndex, lockingIndex := -1, -1
+	for i := range tokens {
+		token := &tokens[i]
+		if token.depth > 0 || (token.Type != KEYWORD && token.Type != IDENT && token.Type != COMMAND) {
+			continue
+		}
+		switch ToUpperASCII(token.Value) {
+		case "SELECT":
+			if selectIndex < 0 {
+				selectIndex = i
+			}
+		case "UNION", "INTERSECT", "EXCEPT", "MINUS":
+			hasSetOperation = true
+		case "OFFSET":
+			hasOffset = true
+		case "FOR":
+			if lockingIndex < 0 {
+				lockingIndex = i
+			}
+		case "LIMIT":
+			capped = true
+			count := i + 1
+			if count+2 < len(tokens) && tokens[count].Type == NUMBER && tokens[count+1].Value == "," {
+				// MySQL LIMIT offset, count
+				count += 2
+			}
+			edits = lowerLimit(edits, tokens, count, n)
+		case "TOP":
+			count, next := i+1, i+2 // the row count and the token following it
+			if count < len(tokens) && tokens[count].Value == "(" {
+				// TOP (n), the closing parenthesis following the row count
+				count, next = count+1, count+3
+			}
+			capped = true
+			if next < len(tokens) && equalFoldASCII(tokens[next].Value, "PERCENT") {
+				continue
+			}
+			edits = lowerLimit(edits, tokens, count, n)
+		case "FETCH":
+			if i+1 < len(tokens) && (equalFoldASCII(tokens[i+1].Value, "FIRST") || equalFoldASCII(tokens[i+1].Value, "NEXT")) {
+				capped = true
+				edits = lowerLimit(edits, tokens, i+2, n)
+			}
+		}
+	}
+
+	if !capped {
+		end := tokens[len(tokens)-1].End
+		switch {
+		case dbms == DBMSSQLServer && hasOffset:
+			edits = append(edits, queryEdit{start: end, end: end, text: " FETCH NEXT " + limit + " ROWS ONLY"})
+		case dbms == DBMSSQLServer && (hasSetOperation || selectIndex < 0):
+			// the SELECT of a parenthesized query is not at depth 0, e.g. (SELECT a FROM t)
+			return query
+		case dbms == DBMSSQLServer:
+			position := selectIndex
+			if position+1 < len(tokens) && (equalFoldASCII(tokens[position+1].Value, "DISTINCT") || equalFoldASCII(tokens[position+1].Value, "ALL")) {
+				position++
+			}
+			end := tokens[position].End
+			edits = append(edits, queryEdit{start: end, end: end, text: " TOP " + limit})
+		case dbms == DBMSOracle && lockingIndex > 0:
+			// FETCH FIRST with FOR UPDATE fails with ORA-02014
+			return query
+		case dbms == DBMSOracle:
+			edits = append(edits, queryEdit{start: end, end: end, text: " FETCH FIRST " + limit + " ROWS ONLY"})
+		case lockingIndex > 0:
+			// the limit precedes the locking clause, e.g. FOR UPDATE
+			end = tokens[lockingIndex-1].End
+			fallthrough
+		default:
+			edits = append(edits, queryEdit{start: end, end: end, text: " LIMIT " + limit})
+		}
+	}
+	return applyEdits(query, edits)
+}
+
+// isSelectQuery returns true if the first command of the query, following its CTE definitions, is SELECT
+func isSelectQuery(tokens []limitToken) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	// a parenthesized query, e.g. (SELECT ...) UNION (SELECT ...), has no command at depth 0
+	depth := 0
+	if tokens[0].Value == "(" {
+		depth = 1
+	}
+	for _, token := range tokens {
+		if token.Type == COMMAND && token.depth <= depth {
+			return equalFoldASCII(token.Value, "SELECT")
+		}
+	}
+	return false
+}
+
+// lowerLimit appends the edit replacing the row count at tokens[i] by n, if it is a number greater than n or ALL
+func lowerLimit(edits []queryEdit, tokens []limitToken, i int, n int) []queryEdit {
+	if i >= len(tokens) {
+		return edits
+	}
+	token := &tokens[i]
+	if token.Type == NUMBER {
+		if count, err := strconv.Atoi(token.Value); err != nil || count <= n {
+			return edits
+		}
+	} else if !equalFoldASCII(token.Value, "ALL") {
+		return edits
+	}
+	return append(edits, queryEdit{start: token.Start, end: token.End, text: strconv.Itoa(n)})
+}
+
+// queryEdit replaces query[start:end] by text
+type queryEdit struct {
+	start, end int
+	text       string
+}
+
+// applyEdits returns the query with the edits, sorted by position and not overlapping, applied
+func applyEdits(query string, edits []queryEdit) string {
+	var builder strings.Builder
+	builder.Grow(len(query) + 32)
+	last := 0
+	for _, edit := range edits {
+		builder.WriteString(query[last:edit.start])
+		builder.WriteString(edit.text)
+		last = edit.end
+	}
+	builder.WriteString(query[last:])
+	return builder.String()
+}
@@ -0,0 +1,154 @@
+package sqllexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnsureLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		lexerOpts []lexerOption
+		expected  string
+	}{
+		{
+			name:     "no limit",
+			input:    "SELECT * FROM t",
+			expected: "SELECT * FROM t LIMIT 100",
+		},
+		{
+			name:     "subquery limit and terminator",
+			input:    "SELECT * FROM t WHERE a IN (SELECT b FROM u LIMIT 5) ORDER BY a; -- comment",
+			expected: "SELECT * FROM t WHERE a IN (SELECT b FROM u LIMIT 5) ORDER BY a LIMIT 100; -- comment",
+		},
+		{
+			name:     "lower limit",
+			input:    "SELECT * FROM t LIMIT 500 OFFSET 3",
+			expected: "SELECT * FROM t LIMIT 100 OFFSET 3",
+		},
+		{
+			name:     "keep lower limit",
+			input:    "SELECT * FROM t LIMIT 5",
+			expected: "SELECT * FROM t LIMIT 5",
+		},
+		{
+			name:     "limit all",
+			input:    "SELECT * FROM t LIMIT ALL",
+			expected: "SELECT * FROM t LIMIT 100",
+		},
+		{
+			name:     "keep parameter",
+			input:    "SELECT * FROM t LIMIT $1",
+			expected: "SELECT * FROM t LIMIT $1",
+		},
+		{
+			name:      "mysql offset and count",
+			input:     "SELECT * FROM t LIMIT 10, 500",
+			lexerOpts: []lexerOption{WithDBMS(DBMSMySQL)},
+			expected:  "SELECT * FROM t LIMIT 10, 100",
+		},
+		{
+			name:     "locking clause",
+			input:    "SELECT * FROM t WHERE id = 1 FOR UPDATE",
+			expected: "SELECT * FROM t WHERE id = 1 LIMIT 100 FOR UPDATE",
+		},
+		{
+			name:     "cte and set operation",
+			input:    "WITH x AS (SELECT 1) SELECT * FROM x UNION SELECT 2",
+			expected: "WITH x AS (SELECT 1) SELECT * FROM x UNION SELECT 2 LIMIT 100",
+		},
+		{
+			name:     "parenthesized query",
+			input:    "(SELECT 1) UNION (SELECT 2)",
+			expected: "(SELECT 1) UNION (SELECT 2) LIMIT 100",
+		},
+		{
+			name:     "not a select",
+			input:    "WITH x AS (SELECT 1) DELETE FROM t WHERE id IN (SELECT * FROM x)",
+			expected: "WITH x AS (SELECT 1) DELETE FROM t WHERE id IN (SELECT * FROM x)",
+		},
+		{
+			name:      "sql server top",
+			input:     "SELECT DISTINCT a FROM t",
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+			expected:  "SELECT DISTINCT TOP 100 a FROM t",
+		},
+		{
+			name:      "sql server lower top",
+			input:     "SELECT TOP (1000) a FROM t",
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+			expected:  "SELECT TOP (100) a FROM t",
+		},
+		{
+			name:      "sql server top percent",
+			input:     "SELECT TOP 50 PERCENT a FROM t",
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+			expected:  "SELECT TOP 50 PERCENT a FROM t",
+		},
+		{
+			name:      "sql server offset",
+			input:     "SELECT a FROM t ORDER BY a OFFSET 5 ROWS",
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+			expected:  "SELECT a FROM t ORDER BY a OFFSET 5 ROWS FETCH NEXT 100 ROWS ONLY",
+		},
+		{
+			name:      "sql server set operation",
+			input:     "SELECT a FROM t UNION SELECT a FROM u",
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+			expected:  "SELECT a FROM t UNION SELECT a FROM u",
+		},
+		{
+			name:      "sql server parenthesized query",
+			input:     "(SELECT a FROM t)",
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+			expected:  "(SELECT a FROM t)",
+		},
+		{
+			name:      "sql server unbalanced parenthesis",
+			input:     "(SELECT * FROM",
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+			expected:  "(SELECT * FROM",
+		},
+		{
+			name:      "oracle",
+			input:     "SELECT a FROM t",
+			lexerOpts: []lexerOption{WithDBMS(DBMSOracle)},
+			expected:  "SELECT a FROM t FETCH FIRST 100 ROWS ONLY",
+		},
+		{
+			name:      "oracle lower fetch",
+			input:     "SELECT a FROM t FETCH FIRST 1000 ROWS ONLY",
+			lexerOpts: []lexerOption{WithDBMS(DBMSOracle)},
+			expected:  "SELECT a FROM t FETCH FIRST 100 ROWS ONLY",
+		},
+		{
+			name:      "oracle for update",
+			input:     "SELECT a FROM t WHERE id = 1 FOR UPDATE OF a",
+			lexerOpts: []lexerOption{WithDBMS(DBMSOracle)},
+			expected:  "SELECT a FROM t WHERE id = 1 FOR UPDATE OF a",
+		},
+		{
+			name:      "oracle lower fetch for update",
+			input:     "SELECT a FROM t FETCH FIRST 1000 ROWS ONLY FOR UPDATE",
+			lexerOpts: []lexerOption{WithDBMS(DBMSOracle)},
+			expected:  "SELECT a FROM t FETCH FIRST 100 ROWS ONLY FOR UPDATE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, EnsureLimit(tt.input, 100, tt.lexerOpts...))
+		})
+	}
+}
+
+func ExampleEnsureLimit() {
+	fmt.Println(EnsureLimit("SELECT * FROM orders ORDER BY created_at DESC", 1000))
+	fmt.Println(EnsureLimit("SELECT * FROM orders", 1000, WithDBMS(DBMSSQLServer)))
+	// Output:
+	// SELECT * FROM orders ORDER BY created_at DESC LIMIT 1000
+	// SELECT TOP 1000 * FROM orders
+}
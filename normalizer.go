@@ -1,8 +1,13 @@
 package sqllexer
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
+	"sync"
+	"unicode"
 )
 
 type normalizerConfig struct {
@@ -18,6 +23,18 @@ type normalizerConfig struct {
 	// CollectProcedure specifies whether the normalizer should extract and return procedure name as SQL metadata
 	CollectProcedure bool `json:"collect_procedure"`
 
+	// CollectColumns specifies whether the normalizer should extract and return the column names
+	// referenced in SELECT lists, WHERE/ON predicates and GROUP BY/ORDER BY clauses as SQL metadata
+	CollectColumns bool `json:"collect_columns"`
+
+	// CollectJoins specifies whether the normalizer should extract and return the joins of the statement,
+	// with their type and the pair of tables they join, as SQL metadata
+	CollectJoins bool `json:"collect_joins"`
+
+	// CollectOrdering specifies whether the normalizer should extract and return the expressions
+	// of the GROUP BY and ORDER BY clauses as SQL metadata, with their ordinal if they reference a select list item
+	CollectOrdering bool `json:"collect_ordering"`
+
 	// KeepSQLAlias specifies whether SQL aliases ("AS") should be truncated.
 	KeepSQLAlias bool `json:"keep_sql_alias"`
 
@@ -35,6 +52,96 @@ type normalizerConfig struct {
 
 	// KeepIdentifierQuotation specifies whether the normalizer should keep the quotation of identifiers.
 	KeepIdentifierQuotation bool `json:"keep_identifier_quotation"`
+
+	// CollapseInsertColumns specifies whether the normalizer should collapse the column list and the value lists
+	// of INSERT statements into a single "( ... )" group, so inserts into the same table normalize identically
+	// regardless of the columns listed and the number of rows inserted.
+	CollapseInsertColumns bool `json:"collapse_insert_columns"`
+
+	// CanonicalizeInList specifies whether the normalizer should replace IN lists made only of literals
+	// with a single placeholder, so the order and the number of values do not produce distinct normalized SQL.
+	CanonicalizeInList bool `json:"canonicalize_in_list"`
+
+	// CanonicalizeNullBoolean specifies whether the normalizer should uppercase NULL, TRUE and FALSE,
+	// so "null", "Null" and "NULL" normalize identically.
+	CanonicalizeNullBoolean bool `json:"canonicalize_null_boolean"`
+
+	// NotEqualOperator specifies the spelling, "<>" or "!=", that both not-equal operators should be normalized to.
+	// Not-equal operators are kept as written when this is empty.
+	NotEqualOperator string `json:"not_equal_operator"`
+
+	// FoldIdentifierCase specifies how the normalizer should fold the case of unquoted identifiers.
+	// Identifiers are kept as written by default.
+	FoldIdentifierCase IdentifierCase `json:"fold_identifier_case"`
+
+	// KeepLiterals specifies whether the normalizer should only canonicalize the formatting of the query,
+	// e.g. whitespace, keyword case and comments, and keep every literal and placeholder as written.
+	// Grouping of placeholders, IN list canonicalization and INSERT collapsing are disabled, so the
+	// normalized SQL can be replayed.
+	KeepLiterals bool `json:"keep_literals"`
+
+	// MaxMetadataEntries specifies the maximum number of entries collected in each metadata list,
+	// e.g. tables, comments or joins. Entries past the limit are dropped and the metadata is flagged as truncated.
+	// There is no limit when this is 0.
+	MaxMetadataEntries int `json:"max_metadata_entries"`
+
+	// MaxMetadataSize specifies the maximum size in bytes of the collected tables, comments, commands,
+	// procedures and columns. Entries that do not fit are dropped and the metadata is flagged as truncated.
+	// There is no limit when this is 0.
+	MaxMetadataSize int `json:"max_metadata_size"`
+
+	// MaxOutputBytes specifies the maximum size in bytes of the normalized SQL and of the metadata combined,
+	// the obfuscated SQL included with Process, the metadata being measured as with MaxMetadataSize.
+	// The normalization stops before the first token which does not fit, the output covering the statement
+	// up to it, and the metadata is flagged as OutputTruncated. NormalizeTo, which streams the normalized SQL,
+	// has written that token already. There is no limit when this is 0.
+	MaxOutputBytes int `json:"max_output_bytes"`
+
+	// UnquoteSafeIdentifiers specifies whether the normalizer should remove the quotes of quoted identifiers
+	// when every part of the identifier is safe to write unquoted: it is made of letters, digits and underscores,
+	// it is not a keyword, and unquoting it does not change its case sensitivity for the DBMS, i.e. it is
+	// lowercase for PostgreSQL and uppercase for Oracle and Snowflake. This is only useful along with
+	// KeepIdentifierQuotation, which otherwise removes every quote.
+	UnquoteSafeIdentifiers bool `json:"unquote_safe_identifiers"`
+
+	// CollectOffsets specifies whether the normalizer should return the mapping of the normalized SQL back to
+	// the ranges of the original SQL it was produced from, e.g. to highlight the original text in a UI.
+	CollectOffsets bool `json:"collect_offsets"`
+
+	// CollectInLists specifies whether the normalizer should collect a summary of the IN lists made of literals
+	// of a single type, e.g. "IN list of 3 literals" for IN (1, 2, 3), when their literals are collapsed into
+	// a single placeholder by obfuscation or CanonicalizeInList. Such lists are scanned as a single token.
+	CollectInLists bool `json:"collect_in_lists"`
+}
+
+// IdentifierCase is the case unquoted identifiers are folded to
+type IdentifierCase string
+
+const (
+	// IdentifierCasePreserve keeps identifiers as written
+	IdentifierCasePreserve IdentifierCase = "preserve"
+	// IdentifierCaseLower folds identifiers to lowercase
+	IdentifierCaseLower IdentifierCase = "lower"
+	// IdentifierCaseUpper folds identifiers to uppercase
+	IdentifierCaseUpper IdentifierCase = "upper"
+	// IdentifierCaseAuto folds identifiers the way the DBMS does: lowercase for PostgreSQL,
+	// uppercase for Oracle and Snowflake, and preserved for the others
+	IdentifierCaseAuto IdentifierCase = "auto"
+)
+
+// resolve returns the case identifiers are folded to for the given DBMS
+func (c IdentifierCase) resolve(dbms DBMSType) IdentifierCase {
+	if c != IdentifierCaseAuto {
+		return c
+	}
+	switch getDBMSFromAlias(dbms) {
+	case DBMSPostgres:
+		return IdentifierCaseLower
+	case DBMSOracle, DBMSSnowflake:
+		return IdentifierCaseUpper
+	default:
+		return IdentifierCasePreserve
+	}
 }
 
 type normalizerOption func(*normalizerConfig)
@@ -57,6 +164,24 @@ func WithCollectComments(collectComments bool) normalizerOption {
 	}
 }
 
+func WithCollectColumns(collectColumns bool) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.CollectColumns = collectColumns
+	}
+}
+
+func WithCollectJoins(collectJoins bool) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.CollectJoins = collectJoins
+	}
+}
+
+func WithCollectOrdering(collectOrdering bool) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.CollectOrdering = collectOrdering
+	}
+}
+
 func WithKeepSQLAlias(keepSQLAlias bool) normalizerOption {
 	return func(c *normalizerConfig) {
 		c.KeepSQLAlias = keepSQLAlias
@@ -93,35 +218,313 @@ func WithKeepIdentifierQuotation(keepIdentifierQuotation bool) normalizerOption
 	}
 }
 
+func WithCollapseInsertColumns(collapseInsertColumns bool) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.CollapseInsertColumns = collapseInsertColumns
+	}
+}
+
+func WithCanonicalizeInList(canonicalizeInList bool) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.CanonicalizeInList = canonicalizeInList
+	}
+}
+
+func WithCanonicalizeNullBoolean(canonicalizeNullBoolean bool) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.CanonicalizeNullBoolean = canonicalizeNullBoolean
+	}
+}
+
+func WithNotEqualOperator(notEqualOperator string) normalizerOption {
+	return func(c *normalizerConfig) {
+		if notEqualOperator == "<>" || notEqualOperator == "!=" {
+			c.NotEqualOperator = notEqualOperator
+		}
+	}
+}
+
+func WithFoldIdentifierCase(foldIdentifierCase IdentifierCase) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.FoldIdentifierCase = foldIdentifierCase
+	}
+}
+
+func WithKeepLiterals(keepLiterals bool) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.KeepLiterals = keepLiterals
+	}
+}
+
+func WithMaxMetadataEntries(maxMetadataEntries int) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.MaxMetadataEntries = maxMetadataEntries
+	}
+}
+
+func WithMaxMetadataSize(maxMetadataSize int) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.MaxMetadataSize = maxMetadataSize
+	}
+}
+
+func WithMaxOutputBytes(maxOutputBytes int) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.MaxOutputBytes = maxOutputBytes
+	}
+}
+
+func WithUnquoteSafeIdentifiers(unquoteSafeIdentifiers bool) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.UnquoteSafeIdentifiers = unquoteSafeIdentifiers
+	}
+}
+
+func WithCollectOffsets(collectOffsets bool) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.CollectOffsets = collectOffsets
+	}
+}
+
+func WithCollectInLists(collectInLists bool) normalizerOption {
+	return func(c *normalizerConfig) {
+		c.CollectInLists = collectInLists
+	}
+}
+
 type StatementMetadata struct {
 	Size       int      `json:"size"`
 	Tables     []string `json:"tables"`
 	Comments   []string `json:"comments"`
 	Commands   []string `json:"commands"`
 	Procedures []string `json:"procedures"`
+	Columns    []string `json:"columns,omitempty"`
+	// TempTables are the collected tables which are temporary, e.g. #orders or pg_temp.orders, collected along with the tables
+	TempTables []string `json:"temp_tables,omitempty"`
+	// StatementKind is the kind of the statement, collected along with the commands
+	StatementKind StatementKind `json:"statement_kind,omitempty"`
+	// TableAliases maps the aliases of the collected tables to their table, collected along with the tables
+	TableAliases map[string]string `json:"table_aliases,omitempty"`
+	Joins        []Join            `json:"joins,omitempty"`
+	// GroupBy and OrderBy are the expressions of the GROUP BY and ORDER BY clauses, collected with CollectOrdering.
+	// The orderings of window definitions and aggregate function arguments are not collected.
+	GroupBy []OrderingExpression `json:"group_by,omitempty"`
+	OrderBy []OrderingExpression `json:"order_by,omitempty"`
+	// Truncated is true if metadata was dropped because of the MaxMetadataEntries or MaxMetadataSize limits
+	Truncated bool `json:"truncated,omitempty"`
+	// OutputTruncated is true if the normalization stopped at the MaxOutputBytes limit, before the end of the statement
+	OutputTruncated bool `json:"output_truncated,omitempty"`
+	// Offsets maps ranges of the normalized SQL to the ranges of the original SQL, collected with CollectOffsets
+	Offsets []OffsetMapping `json:"offsets,omitempty"`
+	// InLists are the distinct summaries of the IN lists made of literals of a single type, collected with CollectInLists
+	InLists []string `json:"in_lists,omitempty"`
+}
+
+// OffsetMapping maps a range of the normalized SQL to the range of the original SQL it was produced from.
+// A token maps to its own range, a collapsed region, e.g. a canonicalized IN list, maps to the range of the whole region.
+type OffsetMapping struct {
+	NormalizedStart int `json:"normalized_start"`
+	NormalizedEnd   int `json:"normalized_end"`
+	OriginalStart   int `json:"original_start"`
+	OriginalEnd     int `json:"original_end"`
+}
+
+// OrderingExpression is an expression of a GROUP BY or ORDER BY clause
+type OrderingExpression struct {
+	// Expression is the expression, e.g. "u.name" or "lower(name)", without its ASC, DESC and NULLS modifiers.
+	// Its literals are obfuscated if the query is.
+	Expression string `json:"expression"`
+	// Ordinal is the position of the select list item the expression references, e.g. 2 for ORDER BY 2, 0 if it is not an ordinal
+	Ordinal int `json:"ordinal,omitempty"`
+	// Descending is true if the expression is sorted in descending order
+	Descending bool `json:"descending,omitempty"`
+}
+
+// Join describes a join between two tables.
+// Type is one of INNER, LEFT, RIGHT, FULL, CROSS (including comma joins), LATERAL or STRAIGHT_JOIN,
+// prefixed with NATURAL for natural joins.
+// Right is empty when the joined relation is a subquery.
+type Join struct {
+	Type  string `json:"type"`
+	Left  string `json:"left"`
+	Right string `json:"right"`
 }
 
 type metadataSet struct {
 	size          int
+	maxEntries    int  // maximum number of entries per metadata list, 0 for no limit
+	maxSize       int  // maximum size of the metadata, 0 for no limit
+	maxOutputSize int  // maximum size of the metadata left by MaxOutputBytes, -1 for no limit
+	truncated     bool // true if metadata was dropped because of the limits
 	tablesSet     map[string]struct{}
 	commentsSet   map[string]struct{}
 	commandsSet   map[string]struct{}
 	proceduresSet map[string]struct{}
+	columnsSet    map[string]struct{}
+	inListsSet    map[string]struct{}
 }
 
-// addMetadata adds a value to a metadata slice if it doesn't exist in the set
+// reset empties the metadata set, keeping its maps
+func (m *metadataSet) reset() {
+	if m.tablesSet == nil {
+		*m = metadataSet{
+			tablesSet:     map[string]struct{}{},
+			commentsSet:   map[string]struct{}{},
+			commandsSet:   map[string]struct{}{},
+			proceduresSet: map[string]struct{}{},
+			columnsSet:    map[string]struct{}{},
+			inListsSet:    map[string]struct{}{},
+		}
+		return
+	}
+	m.size = 0
+	m.maxEntries = 0
+	m.maxSize = 0
+	m.truncated = false
+	clear(m.tablesSet)
+	clear(m.commentsSet)
+	clear(m.commandsSet)
+	clear(m.proceduresSet)
+	clear(m.columnsSet)
+	clear(m.inListsSet)
+}
+
+// addMetadata adds a value to a metadata slice if it doesn't exist in the set and fits in the limits
 func (m *metadataSet) addMetadata(value string, set map[string]struct{}, slice *[]string) {
-	if _, exists := set[value]; !exists {
-		set[value] = struct{}{}
-		*slice = append(*slice, value)
-		m.size += len(value)
+	if _, exists := set[value]; exists {
+		return
 	}
+	if m.isFull(len(*slice)) || (m.maxSize > 0 && m.size+len(value) > m.maxSize) ||
+		(m.maxOutputSize >= 0 && m.size+len(value) > m.maxOutputSize) {
+		m.truncated = true
+		return
+	}
+	set[value] = struct{}{}
+	*slice = append(*slice, value)
+	m.size += len(value)
+}
+
+// isFull returns true, and flags the metadata as truncated, if a metadata list of the given length cannot grow
+func (m *metadataSet) isFull(length int) bool {
+	if m.maxEntries > 0 && length >= m.maxEntries {
+		m.truncated = true
+		return true
+	}
+	return false
+}
+
+// sqlClause is the clause of the statement the current token belongs to
+type sqlClause int
+
+const (
+	clauseNone sqlClause = iota
+	clauseSelect
+	clauseFrom
+	clauseWhere
+	clauseGroupBy
+	clauseOrderBy
+)
+
+// metadataState holds the state carried across tokens while collecting metadata
+type metadataState struct {
+	ctes       map[string]bool
+	classifier statementClassifier
+	clause     sqlClause
+	clauses    []sqlClause // clauses saved at each opening parenthesis, restored at the closing one
+	// aliasedTable is the last collected table, which the next identifier may alias
+	aliasedTable string
+	// inCTEs is true while reading the CTE definitions of a WITH clause, at parentheses depth cteDepth
+	inCTEs   bool
+	cteDepth int
+	// fromTable is the last table of the current FROM clause, the left side of the next join
+	fromTable  string
+	fromTables []string // from tables saved at each opening parenthesis, restored at the closing one
+	// pendingJoin is the type of the join waiting for its right side
+	pendingJoin   string
+	joinModifiers []string // join type words, e.g. LEFT OUTER, preceding the JOIN command
+	ordering      orderingState
+}
+
+// orderingState is the state of the GROUP BY or ORDER BY expression being collected
+type orderingState struct {
+	input  string    // the query, whose ordinals are read before obfuscation
+	clause sqlClause // clauseGroupBy or clauseOrderBy while collecting the expressions of the clause, clauseNone otherwise
+	depth  int       // parentheses depth of the clause
+	// expressionDepth is the parentheses depth of the window definition or function arguments being read, 0 if none,
+	// whose orderings are not collected
+	expressionDepth int
+	windowClause    bool // true after a WINDOW clause, whose definitions follow AS
+	expression      []byte
+	end             int    // byte offset following the last token of the expression
+	tokens          int    // number of tokens of the expression
+	ordinal         string // the number of the expression if its only token is a number
+	descending      bool
+	done            bool // true after the ASC, DESC or NULLS modifiers ending the expression
+}
+
+// reset empties the metadata state, keeping its map and stacks
+func (m *metadataState) reset() {
+	clear(m.ctes)
+	*m = metadataState{
+		ctes:          m.ctes,
+		clauses:       m.clauses[:0],
+		fromTables:    m.fromTables[:0],
+		joinModifiers: m.joinModifiers[:0],
+		ordering:      orderingState{expression: m.ordering.expression[:0]},
+	}
+}
+
+// nonTableWords are words which are lexed as identifiers but can neither reference nor alias a table
+var nonTableWords = map[string]bool{
+	"NATURAL":     true,
+	"CROSS":       true,
+	"FULL":        true,
+	"LATERAL":     true,
+	"WHEN":        true,
+	"FOR":         true,
+	"FETCH":       true,
+	"OUTPUT":      true,
+	"TABLESAMPLE": true,
+}
+
+// maxNonTableWordLength is the length of the longest word of nonTableWords
+const maxNonTableWordLength = len("TABLESAMPLE")
+
+// isNonTableWord returns true if the word is one of nonTableWords, matched case-insensitively without allocating
+func isNonTableWord(word string) bool {
+	if len(word) > maxNonTableWordLength {
+		return false
+	}
+	var upper [maxNonTableWordLength]byte
+	for i := 0; i < len(word); i++ {
+		c := word[i]
+		if c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		upper[i] = c
+	}
+	return nonTableWords[string(upper[:len(word)])]
 }
 
 type groupablePlaceholder struct {
 	groupable bool
 }
 
+type insertGroupsState struct {
+	inInsert        bool // true after an INSERT command
+	afterValues     bool // true after the VALUES keyword of an INSERT
+	depth           int  // parentheses depth inside the group being collapsed
+	collapsedRow    bool // true if the last group was a collapsed value list
+	pendingRowComma bool // true if a comma following a collapsed value list was swallowed
+}
+
+type inListState struct {
+	buffering   bool            // true while inside an IN list
+	literalOnly bool            // true if the IN list only contains literals so far
+	expectValue bool            // true if the next token in the IN list should be a value
+	buffer      strings.Builder // the normalized content of the IN list
+}
+
 type headState struct {
 	readFirstNonSpaceNonComment         bool
 	inLeadingParenthesesExpression      bool
@@ -130,6 +533,134 @@ type headState struct {
 	expressionInParentheses             strings.Builder
 }
 
+// normalizerState is the state of a single normalization. States are pooled and reused across calls,
+// along with their buffers, so that normalizing a statement does not allocate on the hot path.
+type normalizerState struct {
+	lexer                Lexer
+	output               sqlBuffer
+	meta                 metadataSet
+	groupablePlaceholder groupablePlaceholder
+	headState            headState
+	insertGroups         insertGroupsState
+	inList               inListState
+	metaState            metadataState
+	offsets              offsetRecorder
+	outputTruncated      bool // true if the normalization stopped at MaxOutputBytes
+}
+
+// maxPooledBufferSize is the capacity above which an output buffer is not returned to the pool,
+// so a single huge statement does not pin its buffer in memory
+const maxPooledBufferSize = 64 * 1024
+
+var normalizerStatePool = sync.Pool{
+	New: func() any {
+		return &normalizerState{}
+	},
+}
+
+func getNormalizerState(input string, lexerOpts ...lexerOption) *normalizerState {
+	state := normalizerStatePool.Get().(*normalizerState)
+	state.lexer.reset(input, lexerOpts...)
+	// the normalized SQL is spaced by the normalizer, the whitespace of the input is not scanned
+	state.lexer.config.SkipWhitespace = true
+	// the clauses are detected from the keywords one by one
+	state.lexer.config.CompoundKeywords = false
+	if cap(state.output) < len(input) {
+		state.output = make(sqlBuffer, 0, len(input))
+	}
+	state.output = state.output[:0]
+	state.meta.reset()
+	state.groupablePlaceholder = groupablePlaceholder{}
+	state.headState = headState{}
+	state.insertGroups = insertGroupsState{}
+	state.inList = inListState{}
+	state.metaState.reset()
+	state.offsets.reset()
+	state.outputTruncated = false
+	return state
+}
+
+func putNormalizerState(state *normalizerState) {
+	if cap(state.output) > maxPooledBufferSize {
+		return
+	}
+	// do not retain the input while the state is pooled
+	state.lexer.reset("")
+	state.metaState.ordering.input = ""
+	normalizerStatePool.Put(state)
+}
+
+// sqlBuffer is a reusable buffer the normalized SQL is written to
+type sqlBuffer []byte
+
+func (b *sqlBuffer) WriteString(s string) (int, error) {
+	*b = append(*b, s...)
+	return len(s), nil
+}
+
+func (b *sqlBuffer) Len() int {
+	return len(*b)
+}
+
+// sqlWriter is the destination of the normalized SQL
+type sqlWriter interface {
+	io.StringWriter
+	Len() int
+}
+
+// offsetRecorder records the mapping of the normalized SQL back to the original SQL
+type offsetRecorder struct {
+	mappings    []OffsetMapping
+	inListStart int // original range of the buffered IN list, -1 if empty
+	inListEnd   int
+	headStart   int // original range of the leading expression in parentheses, -1 if empty
+	headEnd     int
+}
+
+func (o *offsetRecorder) reset() {
+	*o = offsetRecorder{mappings: o.mappings[:0], inListStart: -1, headStart: -1}
+}
+
+// record maps the normalized range to the original range, o may be nil when offsets are not collected
+func (o *offsetRecorder) record(normalizedStart, normalizedEnd, originalStart, originalEnd int) {
+	if o == nil || normalizedStart >= normalizedEnd || originalStart < 0 {
+		return
+	}
+	o.mappings = append(o.mappings, OffsetMapping{
+		NormalizedStart: normalizedStart,
+		NormalizedEnd:   normalizedEnd,
+		OriginalStart:   originalStart,
+		OriginalEnd:     originalEnd,
+	})
+}
+
+// extendRange extends the original range [start, end) to cover the token
+func extendRange(start, end *int, token *Token) {
+	if *start < 0 {
+		*start = token.Start
+	}
+	*end = token.End
+}
+
+// result returns the mappings of the trimmed normalized SQL, given the number of leading bytes trimmed
+// from the normalized SQL and its trimmed length
+func (o *offsetRecorder) result(trimmedPrefix, length int) []OffsetMapping {
+	mappings := make([]OffsetMapping, 0, len(o.mappings))
+	for _, mapping := range o.mappings {
+		mapping.NormalizedStart -= trimmedPrefix
+		mapping.NormalizedEnd -= trimmedPrefix
+		if mapping.NormalizedEnd <= 0 || mapping.NormalizedStart >= length {
+			// trimmed, e.g. the trailing semicolon
+			continue
+		}
+		// regions may start or end with trimmed spaces
+		mapping.NormalizedStart = max(mapping.NormalizedStart, 0)
+		mapping.NormalizedEnd = min(mapping.NormalizedEnd, length)
+		mappings = append(mappings, mapping)
+	}
+	return mappings
+}
+
 type Normalizer struct {
 	config *normalizerConfig
 }
@@ -147,84 +678,420 @@ func NewNormalizer(opts ...normalizerOption) *Normalizer {
 }
 
 // normalizeToken is a helper function that handles the common normalization logic
-func (n *Normalizer) normalizeToken(lexer *Lexer, normalizedSQLBuilder *strings.Builder, meta *metadataSet, statementMetadata *StatementMetadata, preProcessToken func(*Token, *LastValueToken), lexerOpts ...lexerOption) (err error) {
+func (n *Normalizer) normalizeToken(state *normalizerState, normalizedSQLBuilder sqlWriter, statementMetadata *StatementMetadata, preProcessToken func(*Token, *LastValueToken), lexerOpts ...lexerOption) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = fmt.Errorf("error normalizing SQL token: %v", r)
 		}
 	}()
 
-	var groupablePlaceholder groupablePlaceholder
-	var headState headState
-	var ctes map[string]bool
-
-	// Only allocate CTEs map if collecting tables
-	if n.config.CollectTables {
-		ctes = make(map[string]bool, 2)
+	operation := OperationNormalize
+	if preProcessToken != nil {
+		operation = OperationObfuscateAndNormalize
 	}
+	timer, timed := newPhaseTimer(state.lexer.config.PhaseTimings)
+
+	n.beginNormalization(state)
 
 	var lastValueToken *LastValueToken
+	summarizeInLists := n.summarizesInLists(preProcessToken != nil)
+	inListStart := false
 
 	for {
-		token := lexer.Scan()
+		var token *Token
+		inListLength := 0 // number of literals of the IN list scanned as a single token, if any
+		if inListStart {
+			// the literals of the list are collapsed anyway, scan them at once
+			token, inListLength = state.lexer.scanLiteralList()
+		}
+		if token == nil {
+			token = state.lexer.Scan()
+		}
+		if timed {
+			timer.end(PhaseLex)
+		}
 		if preProcessToken != nil {
 			// pre-process the token, often used for obfuscation
 			preProcessToken(token, lastValueToken)
+			if timed {
+				timer.end(PhaseObfuscate)
+			}
 		}
-		if n.shouldCollectMetadata() {
-			n.collectMetadata(token, lastValueToken, meta, statementMetadata, ctes)
+		length := normalizedSQLBuilder.Len()
+		n.limitMetadata(state, length)
+		n.collectInList(state, statementMetadata, inListLength)
+		n.normalizeNextToken(state, token, lastValueToken, normalizedSQLBuilder, statementMetadata, lexerOpts...)
+		if timed {
+			timer.end(PhaseNormalize)
+		}
+		if n.exceedsMaxOutput(state, normalizedSQLBuilder.Len()) {
+			// the normalized SQL ends before the token, unless it has been streamed already
+			if buffer, ok := normalizedSQLBuilder.(*sqlBuffer); ok {
+				*buffer = (*buffer)[:length]
+			}
+			state.outputTruncated = true
+			break
 		}
-		n.normalizeSQL(token, lastValueToken, normalizedSQLBuilder, &groupablePlaceholder, &headState, lexerOpts...)
 		if token.Type == EOF {
 			break
 		}
+		inListStart = summarizeInLists && isInListStart(token, lastValueToken)
 		if isValueToken(token) {
 			lastValueToken = token.getLastValueToken()
 		}
 	}
 
+	n.endNormalization(state, statementMetadata)
+	if timed {
+		timer.report(operation)
+	}
 	return nil
 }
 
-func (n *Normalizer) Normalize(input string, lexerOpts ...lexerOption) (normalizedSQL string, statementMetadata *StatementMetadata, err error) {
-	lexer := New(input, lexerOpts...)
-	var normalizedSQLBuilder strings.Builder
-	normalizedSQLBuilder.Grow(len(input))
+// beginNormalization prepares the state to normalize the input of its lexer
+func (n *Normalizer) beginNormalization(state *normalizerState) {
+	// Only allocate CTEs map if collecting tables, pooled states keep it once allocated
+	if (n.config.CollectTables || n.config.CollectJoins) && state.metaState.ctes == nil {
+		state.metaState.ctes = make(map[string]bool, 2)
+	}
+	state.metaState.classifier.dbms = state.lexer.config.DBMS
+	state.metaState.ordering.input = state.lexer.src
+	state.meta.maxEntries = n.config.MaxMetadataEntries
+	state.meta.maxSize = n.config.MaxMetadataSize
+	state.meta.maxOutputSize = -1
+}
+
+// limitMetadata limits the metadata collected from the next token to the part of MaxOutputBytes
+// the output, of the given length, leaves
+func (n *Normalizer) limitMetadata(state *normalizerState, outputLength int) {
+	if n.config.MaxOutputBytes > 0 {
+		state.meta.maxOutputSize = max(n.config.MaxOutputBytes-outputLength, 0)
+	}
+}
+
+// exceedsMaxOutput checks if the output, of the given length, and the metadata are larger than MaxOutputBytes
+func (n *Normalizer) exceedsMaxOutput(state *normalizerState, outputLength int) bool {
+	return n.config.MaxOutputBytes > 0 && outputLength+state.meta.size > n.config.MaxOutputBytes
+}
 
-	meta := &metadataSet{
-		tablesSet:     map[string]struct{}{},
-		commentsSet:   map[string]struct{}{},
-		commandsSet:   map[string]struct{}{},
-		proceduresSet: map[string]struct{}{},
+// normalizeNextToken collects the metadata of the token and writes it normalized
+func (n *Normalizer) normalizeNextToken(state *normalizerState, token *Token, lastValueToken *LastValueToken, normalizedSQLBuilder sqlWriter, statementMetadata *StatementMetadata, lexerOpts ...lexerOption) {
+	dbms := state.lexer.config.DBMS
+	if token.Type == QUOTED_IDENT && n.config.UnquoteSafeIdentifiers {
+		if unquoted, ok := unquoteSafeIdentifier(token.Value, dbms); ok {
+			token.Value = unquoted
+			token.Type = IDENT
+			token.quotes = nil
+		}
+	}
+	if token.Type == IDENT {
+		// fold unquoted identifiers before collecting metadata, so tables and columns are folded too
+		switch n.config.FoldIdentifierCase.resolve(dbms) {
+		case IdentifierCaseLower:
+			token.Value = ToLowerASCII(token.Value)
+		case IdentifierCaseUpper:
+			token.Value = ToUpperASCII(token.Value)
+		}
+	}
+	if n.shouldCollectMetadata() {
+		n.collectMetadata(token, lastValueToken, &state.meta, statementMetadata, &state.metaState)
 	}
+	var offsets *offsetRecorder
+	if n.config.CollectOffsets {
+		offsets = &state.offsets
+	}
+	n.normalizeSQL(token, lastValueToken, normalizedSQLBuilder, &state.groupablePlaceholder, &state.headState, &state.insertGroups, &state.inList, offsets, lexerOpts...)
+}
 
-	statementMetadata = &StatementMetadata{
-		Tables:     []string{},
-		Comments:   []string{},
-		Commands:   []string{},
-		Procedures: []string{},
+// endNormalization sets the metadata known once every token is normalized
+func (n *Normalizer) endNormalization(state *normalizerState, statementMetadata *StatementMetadata) {
+	if n.config.CollectCommands {
+		statementMetadata.StatementKind = state.metaState.classifier.result()
 	}
+	statementMetadata.Truncated = state.meta.truncated
+	statementMetadata.OutputTruncated = state.outputTruncated
+}
 
-	if err = n.normalizeToken(lexer, &normalizedSQLBuilder, meta, statementMetadata, nil, lexerOpts...); err != nil {
+func (n *Normalizer) Normalize(input string, lexerOpts ...lexerOption) (normalizedSQL string, statementMetadata *StatementMetadata, err error) {
+	state := getNormalizerState(input, lexerOpts...)
+	defer putNormalizerState(state)
+
+	statementMetadata = n.newMetadata()
+
+	if err = n.normalizeToken(state, &state.output, statementMetadata, nil, lexerOpts...); err != nil {
 		return "", nil, err
 	}
 
-	normalizedSQL = normalizedSQLBuilder.String()
-	statementMetadata.Size = meta.size
-	return n.trimNormalizedSQL(normalizedSQL), statementMetadata, nil
+	statementMetadata.Size = state.meta.size
+	normalizedSQL = n.trimNormalizedSQL(string(state.output))
+	if n.config.CollectOffsets {
+		statementMetadata.Offsets = state.offsets.result(leadingSpaces(state.output), len(normalizedSQL))
+	}
+	return normalizedSQL, statementMetadata, nil
+}
+
+// NormalizeAppend normalizes the input SQL as Normalize does and appends the normalized SQL to dst.
+// A caller reusing dst across calls, e.g. NormalizeAppend(dst[:0], query), does not allocate the normalized SQL,
+// only its metadata.
+func (n *Normalizer) NormalizeAppend(dst []byte, input string, lexerOpts ...lexerOption) ([]byte, *StatementMetadata, error) {
+	state := getNormalizerState(input, lexerOpts...)
+	defer putNormalizerState(state)
+
+	statementMetadata := n.newMetadata()
+
+	if err := n.normalizeToken(state, &state.output, statementMetadata, nil, lexerOpts...); err != nil {
+		return dst, nil, err
+	}
+
+	statementMetadata.Size = state.meta.size
+	normalizedSQL := n.trimNormalizedBytes(state.output)
+	if n.config.CollectOffsets {
+		statementMetadata.Offsets = state.offsets.result(leadingSpaces(state.output), len(normalizedSQL))
+	}
+	return append(dst, normalizedSQL...), statementMetadata, nil
+}
+
+// leadingSpaces returns the number of leading space bytes of the normalized SQL
+func leadingSpaces(normalizedSQL []byte) int {
+	return len(normalizedSQL) - len(bytes.TrimLeftFunc(normalizedSQL, unicode.IsSpace))
+}
+
+// unquoteSafeIdentifier returns the identifier without its quotes if every quoted part of it is safe to unquote
+func unquoteSafeIdentifier(ident string, dbms DBMSType) (string, bool) {
+	var unquoted strings.Builder
+	unquoted.Grow(len(ident))
+	for i := 0; i < len(ident); {
+		var closing byte
+		switch ident[i] {
+		case '"', '`':
+			closing = ident[i]
+		case '[':
+			closing = ']'
+		default:
+			unquoted.WriteByte(ident[i])
+			i++
+			continue
+		}
+		end := strings.IndexByte(ident[i+1:], closing)
+		if end < 0 {
+			return "", false
+		}
+		name := ident[i+1 : i+1+end]
+		if !isSafeUnquotedIdentifier(name, dbms) {
+			return "", false
+		}
+		unquoted.WriteString(name)
+		i += end + 2
+	}
+	return unquoted.String(), true
+}
+
+// isSafeUnquotedIdentifier returns true if the name means the same to the DBMS whether it is quoted or not
+func isSafeUnquotedIdentifier(name string, dbms DBMSType) bool {
+	if name == "" || isDigit(rune(name[0])) || isKeyword(name) {
+		return false
+	}
+	hasLower, hasUpper := false, false
+	for i := 0; i < len(name); i++ {
+		ch := rune(name[i])
+		switch {
+		case ch >= 'a' && ch <= 'z':
+			hasLower = true
+		case ch >= 'A' && ch <= 'Z':
+			hasUpper = true
+		case isDigit(ch) || ch == '_':
+		default:
+			return false
+		}
+	}
+	// unquoted identifiers are case folded by the DBMS, the quoted name must already be folded
+	switch getDBMSFromAlias(dbms) {
+	case DBMSPostgres:
+		return !hasUpper
+	case DBMSOracle, DBMSSnowflake:
+		return !hasLower
+	default:
+		return true
+	}
+}
+
+// NormalizedStatement is the normalized SQL and the metadata of one statement of a multi-statement input
+type NormalizedStatement struct {
+	NormalizedSQL string             `json:"normalized_sql"`
+	Metadata      *StatementMetadata `json:"metadata"`
+	Start         int                `json:"start"` // byte offset of the statement in the input
+	End           int                `json:"end"`   // byte offset following the statement in the input
+}
+
+// NormalizeStatements splits the input into its statements and normalizes each of them separately,
+// so the commands and tables of a batch, e.g. "BEGIN; UPDATE ...; COMMIT;", can be attributed per statement.
+// Statements are delimited as by a Splitter, comments preceding a statement belong to it,
+// and empty statements are skipped.
+func (n *Normalizer) NormalizeStatements(input string, lexerOpts ...lexerOption) ([]NormalizedStatement, error) {
+	var statements []NormalizedStatement
+	for _, statement := range NewSplitter(lexerOpts...).Split(input) {
+		normalizedSQL, statementMetadata, err := n.Normalize(statement.Text, lexerOpts...)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, NormalizedStatement{
+			NormalizedSQL: normalizedSQL,
+			Metadata:      statementMetadata,
+			Start:         statement.Start,
+			End:           statement.End,
+		})
+	}
+	return statements, nil
+}
+
+// NormalizeTo normalizes the input SQL and streams the normalized SQL to w instead of returning it as a string.
+// The output is identical to the one of Normalize. Write errors are returned once the statement has been processed.
+func (n *Normalizer) NormalizeTo(w io.Writer, input string, lexerOpts ...lexerOption) (statementMetadata *StatementMetadata, err error) {
+	state := getNormalizerState(input, lexerOpts...)
+	defer putNormalizerState(state)
+	writer := newTrimmingWriter(w, !n.config.KeepTrailingSemicolon)
+
+	statementMetadata = n.newMetadata()
+
+	if err = n.normalizeToken(state, writer, statementMetadata, nil, lexerOpts...); err != nil {
+		return nil, err
+	}
+	if err = writer.flush(); err != nil {
+		return nil, err
+	}
+
+	statementMetadata.Size = state.meta.size
+	if n.config.CollectOffsets {
+		statementMetadata.Offsets = state.offsets.result(writer.trimmedPrefix, writer.written)
+	}
+	return statementMetadata, nil
+}
+
+// trimmingWriter streams the normalized SQL to an io.Writer and trims it the same way trimNormalizedSQL does.
+// Leading spaces are dropped, and the trailing run of spaces and semicolons is held back until more SQL follows
+// or the statement ends.
+type trimmingWriter struct {
+	w             io.Writer
+	trimSemicolon bool
+	started       bool
+	pending       strings.Builder
+	err           error
+	received      int // number of bytes received
+	trimmedPrefix int // number of leading bytes trimmed
+	written       int // number of bytes written to w
+}
+
+func newTrimmingWriter(w io.Writer, trimSemicolon bool) *trimmingWriter {
+	return &trimmingWriter{w: w, trimSemicolon: trimSemicolon}
+}
+
+func (t *trimmingWriter) WriteString(s string) (int, error) {
+	written := len(s)
+	t.received += written
+	if !t.started {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		t.trimmedPrefix += written - len(s)
+		if s == "" {
+			return written, nil
+		}
+		t.started = true
+	}
+	head := strings.TrimRightFunc(s, isTrimmedSuffix)
+	if head == "" {
+		t.pending.WriteString(s)
+		return written, nil
+	}
+	if t.pending.Len() > 0 {
+		t.write(t.pending.String())
+		t.pending.Reset()
+	}
+	t.write(head)
+	t.pending.WriteString(s[len(head):])
+	return written, nil
+}
+
+func (t *trimmingWriter) Len() int {
+	return t.received
+}
+
+func (t *trimmingWriter) write(s string) {
+	t.written += len(s)
+	if t.err == nil {
+		_, t.err = io.WriteString(t.w, s)
+	}
+}
+
+// flush writes the held back suffix, without its trailing semicolon and spaces
+func (t *trimmingWriter) flush() error {
+	suffix := t.pending.String()
+	if t.trimSemicolon {
+		suffix = strings.TrimSuffix(suffix, ";")
+	}
+	if suffix = strings.TrimRightFunc(suffix, unicode.IsSpace); suffix != "" {
+		t.write(suffix)
+	}
+	t.pending.Reset()
+	return t.err
+}
+
+func isTrimmedSuffix(r rune) bool {
+	return r == ';' || unicode.IsSpace(r)
+}
+
+// statementMetadataBlock allocates the statement metadata together with a backing array for its slices,
+// so the metadata of a typical statement, e.g. one command on one or two tables, takes a single allocation.
+// The slices have a capped capacity, appending past it moves them to their own array.
+type statementMetadataBlock struct {
+	metadata StatementMetadata
+	values   [6]string
+}
+
+// newMetadata returns an empty statement metadata
+func (n *Normalizer) newMetadata() *StatementMetadata {
+	block := &statementMetadataBlock{}
+	statementMetadata := &block.metadata
+	statementMetadata.Tables = block.values[0:0:2]
+	statementMetadata.Commands = block.values[2:2:4]
+	statementMetadata.Comments = block.values[4:4:5]
+	statementMetadata.Procedures = block.values[5:5:6]
+	if n.config.CollectColumns {
+		statementMetadata.Columns = []string{}
+	}
+	if n.config.CollectJoins {
+		statementMetadata.Joins = []Join{}
+	}
+	if n.config.CollectOrdering {
+		statementMetadata.GroupBy = []OrderingExpression{}
+		statementMetadata.OrderBy = []OrderingExpression{}
+	}
+	return statementMetadata
 }
 
 func (n *Normalizer) shouldCollectMetadata() bool {
-	return n.config.CollectTables || n.config.CollectCommands || n.config.CollectComments || n.config.CollectProcedure
+	return n.config.CollectTables || n.config.CollectCommands || n.config.CollectComments || n.config.CollectProcedure || n.config.CollectColumns || n.config.CollectJoins || n.config.CollectOrdering
 }
 
-func (n *Normalizer) collectMetadata(token *Token, lastValueToken *LastValueToken, meta *metadataSet, statementMetadata *StatementMetadata, ctes map[string]bool) {
-	if n.config.CollectComments && (token.Type == COMMENT || token.Type == MULTILINE_COMMENT) {
+func (n *Normalizer) collectMetadata(token *Token, lastValueToken *LastValueToken, meta *metadataSet, statementMetadata *StatementMetadata, state *metadataState) {
+	if n.config.CollectJoins {
+		n.trackJoin(token, meta, state, statementMetadata)
+	}
+	if n.config.CollectColumns || n.config.CollectTables || n.config.CollectJoins || n.config.CollectOrdering {
+		state.trackClause(token, lastValueToken)
+	}
+	if n.config.CollectCommands {
+		state.classifier.classify(token)
+	}
+	state.trackCTEs(token)
+	if state.aliasedTable != "" && isValueToken(token) && token.Type != ALIAS_INDICATOR && token.Type != IDENT && token.Type != QUOTED_IDENT {
+		// the last table is not aliased
+		state.aliasedTable = ""
+	}
+	if n.config.CollectComments && token.IsComment() {
 		comment := token.Value
 		meta.addMetadata(comment, meta.commentsSet, &statementMetadata.Comments)
 	} else if token.Type == COMMAND {
 		if n.config.CollectCommands {
-			command := strings.ToUpper(token.Value)
+			command := ToUpperASCII(token.Value)
 			meta.addMetadata(command, meta.commandsSet, &statementMetadata.Commands)
 		}
 	} else if token.Type == IDENT || token.Type == QUOTED_IDENT || token.Type == FUNCTION {
@@ -237,20 +1104,327 @@ func (n *Normalizer) collectMetadata(token *Token, lastValueToken *LastValueToke
 				token.Type = IDENT
 			}
 		}
-		if lastValueToken != nil && lastValueToken.Type == CTE_INDICATOR {
-			ctes[tokenVal] = true
-		} else if n.config.CollectTables && lastValueToken != nil && lastValueToken.isTableIndicator {
-			if _, ok := ctes[tokenVal]; !ok {
+		if isCTEName(state, lastValueToken) {
+			if state.ctes != nil {
+				state.ctes[tokenVal] = true
+			}
+		} else if (n.config.CollectTables || n.config.CollectJoins) && isTablePosition(state.clause, lastValueToken) && !isNonTableWord(tokenVal) {
+			if _, ok := state.ctes[tokenVal]; !ok && n.config.CollectTables {
+				tables := len(statementMetadata.Tables)
 				meta.addMetadata(tokenVal, meta.tablesSet, &statementMetadata.Tables)
+				if token.isTemporaryTable && len(statementMetadata.Tables) > tables {
+					// the temporary tables are collected tables, their size is not counted twice
+					statementMetadata.TempTables = append(statementMetadata.TempTables, tokenVal)
+				}
+				state.aliasedTable = tokenVal
+			}
+			if n.config.CollectJoins {
+				n.collectJoin(tokenVal, meta, state, statementMetadata)
+			}
+		} else if state.aliasedTable != "" {
+			if token.Type != FUNCTION && !isNonTableWord(tokenVal) && !meta.isFull(len(statementMetadata.TableAliases)) {
+				if statementMetadata.TableAliases == nil {
+					statementMetadata.TableAliases = make(map[string]string, 2)
+				}
+				statementMetadata.TableAliases[tokenVal] = state.aliasedTable
 			}
+			state.aliasedTable = ""
 		} else if n.config.CollectProcedure && lastValueToken != nil && lastValueToken.Type == PROC_INDICATOR {
 			meta.addMetadata(tokenVal, meta.proceduresSet, &statementMetadata.Procedures)
+		} else if n.config.CollectColumns && token.Type != FUNCTION && !isCaseKeyword(tokenVal) && isColumnPosition(state.clause, lastValueToken) {
+			// only keep the column name, the qualifier is usually a table alias
+			column := tokenVal[strings.LastIndexByte(tokenVal, '.')+1:]
+			if column != "" {
+				meta.addMetadata(column, meta.columnsSet, &statementMetadata.Columns)
+			}
+		}
+	}
+	if n.config.CollectOrdering {
+		n.trackOrdering(token, lastValueToken, meta, statementMetadata, state)
+	}
+}
+
+// trackClause updates the clause the statement is currently in.
+// Parentheses save and restore the enclosing clause so that subqueries do not leak their clauses.
+func (m *metadataState) trackClause(token *Token, lastValueToken *LastValueToken) {
+	switch token.Type {
+	case PUNCTUATION:
+		if token.Value == "(" {
+			m.clauses = append(m.clauses, m.clause)
+			m.fromTables = append(m.fromTables, m.fromTable)
+			if m.clause == clauseFrom {
+				// parentheses in a FROM clause are either subqueries, which set their own clauses,
+				// or function arguments, which are not table references
+				m.clause = clauseNone
+			}
+		} else if token.Value == ")" && len(m.clauses) > 0 {
+			m.clause = m.clauses[len(m.clauses)-1]
+			m.clauses = m.clauses[:len(m.clauses)-1]
+			m.fromTable = m.fromTables[len(m.fromTables)-1]
+			m.fromTables = m.fromTables[:len(m.fromTables)-1]
+		}
+	case COMMAND:
+		switch ToUpperASCII(token.Value) {
+		case "SELECT":
+			m.clause = clauseSelect
+		case "JOIN", "STRAIGHT_JOIN", "UPDATE":
+			m.clause = clauseFrom
+		default:
+			m.clause = clauseNone
 		}
+	case KEYWORD:
+		switch ToUpperASCII(token.Value) {
+		case "FROM":
+			m.clause = clauseFrom
+			m.fromTable = ""
+		case "USING":
+			m.clause = clauseFrom
+		case "WHERE", "ON", "HAVING":
+			m.clause = clauseWhere
+		case "BY":
+			if lastValueToken != nil && equalFoldASCII(lastValueToken.Value, "GROUP") {
+				m.clause = clauseGroupBy
+			} else if lastValueToken != nil && equalFoldASCII(lastValueToken.Value, "ORDER") {
+				m.clause = clauseOrderBy
+			}
+		case "LIMIT", "OFFSET", "UNION", "INTO", "VALUES", "SET", "RETURNING":
+			m.clause = clauseNone
+		}
+	}
+}
+
+// trackCTEs tracks whether the CTE definitions of a WITH clause are being read
+func (m *metadataState) trackCTEs(token *Token) {
+	if token.Type == CTE_INDICATOR {
+		m.inCTEs = true
+		m.cteDepth = len(m.clauses)
+	} else if token.Type == COMMAND && len(m.clauses) == m.cteDepth {
+		// the statement following the CTE definitions
+		m.inCTEs = false
 	}
 }
 
-func (n *Normalizer) normalizeSQL(token *Token, lastValueToken *LastValueToken, normalizedSQLBuilder *strings.Builder, groupablePlaceholder *groupablePlaceholder, headState *headState, lexerOpts ...lexerOption) {
-	if token.Type != SPACE && token.Type != COMMENT && token.Type != MULTILINE_COMMENT {
+// trackJoin tracks the type of the next join: the words preceding a JOIN command, comma joins and lateral joins.
+// Joins with subqueries are collected as soon as the subquery starts.
+func (n *Normalizer) trackJoin(token *Token, meta *metadataSet, state *metadataState, statementMetadata *StatementMetadata) {
+	switch token.Type {
+	case COMMAND:
+		if command := ToUpperASCII(token.Value); command == "JOIN" || command == "STRAIGHT_JOIN" {
+			state.pendingJoin = joinType(state.joinModifiers, command)
+		}
+		state.joinModifiers = state.joinModifiers[:0]
+	case PUNCTUATION:
+		if token.Value == "," && state.clause == clauseFrom {
+			state.pendingJoin = "CROSS"
+		} else if token.Value == "(" && state.pendingJoin != "" {
+			if !meta.isFull(len(statementMetadata.Joins)) {
+				statementMetadata.Joins = append(statementMetadata.Joins, Join{Type: state.pendingJoin, Left: state.fromTable})
+			}
+			state.pendingJoin = ""
+		}
+		state.joinModifiers = state.joinModifiers[:0]
+	case KEYWORD, IDENT:
+		switch word := ToUpperASCII(token.Value); word {
+		case "LATERAL":
+			if state.pendingJoin != "" {
+				state.pendingJoin = word
+			}
+		case "NATURAL", "CROSS", "LEFT", "RIGHT", "FULL", "INNER", "OUTER":
+			state.joinModifiers = append(state.joinModifiers, word)
+		default:
+			state.joinModifiers = state.joinModifiers[:0]
+		}
+	default:
+		if isValueToken(token) {
+			// join type words must directly precede the JOIN command
+			state.joinModifiers = state.joinModifiers[:0]
+		}
+	}
+}
+
+// collectJoin collects the join whose right side is table, if any, and makes table the left side of the next join
+func (n *Normalizer) collectJoin(table string, meta *metadataSet, state *metadataState, statementMetadata *StatementMetadata) {
+	if state.pendingJoin != "" {
+		if !meta.isFull(len(statementMetadata.Joins)) {
+			statementMetadata.Joins = append(statementMetadata.Joins, Join{Type: state.pendingJoin, Left: state.fromTable, Right: table})
+		}
+		state.pendingJoin = ""
+	}
+	state.fromTable = table
+	state.joinModifiers = state.joinModifiers[:0]
+}
+
+// orderingEndWords are the words which end the expressions of a GROUP BY or ORDER BY clause,
+// besides the ones starting another clause
+var orderingEndWords = map[string]bool{
+	"ORDER":     true,
+	"WINDOW":    true,
+	"FOR":       true,
+	"FETCH":     true,
+	"WITH":      true, // WITH ROLLUP
+	"EXCEPT":    true,
+	"INTERSECT": true,
+	"MINUS":     true,
+	"LOCK":      true,
+	"QUALIFY":   true,
+	"OPTION":    true,
+}
+
+// trackOrdering collects the expressions of the GROUP BY and ORDER BY clauses,
+// skipping the ones of window definitions and function arguments, e.g. OVER (ORDER BY a) or array_agg(a ORDER BY b)
+func (n *Normalizer) trackOrdering(token *Token, lastValueToken *LastValueToken, meta *metadataSet, statementMetadata *StatementMetadata, state *metadataState) {
+	o := &state.ordering
+	depth := len(state.clauses)
+	if token.Type == PUNCTUATION && token.Value == "(" && o.expressionDepth == 0 && lastValueToken != nil &&
+		(lastValueToken.Type == FUNCTION || equalFoldASCII(lastValueToken.Value, "OVER") ||
+			equalFoldASCII(lastValueToken.Value, "GROUP") || (o.windowClause && lastValueToken.Type == ALIAS_INDICATOR)) {
+		o.expressionDepth = depth
+	} else if depth < o.expressionDepth {
+		o.expressionDepth = 0
+	}
+
+	if o.clause != clauseNone {
+		word := ""
+		if token.Type == KEYWORD || token.Type == IDENT || token.Type == CTE_INDICATOR {
+			word = ToUpperASCII(token.Value)
+		}
+		switch {
+		case token.Type == EOF || depth < o.depth || (depth == o.depth && (state.clause != o.clause ||
+			(token.Type == PUNCTUATION && token.Value == ";") || orderingEndWords[word])):
+			n.collectOrdering(meta, statementMetadata, state)
+			o.clause = clauseNone
+		case depth == o.depth && token.Type == PUNCTUATION && token.Value == ",":
+			n.collectOrdering(meta, statementMetadata, state)
+		case depth == o.depth && (word == "ASC" || word == "DESC" || word == "NULLS"):
+			o.descending = o.descending || word == "DESC"
+			o.done = true
+		case isValueToken(token) && !o.done:
+			if len(o.expression) > 0 && token.Start > o.end {
+				o.expression = append(o.expression, ' ')
+			}
+			o.expression = append(o.expression, token.Value...)
+			o.end = token.End
+			o.tokens++
+			if token.Type == NUMBER {
+				o.ordinal = o.input[token.Start:token.End]
+			}
+		}
+	}
+
+	switch {
+	case token.Type == KEYWORD && equalFoldASCII(token.Value, "WINDOW"):
+		o.windowClause = true
+	case token.Type == PUNCTUATION && token.Value == ";":
+		o.windowClause = false
+	case token.Type == KEYWORD && equalFoldASCII(token.Value, "BY") && o.expressionDepth == 0 &&
+		(state.clause == clauseGroupBy || state.clause == clauseOrderBy):
+		o.clause, o.depth = state.clause, depth
+	}
+}
+
+// collectOrdering collects the GROUP BY or ORDER BY expression being read, if any
+func (n *Normalizer) collectOrdering(meta *metadataSet, statementMetadata *StatementMetadata, state *metadataState) {
+	o := &state.ordering
+	if len(o.expression) > 0 {
+		expressions := &statementMetadata.OrderBy
+		if o.clause == clauseGroupBy {
+			expressions = &statementMetadata.GroupBy
+		}
+		if !meta.isFull(len(*expressions)) {
+			expression := OrderingExpression{Expression: string(o.expression), Descending: o.descending}
+			if ordinal, err := strconv.Atoi(o.ordinal); o.tokens == 1 && err == nil {
+				// ordinals are positions, not literals, they are kept even if the query is obfuscated
+				expression.Expression, expression.Ordinal = o.ordinal, ordinal
+			}
+			*expressions = append(*expressions, expression)
+		}
+	}
+	o.expression, o.tokens, o.ordinal, o.descending, o.done = o.expression[:0], 0, "", false, false
+}
+
+// joinType returns the type of a join from the words preceding the JOIN command
+func joinType(modifiers []string, command string) string {
+	if command == "STRAIGHT_JOIN" {
+		return command
+	}
+	natural := false
+	kind := ""
+	for _, modifier := range modifiers {
+		switch modifier {
+		case "NATURAL":
+			natural = true
+		case "OUTER":
+		default:
+			kind = modifier
+		}
+	}
+	switch {
+	case natural && kind == "":
+		return "NATURAL"
+	case natural:
+		return "NATURAL " + kind
+	case kind == "":
+		return "INNER"
+	}
+	return kind
+}
+
+// isCTEName returns true if an identifier following lastValueToken names a CTE,
+// i.e. it follows WITH, WITH RECURSIVE or the comma separating two CTE definitions.
+func isCTEName(state *metadataState, lastValueToken *LastValueToken) bool {
+	if lastValueToken == nil {
+		return false
+	}
+	if lastValueToken.Type == CTE_INDICATOR {
+		return true
+	}
+	if !state.inCTEs || len(state.clauses) != state.cteDepth {
+		return false
+	}
+	return (lastValueToken.Type == KEYWORD && equalFoldASCII(lastValueToken.Value, "RECURSIVE")) ||
+		(lastValueToken.Type == PUNCTUATION && lastValueToken.Value == ",")
+}
+
+// isTablePosition returns true if an identifier following lastValueToken in the given clause references a table,
+// i.e. it follows a table indicator such as FROM or JOIN, USING (DELETE ... USING, MERGE ... USING)
+// or a comma in a FROM clause.
+func isTablePosition(clause sqlClause, lastValueToken *LastValueToken) bool {
+	if lastValueToken == nil {
+		return false
+	}
+	if lastValueToken.isTableIndicator {
+		return true
+	}
+	if lastValueToken.Type == KEYWORD && equalFoldASCII(lastValueToken.Value, "USING") {
+		return true
+	}
+	return clause == clauseFrom && lastValueToken.Type == PUNCTUATION && lastValueToken.Value == ","
+}
+
+// isColumnPosition returns true if an identifier following lastValueToken in the given clause references a column.
+// Identifiers directly following another value, e.g. "SELECT id user_id" or "FROM users u", are aliases.
+func isColumnPosition(clause sqlClause, lastValueToken *LastValueToken) bool {
+	if clause == clauseNone || clause == clauseFrom || lastValueToken == nil {
+		return false
+	}
+	switch lastValueToken.Type {
+	case IDENT:
+		// CASE expressions are not keywords, WHEN and THEN are lexed as identifiers
+		return isCaseKeyword(lastValueToken.Value)
+	case QUOTED_IDENT, NUMBER, STRING, ALIAS_INDICATOR, BOOLEAN, NULL:
+		return false
+	case PUNCTUATION:
+		return lastValueToken.Value != ")"
+	}
+	return true
+}
+
+func isCaseKeyword(value string) bool {
+	return equalFoldASCII(value, "WHEN") || equalFoldASCII(value, "THEN")
+}
+
+func (n *Normalizer) normalizeSQL(token *Token, lastValueToken *LastValueToken, normalizedSQLBuilder sqlWriter, groupablePlaceholder *groupablePlaceholder, headState *headState, insertGroups *insertGroupsState, inList *inListState, offsets *offsetRecorder, lexerOpts ...lexerOption) {
+	if token.Type != SPACE && token.Type != COMMENT && token.Type != MULTILINE_COMMENT && token.Type != INCOMPLETE_COMMENT {
 		if token.Type == QUOTED_IDENT && !n.config.KeepIdentifierQuotation {
 			token.Value = trimQuotes(token)
 		}
@@ -265,7 +1439,11 @@ func (n *Normalizer) normalizeSQL(token *Token, lastValueToken *LastValueToken,
 		}
 		if token.Type == EOF {
 			if headState.standaloneExpressionInParentheses {
+				start := normalizedSQLBuilder.Len()
 				normalizedSQLBuilder.WriteString(headState.expressionInParentheses.String())
+				if offsets != nil {
+					offsets.record(start, normalizedSQLBuilder.Len(), offsets.headStart, offsets.headEnd)
+				}
 			}
 			return
 		} else if headState.foundLeadingExpressionInParentheses {
@@ -308,35 +1486,83 @@ func (n *Normalizer) normalizeSQL(token *Token, lastValueToken *LastValueToken,
 			}
 		}
 
+		// collapse INSERT column and value lists into a single group
+		if n.config.CollapseInsertColumns && !n.config.KeepLiterals && n.isInsertGroupCollapsed(token, lastValueToken, insertGroups, normalizedSQLBuilder) {
+			return
+		}
+
+		// canonicalize literal-only IN lists, the content of the list is buffered until its closing parenthesis
+		var inListBuffer *strings.Builder
+		if n.config.CanonicalizeInList && !n.config.KeepLiterals {
+			target := normalizedSQLBuilder
+			if headState.inLeadingParenthesesExpression {
+				target = &headState.expressionInParentheses
+			}
+			wasBuffering, targetLen := inList.buffering, target.Len()
+			inListBuffer = n.bufferInList(token, lastValueToken, inList, target)
+			if offsets != nil {
+				if wasBuffering && !inList.buffering && !headState.inLeadingParenthesesExpression {
+					// the buffered list was written to the normalized SQL, map it as a whole
+					offsets.record(targetLen, target.Len(), offsets.inListStart, offsets.inListEnd)
+				}
+				if inListBuffer != nil {
+					extendRange(&offsets.inListStart, &offsets.inListEnd, token)
+				} else if !inList.buffering {
+					offsets.inListStart = -1
+				}
+			}
+		}
+
+		groupableBuilder := normalizedSQLBuilder
+		if inListBuffer != nil {
+			groupableBuilder = inListBuffer
+		}
+
 		// group consecutive obfuscated values into single placeholder
-		if n.isObfuscatedValueGroupable(token, lastValueToken, groupablePlaceholder, normalizedSQLBuilder) {
+		if !n.config.KeepLiterals && n.isObfuscatedValueGroupable(token, lastValueToken, groupablePlaceholder, groupableBuilder) {
 			// return the token but not write it to the normalizedSQLBuilder
 			return
 		}
 
-		if headState.inLeadingParenthesesExpression {
+		if inListBuffer != nil {
+			n.appendSpace(token, lastValueToken, inListBuffer)
+			n.writeToken(token.Type, token.Value, inListBuffer)
+		} else if headState.inLeadingParenthesesExpression {
 			n.appendSpace(token, lastValueToken, &headState.expressionInParentheses)
 			n.writeToken(token.Type, token.Value, &headState.expressionInParentheses)
+			if offsets != nil {
+				extendRange(&offsets.headStart, &offsets.headEnd, token)
+			}
 			if token.Type == PUNCTUATION && token.Value == ")" {
 				headState.inLeadingParenthesesExpression = false
 				headState.foundLeadingExpressionInParentheses = true
 			}
 		} else {
 			n.appendSpace(token, lastValueToken, normalizedSQLBuilder)
-			n.writeToken(token.Type, token.Value, normalizedSQLBuilder)
+			if offsets != nil {
+				start := normalizedSQLBuilder.Len()
+				n.writeToken(token.Type, token.Value, normalizedSQLBuilder)
+				offsets.record(start, normalizedSQLBuilder.Len(), token.Start, token.End)
+			} else {
+				n.writeToken(token.Type, token.Value, normalizedSQLBuilder)
+			}
 		}
 	}
 }
 
-func (n *Normalizer) writeToken(tokenType TokenType, tokenValue string, normalizedSQLBuilder *strings.Builder) {
+func (n *Normalizer) writeToken(tokenType TokenType, tokenValue string, normalizedSQLBuilder io.StringWriter) {
 	if n.config.UppercaseKeywords && (tokenType == COMMAND || tokenType == KEYWORD) {
-		normalizedSQLBuilder.WriteString(strings.ToUpper(tokenValue))
+		normalizedSQLBuilder.WriteString(ToUpperASCII(tokenValue))
+	} else if n.config.CanonicalizeNullBoolean && (tokenType == NULL || tokenType == BOOLEAN) {
+		normalizedSQLBuilder.WriteString(ToUpperASCII(tokenValue))
+	} else if n.config.NotEqualOperator != "" && tokenType == OPERATOR && (tokenValue == "<>" || tokenValue == "!=") {
+		normalizedSQLBuilder.WriteString(n.config.NotEqualOperator)
 	} else {
 		normalizedSQLBuilder.WriteString(tokenValue)
 	}
 }
 
-func (n *Normalizer) isObfuscatedValueGroupable(token *Token, lastValueToken *LastValueToken, groupablePlaceholder *groupablePlaceholder, normalizedSQLBuilder *strings.Builder) bool {
+func (n *Normalizer) isObfuscatedValueGroupable(token *Token, lastValueToken *LastValueToken, groupablePlaceholder *groupablePlaceholder, normalizedSQLBuilder io.StringWriter) bool {
 	if token.Value == NumberPlaceholder || token.Value == StringPlaceholder {
 		if lastValueToken == nil {
 			// if the last token is nil, we know it's the start of groupable placeholders
@@ -373,7 +1599,130 @@ func (n *Normalizer) isObfuscatedValueGroupable(token *Token, lastValueToken *La
 	return false
 }
 
-func (n *Normalizer) appendSpace(token *Token, lastValueToken *LastValueToken, normalizedSQLBuilder *strings.Builder) {
+// isInsertGroupCollapsed returns true if the token belongs to an INSERT column or value list that is collapsed
+// into "( ... )". The collapsed group is written when its opening parenthesis is seen, the rest of the group is skipped.
+// Consecutive value lists, e.g. VALUES (?, ?), (?, ?), are collapsed into a single group.
+func (n *Normalizer) isInsertGroupCollapsed(token *Token, lastValueToken *LastValueToken, insertGroups *insertGroupsState, normalizedSQLBuilder io.StringWriter) bool {
+	if insertGroups.depth > 0 {
+		if token.Value == "(" {
+			insertGroups.depth++
+		} else if token.Value == ")" {
+			insertGroups.depth--
+		}
+		return true
+	}
+
+	if token.Type == COMMAND {
+		*insertGroups = insertGroupsState{inInsert: equalFoldASCII(token.Value, "INSERT")}
+		return false
+	}
+	if !insertGroups.inInsert {
+		return false
+	}
+
+	if insertGroups.pendingRowComma {
+		insertGroups.pendingRowComma = false
+		if token.Value == "(" {
+			// another row of values, skip it entirely
+			insertGroups.depth = 1
+			return true
+		}
+		// the swallowed comma was not followed by another row, write it back
+		normalizedSQLBuilder.WriteString(",")
+	}
+
+	if token.Type == KEYWORD && equalFoldASCII(token.Value, "VALUES") {
+		insertGroups.afterValues = true
+	}
+
+	if token.Value == "," && insertGroups.collapsedRow {
+		insertGroups.pendingRowComma = true
+		return true
+	}
+	insertGroups.collapsedRow = false
+
+	if token.Value != "(" || lastValueToken == nil {
+		return false
+	}
+	isColumnList := !insertGroups.afterValues && (lastValueToken.Type == IDENT || lastValueToken.Type == QUOTED_IDENT)
+	isValueList := insertGroups.afterValues && lastValueToken.Type == KEYWORD && equalFoldASCII(lastValueToken.Value, "VALUES")
+	if !isColumnList && !isValueList {
+		return false
+	}
+
+	n.appendSpace(token, lastValueToken, normalizedSQLBuilder)
+	if n.config.RemoveSpaceBetweenParentheses {
+		normalizedSQLBuilder.WriteString("(...)")
+	} else {
+		normalizedSQLBuilder.WriteString("( ... )")
+	}
+	insertGroups.depth = 1
+	insertGroups.collapsedRow = isValueList
+	return true
+}
+
+// bufferInList returns the builder the token should be written to if it is part of an IN list, or nil otherwise.
+// When the IN list is closed, its buffered content is written to target, or replaced with a single placeholder
+// if the list only contains literals.
+func (n *Normalizer) bufferInList(token *Token, lastValueToken *LastValueToken, inList *inListState, target sqlWriter) *strings.Builder {
+	if !inList.buffering {
+		if isInListStart(token, lastValueToken) {
+			inList.buffering = true
+			inList.literalOnly = true
+			inList.expectValue = true
+			inList.buffer.Reset()
+		}
+		return nil
+	}
+
+	switch token.Value {
+	case "(":
+		inList.literalOnly = false
+	case ")":
+		inList.buffering = false
+		if inList.expectValue {
+			// empty list or trailing comma
+			target.WriteString(inList.buffer.String())
+			return nil
+		}
+		if !n.config.RemoveSpaceBetweenParentheses {
+			target.WriteString(" ")
+		}
+		target.WriteString(NumberPlaceholder)
+		return nil
+	default:
+		if inList.expectValue {
+			inList.expectValue = false
+			if !isLiteral(token) {
+				inList.literalOnly = false
+			}
+		} else if token.Value == "," {
+			inList.expectValue = true
+		} else {
+			inList.literalOnly = false
+		}
+	}
+
+	if !inList.literalOnly {
+		// the list cannot be canonicalized, write what was buffered so far and stop buffering
+		// so that nested IN lists, e.g. in subqueries, can still be canonicalized
+		inList.buffering = false
+		target.WriteString(inList.buffer.String())
+		return nil
+	}
+	return &inList.buffer
+}
+
+// isLiteral returns true if the token is a literal value or a placeholder
+func isLiteral(token *Token) bool {
+	switch token.Type {
+	case NUMBER, STRING, INCOMPLETE_STRING, DOLLAR_QUOTED_STRING, BOOLEAN, NULL, POSITIONAL_PARAMETER, BIND_PARAMETER, PLACEHOLDER:
+		return true
+	}
+	return token.Value == StringPlaceholder
+}
+
+func (n *Normalizer) appendSpace(token *Token, lastValueToken *LastValueToken, normalizedSQLBuilder io.StringWriter) {
 	// do not add a space between parentheses if RemoveSpaceBetweenParentheses is true
 	if n.config.RemoveSpaceBetweenParentheses && lastValueToken != nil && (lastValueToken.Type == FUNCTION || lastValueToken.Value == "(" || lastValueToken.Value == "[") {
 		return
@@ -403,3 +1752,11 @@ func (n *Normalizer) trimNormalizedSQL(normalizedSQL string) string {
 	}
 	return strings.TrimSpace(normalizedSQL)
 }
+
+// trimNormalizedBytes trims the normalized SQL as trimNormalizedSQL does, without converting it to a string
+func (n *Normalizer) trimNormalizedBytes(normalizedSQL []byte) []byte {
+	if !n.config.KeepTrailingSemicolon {
+		normalizedSQL = bytes.TrimSuffix(normalizedSQL, []byte(";"))
+	}
+	return bytes.TrimSpace(normalizedSQL)
+}
@@ -36,3 +36,38 @@ func BenchmarkNormalizer(b *testing.B) {
 		})
 	}
 }
+
+// BenchmarkNormalizerOLTP measures the normalization of typical OLTP statements,
+// which are expected to take at most 2 allocations: the normalized SQL and the statement metadata.
+// Statements aliasing their tables also allocate the map of table aliases.
+func BenchmarkNormalizerOLTP(b *testing.B) {
+	benchmarks := []struct {
+		name  string
+		query string
+	}{
+		{"Select", "SELECT id, name, email FROM users WHERE id = ? AND deleted_at IS NULL"},
+		{"Insert", "INSERT INTO orders (user_id, total, created_at) VALUES (?, ?, ?)"},
+		{"Update", "UPDATE accounts SET balance = balance - ? WHERE id = ?"},
+		{"Delete", "DELETE FROM sessions WHERE expires_at < ?"},
+		{"Join", "SELECT o.id, o.total FROM orders o JOIN users u ON u.id = o.user_id WHERE u.id = ? ORDER BY o.created_at DESC LIMIT ?"},
+	}
+	normalizer := NewNormalizer(
+		WithCollectComments(true),
+		WithCollectCommands(true),
+		WithCollectTables(true),
+		WithKeepSQLAlias(false),
+	)
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ResetTimer()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _, err := normalizer.Normalize(bm.query)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package sqllexer
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -12,6 +15,7 @@ func TestNormalizer(t *testing.T) {
 		input             string
 		expected          string
 		statementMetadata StatementMetadata
+		lexerOpts         []lexerOption
 	}{
 		{
 			input:    "SELECT ?",
@@ -64,6 +68,8 @@ func TestNormalizer(t *testing.T) {
 				Procedures: []string{},
 				Size:       32,
 			},
+			// the digits of the identifiers were obfuscated
+			lexerOpts: []lexerOption{WithQuestionMarkIdentifiers(true)},
 		},
 		{
 			input:    "/* this is a comment */ SELECT * FROM users WHERE id = ?",
@@ -76,6 +82,18 @@ func TestNormalizer(t *testing.T) {
 				Size:       34,
 			},
 		},
+		{
+			// the comment is truncated
+			input:    "SELECT * FROM users WHERE id = ? /* this is a trunc",
+			expected: "SELECT * FROM users WHERE id = ?",
+			statementMetadata: StatementMetadata{
+				Tables:     []string{"users"},
+				Comments:   []string{"/* this is a trunc"},
+				Commands:   []string{"SELECT"},
+				Procedures: []string{},
+				Size:       29,
+			},
+		},
 		{
 			input: `
 					/* this is a 
@@ -167,6 +185,8 @@ multiline comment */
 				Procedures: []string{},
 				Size:       49,
 			},
+			// the digits of the identifiers were obfuscated
+			lexerOpts: []lexerOption{WithQuestionMarkIdentifiers(true)},
 		},
 		{
 			input: `
@@ -283,11 +303,11 @@ multiline comment */
 			input:    "/* Testing explicit table SQL expression */ WITH T1 AS (SELECT PNO , PNAME , COLOR , WEIGHT , CITY FROM P WHERE CITY = ?), T2 AS (SELECT PNO, PNAME, COLOR, WEIGHT, CITY, ? * WEIGHT AS NEW_WEIGHT, ? AS NEW_CITY FROM T1), T3 AS ( SELECT PNO , PNAME, COLOR, NEW_WEIGHT AS WEIGHT, NEW_CITY AS CITY FROM T2), T4 AS ( TABLE P EXCEPT CORRESPONDING TABLE T1) TABLE T4 UNION CORRESPONDING TABLE T3",
 			expected: "WITH T1 AS ( SELECT PNO, PNAME, COLOR, WEIGHT, CITY FROM P WHERE CITY = ? ), T2 AS ( SELECT PNO, PNAME, COLOR, WEIGHT, CITY, ? * WEIGHT, ? FROM T1 ), T3 AS ( SELECT PNO, PNAME, COLOR, NEW_WEIGHT, NEW_CITY FROM T2 ), T4 AS ( TABLE P EXCEPT CORRESPONDING TABLE T1 ) TABLE T4 UNION CORRESPONDING TABLE T3",
 			statementMetadata: StatementMetadata{
-				Tables:     []string{"P", "T2", "T4", "T3"},
+				Tables:     []string{"P"},
 				Comments:   []string{"/* Testing explicit table SQL expression */"},
 				Commands:   []string{"SELECT"},
 				Procedures: []string{},
-				Size:       56,
+				Size:       50,
 			},
 		},
 		{
@@ -306,11 +326,11 @@ multiline comment */
 			input:    "SELECT d.id, d.uuid, d.org_id, d.creator_id, d.updater_id, d.monitor_id, d.parent_id, d.original_parent_id, d.scope, d.start_dt, d.end_dt, d.canceled_dt, d.active, d.disabled, d.created, d.modified, d.message, d.monitor_tags, d.recurrence, d.mute_first_recovery_notification, d.scope_v2_query, d.scope_v2 FROM monitor_downtime d, org o WHERE o.id = d.org_id AND d.modified >= ? AND o.partition_num = ANY (?, ?, ?)",
 			expected: "SELECT d.id, d.uuid, d.org_id, d.creator_id, d.updater_id, d.monitor_id, d.parent_id, d.original_parent_id, d.scope, d.start_dt, d.end_dt, d.canceled_dt, d.active, d.disabled, d.created, d.modified, d.message, d.monitor_tags, d.recurrence, d.mute_first_recovery_notification, d.scope_v2_query, d.scope_v2 FROM monitor_downtime d, org o WHERE o.id = d.org_id AND d.modified >= ? AND o.partition_num = ANY ( ? )",
 			statementMetadata: StatementMetadata{
-				Tables:     []string{"monitor_downtime"},
+				Tables:     []string{"monitor_downtime", "org"},
 				Comments:   []string{},
 				Commands:   []string{"SELECT"},
 				Procedures: []string{},
-				Size:       22,
+				Size:       25,
 			},
 		},
 		{
@@ -390,7 +410,7 @@ multiline comment */
 
 	for _, test := range tests {
 		t.Run("", func(t *testing.T) {
-			got, statementMetadata, err := normalizer.Normalize(test.input)
+			got, statementMetadata, err := normalizer.Normalize(test.input, test.lexerOpts...)
 			assert.NoError(t, err)
 			assert.Equal(t, test.expected, got)
 			assertStatementMetadataEqual(t, &test.statementMetadata, statementMetadata)
@@ -517,6 +537,279 @@ func TestNormalizerFormatting(t *testing.T) {
 	}
 }
 
+func TestNormalizerCollectTablesAndAliases(t *testing.T) {
+	tests := []struct {
+		input        string
+		tables       []string
+		tableAliases map[string]string
+		lexerOpts    []lexerOption
+	}{
+		{
+			input:        "SELECT * FROM a AS x JOIN b y ON x.id = y.id",
+			tables:       []string{"a", "b"},
+			tableAliases: map[string]string{"x": "a", "y": "b"},
+		},
+		{
+			input:        "SELECT * FROM public.users u, sales.orders o WHERE u.id = o.user_id",
+			tables:       []string{"public.users", "sales.orders"},
+			tableAliases: map[string]string{"u": "public.users", "o": "sales.orders"},
+		},
+		{
+			input:        `SELECT * FROM "public"."users" AS "u" NATURAL JOIN orders`,
+			tables:       []string{"public.users", "orders"},
+			tableAliases: map[string]string{"u": "public.users"},
+		},
+		{
+			input:        "UPDATE users u SET name = ? WHERE u.id = ?",
+			tables:       []string{"users"},
+			tableAliases: map[string]string{"u": "users"},
+		},
+		{
+			input:        "DELETE FROM users u USING orders o WHERE u.id = o.user_id",
+			tables:       []string{"users", "orders"},
+			tableAliases: map[string]string{"u": "users", "o": "orders"},
+		},
+		{
+			input:  "SELECT * FROM generate_series(1, n) WHERE id IN (SELECT id FROM users)",
+			tables: []string{"generate_series", "users"},
+		},
+		{
+			input:  "SELECT * FROM users WHERE id = ? ORDER BY name",
+			tables: []string{"users"},
+		},
+		{
+			input:        "SELECT * FROM [dbo].[users] u",
+			tables:       []string{"dbo.users"},
+			tableAliases: map[string]string{"u": "dbo.users"},
+			lexerOpts:    []lexerOption{WithDBMS(DBMSSQLServer)},
+		},
+	}
+
+	normalizer := NewNormalizer(WithCollectTables(true))
+
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			_, statementMetadata, err := normalizer.Normalize(test.input, test.lexerOpts...)
+			assert.NoError(t, err)
+			assert.Equal(t, test.tables, statementMetadata.Tables)
+			assert.Equal(t, test.tableAliases, statementMetadata.TableAliases)
+		})
+	}
+}
+
+func TestNormalizerExcludeCTEsFromTables(t *testing.T) {
+	tests := []struct {
+		input  string
+		tables []string
+	}{
+		{
+			input:  "WITH recent AS (SELECT * FROM orders WHERE created > ?) SELECT * FROM recent JOIN users ON recent.user_id = users.id",
+			tables: []string{"orders", "users"},
+		},
+		{
+			input:  "WITH a AS (SELECT * FROM t1), b AS (SELECT * FROM t2, a) SELECT * FROM a, b, t3",
+			tables: []string{"t1", "t2", "t3"},
+		},
+		{
+			input:  "WITH RECURSIVE tree(id, parent_id) AS (SELECT id, parent_id FROM nodes UNION ALL SELECT n.id, n.parent_id FROM nodes n JOIN tree ON n.parent_id = tree.id) SELECT * FROM tree",
+			tables: []string{"nodes"},
+		},
+		{
+			input:  "WITH moved AS (DELETE FROM queue RETURNING *) INSERT INTO archive SELECT * FROM moved",
+			tables: []string{"queue", "archive"},
+		},
+	}
+
+	normalizer := NewNormalizer(WithCollectTables(true))
+
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			_, statementMetadata, err := normalizer.Normalize(test.input)
+			assert.NoError(t, err)
+			assert.Equal(t, test.tables, statementMetadata.Tables)
+		})
+	}
+}
+
+func TestNormalizerCollectJoins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []Join
+	}{
+		{
+			input:    "SELECT * FROM users",
+			expected: []Join{},
+		},
+		{
+			input: "SELECT * FROM a AS x JOIN b y ON x.id = y.id LEFT OUTER JOIN c ON c.id = b.id",
+			expected: []Join{
+				{Type: "INNER", Left: "a", Right: "b"},
+				{Type: "LEFT", Left: "b", Right: "c"},
+			},
+		},
+		{
+			input: "SELECT * FROM a INNER JOIN b ON a.id = b.id RIGHT JOIN c ON c.id = b.id FULL OUTER JOIN d ON d.id = c.id",
+			expected: []Join{
+				{Type: "INNER", Left: "a", Right: "b"},
+				{Type: "RIGHT", Left: "b", Right: "c"},
+				{Type: "FULL", Left: "c", Right: "d"},
+			},
+		},
+		{
+			input: "SELECT * FROM a CROSS JOIN b NATURAL JOIN c, d",
+			expected: []Join{
+				{Type: "CROSS", Left: "a", Right: "b"},
+				{Type: "NATURAL", Left: "b", Right: "c"},
+				{Type: "CROSS", Left: "c", Right: "d"},
+			},
+		},
+		{
+			input: "SELECT * FROM users u, LATERAL (SELECT * FROM logs l WHERE l.user_id = u.id) AS l2 LEFT JOIN LATERAL (SELECT 1) x ON true",
+			expected: []Join{
+				{Type: "LATERAL", Left: "users", Right: ""},
+				{Type: "LATERAL", Left: "users", Right: ""},
+			},
+		},
+		{
+			input: "SELECT * FROM a WHERE a.id IN (SELECT b.id FROM b JOIN c ON b.id = c.id) AND a.x = ?",
+			expected: []Join{
+				{Type: "INNER", Left: "b", Right: "c"},
+			},
+		},
+		{
+			input: "SELECT * FROM a STRAIGHT_JOIN b ON a.id = b.id",
+			expected: []Join{
+				{Type: "STRAIGHT_JOIN", Left: "a", Right: "b"},
+			},
+		},
+	}
+
+	normalizer := NewNormalizer(WithCollectJoins(true))
+
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			_, statementMetadata, err := normalizer.Normalize(test.input)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, statementMetadata.Joins)
+			assert.Equal(t, []string{}, statementMetadata.Tables)
+		})
+	}
+}
+
+func TestNormalizerCollectOrdering(t *testing.T) {
+	tests := []struct {
+		input   string
+		groupBy []OrderingExpression
+		orderBy []OrderingExpression
+	}{
+		{
+			input:   "SELECT * FROM users",
+			groupBy: []OrderingExpression{},
+			orderBy: []OrderingExpression{},
+		},
+		{
+			input: "SELECT a, count(*) FROM t GROUP BY a, 2 HAVING count(*) > 1 ORDER BY 2 DESC, lower(\"Name\") ASC NULLS LAST LIMIT 10",
+			groupBy: []OrderingExpression{
+				{Expression: "a"},
+				{Expression: "2", Ordinal: 2},
+			},
+			orderBy: []OrderingExpression{
+				{Expression: "2", Ordinal: 2, Descending: true},
+				{Expression: "lower(Name)"},
+			},
+		},
+		{
+			input:   "SELECT row_number() OVER (PARTITION BY a ORDER BY b), array_agg(c ORDER BY d) FROM t ORDER BY a",
+			groupBy: []OrderingExpression{},
+			orderBy: []OrderingExpression{{Expression: "a"}},
+		},
+		{
+			input:   "SELECT * FROM (SELECT a FROM t ORDER BY a DESC LIMIT 1) s ORDER BY s.a, CASE WHEN x = 1 THEN 1 ELSE 2 END",
+			groupBy: []OrderingExpression{},
+			orderBy: []OrderingExpression{
+				{Expression: "a", Descending: true},
+				{Expression: "s.a"},
+				{Expression: "CASE WHEN x = 1 THEN 1 ELSE 2 END"},
+			},
+		},
+		{
+			input:   "SELECT a FROM t GROUP BY a WITH ROLLUP",
+			groupBy: []OrderingExpression{{Expression: "a"}},
+			orderBy: []OrderingExpression{},
+		},
+		{
+			input:   "SELECT a FROM t WINDOW w AS (ORDER BY b) ORDER BY c FOR UPDATE",
+			groupBy: []OrderingExpression{},
+			orderBy: []OrderingExpression{{Expression: "c"}},
+		},
+		{
+			input:   "SELECT a FROM t GROUP BY a ORDER BY 1.5",
+			groupBy: []OrderingExpression{{Expression: "a"}},
+			orderBy: []OrderingExpression{{Expression: "1.5"}},
+		},
+	}
+
+	normalizer := NewNormalizer(WithCollectOrdering(true))
+
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			_, statementMetadata, err := normalizer.Normalize(test.input)
+			assert.NoError(t, err)
+			assert.Equal(t, test.groupBy, statementMetadata.GroupBy)
+			assert.Equal(t, test.orderBy, statementMetadata.OrderBy)
+		})
+	}
+}
+
+func TestNormalizerCollectOrderingObfuscated(t *testing.T) {
+	normalizer := NewNormalizer(WithCollectOrdering(true))
+	_, statementMetadata, err := ObfuscateAndNormalize("SELECT a FROM t ORDER BY 1, a = 'secret' DESC", NewObfuscator(), normalizer)
+	assert.NoError(t, err)
+	assert.Equal(t, []OrderingExpression{
+		{Expression: "1", Ordinal: 1},
+		{Expression: "a = ?", Descending: true},
+	}, statementMetadata.OrderBy)
+}
+
+func TestNormalizerCollapseWhitespace(t *testing.T) {
+	tests := []struct {
+		queries  []string
+		expected string
+	}{
+		{
+			queries: []string{
+				"SELECT id, name FROM users WHERE id = ?",
+				"SELECT id,\n\tname\nFROM users\nWHERE id = ?",
+				"SELECT id,\r\n\tname\r\nFROM users\r\nWHERE id = ?\r\n",
+				"\n\n  SELECT   id ,   name\tFROM\t\tusers   WHERE\n  id   =   ?  \n",
+				"SELECT\fid,\vname FROM users WHERE id = ?",
+				"SELECT id, -- inline comment\n name\n/* block\n comment */FROM users WHERE id = ?",
+			},
+			expected: "SELECT id, name FROM users WHERE id = ?",
+		},
+		{
+			queries: []string{
+				"(SELECT id FROM users)",
+				"(\n\tSELECT id\n\tFROM users\n)",
+				"(\r\n  SELECT id\r\n  FROM users\r\n)\r\n",
+			},
+			expected: "( SELECT id FROM users )",
+		},
+	}
+
+	normalizer := NewNormalizer()
+
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			for _, query := range test.queries {
+				got, _, err := normalizer.Normalize(query)
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, got)
+			}
+		})
+	}
+}
+
 func TestNormalizerRepeatedExecution(t *testing.T) {
 	// This test is to ensure that repeated executions of the normalizer
 	// should always produce the same normalized SQL.
@@ -788,6 +1081,22 @@ func TestNormalizeDeobfuscatedSQL(t *testing.T) {
 				KeepIdentifierQuotation: false,
 			},
 		},
+		{
+			input:    `SELECT "say ""hi""" FROM "my""users" WHERE id = ?`,
+			expected: `SELECT say "hi" FROM my"users WHERE id = ?`,
+			statementMetadata: StatementMetadata{
+				Tables:     []string{`my"users`},
+				Comments:   []string{},
+				Commands:   []string{"SELECT"},
+				Procedures: []string{},
+				Size:       14,
+			},
+			normalizationConfig: &normalizerConfig{
+				CollectComments: true,
+				CollectCommands: true,
+				CollectTables:   true,
+			},
+		},
 		{
 			input:    `SELECT * FROM "public"."users" WHERE id = ?`,
 			expected: `SELECT * FROM public.users WHERE id = ?`,
@@ -1197,6 +1506,644 @@ func TestNormalizerKeepTrailingSemicolon(t *testing.T) {
 	}
 }
 
+func TestNormalizerCollectColumns(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			input:    "SELECT id, name FROM users WHERE org_id = ? ORDER BY created_at",
+			expected: []string{"id", "name", "org_id", "created_at"},
+		},
+		{
+			input:    "SELECT u.id AS user_id, u.name username FROM users u WHERE u.status IN (?, ?)",
+			expected: []string{"id", "name", "status"},
+		},
+		{
+			input:    "SELECT count(*), dept FROM employees e JOIN depts d ON e.dept_id = d.id GROUP BY dept HAVING count(*) > ?",
+			expected: []string{"dept", "dept_id", "id"},
+		},
+		{
+			input:    "SELECT name FROM users WHERE id IN (SELECT user_id FROM orders WHERE total > ?) AND active = ?",
+			expected: []string{"name", "id", "user_id", "total", "active"},
+		},
+		{
+			input:    "SELECT CASE WHEN age < ? THEN ? ELSE ? END FROM users",
+			expected: []string{"age"},
+		},
+		{
+			input:    "INSERT INTO users (id, name) VALUES (?, ?)",
+			expected: []string{},
+		},
+	}
+
+	normalizer := NewNormalizer(WithCollectColumns(true))
+
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			_, statementMetadata, err := normalizer.Normalize(test.input)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, statementMetadata.Columns)
+		})
+	}
+}
+
+func TestNormalizerCollapseInsertColumns(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		options  []normalizerOption
+	}{
+		{
+			input:    "INSERT INTO users (id, name, email) VALUES (?, ?, ?)",
+			expected: "INSERT INTO users ( ... ) VALUES ( ... )",
+		},
+		{
+			input:    "INSERT INTO users (name, id) VALUES (?, ?), (?, ?), (?, ?)",
+			expected: "INSERT INTO users ( ... ) VALUES ( ... )",
+		},
+		{
+			input:    "INSERT INTO users (id, created_at) VALUES (?, now()) RETURNING id",
+			expected: "INSERT INTO users ( ... ) VALUES ( ... ) RETURNING id",
+		},
+		{
+			input:    "INSERT INTO users VALUES (?, ?)",
+			expected: "INSERT INTO users VALUES ( ... )",
+		},
+		{
+			input:    "INSERT INTO archive (id, name) SELECT id, name FROM users WHERE id IN (?, ?)",
+			expected: "INSERT INTO archive ( ... ) SELECT id, name FROM users WHERE id IN ( ? )",
+		},
+		{
+			input:    "INSERT INTO users (id, name) VALUES (?, ?)",
+			expected: "INSERT INTO users (...) VALUES (...)",
+			options:  []normalizerOption{WithRemoveSpaceBetweenParentheses(true)},
+		},
+		{
+			input:    "SELECT * FROM users WHERE id IN (?, ?)",
+			expected: "SELECT * FROM users WHERE id IN ( ? )",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			normalizer := NewNormalizer(append([]normalizerOption{WithCollapseInsertColumns(true)}, test.options...)...)
+			got, _, err := normalizer.Normalize(test.input)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, got)
+		})
+	}
+}
+
+func TestNormalizerCanonicalizeInList(t *testing.T) {
+	tests := []struct {
+		queries  []string
+		expected string
+		options  []normalizerOption
+	}{
+		{
+			queries: []string{
+				"SELECT * FROM users WHERE id IN (1, 2, 3)",
+				"SELECT * FROM users WHERE id IN (3, 1, 2)",
+				"SELECT * FROM users WHERE id IN (42)",
+				"SELECT * FROM users WHERE id IN (?, ?)",
+				"SELECT * FROM users WHERE id IN ($1, $2, $3)",
+			},
+			expected: "SELECT * FROM users WHERE id IN ( ? )",
+		},
+		{
+			queries: []string{
+				"SELECT * FROM users WHERE name IN ('b', 'a') AND id IN (2, 1)",
+				"SELECT * FROM users WHERE name IN ('a') AND id IN (1, 2, 3)",
+			},
+			expected: "SELECT * FROM users WHERE name IN ( ? ) AND id IN ( ? )",
+		},
+		{
+			queries: []string{
+				"SELECT * FROM users WHERE id IN (2,1)",
+			},
+			expected: "SELECT * FROM users WHERE id IN (?)",
+			options:  []normalizerOption{WithRemoveSpaceBetweenParentheses(true)},
+		},
+		{
+			queries: []string{
+				"SELECT * FROM users WHERE id IN (SELECT user_id FROM orders WHERE total IN (3, 1))",
+			},
+			expected: "SELECT * FROM users WHERE id IN ( SELECT user_id FROM orders WHERE total IN ( ? ) )",
+		},
+		{
+			queries: []string{
+				"SELECT * FROM users WHERE id IN (1, other_id, 3)",
+			},
+			expected: "SELECT * FROM users WHERE id IN ( 1, other_id, 3 )",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			normalizer := NewNormalizer(append([]normalizerOption{WithCanonicalizeInList(true)}, test.options...)...)
+			for _, query := range test.queries {
+				got, _, err := normalizer.Normalize(query)
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, got)
+			}
+		})
+	}
+}
+
+func TestNormalizerCanonicalizeNullBooleanAndNotEqual(t *testing.T) {
+	tests := []struct {
+		queries  []string
+		expected string
+		options  []normalizerOption
+	}{
+		{
+			queries: []string{
+				"SELECT * FROM users WHERE deleted_at IS NULL AND active = TRUE",
+				"SELECT * FROM users WHERE deleted_at IS null AND active = true",
+				"SELECT * FROM users WHERE deleted_at IS Null AND active = True",
+			},
+			expected: "SELECT * FROM users WHERE deleted_at IS NULL AND active = TRUE",
+			options:  []normalizerOption{WithCanonicalizeNullBoolean(true)},
+		},
+		{
+			queries: []string{
+				"SELECT * FROM users WHERE id <> 1",
+				"SELECT * FROM users WHERE id != 1",
+			},
+			expected: "SELECT * FROM users WHERE id <> 1",
+			options:  []normalizerOption{WithNotEqualOperator("<>")},
+		},
+		{
+			queries: []string{
+				"SELECT * FROM users WHERE id <> 1",
+				"SELECT * FROM users WHERE id != 1",
+			},
+			expected: "SELECT * FROM users WHERE id != 1",
+			options:  []normalizerOption{WithNotEqualOperator("!=")},
+		},
+		{
+			queries: []string{
+				"SELECT * FROM users WHERE id <> 1 AND name = null",
+			},
+			expected: "SELECT * FROM users WHERE id <> 1 AND name = null",
+			options:  []normalizerOption{WithNotEqualOperator("=!")},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			normalizer := NewNormalizer(test.options...)
+			for _, query := range test.queries {
+				got, _, err := normalizer.Normalize(query)
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, got)
+			}
+		})
+	}
+}
+
+func TestNormalizerFoldIdentifierCase(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  string
+		tables    []string
+		foldCase  IdentifierCase
+		lexerOpts []lexerOption
+	}{
+		{
+			input:    `SELECT Id FROM Users WHERE "Name" = ?`,
+			expected: `SELECT Id FROM Users WHERE Name = ?`,
+			tables:   []string{"Users"},
+		},
+		{
+			input:    `SELECT Id FROM Users WHERE "Name" = ?`,
+			expected: `SELECT Id FROM Users WHERE Name = ?`,
+			tables:   []string{"Users"},
+			foldCase: IdentifierCasePreserve,
+		},
+		{
+			input:    `SELECT Id FROM Public.Users WHERE "Name" = ?`,
+			expected: `SELECT id FROM public.users WHERE Name = ?`,
+			tables:   []string{"public.users"},
+			foldCase: IdentifierCaseLower,
+		},
+		{
+			input:    `select id from users where "Name" = ?`,
+			expected: `select ID from USERS where Name = ?`,
+			tables:   []string{"USERS"},
+			foldCase: IdentifierCaseUpper,
+		},
+		{
+			input:     `SELECT Id FROM Users`,
+			expected:  `SELECT id FROM users`,
+			tables:    []string{"users"},
+			foldCase:  IdentifierCaseAuto,
+			lexerOpts: []lexerOption{WithDBMS(DBMSPostgres)},
+		},
+		{
+			input:     `SELECT Id FROM Users`,
+			expected:  `SELECT ID FROM USERS`,
+			tables:    []string{"USERS"},
+			foldCase:  IdentifierCaseAuto,
+			lexerOpts: []lexerOption{WithDBMS(DBMSOracle)},
+		},
+		{
+			input:     `SELECT Id FROM Users`,
+			expected:  `SELECT Id FROM Users`,
+			tables:    []string{"Users"},
+			foldCase:  IdentifierCaseAuto,
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			normalizer := NewNormalizer(WithCollectTables(true), WithFoldIdentifierCase(test.foldCase))
+			got, statementMetadata, err := normalizer.Normalize(test.input, test.lexerOpts...)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, got)
+			assert.Equal(t, test.tables, statementMetadata.Tables)
+		})
+	}
+}
+
+func TestNormalizerASCIICaseFolding(t *testing.T) {
+	normalizer := NewNormalizer(WithUppercaseKeywords(true), WithFoldIdentifierCase(IdentifierCaseUpper))
+	got, _, err := normalizer.Normalize("select straße, ıd from tablo where ad = ?")
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT STRAßE, ıD FROM TABLO WHERE AD = ?", got)
+
+	normalizer = NewNormalizer(WithUppercaseKeywords(true), WithFoldIdentifierCase(IdentifierCaseLower))
+	got, _, err = normalizer.Normalize("SELECT İD FROM TABLO")
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT İd FROM tablo", got)
+}
+
+func TestNormalizerKeepLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    "select *\n  from users   where id in (?, ?, ?) -- comment",
+			expected: "SELECT * FROM users WHERE id IN ( ?, ?, ? )",
+		},
+		{
+			input:    "SELECT * FROM users WHERE name IN ('b', 'a') AND id = 42",
+			expected: "SELECT * FROM users WHERE name IN ( 'b', 'a' ) AND id = 42",
+		},
+		{
+			input:    "INSERT INTO users (id, name) VALUES (1, 'a'), (2, 'b')",
+			expected: "INSERT INTO users ( id, name ) VALUES ( 1, 'a' ), ( 2, 'b' )",
+		},
+	}
+
+	normalizer := NewNormalizer(
+		WithKeepLiterals(true),
+		WithUppercaseKeywords(true),
+		WithCanonicalizeInList(true),
+		WithCollapseInsertColumns(true),
+	)
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			got, _, err := normalizer.Normalize(test.input)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, got)
+		})
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestNormalizerNormalizeTo(t *testing.T) {
+	tests := []struct {
+		input   string
+		options []normalizerOption
+	}{
+		{input: "SELECT * FROM users WHERE id = ?"},
+		{input: "  /* comment */ select id   from users;  "},
+		{input: "SELECT * FROM users WHERE id IN (?, ?, ?);", options: []normalizerOption{WithKeepTrailingSemicolon(true)}},
+		{input: "BEGIN NULL; END;", options: []normalizerOption{WithKeepTrailingSemicolon(true)}},
+		{input: "(SELECT 1) UNION (SELECT 2);"},
+		{input: ";"},
+		{input: ""},
+		{
+			input:   "insert into users (id, name) values (?, ?), (?, ?) -- batch",
+			options: []normalizerOption{WithCollapseInsertColumns(true), WithUppercaseKeywords(true), WithCollectTables(true)},
+		},
+		{
+			input:   "SELECT * FROM t WHERE a IN (1, 2) AND b IN (SELECT c FROM u WHERE d IN (3))",
+			options: []normalizerOption{WithCanonicalizeInList(true), WithCollectTables(true), WithCollectCommands(true)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			normalizer := NewNormalizer(test.options...)
+			expected, expectedMetadata, err := normalizer.Normalize(test.input)
+			assert.NoError(t, err)
+
+			var buf bytes.Buffer
+			statementMetadata, err := normalizer.NormalizeTo(&buf, test.input)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, buf.String())
+			assert.Equal(t, expectedMetadata, statementMetadata)
+		})
+	}
+
+	t.Run("write error", func(t *testing.T) {
+		_, err := NewNormalizer().NormalizeTo(failingWriter{}, "SELECT 1")
+		assert.EqualError(t, err, "write failed")
+	})
+}
+
+func TestNormalizerNormalizeAppend(t *testing.T) {
+	tests := []struct {
+		input   string
+		options []normalizerOption
+	}{
+		{input: "  /* comment */ select id   from users;  "},
+		{input: "SELECT * FROM users WHERE id IN (?, ?, ?);", options: []normalizerOption{WithKeepTrailingSemicolon(true)}},
+		{input: ";"},
+		{input: ""},
+		{
+			input:   "  SELECT a FROM t WHERE a IN (1, 2) ORDER BY a",
+			options: []normalizerOption{WithCollectTables(true), WithCollectOrdering(true), WithCollectOffsets(true)},
+		},
+	}
+
+	dst := []byte("-- ")
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			normalizer := NewNormalizer(test.options...)
+			expected, expectedMetadata, err := normalizer.Normalize(test.input)
+			assert.NoError(t, err)
+
+			// the normalized SQL is appended to the bytes of dst, which are kept
+			got, statementMetadata, err := normalizer.NormalizeAppend(dst[:3], test.input)
+			assert.NoError(t, err)
+			assert.Equal(t, "-- "+expected, string(got))
+			assert.Equal(t, expectedMetadata, statementMetadata)
+			dst = got
+		})
+	}
+}
+
+func TestNormalizerMetadataLimits(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected *StatementMetadata
+		options  []normalizerOption
+	}{
+		{
+			input: "SELECT * FROM a JOIN b ON a.id = b.id JOIN c ON b.id = c.id",
+			expected: &StatementMetadata{
+				Size:       12,
+				Tables:     []string{"a", "b"},
+				Comments:   []string{},
+				Commands:   []string{"SELECT", "JOIN"},
+				Procedures: []string{},
+				Joins:      []Join{{Type: "INNER", Left: "a", Right: "b"}, {Type: "INNER", Left: "b", Right: "c"}},
+				Truncated:  true,
+			},
+			options: []normalizerOption{WithMaxMetadataEntries(2)},
+		},
+		{
+			input: "SELECT * FROM a JOIN b ON a.id = b.id JOIN c ON b.id = c.id",
+			expected: &StatementMetadata{
+				Size:       7,
+				Tables:     []string{"a"},
+				Comments:   []string{},
+				Commands:   []string{"SELECT"},
+				Procedures: []string{},
+				Joins:      []Join{{Type: "INNER", Left: "a", Right: "b"}},
+				Truncated:  true,
+			},
+			options: []normalizerOption{WithMaxMetadataEntries(1)},
+		},
+		{
+			input: "/* a very long comment that does not fit */ SELECT * FROM users u",
+			expected: &StatementMetadata{
+				Size:         11,
+				Tables:       []string{"users"},
+				Comments:     []string{},
+				Commands:     []string{"SELECT"},
+				Procedures:   []string{},
+				TableAliases: map[string]string{"u": "users"},
+				Truncated:    true,
+			},
+			options: []normalizerOption{WithMaxMetadataSize(16)},
+		},
+		{
+			input: "/* comment */ SELECT * FROM users",
+			expected: &StatementMetadata{
+				Size:       24,
+				Tables:     []string{"users"},
+				Comments:   []string{"/* comment */"},
+				Commands:   []string{"SELECT"},
+				Procedures: []string{},
+			},
+			options: []normalizerOption{WithMaxMetadataEntries(1), WithMaxMetadataSize(24)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			options := append([]normalizerOption{
+				WithCollectTables(true),
+				WithCollectCommands(true),
+				WithCollectComments(true),
+			}, test.options...)
+			if test.expected.Joins != nil {
+				options = append(options, WithCollectJoins(true))
+			}
+			normalizer := NewNormalizer(options...)
+			_, statementMetadata, err := normalizer.Normalize(test.input)
+			assert.NoError(t, err)
+			statementMetadata.StatementKind = ""
+			assert.Equal(t, test.expected, statementMetadata)
+		})
+	}
+}
+
+func TestNormalizerMaxOutputBytes(t *testing.T) {
+	input := "/* job */ SELECT name, email FROM users u JOIN orders o ON o.user_id = u.id WHERE o.total > 10 ORDER BY name"
+	options := []normalizerOption{WithCollectTables(true), WithCollectCommands(true), WithCollectComments(true)}
+	expected, expectedMetadata, err := NewNormalizer(options...).Normalize(input)
+	assert.NoError(t, err)
+	full := len(expected) + expectedMetadata.Size
+
+	for _, maxOutputBytes := range []int{1, 10, 40, 60, full - 1, 1000} {
+		t.Run(fmt.Sprint(maxOutputBytes), func(t *testing.T) {
+			normalizer := NewNormalizer(append(options, WithMaxOutputBytes(maxOutputBytes))...)
+			normalized, metadata, err := normalizer.Normalize(input)
+			assert.NoError(t, err)
+			assert.LessOrEqual(t, len(normalized)+metadata.Size, maxOutputBytes)
+			assert.True(t, strings.HasPrefix(expected, normalized), normalized)
+			assert.Equal(t, maxOutputBytes < full, metadata.OutputTruncated)
+			if !metadata.OutputTruncated {
+				assert.Equal(t, expected, normalized)
+				assert.Equal(t, expectedMetadata, metadata)
+			}
+
+			result, err := Process(input, NewObfuscator(), normalizer)
+			assert.NoError(t, err)
+			assert.LessOrEqual(t, len(result.Obfuscated)+len(result.Normalized)+result.Metadata.Size, maxOutputBytes)
+			assert.Equal(t, metadata.OutputTruncated, result.Metadata.OutputTruncated)
+		})
+	}
+}
+
+func TestNormalizerNormalizeStatements(t *testing.T) {
+	normalizer := NewNormalizer(WithCollectTables(true), WithCollectCommands(true), WithCollectComments(true))
+
+	input := "BEGIN; /* batch */ UPDATE accounts SET balance = ? WHERE id IN (SELECT id FROM users WHERE x = ';'); COMMIT; ;"
+	statements, err := normalizer.NormalizeStatements(input)
+	assert.NoError(t, err)
+	assert.Len(t, statements, 3)
+
+	assert.Equal(t, "BEGIN", statements[0].NormalizedSQL)
+	assert.Equal(t, []string{"BEGIN"}, statements[0].Metadata.Commands)
+	assert.Equal(t, StatementTCL, statements[0].Metadata.StatementKind)
+
+	assert.Equal(t, "UPDATE accounts SET balance = ? WHERE id IN ( SELECT id FROM users WHERE x = ';' )", statements[1].NormalizedSQL)
+	assert.Equal(t, []string{"UPDATE", "SELECT"}, statements[1].Metadata.Commands)
+	assert.Equal(t, []string{"accounts", "users"}, statements[1].Metadata.Tables)
+	assert.Equal(t, []string{"/* batch */"}, statements[1].Metadata.Comments)
+	assert.Equal(t, "/* batch */ UPDATE accounts SET balance = ? WHERE id IN (SELECT id FROM users WHERE x = ';')", input[statements[1].Start:statements[1].End])
+
+	assert.Equal(t, "COMMIT", statements[2].NormalizedSQL)
+	assert.Equal(t, []string{"COMMIT"}, statements[2].Metadata.Commands)
+
+	statements, err = normalizer.NormalizeStatements("  -- nothing to see\n ; ")
+	assert.NoError(t, err)
+	assert.Empty(t, statements)
+}
+
+func TestNormalizerUnquoteSafeIdentifiers(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  string
+		lexerOpts []lexerOption
+	}{
+		{
+			input:    `SELECT "users"."id" FROM "users"`,
+			expected: `SELECT users.id FROM users`,
+		},
+		{
+			input:    `SELECT "first name", "order", "1st" FROM "my-table"`,
+			expected: `SELECT "first name", "order", "1st" FROM "my-table"`,
+		},
+		{
+			input:    `SELECT "users"."First Name" FROM "users"`,
+			expected: `SELECT "users"."First Name" FROM users`,
+		},
+		{
+			input:     `SELECT "Users"."id" FROM "users"`,
+			expected:  `SELECT "Users"."id" FROM users`,
+			lexerOpts: []lexerOption{WithDBMS(DBMSPostgres)},
+		},
+		{
+			input:     `SELECT "USERS"."ID", "users"."id" FROM DUAL`,
+			expected:  `SELECT USERS.ID, "users"."id" FROM DUAL`,
+			lexerOpts: []lexerOption{WithDBMS(DBMSOracle)},
+		},
+		{
+			input:     `SELECT [Users].[Id] FROM [dbo].[Users]`,
+			expected:  `SELECT Users.Id FROM dbo.Users`,
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+		},
+	}
+
+	normalizer := NewNormalizer(WithKeepIdentifierQuotation(true), WithUnquoteSafeIdentifiers(true), WithCollectTables(true))
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			got, _, err := normalizer.Normalize(test.input, test.lexerOpts...)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, got)
+		})
+	}
+}
+
+func TestNormalizerCollectOffsets(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []OffsetMapping
+		options  []normalizerOption
+	}{
+		{
+			// normalized: "SELECT * FROM users WHERE id = ?"
+			input: "  select *\n  from users /* c */ where id = ?;",
+			expected: []OffsetMapping{
+				{NormalizedStart: 0, NormalizedEnd: 6, OriginalStart: 2, OriginalEnd: 8},
+				{NormalizedStart: 7, NormalizedEnd: 8, OriginalStart: 9, OriginalEnd: 10},
+				{NormalizedStart: 9, NormalizedEnd: 13, OriginalStart: 13, OriginalEnd: 17},
+				{NormalizedStart: 14, NormalizedEnd: 19, OriginalStart: 18, OriginalEnd: 23},
+				{NormalizedStart: 20, NormalizedEnd: 25, OriginalStart: 32, OriginalEnd: 37},
+				{NormalizedStart: 26, NormalizedEnd: 28, OriginalStart: 38, OriginalEnd: 40},
+				{NormalizedStart: 29, NormalizedEnd: 30, OriginalStart: 41, OriginalEnd: 42},
+				{NormalizedStart: 31, NormalizedEnd: 32, OriginalStart: 43, OriginalEnd: 44},
+			},
+			options: []normalizerOption{WithUppercaseKeywords(true)},
+		},
+		{
+			// normalized: "id IN ( ? )"
+			input: "id IN (3, 1, 2)",
+			expected: []OffsetMapping{
+				{NormalizedStart: 0, NormalizedEnd: 2, OriginalStart: 0, OriginalEnd: 2},
+				{NormalizedStart: 3, NormalizedEnd: 5, OriginalStart: 3, OriginalEnd: 5},
+				{NormalizedStart: 6, NormalizedEnd: 7, OriginalStart: 6, OriginalEnd: 7},
+				{NormalizedStart: 7, NormalizedEnd: 9, OriginalStart: 7, OriginalEnd: 14},
+				{NormalizedStart: 10, NormalizedEnd: 11, OriginalStart: 14, OriginalEnd: 15},
+			},
+			options: []normalizerOption{WithCanonicalizeInList(true)},
+		},
+		{
+			// normalized: "( SELECT 1 )"
+			input: "(SELECT 1)",
+			expected: []OffsetMapping{
+				{NormalizedStart: 0, NormalizedEnd: 12, OriginalStart: 0, OriginalEnd: 10},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			normalizer := NewNormalizer(append([]normalizerOption{WithCollectOffsets(true)}, test.options...)...)
+			normalizedSQL, statementMetadata, err := normalizer.Normalize(test.input)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, statementMetadata.Offsets)
+
+			var buf bytes.Buffer
+			streamedMetadata, err := normalizer.NormalizeTo(&buf, test.input)
+			assert.NoError(t, err)
+			assert.Equal(t, normalizedSQL, buf.String())
+			assert.Equal(t, test.expected, streamedMetadata.Offsets)
+		})
+	}
+}
+
+func TestObfuscateAndNormalizeCollectOffsets(t *testing.T) {
+	input := "SELECT * FROM users WHERE name = 'alice' AND age > 30"
+	normalizedSQL, statementMetadata, err := ObfuscateAndNormalize(input, NewObfuscator(), NewNormalizer(WithCollectOffsets(true)))
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE name = ? AND age > ?", normalizedSQL)
+	for _, mapping := range statementMetadata.Offsets {
+		normalized := normalizedSQL[mapping.NormalizedStart:mapping.NormalizedEnd]
+		original := input[mapping.OriginalStart:mapping.OriginalEnd]
+		if normalized == "?" {
+			assert.Contains(t, []string{"'alice'", "30"}, original)
+		} else {
+			assert.Equal(t, original, normalized)
+		}
+	}
+}
+
 func ExampleNormalizer() {
 	normalizer := NewNormalizer(
 		WithCollectComments(true),
@@ -1216,12 +2163,13 @@ func ExampleNormalizer() {
 	fmt.Println(normalizedSQL)
 	fmt.Println(statementMetadata)
 	// Output: SELECT * FROM users WHERE id in ( ? )
-	// &{34 [users] [/* this is a comment */] [SELECT] []}
+	// &{34 [users] [/* this is a comment */] [SELECT] [] [] [] SELECT map[] [] [] [] false false [] []}
 }
 
 func assertStatementMetadataEqual(t *testing.T, expected, actual *StatementMetadata) {
 	assert.Equal(t, expected.Size, actual.Size)
 	assert.Equal(t, expected.Tables, actual.Tables)
+	assert.Equal(t, expected.TempTables, actual.TempTables)
 	assert.Equal(t, expected.Comments, actual.Comments)
 	assert.Equal(t, expected.Commands, actual.Commands)
 	assert.Equal(t, expected.Procedures, actual.Procedures)
@@ -3,6 +3,7 @@ package sqllexer
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -117,6 +118,12 @@ ORDER BY
 
 	var backtickQuotedQuery = "SELECT `orders`.`OrderID`, `customers`.`CustomerName`, `products`.`ProductName`, `order_details`.`Quantity`, `order_details`.`UnitPrice`, (`order_details`.`Quantity` * `order_details`.`UnitPrice`) AS `TotalPrice`, `orders`.`OrderDate`, `orders`.`ShippedDate`, CASE WHEN `orders`.`ShippedDate` IS NULL THEN 'Pending' ELSE 'Shipped' END AS `OrderStatus` FROM `orders` INNER JOIN `customers` ON `orders`.`CustomerID` = `customers`.`CustomerID` INNER JOIN `order_details` ON `orders`.`OrderID` = `order_details`.`OrderID` INNER JOIN `products` ON `order_details`.`ProductID` = `products`.`ProductID` WHERE `orders`.`OrderDate` >= '2024-01-01' AND `orders`.`OrderDate` <= '2024-12-31' AND `customers`.`Region` = 'North America' GROUP BY `orders`.`OrderID`, `customers`.`CustomerName`, `products`.`ProductName`, `order_details`.`Quantity`, `order_details`.`UnitPrice`, `orders`.`OrderDate`, `orders`.`ShippedDate` HAVING SUM(`order_details`.`Quantity`) > 10 ORDER BY `orders`.`OrderDate` DESC;"
 
+	inListValues := make([]string, 10000)
+	for i := range inListValues {
+		inListValues[i] = strconv.Itoa(i)
+	}
+	largeInListQuery := "SELECT * FROM orders WHERE id IN (" + strings.Join(inListValues, ", ") + ")"
+
 	benchmarks := []struct {
 		name  string
 		query string
@@ -128,6 +135,7 @@ ORDER BY
 		{"SuperLarge", fmt.Sprintf(superLargeQuery, 1)},
 		{"BracketQuoted", bracketQuotedQuery},
 		{"BacktickQuoted", backtickQuotedQuery},
+		{"LargeInList", largeInListQuery},
 	}
 	obfuscator := NewObfuscator(
 		WithReplaceDigits(true),
@@ -78,6 +78,8 @@ func TestObfuscationAndNormalization(t *testing.T) {
 				Procedures: []string{},
 				Size:       32,
 			},
+			// the digits of the identifiers were obfuscated
+			lexerOpts: []lexerOption{WithQuestionMarkIdentifiers(true)},
 		},
 		{
 			input:    "/* this is a comment */ SELECT * FROM users WHERE id = '2'",
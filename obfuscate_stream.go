@@ -0,0 +1,104 @@
+package sqllexer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"unicode"
+)
+
+const (
+	// streamChunkSize is the size of the window of the input ObfuscateStream lexes at once
+	streamChunkSize = 64 * 1024
+	// streamLookahead is the number of bytes the lexer may read past the end of a token to decide where it ends,
+	// the tokens ending closer than that to the end of the window are lexed again with the next chunk
+	streamLookahead = 8
+)
+
+// ObfuscateStream reads the SQL from r and writes it obfuscated to w, as Obfuscate does, in bounded memory.
+// The input is lexed in chunks through a sliding window, so huge statements, e.g. bulk INSERTs or giant IN lists,
+// are obfuscated without holding them nor their tokens in memory. The window only grows to hold a token
+// longer than it, e.g. a huge string literal.
+func (o *Obfuscator) ObfuscateStream(r io.Reader, w io.Writer, lexerOpts ...lexerOption) error {
+	return o.obfuscateChunks(r, w, streamChunkSize, lexerOpts...)
+}
+
+// obfuscateChunks obfuscates the SQL of r to w, lexing windows of chunkSize bytes
+func (o *Obfuscator) obfuscateChunks(r io.Reader, w io.Writer, chunkSize int, lexerOpts ...lexerOption) error {
+	lexer := GetLexer("", lexerOpts...)
+	defer PutLexer(lexer)
+	window := make([]byte, 0, chunkSize)
+	var output, pendingSpaces []byte
+	started := false
+
+	// the last value token is carried from a window to the next, as it decides how some tokens are lexed and obfuscated
+	var last LastValueToken
+	hasLast := false
+
+	for {
+		n, err := io.ReadFull(r, window[len(window):cap(window)])
+		window = window[:len(window)+n]
+		eof := errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+		if err != nil && !eof {
+			return err
+		}
+
+		src := string(window)
+		lexer.reset(src, lexerOpts...)
+		lexer.config.CompoundKeywords = false
+		var lastValueToken *LastValueToken
+		if hasLast {
+			lexer.token.lastValueToken = last
+			lexer.afterOperand = isOperandEnd(last.Type, last.Value)
+			lastValueToken = &lexer.token.lastValueToken
+		}
+
+		// the tokens which may continue in the next chunk are left in the window
+		consumed := 0
+		output = output[:0]
+		for {
+			token := lexer.Scan()
+			if token.Type == EOF {
+				// a NUL byte is read as the end of the input, the rest of the input is ignored
+				eof = eof || token.Start < len(src)
+				break
+			}
+			if !eof && token.End+streamLookahead > len(src) {
+				break
+			}
+			o.ObfuscateTokenValue(token, lastValueToken, lexerOpts...)
+			output = append(output, token.Value...)
+			if isValueToken(token) {
+				lastValueToken = token.getLastValueToken()
+			}
+			consumed = token.End
+		}
+		if lastValueToken != nil {
+			last, hasLast = *lastValueToken, true
+		}
+
+		// trim the output as Obfuscate does, holding back the trailing spaces until more SQL follows
+		if !started {
+			output = bytes.TrimLeftFunc(output, unicode.IsSpace)
+			started = len(output) > 0
+		}
+		if trimmed := bytes.TrimRightFunc(output, unicode.IsSpace); len(trimmed) > 0 {
+			if _, err := w.Write(append(pendingSpaces, trimmed...)); err != nil {
+				return err
+			}
+			pendingSpaces = append(pendingSpaces[:0], output[len(trimmed):]...)
+		} else {
+			pendingSpaces = append(pendingSpaces, output...)
+		}
+
+		if eof {
+			return nil
+		}
+		if consumed == 0 && len(window) == cap(window) {
+			// a single token fills the window, which grows to hold it
+			window = append(make([]byte, 0, 2*cap(window)), window...)
+			continue
+		}
+		window = window[:copy(window, window[consumed:])]
+	}
+}
@@ -0,0 +1,94 @@
+package sqllexer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscateStream(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		lexerOpts []lexerOption
+		expected  string
+	}{
+		{
+			name:     "bulk insert",
+			input:    "  INSERT INTO t (a, b) VALUES " + strings.Repeat("(1, 'x'), ", 10000) + "(2, 'y');\n",
+			expected: "INSERT INTO t (a, b) VALUES " + strings.Repeat("(?, ?), ", 10000) + "(?, ?);",
+		},
+		{
+			name:     "huge string literal",
+			input:    "SELECT '" + strings.Repeat("secret ", 20000) + "' FROM t",
+			expected: "SELECT ? FROM t",
+		},
+		{
+			name:      "dollar quoted string",
+			input:     "SELECT $tag$" + strings.Repeat("$ ", 50000) + "$tag$, data->'key' FROM t",
+			lexerOpts: []lexerOption{WithDBMS(DBMSPostgres)},
+			expected:  "SELECT ?, data->? FROM t",
+		},
+		{
+			name:     "NUL byte",
+			input:    "SELECT 1" + strings.Repeat(" ", 100000) + "\x00 'ignored'",
+			expected: "SELECT ?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			err := NewObfuscator().ObfuscateStream(strings.NewReader(tt.input), &output, tt.lexerOpts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output.String())
+		})
+	}
+
+	t.Run("read error", func(t *testing.T) {
+		err := NewObfuscator().ObfuscateStream(iotest.ErrReader(errors.New("read failed")), &bytes.Buffer{})
+		assert.EqualError(t, err, "read failed")
+	})
+}
+
+// TestObfuscateStreamMatchesObfuscate checks that the tokens cut by the end of a chunk are obfuscated as a whole
+func TestObfuscateStreamMatchesObfuscate(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	fragments := append(hostileFragments, "'a\\'b'", "$tag$x$tag$", "ESCAPE", " LIKE ", "->", "#>>", "123.45e6", "0x1F",
+		"true", "NULL", "users2", "\t\r\n")
+	dbmsTypes := []DBMSType{"", DBMSPostgres, DBMSMySQL, DBMSSQLServer, DBMSOracle, DBMSSnowflake}
+	obfuscator := NewObfuscator(WithReplaceDigits(true), WithKeepJsonPath(true), WithDollarQuotedFunc(true))
+	for i := 0; i < 3000; i++ {
+		var builder strings.Builder
+		for n := random.Intn(30); n >= 0; n-- {
+			builder.WriteString(fragments[random.Intn(len(fragments))])
+		}
+		input := builder.String()
+		dbms := dbmsTypes[random.Intn(len(dbmsTypes))]
+		chunkSize := 1 + random.Intn(32)
+
+		var output bytes.Buffer
+		reader := iotest.OneByteReader(strings.NewReader(input))
+		err := obfuscator.obfuscateChunks(reader, &output, chunkSize, WithDBMS(dbms))
+		assert.NoError(t, err)
+		if !assert.Equal(t, obfuscator.Obfuscate(input, WithDBMS(dbms)), output.String(), "%q for %s in chunks of %d", input, dbms, chunkSize) {
+			return
+		}
+	}
+}
+
+func ExampleObfuscator_ObfuscateStream() {
+	var output bytes.Buffer
+	input := strings.NewReader("INSERT INTO t VALUES (1, 'a'), (2, 'b')")
+	if err := NewObfuscator().ObfuscateStream(input, &output); err != nil {
+		panic(err)
+	}
+	fmt.Println(output.String())
+	// Output: INSERT INTO t VALUES (?, ?), (?, ?)
+}
@@ -1,7 +1,10 @@
 package sqllexer
 
 import (
+	"bytes"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 type obfuscatorConfig struct {
@@ -82,29 +85,86 @@ const (
 // Obfuscate takes an input SQL string and returns an obfuscated SQL string.
 // The obfuscator replaces all literal values with a single placeholder
 func (o *Obfuscator) Obfuscate(input string, lexerOpts ...lexerOption) string {
-	var obfuscatedSQL strings.Builder
-	obfuscatedSQL.Grow(len(input))
+	// the buffer is pooled, only the obfuscated SQL is allocated
+	buffer := getObfuscatorBuffer()
+	obfuscatedSQL := o.ObfuscateAppend((*buffer)[:0], input, lexerOpts...)
 
-	lexer := New(
-		input,
-		lexerOpts...,
-	)
+	result := string(obfuscatedSQL)
+	putObfuscatorBuffer(buffer, obfuscatedSQL)
+	return result
+}
+
+// ObfuscateAppend obfuscates the input SQL as Obfuscate does and appends the obfuscated SQL to dst.
+// A caller reusing dst across calls, e.g. ObfuscateAppend(dst[:0], query), does not allocate.
+func (o *Obfuscator) ObfuscateAppend(dst []byte, input string, lexerOpts ...lexerOption) []byte {
+	lexer := GetLexer(input, lexerOpts...)
+	defer PutLexer(lexer)
+	// the whitespace is copied from the input between the tokens, instead of being scanned as SPACE tokens
+	lexer.config.SkipWhitespace = true
+	lexer.config.CompoundKeywords = false
+	base := lexer.baseOffset()
+	start, end := len(dst), 0
+	timer, timed := newPhaseTimer(lexer.config.PhaseTimings)
 
 	var lastValueToken *LastValueToken
 
 	for {
 		token := lexer.Scan()
+		if timed {
+			timer.end(PhaseLex)
+		}
 		if token.Type == EOF {
 			break
 		}
+		dst = append(dst, input[end:token.Start-base]...)
+		end = token.End - base
 		o.ObfuscateTokenValue(token, lastValueToken, lexerOpts...)
-		obfuscatedSQL.WriteString(token.Value)
+		dst = append(dst, token.Value...)
 		if isValueToken(token) {
 			lastValueToken = token.getLastValueToken()
 		}
+		if timed {
+			timer.end(PhaseObfuscate)
+		}
+	}
+	if timed {
+		timer.report(OperationObfuscate)
+	}
+
+	return append(dst[:start], bytes.TrimSpace(dst[start:])...)
+}
+
+var obfuscatorBufferPool = sync.Pool{
+	New: func() any {
+		return new([]byte)
+	},
+}
+
+// obfuscatedLength is a moving average of the lengths of the obfuscated SQL, the capacity of the buffers
+// taken from the pool, so a new buffer is allocated once instead of growing while the SQL is appended
+var obfuscatedLength atomic.Int64
+
+// getObfuscatorBuffer returns a pooled buffer, with at least the average length of the obfuscated SQL as capacity
+func getObfuscatorBuffer() *[]byte {
+	buffer := obfuscatorBufferPool.Get().(*[]byte)
+	if length := int(obfuscatedLength.Load()); cap(*buffer) < length {
+		*buffer = make([]byte, 0, length)
 	}
+	return buffer
+}
 
-	return strings.TrimSpace(obfuscatedSQL.String())
+// putObfuscatorBuffer records the length of the obfuscated SQL and returns its buffer to the pool,
+// unless it is too large to be retained
+func putObfuscatorBuffer(buffer *[]byte, obfuscatedSQL []byte) {
+	// the average weighs the last length by 1/8, concurrent updates may be lost as it is only an estimate
+	// the lengths are capped to the largest pooled buffer, so huge queries do not size every later buffer
+	average := obfuscatedLength.Load()
+	length := int64(min(len(obfuscatedSQL), maxPooledBufferSize))
+	obfuscatedLength.Store(average + (length-average)/8)
+	if cap(obfuscatedSQL) <= maxPooledBufferSize {
+		*buffer = obfuscatedSQL
+		obfuscatorBufferPool.Put(buffer)
+	}
 }
 
 func (o *Obfuscator) ObfuscateTokenValue(token *Token, lastValueToken *LastValueToken, lexerOpts ...lexerOption) {
@@ -148,7 +208,7 @@ func (o *Obfuscator) ObfuscateTokenValue(token *Token, lastValueToken *LastValue
 		if o.config.ReplaceNull {
 			token.Value = StringPlaceholder
 		}
-	case IDENT, QUOTED_IDENT:
+	case IDENT, QUOTED_IDENT, INCOMPLETE_QUOTED_IDENT:
 		if o.config.ReplaceDigits && len(token.digits) > 0 {
 			token.Value = replaceDigits(token, NumberPlaceholder)
 		}
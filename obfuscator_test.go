@@ -2,6 +2,7 @@ package sqllexer
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -45,11 +46,20 @@ func TestObfuscator(t *testing.T) {
 			expected:      "SELECT * FROM users where id = ?",
 			replaceDigits: false,
 		},
+		{
+			input:    "SELECT * FROM users where name = 'it''s here'",
+			expected: "SELECT * FROM users where name = ?",
+		},
 		{
 			input:         "SELECT * FROM \"users table\" where id = 1",
 			expected:      "SELECT * FROM \"users table\" where id = ?",
 			replaceDigits: true,
 		},
+		{
+			input:         "SELECT * FROM \"users table1",
+			expected:      "SELECT * FROM \"users table?",
+			replaceDigits: true,
+		},
 		{
 			input:         "SELECT * FROM users1 where id = ?",
 			expected:      "SELECT * FROM users1 where id = ?",
@@ -322,6 +332,11 @@ func TestObfuscator(t *testing.T) {
 			input:    `SELECT * FROM users where '{"a": 1, "b": 2}'::jsonb - 'a'`,
 			expected: `SELECT * FROM users where ?::jsonb - ?`,
 		},
+		{
+			// the sign following an operand is an operator
+			input:    "SELECT a-1, (b)+2, c - -3 FROM users WHERE id=-4 AND d IN (-5, +6)",
+			expected: "SELECT a-?, (b)+?, c - ? FROM users WHERE id=? AND d IN (?, ?)",
+		},
 		{
 			input: `
 			-- Testing explicit table SQL expression
@@ -570,6 +585,62 @@ func TestObfuscator(t *testing.T) {
 	}
 }
 
+func TestObfuscatorObfuscateAppend(t *testing.T) {
+	obfuscator := NewObfuscator(WithReplaceDigits(true))
+	inputs := []string{
+		"  SELECT * FROM users2 WHERE id = 1;  ",
+		"UPDATE t SET a = 'x' WHERE b IN (1, 2, 3)",
+		"",
+		"   ",
+	}
+
+	var dst []byte
+	for _, input := range inputs {
+		dst = obfuscator.ObfuscateAppend(dst[:0], input)
+		assert.Equal(t, obfuscator.Obfuscate(input), string(dst))
+	}
+
+	// the obfuscated SQL is appended to the bytes of dst, which are kept
+	assert.Equal(t, "/* q */ SELECT ?", string(obfuscator.ObfuscateAppend([]byte("/* q */ "), " SELECT 1 ")))
+
+	// reusing dst, obfuscating does not allocate, unless a token value is rewritten, e.g. its digits replaced
+	allocs := testing.AllocsPerRun(100, func() {
+		dst = obfuscator.ObfuscateAppend(dst[:0], inputs[1])
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestObfuscatorBufferSizing(t *testing.T) {
+	obfuscator := NewObfuscator()
+	query := "SELECT * FROM users WHERE name = 'alice' AND " + strings.Repeat("id = 1 AND ", 20) + "age > 30"
+	expected := obfuscator.Obfuscate(query)
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, expected, obfuscator.Obfuscate(query))
+	}
+
+	// the buffers are sized by the average length of the obfuscated SQL, which converges to the one of the query
+	average := int(obfuscatedLength.Load())
+	assert.InDelta(t, len(expected), average, float64(len(expected))/10)
+	buffer := getObfuscatorBuffer()
+	assert.GreaterOrEqual(t, cap(*buffer), average)
+	putObfuscatorBuffer(buffer, (*buffer)[:0])
+}
+
+func TestObfuscatorBufferSizingHugeQueries(t *testing.T) {
+	defer obfuscatedLength.Store(obfuscatedLength.Load())
+	obfuscator := NewObfuscator()
+	huge := "SELECT * FROM users WHERE " + strings.Repeat("id = 1 AND ", 20000) + "age > 30"
+	for i := 0; i < 20; i++ {
+		obfuscator.Obfuscate(huge)
+	}
+
+	// the huge queries do not make the later buffers larger than the pooled ones
+	assert.LessOrEqual(t, obfuscatedLength.Load(), int64(maxPooledBufferSize))
+	buffer := getObfuscatorBuffer()
+	assert.LessOrEqual(t, cap(*buffer), maxPooledBufferSize)
+	putObfuscatorBuffer(buffer, (*buffer)[:0])
+}
+
 func ExampleObfuscator() {
 	obfuscator := NewObfuscator()
 	obfuscated := obfuscator.Obfuscate("SELECT * FROM users WHERE id = 1")
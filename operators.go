@@ -0,0 +1,71 @@
+package sqllexer
+
+import "strings"
+
+// operatorSpec is an operator of several characters, and the feature of the dialects which have it
+type operatorSpec struct {
+	value   string
+	feature dialectFeature // 0 if every dialect has the operator
+}
+
+// operators are the operators of several characters. The longest operator of the dialect at the cursor
+// is scanned as a single token, e.g. >= in >=-1, and any other operator character as a token of its own.
+var operators = []operatorSpec{
+	{"<>", 0},
+	{"!=", 0},
+	{"<=", 0},
+	{">=", 0},
+	{"==", 0},
+	{"||", 0},
+	{"&&", 0},
+	{"<<", 0},
+	{">>", 0},
+	{"::", 0},
+	{":=", 0},
+	{"=>", 0},
+	{"^=", 0},
+	{"<=>", dialectNullSafeEquals},
+	{"~*", dialectPatternOperators},
+	{"!~", dialectPatternOperators},
+	{"!~*", dialectPatternOperators},
+	{"~~", dialectPatternOperators},
+	{"~~*", dialectPatternOperators},
+	{"!~~", dialectPatternOperators},
+	{"!~~*", dialectPatternOperators},
+	{"+=", dialectAssignmentOperators},
+	{"-=", dialectAssignmentOperators},
+	{"*=", dialectAssignmentOperators},
+	{"/=", dialectAssignmentOperators},
+	{"%=", dialectAssignmentOperators},
+	{"&=", dialectAssignmentOperators},
+	{"|=", dialectAssignmentOperators},
+	{"!<", dialectNegatedComparisons},
+	{"!>", dialectNegatedComparisons},
+}
+
+// operatorsByFirstChar are the operators starting with each character, the longest first
+var operatorsByFirstChar = func() (index [256][]operatorSpec) {
+	for _, op := range operators {
+		index[op.value[0]] = append(index[op.value[0]], op)
+	}
+	for _, ops := range index {
+		// insertion sort, the lists are short
+		for i := 1; i < len(ops); i++ {
+			for j := i; j > 0 && len(ops[j].value) > len(ops[j-1].value); j-- {
+				ops[j], ops[j-1] = ops[j-1], ops[j]
+			}
+		}
+	}
+	return index
+}()
+
+// operatorLength returns the length of the longest operator of the dialect at start, 1 if there is none
+func (s *Lexer) operatorLength(start int) int {
+	rest := s.src[start:]
+	for _, op := range operatorsByFirstChar[rest[0]] {
+		if (op.feature == 0 || s.dialect&op.feature != 0) && strings.HasPrefix(rest, op.value) {
+			return len(op.value)
+		}
+	}
+	return 1
+}
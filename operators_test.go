@@ -0,0 +1,61 @@
+package sqllexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLexerOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		dbms     DBMSType
+		expected []TokenSpec
+	}{
+		{"a>=-1", "", []TokenSpec{{IDENT, "a"}, {OPERATOR, ">="}, {NUMBER, "-1"}}},
+		{"f(a=>1)", DBMSPostgres, []TokenSpec{{FUNCTION, "f"}, {PUNCTUATION, "("}, {IDENT, "a"}, {OPERATOR, "=>"}, {NUMBER, "1"}, {PUNCTUATION, ")"}}},
+		{"1!=~2", "", []TokenSpec{{NUMBER, "1"}, {OPERATOR, "!="}, {OPERATOR, "~"}, {NUMBER, "2"}}},
+		{"a=?", "", []TokenSpec{{IDENT, "a"}, {OPERATOR, "="}, {OPERATOR, "?"}}},
+		{"a<>b||c", "", []TokenSpec{{IDENT, "a"}, {OPERATOR, "<>"}, {IDENT, "b"}, {OPERATOR, "||"}, {IDENT, "c"}}},
+		{"a<=>b", DBMSMySQL, []TokenSpec{{IDENT, "a"}, {OPERATOR, "<=>"}, {IDENT, "b"}}},
+		{"a<=>b", DBMSPostgres, []TokenSpec{{IDENT, "a"}, {OPERATOR, "<="}, {OPERATOR, ">"}, {IDENT, "b"}}},
+		{"a<=>b", "", []TokenSpec{{IDENT, "a"}, {OPERATOR, "<=>"}, {IDENT, "b"}}},
+		{"'a'!~*'b'", DBMSPostgres, []TokenSpec{{STRING, "'a'"}, {OPERATOR, "!~*"}, {STRING, "'b'"}}},
+		{"'a'!~~'b'", DBMSPostgres, []TokenSpec{{STRING, "'a'"}, {OPERATOR, "!~~"}, {STRING, "'b'"}}},
+		{"'a'!~'b'", DBMSMySQL, []TokenSpec{{STRING, "'a'"}, {OPERATOR, "!"}, {OPERATOR, "~"}, {STRING, "'b'"}}},
+		{"a-=1", DBMSSQLServer, []TokenSpec{{IDENT, "a"}, {OPERATOR, "-="}, {NUMBER, "1"}}},
+		{"a-=1", DBMSPostgres, []TokenSpec{{IDENT, "a"}, {OPERATOR, "-"}, {OPERATOR, "="}, {NUMBER, "1"}}},
+		{"1!<2", DBMSSQLServer, []TokenSpec{{NUMBER, "1"}, {OPERATOR, "!<"}, {NUMBER, "2"}}},
+		{"a::int", DBMSPostgres, []TokenSpec{{IDENT, "a"}, {OPERATOR, "::"}, {IDENT, "int"}}},
+		{"a->>'b'", DBMSPostgres, []TokenSpec{{IDENT, "a"}, {JSON_OP, "->>"}, {STRING, "'b'"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			lexer := New(tt.input, WithDBMS(tt.dbms))
+			var tokens []TokenSpec
+			for token := lexer.Scan(); token.Type != EOF; token = lexer.Scan() {
+				tokens = append(tokens, TokenSpec{token.Type, token.Value})
+			}
+			assert.Equal(t, tt.expected, tokens)
+		})
+	}
+}
+
+func TestOperatorsByFirstChar(t *testing.T) {
+	// every operator is indexed, the longest first, and made of operator characters
+	count := 0
+	for ch, ops := range operatorsByFirstChar {
+		for i, op := range ops {
+			assert.Equal(t, byte(ch), op.value[0])
+			if i > 0 {
+				assert.LessOrEqual(t, len(op.value), len(ops[i-1].value), op.value)
+			}
+			for _, c := range op.value {
+				assert.True(t, isOperator(c), op.value)
+			}
+			count++
+		}
+	}
+	assert.Equal(t, len(operators), count)
+}
@@ -0,0 +1,39 @@
+package sqllexer
+
+import (
+	"fmt"
+	"math"
+)
+
+// PackedToken is a token without its value, packed in 12 bytes instead of the more than 100 of a Token,
+// for the callers keeping the tokens of large statements. Its value is the input between Start and End.
+type PackedToken struct {
+	Start uint32 // byte offset of the token in the input
+	End   uint32 // byte offset following the token in the input
+	typ   uint8
+}
+
+// Type returns the type of the token
+func (t PackedToken) Type() TokenType {
+	return TokenType(t.typ)
+}
+
+// Token returns the token with its value, read from the input it was scanned from
+func (t PackedToken) Token(input string) Token {
+	return Token{Type: t.Type(), Value: input[t.Start:t.End], Start: int(t.Start), End: int(t.End)}
+}
+
+// ScanPacked scans the remaining tokens of the input, but EOF, and appends them packed to dst.
+// The input must be smaller than 4GiB for the offsets of its tokens to fit in a PackedToken.
+func (s *Lexer) ScanPacked(dst []PackedToken) ([]PackedToken, error) {
+	if len(s.src) > math.MaxUint32 {
+		return dst, fmt.Errorf("input of %d bytes is too large for packed tokens", len(s.src))
+	}
+	for {
+		token := s.Scan()
+		if token.Type == EOF {
+			return dst, nil
+		}
+		dst = append(dst, PackedToken{Start: uint32(token.Start), End: uint32(token.End), typ: uint8(token.Type)})
+	}
+}
@@ -0,0 +1,57 @@
+package sqllexer
+
+import (
+	"fmt"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanPacked(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		lexerOpts []lexerOption
+	}{
+		{name: "select", input: "SELECT a, 'b' FROM t WHERE c = 1 -- comment"},
+		{name: "postgres", input: "SELECT $1, data->>'k' FROM t /* c */ WHERE x::int = $tag$y$tag$", lexerOpts: []lexerOption{WithDBMS(DBMSPostgres)}},
+		{name: "non-ASCII", input: "SELECT 'é', \"ü\" FROM t"},
+		{name: "empty", input: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var expected []Token
+			lexer := New(tt.input, tt.lexerOpts...)
+			for token := lexer.Scan(); token.Type != EOF; token = lexer.Scan() {
+				expected = append(expected, Token{Type: token.Type, Value: token.Value, Start: token.Start, End: token.End})
+			}
+
+			packed, err := New(tt.input, tt.lexerOpts...).ScanPacked(nil)
+			assert.NoError(t, err)
+			var got []Token
+			for _, token := range packed {
+				got = append(got, token.Token(tt.input))
+			}
+			assert.Equal(t, expected, got)
+		})
+	}
+
+	assert.Equal(t, uintptr(12), unsafe.Sizeof(PackedToken{}))
+}
+
+func ExampleLexer_ScanPacked() {
+	input := "SELECT id FROM users"
+	tokens, _ := New(input).ScanPacked(nil)
+	for _, token := range tokens {
+		if token.Type() != SPACE {
+			fmt.Println(token.Start, token.End, token.Token(input).Value)
+		}
+	}
+	// Output:
+	// 0 6 SELECT
+	// 7 9 id
+	// 10 14 FROM
+	// 15 20 users
+}
@@ -0,0 +1,223 @@
+package sqllexer
+
+import "strconv"
+
+// ParameterStyle is the placeholder syntax of a bind parameter
+type ParameterStyle string
+
+const (
+	ParameterQuestionMark ParameterStyle = "?"     // ?, or ?N in SQLite
+	ParameterDollar       ParameterStyle = "$N"    // PostgreSQL $1
+	ParameterColon        ParameterStyle = ":name" // :name or :N, e.g. Oracle
+	ParameterAt           ParameterStyle = "@name" // e.g. SQL Server @name
+	ParameterFormat       ParameterStyle = "%s"    // Python DB-API %s or %(name)s
+)
+
+// Parameter is a bind parameter placeholder of a query
+type Parameter struct {
+	Style ParameterStyle `json:"style"`
+	Text  string         `json:"text"`           // e.g. ?, $1, :name, @name, %(name)s
+	Name  string         `json:"name,omitempty"` // name of a named parameter, e.g. name for :name
+	// Ordinal is the number of a numbered parameter, e.g. 2 for $2 or :2, the rank among the anonymous
+	// parameters of the query, starting at 1, for ? and %s, and 0 for named parameters
+	Ordinal int    `json:"ordinal,omitempty"`
+	Start   int    `json:"start"`            // byte offset of the placeholder in the query
+	End     int    `json:"end"`              // byte offset following the placeholder in the query
+	Clause  string `json:"clause,omitempty"` // clause the parameter belongs to, e.g. SELECT, WHERE, VALUES, SET or LIMIT
+}
+
+// parameterClauses are the words starting a clause, the first word of multi-word clauses being mapped to the clause
+var parameterClauses = map[string]string{
+	"SELECT":    "SELECT",
+	"FROM":      "FROM",
+	"WHERE":     "WHERE",
+	"ON":        "ON",
+	"USING":     "USING",
+	"GROUP":     "GROUP BY",
+	"HAVING":    "HAVING",
+	"ORDER":     "ORDER BY",
+	"LIMIT":     "LIMIT",
+	"OFFSET":    "OFFSET",
+	"FETCH":     "FETCH",
+	"TOP":       "TOP",
+	"INSERT":    "INSERT",
+	"VALUES":    "VALUES",
+	"UPDATE":    "UPDATE",
+	"SET":       "SET",
+	"DELETE":    "DELETE",
+	"RETURNING": "RETURNING",
+	"CALL":      "CALL",
+	"EXEC":      "EXEC",
+	"EXECUTE":   "EXECUTE",
+}
+
+// ExtractParameters returns the bind parameter placeholders of the query, in order, with the clause they belong to.
+// Placeholders in strings, quoted identifiers and comments are ignored, and so are system variables such as @@version.
+// The ? operator of PostgreSQL JSON is reported as a placeholder.
+func ExtractParameters(query string, lexerOpts ...lexerOption) []Parameter {
+	var parameters []Parameter
+	scope := &parameterScope{}
+	anonymous := 0
+	lexer := New(query, lexerOpts...)
+	var pending []Token // tokens of a placeholder split by the lexer, e.g. : and name
+	flush := func() {
+		if parameter, ok := newParameter(query, pending, scope.clause, &anonymous); ok {
+			parameters = append(parameters, parameter)
+		} else {
+			// e.g. a modulo operator followed by parentheses
+			for i := range pending[1:] {
+				scope.update(&pending[i+1])
+			}
+		}
+		pending = pending[:0]
+	}
+	for {
+		token := lexer.Scan()
+		if token.Type == EOF {
+			break
+		}
+		if len(pending) > 0 {
+			if token.Start == pending[len(pending)-1].End && continuesPlaceholder(pending, token) {
+				pending = append(pending, *token)
+				continue
+			}
+			flush()
+		}
+		switch {
+		case token.Type == POSITIONAL_PARAMETER, token.Type == BIND_PARAMETER, token.Type == PLACEHOLDER:
+			pending = append(pending, *token)
+			flush()
+		case token.Type == OPERATOR && (token.Value == "?" || token.Value == ":" || token.Value == "%"):
+			pending = append(pending, *token)
+		default:
+			scope.update(token)
+		}
+	}
+	if len(pending) > 0 {
+		flush()
+	}
+	return parameters
+}
+
+// parameterScope tracks the clause of the tokens, parentheses restoring the clause they interrupted
+type parameterScope struct {
+	clause  string
+	clauses []string // clauses of the enclosing parentheses
+}
+
+func (s *parameterScope) update(token *Token) {
+	switch token.Type {
+	case PUNCTUATION:
+		switch token.Value {
+		case "(":
+			s.clauses = append(s.clauses, s.clause)
+		case ")":
+			if len(s.clauses) > 0 {
+				s.clause = s.clauses[len(s.clauses)-1]
+				s.clauses = s.clauses[:len(s.clauses)-1]
+			}
+		}
+	case COMMAND, KEYWORD, IDENT:
+		if name, ok := parameterClauses[ToUpperASCII(token.Value)]; ok {
+			s.clause = name
+		}
+	}
+}
+
+// continuesPlaceholder returns true if the token, adjacent to the pending tokens, belongs to their placeholder,
+// e.g. the number of ?1, the name of :name, or the (name)s of %(name)s
+func continuesPlaceholder(pending []Token, token *Token) bool {
+	first := pending[0].Value
+	switch len(pending) {
+	case 1:
+		switch first {
+		case "?":
+			return token.Type == NUMBER
+		case ":":
+			return token.Type == IDENT || token.Type == NUMBER
+		case "%":
+			return (token.Type == IDENT && token.Value == "s") || (token.Type == PUNCTUATION && token.Value == "(")
+		}
+	case 2:
+		return first == "%" && pending[1].Value == "(" && token.Type == IDENT
+	case 3:
+		return first == "%" && token.Type == PUNCTUATION && token.Value == ")"
+	case 4:
+		return first == "%" && token.Type == IDENT && token.Value == "s"
+	}
+	return false
+}
+
+// Parameter returns the name of a BIND_PARAMETER token, e.g. user_id for :user_id or @user_id,
+// or its number if it is numbered, e.g. 2 for :2, and the number of a POSITIONAL_PARAMETER token, e.g. 3 for $3,
+// or of a numbered PLACEHOLDER token, e.g. 1 for ?1.
+// It returns "" and 0 for the other tokens, and for the tokens lexed WithOffsetsOnly.
+func (t *Token) Parameter() (name string, ordinal int) {
+	if len(t.Value) < 2 {
+		return "", 0
+	}
+	switch {
+	case t.Type == POSITIONAL_PARAMETER, t.Type == PLACEHOLDER:
+		ordinal, _ = strconv.Atoi(t.Value[1:])
+		return "", ordinal
+	case t.Type == BIND_PARAMETER && t.Value[0] == '@':
+		// the @ parameters are named, even @1
+		return t.Value[1:], 0
+	case t.Type == BIND_PARAMETER:
+		if ordinal, err := strconv.Atoi(t.Value[1:]); err == nil {
+			return "", ordinal
+		}
+		return t.Value[1:], 0
+	}
+	return "", 0
+}
+
+// newParameter returns the parameter made of the tokens, or false if they are not a complete placeholder
+func newParameter(query string, tokens []Token, clause string, anonymous *int) (Parameter, bool) {
+	first, last := tokens[0], tokens[len(tokens)-1]
+	parameter := Parameter{Text: query[first.Start:last.End], Start: first.Start, End: last.End, Clause: clause}
+	switch {
+	case first.Type == POSITIONAL_PARAMETER:
+		parameter.Style = ParameterDollar
+		_, parameter.Ordinal = first.Parameter()
+	case first.Type == BIND_PARAMETER && first.Value[0] == '@':
+		parameter.Style = ParameterAt
+		parameter.Name, _ = first.Parameter()
+	case first.Type == BIND_PARAMETER:
+		parameter.Style = ParameterColon
+		parameter.Name, parameter.Ordinal = first.Parameter()
+	case first.Value == ":" && len(tokens) == 2:
+		parameter.Style = ParameterColon
+		name := parameter.Text[1:]
+		if ordinal, err := strconv.Atoi(name); err == nil {
+			parameter.Ordinal = ordinal
+		} else {
+			parameter.Name = name
+		}
+	case first.Type == PLACEHOLDER:
+		parameter.Style = ParameterQuestionMark
+		if _, parameter.Ordinal = first.Parameter(); parameter.Ordinal == 0 {
+			*anonymous++
+			parameter.Ordinal = *anonymous
+		}
+	case first.Value == "?":
+		parameter.Style = ParameterQuestionMark
+		if len(tokens) == 2 {
+			parameter.Ordinal, _ = strconv.Atoi(tokens[1].Value)
+		} else {
+			*anonymous++
+			parameter.Ordinal = *anonymous
+		}
+	case first.Value == "%" && len(tokens) == 2 && last.Value == "s":
+		parameter.Style = ParameterFormat
+		*anonymous++
+		parameter.Ordinal = *anonymous
+	case first.Value == "%" && len(tokens) == 5:
+		parameter.Style = ParameterFormat
+		parameter.Name = tokens[2].Value
+	default:
+		// e.g. a modulo operator or a lone colon
+		return parameter, false
+	}
+	return parameter, true
+}
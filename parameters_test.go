@@ -0,0 +1,150 @@
+package sqllexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractParameters(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		lexerOpts []lexerOption
+		expected  []Parameter
+	}{
+		{
+			name:  "no parameters",
+			input: "SELECT a % b, '?', c::int FROM t -- ?",
+		},
+		{
+			name:  "question marks",
+			input: "SELECT * FROM t WHERE a = ? AND b IN (?, ?) LIMIT ?",
+			expected: []Parameter{
+				{Style: ParameterQuestionMark, Text: "?", Ordinal: 1, Start: 26, End: 27, Clause: "WHERE"},
+				{Style: ParameterQuestionMark, Text: "?", Ordinal: 2, Start: 38, End: 39, Clause: "WHERE"},
+				{Style: ParameterQuestionMark, Text: "?", Ordinal: 3, Start: 41, End: 42, Clause: "WHERE"},
+				{Style: ParameterQuestionMark, Text: "?", Ordinal: 4, Start: 50, End: 51, Clause: "LIMIT"},
+			},
+		},
+		{
+			name:  "numbered question marks",
+			input: "SELECT * FROM t WHERE a = ?2 OR b = ?1",
+			expected: []Parameter{
+				{Style: ParameterQuestionMark, Text: "?2", Ordinal: 2, Start: 26, End: 28, Clause: "WHERE"},
+				{Style: ParameterQuestionMark, Text: "?1", Ordinal: 1, Start: 36, End: 38, Clause: "WHERE"},
+			},
+		},
+		{
+			name:      "placeholders",
+			input:     "SELECT * FROM t WHERE a = ?2 OR b = ? OR c = ?",
+			lexerOpts: []lexerOption{WithQuestionMarkPlaceholders(true)},
+			expected: []Parameter{
+				{Style: ParameterQuestionMark, Text: "?2", Ordinal: 2, Start: 26, End: 28, Clause: "WHERE"},
+				{Style: ParameterQuestionMark, Text: "?", Ordinal: 1, Start: 36, End: 37, Clause: "WHERE"},
+				{Style: ParameterQuestionMark, Text: "?", Ordinal: 2, Start: 45, End: 46, Clause: "WHERE"},
+			},
+		},
+		{
+			name:      "dollar",
+			input:     "INSERT INTO t (a, b) VALUES ($1, $2) RETURNING id",
+			lexerOpts: []lexerOption{WithDBMS(DBMSPostgres)},
+			expected: []Parameter{
+				{Style: ParameterDollar, Text: "$1", Ordinal: 1, Start: 29, End: 31, Clause: "VALUES"},
+				{Style: ParameterDollar, Text: "$2", Ordinal: 2, Start: 33, End: 35, Clause: "VALUES"},
+			},
+		},
+		{
+			name:  "colon",
+			input: "UPDATE t SET a = :name, b = :1 WHERE c = :name",
+			expected: []Parameter{
+				{Style: ParameterColon, Text: ":name", Name: "name", Start: 17, End: 22, Clause: "SET"},
+				{Style: ParameterColon, Text: ":1", Ordinal: 1, Start: 28, End: 30, Clause: "SET"},
+				{Style: ParameterColon, Text: ":name", Name: "name", Start: 41, End: 46, Clause: "WHERE"},
+			},
+		},
+		{
+			name:      "oracle colon",
+			input:     "SELECT * FROM t WHERE a = :name AND b = :2",
+			lexerOpts: []lexerOption{WithDBMS(DBMSOracle)},
+			expected: []Parameter{
+				{Style: ParameterColon, Text: ":name", Name: "name", Start: 26, End: 31, Clause: "WHERE"},
+				{Style: ParameterColon, Text: ":2", Ordinal: 2, Start: 40, End: 42, Clause: "WHERE"},
+			},
+		},
+		{
+			name:      "at",
+			input:     "SELECT TOP (@n) * FROM t WHERE a = @id AND b = @@version",
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+			expected: []Parameter{
+				{Style: ParameterAt, Text: "@n", Name: "n", Start: 12, End: 14, Clause: "TOP"},
+				{Style: ParameterAt, Text: "@id", Name: "id", Start: 35, End: 38, Clause: "WHERE"},
+			},
+		},
+		{
+			name:  "format",
+			input: "SELECT a % (b) FROM t WHERE x = %s AND y = %(name)s ORDER BY %s",
+			expected: []Parameter{
+				{Style: ParameterFormat, Text: "%s", Ordinal: 1, Start: 32, End: 34, Clause: "WHERE"},
+				{Style: ParameterFormat, Text: "%(name)s", Name: "name", Start: 43, End: 51, Clause: "WHERE"},
+				{Style: ParameterFormat, Text: "%s", Ordinal: 2, Start: 61, End: 63, Clause: "ORDER BY"},
+			},
+		},
+		{
+			name:  "subquery",
+			input: "SELECT * FROM t WHERE a = (SELECT b FROM u WHERE c = ?) AND d = ?",
+			expected: []Parameter{
+				{Style: ParameterQuestionMark, Text: "?", Ordinal: 1, Start: 53, End: 54, Clause: "WHERE"},
+				{Style: ParameterQuestionMark, Text: "?", Ordinal: 2, Start: 64, End: 65, Clause: "WHERE"},
+			},
+		},
+		{
+			name:  "clause restored after parentheses",
+			input: "INSERT INTO t (a) SELECT ? FROM u",
+			expected: []Parameter{
+				{Style: ParameterQuestionMark, Text: "?", Ordinal: 1, Start: 25, End: 26, Clause: "SELECT"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ExtractParameters(tt.input, tt.lexerOpts...))
+		})
+	}
+}
+
+func TestTokenParameter(t *testing.T) {
+	tests := []struct {
+		input   string
+		dbms    DBMSType
+		name    string
+		ordinal int
+	}{
+		{"$3", DBMSPostgres, "", 3},
+		{":user_id", DBMSOracle, "user_id", 0},
+		{":2", DBMSOracle, "", 2},
+		{"@user_id", DBMSSQLServer, "user_id", 0},
+		{"@1", DBMSSQLServer, "1", 0},
+		{"?1", "", "", 0},
+		{"user_id", "", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			name, ordinal := New(tt.input, WithDBMS(tt.dbms)).Scan().Parameter()
+			assert.Equal(t, tt.name, name)
+			assert.Equal(t, tt.ordinal, ordinal)
+		})
+	}
+}
+
+func ExampleExtractParameters() {
+	for _, parameter := range ExtractParameters("UPDATE users SET name = :name WHERE id = :id LIMIT 1") {
+		fmt.Println(parameter.Text, parameter.Name, parameter.Clause)
+	}
+	// Output:
+	// :name name SET
+	// :id id WHERE
+}
@@ -0,0 +1,1107 @@
+package sqllexer
+
+import "fmt"
+
+// DMLStatement is the syntax tree of a SELECT, INSERT, UPDATE or DELETE statement,
+// i.e. a *SelectStatement, *InsertStatement, *UpdateStatement or *DeleteStatement
+type DMLStatement interface {
+	Kind() StatementKind
+}
+
+// Expression is an expression of a statement, e.g. a select item, a predicate or a row count.
+// The parser does not look into expressions, it only records their text and byte range in the query.
+type Expression struct {
+	Text  string `json:"text"`
+	Start int    `json:"start"` // byte offset of the expression in the query
+	End   int    `json:"end"`   // byte offset following the expression in the query
+}
+
+// CommonTableExpression is a named statement of a WITH clause
+type CommonTableExpression struct {
+	Name      string       `json:"name"`
+	Columns   []string     `json:"columns,omitempty"`
+	Statement DMLStatement `json:"statement"`
+}
+
+// TableReference is a table, a table function or a subquery of a FROM, JOIN, INTO, UPDATE or USING clause
+type TableReference struct {
+	Name     string           `json:"name,omitempty"` // e.g. users or public.users, empty for a subquery
+	Alias    string           `json:"alias,omitempty"`
+	Subquery *SelectStatement `json:"subquery,omitempty"`
+	Start    int              `json:"start"`
+	End      int              `json:"end"`
+}
+
+// JoinClause is a table joined in a FROM clause
+type JoinClause struct {
+	Kind  string         `json:"kind"` // e.g. JOIN, LEFT JOIN, CROSS JOIN
+	Table TableReference `json:"table"`
+	On    *Expression    `json:"on,omitempty"`
+	Using []string       `json:"using,omitempty"`
+}
+
+// SelectItem is an item of a select list or of a RETURNING clause
+type SelectItem struct {
+	Expression Expression `json:"expression"`
+	Alias      string     `json:"alias,omitempty"`
+}
+
+// OrderItem is an item of an ORDER BY clause
+type OrderItem struct {
+	Expression Expression `json:"expression"`
+	Descending bool       `json:"descending,omitempty"`
+}
+
+// Assignment is an assignment of the SET clause of an UPDATE
+type Assignment struct {
+	Column string     `json:"column"` // e.g. name, u.name or (a, b)
+	Value  Expression `json:"value"`
+}
+
+// SetOperation combines a SELECT with the SELECT that follows it
+type SetOperation struct {
+	Operator string           `json:"operator"` // e.g. UNION, UNION ALL, EXCEPT
+	Select   *SelectStatement `json:"select"`
+}
+
+// SelectStatement is the syntax tree of a SELECT.
+// In a compound select, the ORDER BY and row limit clauses following the last SELECT belong to it.
+type SelectStatement struct {
+	With         []CommonTableExpression `json:"with,omitempty"`
+	Distinct     bool                    `json:"distinct,omitempty"`
+	Top          *Expression             `json:"top,omitempty"` // SQL Server
+	Columns      []SelectItem            `json:"columns"`
+	From         []TableReference        `json:"from,omitempty"`
+	Joins        []JoinClause            `json:"joins,omitempty"`
+	Where        *Expression             `json:"where,omitempty"`
+	GroupBy      []Expression            `json:"group_by,omitempty"`
+	Having       *Expression             `json:"having,omitempty"`
+	OrderBy      []OrderItem             `json:"order_by,omitempty"`
+	Limit        *Expression             `json:"limit,omitempty"` // LIMIT, FETCH FIRST or MySQL LIMIT offset, count
+	Offset       *Expression             `json:"offset,omitempty"`
+	Locking      *Expression             `json:"locking,omitempty"` // e.g. FOR UPDATE SKIP LOCKED
+	SetOperation *SetOperation           `json:"set_operation,omitempty"`
+}
+
+// InsertStatement is the syntax tree of an INSERT
+type InsertStatement struct {
+	With       []CommonTableExpression `json:"with,omitempty"`
+	Table      TableReference          `json:"table"`
+	Columns    []string                `json:"columns,omitempty"`
+	Values     [][]Expression          `json:"values,omitempty"`
+	Select     *SelectStatement        `json:"select,omitempty"`
+	OnConflict *Expression             `json:"on_conflict,omitempty"` // e.g. ON CONFLICT ... or ON DUPLICATE KEY UPDATE ...
+	Returning  []SelectItem            `json:"returning,omitempty"`
+}
+
+// UpdateStatement is the syntax tree of an UPDATE
+type UpdateStatement struct {
+	With      []CommonTableExpression `json:"with,omitempty"`
+	Table     TableReference          `json:"table"`
+	Set       []Assignment            `json:"set"`
+	From      []TableReference        `json:"from,omitempty"`
+	Joins     []JoinClause            `json:"joins,omitempty"`
+	Where     *Expression             `json:"where,omitempty"`
+	OrderBy   []OrderItem             `json:"order_by,omitempty"`
+	Limit     *Expression             `json:"limit,omitempty"`
+	Returning []SelectItem            `json:"returning,omitempty"`
+}
+
+// DeleteStatement is the syntax tree of a DELETE
+type DeleteStatement struct {
+	With      []CommonTableExpression `json:"with,omitempty"`
+	Table     TableReference          `json:"table"`
+	Using     []TableReference        `json:"using,omitempty"` // USING tables, or the tables following the first one in FROM
+	Joins     []JoinClause            `json:"joins,omitempty"`
+	Where     *Expression             `json:"where,omitempty"`
+	OrderBy   []OrderItem             `json:"order_by,omitempty"`
+	Limit     *Expression             `json:"limit,omitempty"`
+	Returning []SelectItem            `json:"returning,omitempty"`
+}
+
+func (s *SelectStatement) Kind() StatementKind { return StatementSelect }
+func (s *InsertStatement) Kind() StatementKind { return StatementInsert }
+func (s *UpdateStatement) Kind() StatementKind { return StatementUpdate }
+func (s *DeleteStatement) Kind() StatementKind { return StatementDelete }
+
+// ParseError is the error returned when a statement cannot be parsed
+type ParseError struct {
+	Message string
+	Offset  int // byte offset of the offending token in the query
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at offset %d", e.Message, e.Offset)
+}
+
+// Parse parses a single SELECT, INSERT, UPDATE or DELETE statement, optionally terminated by a semicolon.
+// The parser is built on the lexer and only structures the clauses of the statement,
+// expressions are returned as fragments of the query.
+func Parse(query string, lexerOpts ...lexerOption) (DMLStatement, error) {
+	p, err := newParser(query, 0, lexerOpts...)
+	if err != nil {
+		return nil, err
+	}
+	statement, err := p.parseStatement()
+	if err != nil {
+		return nil, err
+	}
+	p.acceptPunctuation(";")
+	if token := p.peek(); token.Type != EOF {
+		return nil, p.errorf("unexpected %q", token.Value)
+	}
+	return statement, nil
+}
+
+// clauseWords are the words ending an expression
+var clauseWords = map[string]bool{
+	"FROM":          true,
+	"WHERE":         true,
+	"GROUP":         true,
+	"HAVING":        true,
+	"ORDER":         true,
+	"LIMIT":         true,
+	"OFFSET":        true,
+	"FETCH":         true,
+	"UNION":         true,
+	"INTERSECT":     true,
+	"EXCEPT":        true,
+	"MINUS":         true,
+	"JOIN":          true,
+	"INNER":         true,
+	"LEFT":          true,
+	"RIGHT":         true,
+	"FULL":          true,
+	"CROSS":         true,
+	"NATURAL":       true,
+	"STRAIGHT_JOIN": true,
+	"ON":            true,
+	"USING":         true,
+	"SET":           true,
+	"VALUES":        true,
+	"RETURNING":     true,
+	"WINDOW":        true,
+	"FOR":           true,
+	"INTO":          true,
+	"ASC":           true,
+	"DESC":          true,
+	"NULLS":         true,
+}
+
+// nonAliasWords are the identifiers following an operand that are not an alias
+var nonAliasWords = map[string]bool{
+	"WHEN":    true,
+	"THEN":    true,
+	"ELSE":    true,
+	"END":     true,
+	"ESCAPE":  true,
+	"COLLATE": true,
+	"OVER":    true,
+	"FILTER":  true,
+	"WITHIN":  true,
+	"AT":      true,
+	"DIV":     true,
+	"MOD":     true,
+	"XOR":     true,
+	"REGEXP":  true,
+	"RLIKE":   true,
+	"GLOB":    true,
+	"SIMILAR": true,
+}
+
+// parser is a recursive descent parser on the value tokens of a query
+type parser struct {
+	query  string
+	offset int // byte offset of the query in the text it belongs to, added to the byte ranges of its tokens
+	tokens []Token
+	pos    int
+	eof    Token
+}
+
+func newParser(query string, offset int, lexerOpts ...lexerOption) (*parser, error) {
+	end := offset + len(query)
+	p := &parser{query: query, offset: offset, eof: Token{Type: EOF, Start: end, End: end}}
+	lexer := New(query, lexerOpts...)
+	for {
+		token := lexer.Scan()
+		if token.Type == EOF {
+			return p, nil
+		}
+		if token.Type == INCOMPLETE_QUOTED_IDENT {
+			return nil, &ParseError{Message: "unterminated quoted identifier", Offset: token.Start + offset}
+		}
+		if isValueToken(token) {
+			p.tokens = append(p.tokens, Token{Type: token.Type, Value: token.Value, Start: token.Start + offset, End: token.End + offset})
+		}
+	}
+}
+
+func (p *parser) peek() *Token {
+	return p.peekAt(0)
+}
+
+func (p *parser) peekAt(n int) *Token {
+	if p.pos+n < len(p.tokens) {
+		return &p.tokens[p.pos+n]
+	}
+	return &p.eof
+}
+
+func (p *parser) next() *Token {
+	token := p.peek()
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+	return token
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &ParseError{Message: fmt.Sprintf(format, args...), Offset: p.peek().Start}
+}
+
+// isWord returns true if the token is the given unquoted word, case insensitively
+func isWord(token *Token, word string) bool {
+	switch token.Type {
+	case IDENT, KEYWORD, COMMAND, FUNCTION, BOOLEAN, NULL, PROC_INDICATOR, CTE_INDICATOR, ALIAS_INDICATOR:
+		return equalFoldASCII(token.Value, word)
+	}
+	return false
+}
+
+// atClauseWord returns true if the next token is a word ending an expression
+func (p *parser) atClauseWord() bool {
+	token := p.peek()
+	if token.Type != IDENT && token.Type != KEYWORD && token.Type != COMMAND {
+		return false
+	}
+	word := ToUpperASCII(token.Value)
+	if (word == "LEFT" || word == "RIGHT") && p.peekAt(1).Type == PUNCTUATION && p.peekAt(1).Value == "(" {
+		// LEFT(...) and RIGHT(...) are functions
+		return false
+	}
+	return clauseWords[word]
+}
+
+// acceptWords consumes the given sequence of words, if the next tokens match it
+func (p *parser) acceptWords(words ...string) bool {
+	for i, word := range words {
+		if !isWord(p.peekAt(i), word) {
+			return false
+		}
+	}
+	p.pos += len(words)
+	return true
+}
+
+func (p *parser) expectWords(words ...string) error {
+	if !p.acceptWords(words...) {
+		return p.errorf("expected %s", words[0])
+	}
+	return nil
+}
+
+func (p *parser) acceptPunctuation(value string) bool {
+	if token := p.peek(); token.Type == PUNCTUATION && token.Value == value {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectPunctuation(value string) error {
+	if !p.acceptPunctuation(value) {
+		return p.errorf("expected %q", value)
+	}
+	return nil
+}
+
+// expression returns the expression made of the tokens from first to the current one, excluded
+func (p *parser) expression(first int) Expression {
+	start, end := p.tokens[first].Start, p.tokens[p.pos-1].End
+	return Expression{Text: p.query[start-p.offset : end-p.offset], Start: start, End: end}
+}
+
+func (p *parser) parseStatement() (DMLStatement, error) {
+	var with []CommonTableExpression
+	if p.acceptWords("WITH") {
+		var err error
+		if with, err = p.parseWith(); err != nil {
+			return nil, err
+		}
+	}
+	token := p.peek()
+	switch {
+	case isWord(token, "SELECT"):
+		statement, err := p.parseSelect(with)
+		if err != nil {
+			return nil, err
+		}
+		return statement, nil
+	case isWord(token, "INSERT"):
+		statement, err := p.parseInsert(with)
+		if err != nil {
+			return nil, err
+		}
+		return statement, nil
+	case isWord(token, "UPDATE"):
+		statement, err := p.parseUpdate(with)
+		if err != nil {
+			return nil, err
+		}
+		return statement, nil
+	case isWord(token, "DELETE"):
+		statement, err := p.parseDelete(with)
+		if err != nil {
+			return nil, err
+		}
+		return statement, nil
+	}
+	return nil, p.errorf("expected SELECT, INSERT, UPDATE or DELETE")
+}
+
+func (p *parser) parseWith() ([]CommonTableExpression, error) {
+	p.acceptWords("RECURSIVE")
+	var ctes []CommonTableExpression
+	for {
+		var cte CommonTableExpression
+		var err error
+		if cte.Name, err = p.parseName(); err != nil {
+			return nil, err
+		}
+		if p.peek().Type == PUNCTUATION && p.peek().Value == "(" {
+			if cte.Columns, err = p.parseNameList(); err != nil {
+				return nil, err
+			}
+		}
+		if err = p.expectWords("AS"); err != nil {
+			return nil, err
+		}
+		p.acceptWords("NOT")
+		p.acceptWords("MATERIALIZED")
+		if err = p.expectPunctuation("("); err != nil {
+			return nil, err
+		}
+		if cte.Statement, err = p.parseStatement(); err != nil {
+			return nil, err
+		}
+		if err = p.expectPunctuation(")"); err != nil {
+			return nil, err
+		}
+		ctes = append(ctes, cte)
+		if !p.acceptPunctuation(",") {
+			return ctes, nil
+		}
+	}
+}
+
+func (p *parser) parseSelect(with []CommonTableExpression) (*SelectStatement, error) {
+	statement := &SelectStatement{With: with}
+	var err error
+	if err = p.expectWords("SELECT"); err != nil {
+		return nil, err
+	}
+	if p.acceptWords("DISTINCT") {
+		statement.Distinct = true
+		if p.acceptWords("ON") {
+			// PostgreSQL DISTINCT ON (...)
+			if _, err = p.parseParenthesized(); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		p.acceptWords("ALL")
+	}
+	if p.acceptWords("TOP") {
+		if statement.Top, err = p.parseOperand(); err != nil {
+			return nil, err
+		}
+	}
+	if statement.Columns, err = p.parseSelectItems(); err != nil {
+		return nil, err
+	}
+	if p.acceptWords("FROM") {
+		if statement.From, statement.Joins, err = p.parseFrom(); err != nil {
+			return nil, err
+		}
+	}
+	if statement.Where, err = p.parseWhere(); err != nil {
+		return nil, err
+	}
+	if p.acceptWords("GROUP", "BY") {
+		if statement.GroupBy, err = p.parseExpressionList(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptWords("HAVING") {
+		having, err := p.parseExpression(false)
+		if err != nil {
+			return nil, err
+		}
+		statement.Having = &having
+	}
+	if statement.SetOperation, err = p.parseSetOperation(); err != nil {
+		return nil, err
+	} else if statement.SetOperation != nil {
+		return statement, nil
+	}
+	if statement.OrderBy, err = p.parseOrderBy(); err != nil {
+		return nil, err
+	}
+	if statement.Limit, statement.Offset, err = p.parseRowLimit(); err != nil {
+		return nil, err
+	}
+	if isWord(p.peek(), "FOR") {
+		locking, err := p.parseUntil()
+		if err != nil {
+			return nil, err
+		}
+		statement.Locking = &locking
+	}
+	return statement, nil
+}
+
+func (p *parser) parseSetOperation() (*SetOperation, error) {
+	token := p.peek()
+	var operator string
+	switch {
+	case isWord(token, "UNION"), isWord(token, "INTERSECT"), isWord(token, "EXCEPT"), isWord(token, "MINUS"):
+		operator = ToUpperASCII(p.next().Value)
+	default:
+		return nil, nil
+	}
+	if p.acceptWords("ALL") {
+		operator += " ALL"
+	} else if p.acceptWords("DISTINCT") {
+		operator += " DISTINCT"
+	}
+	selectStatement, err := p.parseSelect(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &SetOperation{Operator: operator, Select: selectStatement}, nil
+}
+
+func (p *parser) parseInsert(with []CommonTableExpression) (*InsertStatement, error) {
+	statement := &InsertStatement{With: with}
+	var err error
+	if err = p.expectWords("INSERT"); err != nil {
+		return nil, err
+	}
+	// e.g. MySQL INSERT IGNORE, SQLite INSERT OR REPLACE
+	for p.acceptWords("IGNORE") || p.acceptWords("OR") || p.acceptWords("REPLACE") || p.acceptWords("ABORT") ||
+		p.acceptWords("FAIL") || p.acceptWords("ROLLBACK") {
+	}
+	p.acceptWords("INTO")
+	start := p.peek().Start
+	if statement.Table.Name, err = p.parseName(); err != nil {
+		return nil, err
+	}
+	if p.acceptWords("AS") {
+		if statement.Table.Alias, err = p.parseName(); err != nil {
+			return nil, err
+		}
+	}
+	statement.Table.Start, statement.Table.End = start, p.tokens[p.pos-1].End
+	if token := p.peek(); token.Type == PUNCTUATION && token.Value == "(" &&
+		!isWord(p.peekAt(1), "SELECT") && !isWord(p.peekAt(1), "WITH") {
+		if statement.Columns, err = p.parseNameList(); err != nil {
+			return nil, err
+		}
+	}
+
+	switch token := p.peek(); {
+	case isWord(token, "VALUES"), isWord(token, "VALUE"):
+		p.next()
+		for {
+			row, err := p.parseParenthesized()
+			if err != nil {
+				return nil, err
+			}
+			statement.Values = append(statement.Values, row)
+			if !p.acceptPunctuation(",") {
+				break
+			}
+		}
+	case isWord(token, "DEFAULT"):
+		if err = p.expectWords("DEFAULT", "VALUES"); err != nil {
+			return nil, err
+		}
+	default:
+		query, err := p.parseQuery()
+		if err != nil {
+			return nil, err
+		}
+		statement.Select = query
+	}
+
+	if isWord(p.peek(), "ON") {
+		onConflict, err := p.parseUntil("RETURNING")
+		if err != nil {
+			return nil, err
+		}
+		statement.OnConflict = &onConflict
+	}
+	if p.acceptWords("RETURNING") {
+		if statement.Returning, err = p.parseSelectItems(); err != nil {
+			return nil, err
+		}
+	}
+	return statement, nil
+}
+
+// parseQuery parses a SELECT, possibly parenthesized or prefixed with a WITH clause
+func (p *parser) parseQuery() (*SelectStatement, error) {
+	if p.acceptPunctuation("(") {
+		query, err := p.parseQuery()
+		if err != nil {
+			return nil, err
+		}
+		return query, p.expectPunctuation(")")
+	}
+	var with []CommonTableExpression
+	if p.acceptWords("WITH") {
+		var err error
+		if with, err = p.parseWith(); err != nil {
+			return nil, err
+		}
+	}
+	return p.parseSelect(with)
+}
+
+func (p *parser) parseUpdate(with []CommonTableExpression) (*UpdateStatement, error) {
+	statement := &UpdateStatement{With: with}
+	var err error
+	if err = p.expectWords("UPDATE"); err != nil {
+		return nil, err
+	}
+	for p.acceptWords("LOW_PRIORITY") || p.acceptWords("IGNORE") {
+	}
+	if statement.Table, err = p.parseTableReference(); err != nil {
+		return nil, err
+	}
+	// e.g. MySQL UPDATE a JOIN b ON ... SET ...
+	if statement.Joins, err = p.parseJoins(); err != nil {
+		return nil, err
+	}
+	if err = p.expectWords("SET"); err != nil {
+		return nil, err
+	}
+	for {
+		assignment, err := p.parseAssignment()
+		if err != nil {
+			return nil, err
+		}
+		statement.Set = append(statement.Set, assignment)
+		if !p.acceptPunctuation(",") {
+			break
+		}
+	}
+	if p.acceptWords("FROM") {
+		var joins []JoinClause
+		if statement.From, joins, err = p.parseFrom(); err != nil {
+			return nil, err
+		}
+		statement.Joins = append(statement.Joins, joins...)
+	}
+	if statement.Where, err = p.parseWhere(); err != nil {
+		return nil, err
+	}
+	if statement.OrderBy, err = p.parseOrderBy(); err != nil {
+		return nil, err
+	}
+	if p.acceptWords("LIMIT") {
+		if statement.Limit, err = p.parseOperand(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptWords("RETURNING") {
+		if statement.Returning, err = p.parseSelectItems(); err != nil {
+			return nil, err
+		}
+	}
+	return statement, nil
+}
+
+func (p *parser) parseAssignment() (Assignment, error) {
+	var assignment Assignment
+	if token := p.peek(); token.Type == PUNCTUATION && token.Value == "(" {
+		first := p.pos
+		if _, err := p.parseParenthesized(); err != nil {
+			return assignment, err
+		}
+		assignment.Column = p.expression(first).Text
+	} else {
+		column, err := p.parseName()
+		if err != nil {
+			return assignment, err
+		}
+		assignment.Column = column
+	}
+	if token := p.peek(); token.Type != OPERATOR || token.Value != "=" {
+		return assignment, p.errorf("expected \"=\"")
+	}
+	p.next()
+	value, err := p.parseExpression(false)
+	if err != nil {
+		return assignment, err
+	}
+	assignment.Value = value
+	return assignment, nil
+}
+
+func (p *parser) parseDelete(with []CommonTableExpression) (*DeleteStatement, error) {
+	statement := &DeleteStatement{With: with}
+	var err error
+	if err = p.expectWords("DELETE"); err != nil {
+		return nil, err
+	}
+	for p.acceptWords("LOW_PRIORITY") || p.acceptWords("QUICK") || p.acceptWords("IGNORE") {
+	}
+	hasFrom := p.acceptWords("FROM")
+	if !hasFrom {
+		// e.g. MySQL DELETE t1, t2 FROM ..., or SQL Server DELETE t
+		targets, err := p.parseTableList()
+		if err != nil {
+			return nil, err
+		}
+		statement.Table = targets[0]
+		hasFrom = p.acceptWords("FROM")
+	}
+	if hasFrom {
+		tables, joins, err := p.parseFrom()
+		if err != nil {
+			return nil, err
+		}
+		statement.Table, statement.Joins = tables[0], joins
+		if len(tables) > 1 {
+			statement.Using = tables[1:]
+		}
+	}
+	if p.acceptWords("USING") {
+		var joins []JoinClause
+		if statement.Using, joins, err = p.parseFrom(); err != nil {
+			return nil, err
+		}
+		statement.Joins = append(statement.Joins, joins...)
+	}
+	if statement.Where, err = p.parseWhere(); err != nil {
+		return nil, err
+	}
+	if statement.OrderBy, err = p.parseOrderBy(); err != nil {
+		return nil, err
+	}
+	if p.acceptWords("LIMIT") {
+		if statement.Limit, err = p.parseOperand(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptWords("RETURNING") {
+		if statement.Returning, err = p.parseSelectItems(); err != nil {
+			return nil, err
+		}
+	}
+	return statement, nil
+}
+
+func (p *parser) parseWhere() (*Expression, error) {
+	if !p.acceptWords("WHERE") {
+		return nil, nil
+	}
+	where, err := p.parseExpression(false)
+	if err != nil {
+		return nil, err
+	}
+	return &where, nil
+}
+
+func (p *parser) parseOrderBy() ([]OrderItem, error) {
+	if !p.acceptWords("ORDER", "BY") {
+		return nil, nil
+	}
+	var items []OrderItem
+	for {
+		expression, err := p.parseExpression(false)
+		if err != nil {
+			return nil, err
+		}
+		item := OrderItem{Expression: expression}
+		if p.acceptWords("DESC") {
+			item.Descending = true
+		} else {
+			p.acceptWords("ASC")
+		}
+		if p.acceptWords("NULLS") && !p.acceptWords("FIRST") && !p.acceptWords("LAST") {
+			return nil, p.errorf("expected FIRST or LAST")
+		}
+		items = append(items, item)
+		if !p.acceptPunctuation(",") {
+			return items, nil
+		}
+	}
+}
+
+// parseRowLimit parses the LIMIT, OFFSET and FETCH clauses, in any order
+func (p *parser) parseRowLimit() (limit *Expression, offset *Expression, err error) {
+	for {
+		switch {
+		case p.acceptWords("LIMIT"):
+			if limit, err = p.parseOperand(); err != nil {
+				return nil, nil, err
+			}
+			if p.acceptPunctuation(",") {
+				// MySQL LIMIT offset, count
+				offset = limit
+				if limit, err = p.parseOperand(); err != nil {
+					return nil, nil, err
+				}
+			}
+		case p.acceptWords("OFFSET"):
+			if offset, err = p.parseOperand(); err != nil {
+				return nil, nil, err
+			}
+			_ = p.acceptWords("ROWS") || p.acceptWords("ROW")
+		case p.acceptWords("FETCH"):
+			if !p.acceptWords("FIRST") && !p.acceptWords("NEXT") {
+				return nil, nil, p.errorf("expected FIRST or NEXT")
+			}
+			if limit, err = p.parseOperand(); err != nil {
+				return nil, nil, err
+			}
+			_ = p.acceptWords("ROWS") || p.acceptWords("ROW")
+			_ = p.acceptWords("ONLY") || p.acceptWords("WITH", "TIES")
+		default:
+			return limit, offset, nil
+		}
+	}
+}
+
+func (p *parser) parseSelectItems() ([]SelectItem, error) {
+	var items []SelectItem
+	for {
+		expression, err := p.parseExpression(true)
+		if err != nil {
+			return nil, err
+		}
+		item := SelectItem{Expression: expression}
+		if item.Alias, err = p.parseAlias(); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if !p.acceptPunctuation(",") {
+			return items, nil
+		}
+	}
+}
+
+// parseAlias parses an optional alias, introduced by AS or not
+func (p *parser) parseAlias() (string, error) {
+	if p.acceptWords("AS") {
+		return p.parseName()
+	}
+	if token := p.peek(); (token.Type == IDENT || token.Type == QUOTED_IDENT) && !p.atClauseWord() &&
+		!nonAliasWords[ToUpperASCII(token.Value)] {
+		return p.parseName()
+	}
+	return "", nil
+}
+
+func (p *parser) parseExpressionList() ([]Expression, error) {
+	var expressions []Expression
+	for {
+		expression, err := p.parseExpression(false)
+		if err != nil {
+			return nil, err
+		}
+		expressions = append(expressions, expression)
+		if !p.acceptPunctuation(",") {
+			return expressions, nil
+		}
+	}
+}
+
+// parseExpression consumes the tokens of an expression, up to a comma, a closing parenthesis or a clause word
+// outside of parentheses. If stopAtAlias is true, the expression also ends before an alias.
+func (p *parser) parseExpression(stopAtAlias bool) (Expression, error) {
+	first := p.pos
+	depth, caseDepth := 0, 0
+loop:
+	for {
+		token := p.peek()
+		switch {
+		case token.Type == EOF:
+			if depth > 0 {
+				return Expression{}, p.errorf("expected %q", ")")
+			}
+			break loop
+		case token.Type == PUNCTUATION:
+			switch token.Value {
+			case "(", "[":
+				depth++
+			case ")", "]":
+				if depth == 0 {
+					break loop
+				}
+				depth--
+			case ",", ";":
+				if depth == 0 {
+					break loop
+				}
+			}
+		case depth > 0:
+		case isWord(token, "CASE"):
+			caseDepth++
+		case isWord(token, "END") && caseDepth > 0:
+			caseDepth--
+		case caseDepth > 0:
+		case p.atClauseWord():
+			break loop
+		case stopAtAlias && p.pos > first && isWord(token, "AS"):
+			break loop
+		case stopAtAlias && p.pos > first && (token.Type == IDENT || token.Type == QUOTED_IDENT) &&
+			!nonAliasWords[ToUpperASCII(token.Value)] && endsOperand(&p.tokens[p.pos-1]):
+			break loop
+		}
+		p.pos++
+	}
+	if p.pos == first {
+		return Expression{}, p.errorf("expected an expression")
+	}
+	return p.expression(first), nil
+}
+
+// endsOperand returns true if the token can be the last token of an operand
+func endsOperand(token *Token) bool {
+	switch token.Type {
+	case IDENT, QUOTED_IDENT, NUMBER, STRING, BOOLEAN, NULL, WILDCARD, POSITIONAL_PARAMETER, BIND_PARAMETER,
+		PLACEHOLDER, SYSTEM_VARIABLE, DOLLAR_QUOTED_STRING:
+		return !nonAliasWords[ToUpperASCII(token.Value)]
+	case KEYWORD:
+		return equalFoldASCII(token.Value, "END")
+	case PUNCTUATION:
+		return token.Value == ")" || token.Value == "]"
+	}
+	return false
+}
+
+// parseOperand parses a single token, or a parenthesized expression, e.g. a row count
+func (p *parser) parseOperand() (*Expression, error) {
+	first := p.pos
+	if token := p.peek(); token.Type == PUNCTUATION && token.Value == "(" {
+		if _, err := p.parseParenthesized(); err != nil {
+			return nil, err
+		}
+	} else if token.Type == EOF || token.Type == PUNCTUATION {
+		return nil, p.errorf("expected an expression")
+	} else {
+		p.next()
+	}
+	expression := p.expression(first)
+	return &expression, nil
+}
+
+// parseParenthesized parses a parenthesized list of expressions
+func (p *parser) parseParenthesized() ([]Expression, error) {
+	if err := p.expectPunctuation("("); err != nil {
+		return nil, err
+	}
+	expressions, err := p.parseExpressionList()
+	if err != nil {
+		return nil, err
+	}
+	return expressions, p.expectPunctuation(")")
+}
+
+// parseUntil consumes the tokens up to one of the stop words outside of parentheses, or the end of the statement
+func (p *parser) parseUntil(stopWords ...string) (Expression, error) {
+	first := p.pos
+	depth := 0
+loop:
+	for {
+		token := p.peek()
+		switch {
+		case token.Type == EOF:
+			if depth > 0 {
+				return Expression{}, p.errorf("expected %q", ")")
+			}
+			break loop
+		case token.Type == PUNCTUATION && token.Value == "(":
+			depth++
+		case token.Type == PUNCTUATION && token.Value == ")":
+			if depth == 0 {
+				break loop
+			}
+			depth--
+		case token.Type == PUNCTUATION && token.Value == ";" && depth == 0:
+			break loop
+		case depth == 0:
+			for _, word := range stopWords {
+				if isWord(token, word) {
+					break loop
+				}
+			}
+		}
+		p.pos++
+	}
+	return p.expression(first), nil
+}
+
+// parseName parses a possibly qualified and quoted name, e.g. users, public."Users" or [dbo].[users]
+func (p *parser) parseName() (string, error) {
+	token := p.peek()
+	if token.Type != IDENT && token.Type != QUOTED_IDENT && token.Type != FUNCTION {
+		return "", p.errorf("expected a name")
+	}
+	start, end := token.Start, token.End
+	p.next()
+	// the parts of a name that mixes quoted and unquoted identifiers are separate tokens
+	for {
+		token = p.peek()
+		if token.Start != end || (token.Type != IDENT && token.Type != QUOTED_IDENT && token.Type != FUNCTION &&
+			(token.Type != PUNCTUATION || token.Value != ".")) {
+			return p.query[start-p.offset : end-p.offset], nil
+		}
+		end = token.End
+		p.next()
+	}
+}
+
+func (p *parser) parseNameList() ([]string, error) {
+	if err := p.expectPunctuation("("); err != nil {
+		return nil, err
+	}
+	var names []string
+	for {
+		name, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if !p.acceptPunctuation(",") {
+			return names, p.expectPunctuation(")")
+		}
+	}
+}
+
+func (p *parser) parseTableReference() (TableReference, error) {
+	var table TableReference
+	var err error
+	p.acceptWords("ONLY")
+	table.Start = p.peek().Start
+	if p.acceptPunctuation("(") {
+		if table.Subquery, err = p.parseQuery(); err != nil {
+			return table, err
+		}
+		if err = p.expectPunctuation(")"); err != nil {
+			return table, err
+		}
+	} else {
+		isFunction := p.peek().Type == FUNCTION
+		if table.Name, err = p.parseName(); err != nil {
+			return table, err
+		}
+		if isFunction {
+			if _, err = p.parseParenthesized(); err != nil {
+				return table, err
+			}
+		}
+	}
+	table.End = p.tokens[p.pos-1].End
+	if table.Alias, err = p.parseAlias(); err != nil {
+		return table, err
+	}
+	if table.Alias != "" {
+		table.End = p.tokens[p.pos-1].End
+	}
+	if isWord(p.peek(), "WITH") && p.peekAt(1).Type == PUNCTUATION && p.peekAt(1).Value == "(" {
+		// SQL Server table hints, e.g. WITH (NOLOCK)
+		p.next()
+		if _, err = p.parseParenthesized(); err != nil {
+			return table, err
+		}
+	}
+	return table, nil
+}
+
+func (p *parser) parseTableList() ([]TableReference, error) {
+	var tables []TableReference
+	for {
+		table, err := p.parseTableReference()
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+		if !p.acceptPunctuation(",") {
+			return tables, nil
+		}
+	}
+}
+
+// parseFrom parses the comma separated tables of a FROM clause and the tables joined to them
+func (p *parser) parseFrom() ([]TableReference, []JoinClause, error) {
+	var tables []TableReference
+	var joins []JoinClause
+	for {
+		table, err := p.parseTableReference()
+		if err != nil {
+			return nil, nil, err
+		}
+		tables = append(tables, table)
+		tableJoins, err := p.parseJoins()
+		if err != nil {
+			return nil, nil, err
+		}
+		joins = append(joins, tableJoins...)
+		if !p.acceptPunctuation(",") {
+			return tables, joins, nil
+		}
+	}
+}
+
+func (p *parser) parseJoins() ([]JoinClause, error) {
+	var joins []JoinClause
+	for {
+		kind, ok := p.parseJoinKind()
+		if !ok {
+			return joins, nil
+		}
+		join := JoinClause{Kind: kind}
+		var err error
+		if join.Table, err = p.parseTableReference(); err != nil {
+			return nil, err
+		}
+		if p.acceptWords("ON") {
+			on, err := p.parseExpression(false)
+			if err != nil {
+				return nil, err
+			}
+			join.On = &on
+		} else if p.acceptWords("USING") {
+			if join.Using, err = p.parseNameList(); err != nil {
+				return nil, err
+			}
+		}
+		joins = append(joins, join)
+	}
+}
+
+// parseJoinKind parses the words introducing a join, e.g. LEFT OUTER JOIN
+func (p *parser) parseJoinKind() (string, bool) {
+	first := p.pos
+	kind := ""
+	for _, words := range [][]string{{"NATURAL"}, {"INNER", "CROSS", "LEFT", "RIGHT", "FULL"}, {"OUTER"}} {
+		for _, word := range words {
+			if p.acceptWords(word) {
+				kind += word + " "
+				break
+			}
+		}
+	}
+	if p.acceptWords("STRAIGHT_JOIN") && kind == "" {
+		return "STRAIGHT_JOIN", true
+	}
+	if !p.acceptWords("JOIN") {
+		p.pos = first
+		return "", false
+	}
+	return kind + "JOIN", true
+}
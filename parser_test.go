@@ -0,0 +1,262 @@
+package sqllexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// expressionTexts returns the texts of the expressions, after checking they match their byte range in the query
+func expressionTexts(t *testing.T, query string, expressions ...Expression) []string {
+	texts := make([]string, 0, len(expressions))
+	for _, expression := range expressions {
+		assert.Equal(t, expression.Text, query[expression.Start:expression.End])
+		texts = append(texts, expression.Text)
+	}
+	return texts
+}
+
+func selectItemTexts(t *testing.T, query string, items []SelectItem) []string {
+	texts := make([]string, 0, len(items))
+	for _, item := range items {
+		text := expressionTexts(t, query, item.Expression)[0]
+		if item.Alias != "" {
+			text += " AS " + item.Alias
+		}
+		texts = append(texts, text)
+	}
+	return texts
+}
+
+func TestParseSelect(t *testing.T) {
+	query := "SELECT DISTINCT u.id, u.name AS n, count(*) c, CASE WHEN a THEN b END kind, LEFT(u.name, 1) " +
+		"FROM users u LEFT OUTER JOIN orders o ON o.user_id = u.id, accounts " +
+		"WHERE u.active = true AND o.total > 10 GROUP BY u.id, u.name HAVING count(*) > 1 ORDER BY n DESC NULLS LAST, 2 LIMIT 10 OFFSET 5;"
+	statement, err := Parse(query)
+	assert.NoError(t, err)
+	selectStatement, ok := statement.(*SelectStatement)
+	assert.True(t, ok)
+	assert.Equal(t, StatementSelect, statement.Kind())
+	assert.True(t, selectStatement.Distinct)
+	assert.Equal(t, []string{"u.id", "u.name AS n", "count(*) AS c", "CASE WHEN a THEN b END AS kind", "LEFT(u.name, 1)"},
+		selectItemTexts(t, query, selectStatement.Columns))
+	assert.Equal(t, []TableReference{
+		{Name: "users", Alias: "u", Start: 97, End: 104},
+		{Name: "accounts", Start: 151, End: 159},
+	}, selectStatement.From)
+	assert.Len(t, selectStatement.Joins, 1)
+	assert.Equal(t, "LEFT OUTER JOIN", selectStatement.Joins[0].Kind)
+	assert.Equal(t, TableReference{Name: "orders", Alias: "o", Start: 121, End: 129}, selectStatement.Joins[0].Table)
+	assert.Equal(t, []string{"o.user_id = u.id"}, expressionTexts(t, query, *selectStatement.Joins[0].On))
+	assert.Equal(t, []string{"u.active = true AND o.total > 10"}, expressionTexts(t, query, *selectStatement.Where))
+	assert.Equal(t, []string{"u.id", "u.name"}, expressionTexts(t, query, selectStatement.GroupBy...))
+	assert.Equal(t, []string{"count(*) > 1"}, expressionTexts(t, query, *selectStatement.Having))
+	assert.Len(t, selectStatement.OrderBy, 2)
+	assert.Equal(t, []string{"n", "2"}, expressionTexts(t, query, selectStatement.OrderBy[0].Expression, selectStatement.OrderBy[1].Expression))
+	assert.True(t, selectStatement.OrderBy[0].Descending)
+	assert.False(t, selectStatement.OrderBy[1].Descending)
+	assert.Equal(t, []string{"10", "5"}, expressionTexts(t, query, *selectStatement.Limit, *selectStatement.Offset))
+}
+
+func TestParseSelectClauses(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		lexerOpts []lexerOption
+		check     func(t *testing.T, query string, statement *SelectStatement)
+	}{
+		{
+			name:  "common table expressions and set operations",
+			query: "WITH RECURSIVE recent(id) AS (SELECT id FROM orders) SELECT id FROM recent UNION ALL SELECT id FROM archive ORDER BY id",
+			check: func(t *testing.T, query string, statement *SelectStatement) {
+				assert.Len(t, statement.With, 1)
+				assert.Equal(t, "recent", statement.With[0].Name)
+				assert.Equal(t, []string{"id"}, statement.With[0].Columns)
+				assert.Equal(t, StatementSelect, statement.With[0].Statement.Kind())
+				assert.Equal(t, "UNION ALL", statement.SetOperation.Operator)
+				assert.Equal(t, "archive", statement.SetOperation.Select.From[0].Name)
+				assert.Nil(t, statement.OrderBy)
+				assert.Len(t, statement.SetOperation.Select.OrderBy, 1)
+			},
+		},
+		{
+			name:  "subqueries and table functions",
+			query: "SELECT s.x FROM (SELECT 1 AS x) AS s CROSS JOIN generate_series(1, 3) g JOIN t USING (id, x)",
+			check: func(t *testing.T, query string, statement *SelectStatement) {
+				assert.Equal(t, "s", statement.From[0].Alias)
+				assert.Equal(t, []string{"1 AS x"}, selectItemTexts(t, query, statement.From[0].Subquery.Columns))
+				assert.Equal(t, "(SELECT 1 AS x) AS s", query[statement.From[0].Start:statement.From[0].End])
+				assert.Len(t, statement.Joins, 2)
+				assert.Equal(t, "CROSS JOIN", statement.Joins[0].Kind)
+				assert.Equal(t, TableReference{Name: "generate_series", Alias: "g", Start: 48, End: 71}, statement.Joins[0].Table)
+				assert.Equal(t, []string{"id", "x"}, statement.Joins[1].Using)
+			},
+		},
+		{
+			name:  "mysql limit",
+			query: "SELECT * FROM t LIMIT 20, 10 FOR UPDATE",
+			check: func(t *testing.T, query string, statement *SelectStatement) {
+				assert.Equal(t, []string{"*"}, selectItemTexts(t, query, statement.Columns))
+				assert.Equal(t, []string{"10", "20", "FOR UPDATE"}, expressionTexts(t, query, *statement.Limit, *statement.Offset, *statement.Locking))
+			},
+		},
+		{
+			name:  "fetch first",
+			query: "SELECT a FROM t ORDER BY a OFFSET 10 ROWS FETCH NEXT ? ROWS ONLY",
+			check: func(t *testing.T, query string, statement *SelectStatement) {
+				assert.Equal(t, []string{"?", "10"}, expressionTexts(t, query, *statement.Limit, *statement.Offset))
+			},
+		},
+		{
+			name:      "sql server top and hints",
+			query:     "SELECT TOP (10) [name] FROM [dbo].[users] WITH (NOLOCK) WHERE [id] > 1",
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+			check: func(t *testing.T, query string, statement *SelectStatement) {
+				assert.Equal(t, []string{"(10)"}, expressionTexts(t, query, *statement.Top))
+				assert.Equal(t, "[dbo].[users]", statement.From[0].Name)
+				assert.Equal(t, []string{"[id] > 1"}, expressionTexts(t, query, *statement.Where))
+			},
+		},
+		{
+			name:      "mixed quoted names",
+			query:     `SELECT "u"."id" FROM public."Users" "u"`,
+			lexerOpts: []lexerOption{WithDBMS(DBMSPostgres)},
+			check: func(t *testing.T, query string, statement *SelectStatement) {
+				assert.Equal(t, []string{`"u"."id"`}, selectItemTexts(t, query, statement.Columns))
+				assert.Equal(t, TableReference{Name: `public."Users"`, Alias: `"u"`, Start: 21, End: 39}, statement.From[0])
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			statement, err := Parse(test.query, test.lexerOpts...)
+			assert.NoError(t, err)
+			selectStatement, ok := statement.(*SelectStatement)
+			assert.True(t, ok)
+			test.check(t, test.query, selectStatement)
+		})
+	}
+}
+
+func TestParseInsert(t *testing.T) {
+	query := "INSERT INTO users(id, name) VALUES (1, 'a'), (2, lower('B')) ON CONFLICT (id) DO UPDATE SET name = excluded.name RETURNING id"
+	statement, err := Parse(query)
+	assert.NoError(t, err)
+	insert, ok := statement.(*InsertStatement)
+	assert.True(t, ok)
+	assert.Equal(t, StatementInsert, statement.Kind())
+	assert.Equal(t, TableReference{Name: "users", Start: 12, End: 17}, insert.Table)
+	assert.Equal(t, []string{"id", "name"}, insert.Columns)
+	assert.Len(t, insert.Values, 2)
+	assert.Equal(t, []string{"1", "'a'"}, expressionTexts(t, query, insert.Values[0]...))
+	assert.Equal(t, []string{"2", "lower('B')"}, expressionTexts(t, query, insert.Values[1]...))
+	assert.Equal(t, []string{"ON CONFLICT (id) DO UPDATE SET name = excluded.name"}, expressionTexts(t, query, *insert.OnConflict))
+	assert.Equal(t, []string{"id"}, selectItemTexts(t, query, insert.Returning))
+
+	query = "INSERT INTO archive (id) WITH old AS (SELECT id FROM users) SELECT id FROM old"
+	statement, err = Parse(query)
+	assert.NoError(t, err)
+	insert = statement.(*InsertStatement)
+	assert.Equal(t, []string{"id"}, insert.Columns)
+	assert.Equal(t, "old", insert.Select.With[0].Name)
+	assert.Nil(t, insert.Values)
+}
+
+func TestParseUpdate(t *testing.T) {
+	query := "UPDATE users u SET name = 'x', (a, b) = (1, 2) FROM accounts a WHERE a.id = u.account_id RETURNING u.id AS id"
+	statement, err := Parse(query)
+	assert.NoError(t, err)
+	update, ok := statement.(*UpdateStatement)
+	assert.True(t, ok)
+	assert.Equal(t, StatementUpdate, statement.Kind())
+	assert.Equal(t, TableReference{Name: "users", Alias: "u", Start: 7, End: 14}, update.Table)
+	assert.Len(t, update.Set, 2)
+	assert.Equal(t, "name", update.Set[0].Column)
+	assert.Equal(t, "(a, b)", update.Set[1].Column)
+	assert.Equal(t, []string{"'x'", "(1, 2)"}, expressionTexts(t, query, update.Set[0].Value, update.Set[1].Value))
+	assert.Equal(t, "accounts", update.From[0].Name)
+	assert.Equal(t, []string{"a.id = u.account_id"}, expressionTexts(t, query, *update.Where))
+	assert.Equal(t, []string{"u.id AS id"}, selectItemTexts(t, query, update.Returning))
+
+	query = "UPDATE a JOIN b ON a.id = b.id SET a.x = b.x ORDER BY a.id LIMIT 10"
+	statement, err = Parse(query, WithDBMS(DBMSMySQL))
+	assert.NoError(t, err)
+	update = statement.(*UpdateStatement)
+	assert.Equal(t, "b", update.Joins[0].Table.Name)
+	assert.Equal(t, "a.x", update.Set[0].Column)
+	assert.Equal(t, []string{"a.id", "10"}, expressionTexts(t, query, update.OrderBy[0].Expression, *update.Limit))
+}
+
+func TestParseDelete(t *testing.T) {
+	query := "DELETE FROM users WHERE id IN (SELECT id FROM banned) RETURNING *"
+	statement, err := Parse(query)
+	assert.NoError(t, err)
+	deleteStatement, ok := statement.(*DeleteStatement)
+	assert.True(t, ok)
+	assert.Equal(t, StatementDelete, statement.Kind())
+	assert.Equal(t, "users", deleteStatement.Table.Name)
+	assert.Equal(t, []string{"id IN (SELECT id FROM banned)"}, expressionTexts(t, query, *deleteStatement.Where))
+	assert.Equal(t, []string{"*"}, selectItemTexts(t, query, deleteStatement.Returning))
+
+	query = "DELETE t1 FROM t1 INNER JOIN t2 ON t1.id = t2.id WHERE t2.x = 1 LIMIT 5"
+	statement, err = Parse(query, WithDBMS(DBMSMySQL))
+	assert.NoError(t, err)
+	deleteStatement = statement.(*DeleteStatement)
+	assert.Equal(t, "t1", deleteStatement.Table.Name)
+	assert.Equal(t, "INNER JOIN", deleteStatement.Joins[0].Kind)
+	assert.Equal(t, []string{"5"}, expressionTexts(t, query, *deleteStatement.Limit))
+
+	query = "DELETE FROM orders o USING users u WHERE o.user_id = u.id"
+	statement, err = Parse(query, WithDBMS(DBMSPostgres))
+	assert.NoError(t, err)
+	deleteStatement = statement.(*DeleteStatement)
+	assert.Equal(t, "o", deleteStatement.Table.Alias)
+	assert.Equal(t, []TableReference{{Name: "users", Alias: "u", Start: 27, End: 34}}, deleteStatement.Using)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query   string
+		message string
+		offset  int
+	}{
+		{query: "", message: "expected SELECT, INSERT, UPDATE or DELETE", offset: 0},
+		{query: "CREATE TABLE t (a int)", message: "expected SELECT, INSERT, UPDATE or DELETE", offset: 0},
+		{query: "SELECT a FROM", message: "expected a name", offset: 13},
+		{query: "SELECT a FROM t WHERE", message: "expected an expression", offset: 21},
+		{query: "SELECT a FROM t; SELECT b", message: `unexpected "SELECT"`, offset: 17},
+		{query: "UPDATE t SET a 1", message: `expected "="`, offset: 15},
+		{query: "INSERT INTO t (a VALUES (1)", message: `expected ")"`, offset: 17},
+		{query: "SELECT (a FROM t", message: `expected ")"`, offset: 16},
+		{query: "SELECT a FROM t WHERE f(x", message: `expected ")"`, offset: 25},
+		{query: "SELECT a FROM t WHERE a IN (1, (2)", message: `expected ")"`, offset: 34},
+		{query: "SELECT a FROM t FOR UPDATE OF (t", message: `expected ")"`, offset: 32},
+		{query: "INSERT INTO t (a) VALUES (1) ON CONFLICT (a DO NOTHING", message: `expected ")"`, offset: 54},
+		{query: `SELECT "a FROM t`, message: "unterminated quoted identifier", offset: 7},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			statement, err := Parse(test.query)
+			assert.Nil(t, statement)
+			assert.Equal(t, &ParseError{Message: test.message, Offset: test.offset}, err)
+		})
+	}
+}
+
+func ExampleParse() {
+	statement, err := Parse("SELECT id, name FROM users u JOIN orders o ON o.user_id = u.id WHERE o.total > 100 LIMIT 10")
+	if err != nil {
+		panic(err)
+	}
+	selectStatement := statement.(*SelectStatement)
+	fmt.Println(statement.Kind(), selectStatement.From[0].Name, selectStatement.Joins[0].Table.Name)
+	fmt.Println(selectStatement.Where.Text)
+	fmt.Println(selectStatement.Limit.Text)
+	// Output:
+	// SELECT users orders
+	// o.total > 100
+	// 10
+}
@@ -0,0 +1,19 @@
+module github.com/DataDog/go-sqllexer/pgxtracer
+
+go 1.21
+
+require (
+	github.com/DataDog/go-sqllexer v0.1.6
+	github.com/jackc/pgx/v5 v5.5.5
+	github.com/stretchr/testify v1.8.4
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20221227161230-091c0ba34f0a // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/crypto v0.17.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,111 @@
+// Package pgxtracer is a pgx v5 QueryTracer obfuscating, normalizing and fingerprinting the queries of a connection,
+// and attaching them to the context of the query, so traces and logs of Postgres queries never contain literals.
+//
+//	config.Tracer = pgxtracer.New(pgxtracer.WithNext(otherTracer))
+//
+// It is a separate module, so applications not using pgx do not depend on it.
+package pgxtracer
+
+import (
+	"context"
+
+	sqllexer "github.com/DataDog/go-sqllexer"
+	"github.com/jackc/pgx/v5"
+)
+
+// Statement is the sanitized form of a query
+type Statement struct {
+	// Query is the query as sent to pgx, literals included
+	Query string
+	// NormalizedSQL is the obfuscated and normalized query, safe to log
+	NormalizedSQL string
+	// Metadata is the metadata collected by the normalizer, e.g. the tables, nil if the query could not be normalized
+	Metadata *sqllexer.StatementMetadata
+	// Fingerprint is the fingerprint of the query, see sqllexer.Fingerprint
+	Fingerprint uint64
+}
+
+type statementKey struct{}
+
+// StatementFromContext returns the statement of the query traced by the context, or nil if there is none
+func StatementFromContext(ctx context.Context) *Statement {
+	statement, _ := ctx.Value(statementKey{}).(*Statement)
+	return statement
+}
+
+// Tracer is a pgx.QueryTracer. It is safe for concurrent use, so a single tracer can be shared by a pool.
+type Tracer struct {
+	obfuscator *sqllexer.Obfuscator
+	normalizer *sqllexer.Normalizer
+	next       pgx.QueryTracer
+}
+
+var _ pgx.QueryTracer = (*Tracer)(nil)
+
+type Option func(*Tracer)
+
+// WithObfuscator sets the obfuscator of the queries, NewObfuscator() by default
+func WithObfuscator(obfuscator *sqllexer.Obfuscator) Option {
+	return func(t *Tracer) {
+		t.obfuscator = obfuscator
+	}
+}
+
+// WithNormalizer sets the normalizer of the queries, collecting tables and commands by default
+func WithNormalizer(normalizer *sqllexer.Normalizer) Option {
+	return func(t *Tracer) {
+		t.normalizer = normalizer
+	}
+}
+
+// WithNext sets the tracer called with the sanitized query, e.g. a tracing or logging tracer.
+// It is passed the normalized SQL instead of the query, and no arguments.
+func WithNext(next pgx.QueryTracer) Option {
+	return func(t *Tracer) {
+		t.next = next
+	}
+}
+
+// New returns a tracer. Its obfuscator and normalizer are shared by every query, the normalizer reusing
+// its buffers across queries.
+func New(opts ...Option) *Tracer {
+	t := &Tracer{}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if t.obfuscator == nil {
+		t.obfuscator = sqllexer.NewObfuscator()
+	}
+	if t.normalizer == nil {
+		t.normalizer = sqllexer.NewNormalizer(sqllexer.WithCollectTables(true), sqllexer.WithCollectCommands(true))
+	}
+	return t
+}
+
+// Statement returns the sanitized form of the query
+func (t *Tracer) Statement(query string) *Statement {
+	result := sqllexer.Sanitize(query, t.obfuscator, t.normalizer, sqllexer.WithDBMS(sqllexer.DBMSPostgres))
+	return &Statement{
+		Query:         query,
+		NormalizedSQL: result.Normalized,
+		Metadata:      result.Metadata,
+		Fingerprint:   result.Fingerprint,
+	}
+}
+
+// TraceQueryStart attaches the statement of the query to the context, and calls the next tracer
+func (t *Tracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	statement := t.Statement(data.SQL)
+	ctx = context.WithValue(ctx, statementKey{}, statement)
+	if t.next != nil {
+		ctx = t.next.TraceQueryStart(ctx, conn, pgx.TraceQueryStartData{SQL: statement.NormalizedSQL})
+	}
+	return ctx
+}
+
+// TraceQueryEnd calls the next tracer
+func (t *Tracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	if t.next != nil {
+		t.next.TraceQueryEnd(ctx, conn, data)
+	}
+}
@@ -0,0 +1,92 @@
+package pgxtracer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	sqllexer "github.com/DataDog/go-sqllexer"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder records the calls of the next tracer
+type recorder struct {
+	starts []pgx.TraceQueryStartData
+	ends   []pgx.TraceQueryEndData
+	// statements are the statements of the contexts passed to the tracer
+	statements []*Statement
+}
+
+func (r *recorder) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	r.starts = append(r.starts, data)
+	r.statements = append(r.statements, StatementFromContext(ctx))
+	return ctx
+}
+
+func (r *recorder) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	r.ends = append(r.ends, data)
+	r.statements = append(r.statements, StatementFromContext(ctx))
+}
+
+func TestTracer(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected *Statement
+	}{
+		{
+			name:  "select",
+			input: "SELECT * FROM users WHERE name = 'alice' AND id = $1",
+			expected: &Statement{
+				NormalizedSQL: "SELECT * FROM users WHERE name = ? AND id = $1",
+				Metadata:      &sqllexer.StatementMetadata{Size: 11, Tables: []string{"users"}, Comments: []string{}, Commands: []string{"SELECT"}, Procedures: []string{}, StatementKind: sqllexer.StatementSelect},
+			},
+		},
+		{
+			name:  "dollar quoted string",
+			input: "UPDATE accounts SET note = $tag$secret$tag$",
+			expected: &Statement{
+				NormalizedSQL: "UPDATE accounts SET note = ?",
+				Metadata:      &sqllexer.StatementMetadata{Size: 14, Tables: []string{"accounts"}, Comments: []string{}, Commands: []string{"UPDATE"}, Procedures: []string{}, StatementKind: sqllexer.StatementUpdate},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &recorder{}
+			tracer := New(WithNext(next))
+			ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: tt.input, Args: []any{1}})
+			tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("fake error")})
+
+			tt.expected.Query = tt.input
+			tt.expected.Fingerprint = sqllexer.Fingerprint(tt.input, sqllexer.WithDBMS(sqllexer.DBMSPostgres))
+			assert.Equal(t, tt.expected, StatementFromContext(ctx))
+			// the next tracer never sees the literals nor the arguments
+			assert.Equal(t, []pgx.TraceQueryStartData{{SQL: tt.expected.NormalizedSQL}}, next.starts)
+			if assert.Len(t, next.ends, 1) {
+				assert.EqualError(t, next.ends[0].Err, "fake error")
+			}
+			assert.Equal(t, []*Statement{tt.expected, tt.expected}, next.statements)
+		})
+	}
+}
+
+func TestTracerWithoutNext(t *testing.T) {
+	tracer := New()
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "DELETE FROM sessions WHERE id = 42"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+	assert.Equal(t, "DELETE FROM sessions WHERE id = ?", StatementFromContext(ctx).NormalizedSQL)
+	assert.Nil(t, StatementFromContext(context.Background()))
+}
+
+func ExampleTracer_Statement() {
+	statement := New().Statement("SELECT name FROM users u JOIN orders o ON o.user_id = u.id WHERE o.total > 100")
+	fmt.Println(statement.NormalizedSQL)
+	fmt.Println(statement.Metadata.Tables)
+	// Output:
+	// SELECT name FROM users u JOIN orders o ON o.user_id = u.id WHERE o.total > ?
+	// [users orders]
+}
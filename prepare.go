@@ -0,0 +1,98 @@
+package sqllexer
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	DiagnosticMultipleStatements DiagnosticCode = "multiple_statements" // a statement following the first one
+	DiagnosticMixedDDLAndDML     DiagnosticCode = "mixed_ddl_and_dml"   // e.g. CREATE TABLE followed by INSERT
+	DiagnosticClientDirective    DiagnosticCode = "client_directive"    // e.g. USE, DELIMITER, GO or psql \ metacommands
+)
+
+// CheckPrepare returns the constructs of the query which prevent preparing it as a server-side prepared statement,
+// in order of position: statements following the first one, DDL statements mixed with DML statements,
+// and directives of client tools, which servers do not understand: USE, MySQL DELIMITER, SQL Server GO,
+// Oracle "/" lines and psql \ metacommands. A query which can be prepared has no diagnostics.
+func CheckPrepare(query string, lexerOpts ...lexerOption) []Diagnostic {
+	splitter := NewSplitter(lexerOpts...)
+	var diagnostics []Diagnostic
+
+	// psql metacommands extend to the end of their line, they are blanked so the statements do not include them
+	script := []byte(query)
+	lexer := New(query, lexerOpts...)
+	for {
+		token := lexer.Scan()
+		if token.Type == EOF {
+			break
+		}
+		if !isLineStart(query, token.Start) {
+			continue
+		}
+		// the directive line, without its newline
+		line := Token{Start: token.Start, End: token.Start + len(strings.TrimRight(query[token.Start:lineEnd(query, token.End)], "\r\n"))}
+		directive := ""
+		if _, ok := splitter.isSeparatorLine(query, token, token.End); ok {
+			directive = strings.TrimSpace(query[line.Start:line.End])
+		} else if _, _, ok := splitter.isDelimiterDirective(query, token, token.End); ok {
+			directive = "DELIMITER"
+		} else if strings.HasPrefix(token.Value, `\`) && (splitter.dbms == DBMSPostgres || splitter.dbms == "") {
+			directive = strings.TrimSpace(query[line.Start:line.End])
+			for i := line.Start; i < line.End; i++ {
+				script[i] = ' '
+			}
+		}
+		if directive != "" {
+			message := fmt.Sprintf("client directive %q", directive)
+			diagnostics = append(diagnostics, newDiagnostic(query, &line, DiagnosticClientDirective, message))
+		}
+	}
+
+	var firstKind StatementKind
+	mixed := false
+	for i, statement := range splitter.Split(string(script)) {
+		statementToken := Token{Start: statement.Start, End: statement.End}
+		if firstWord(statement.Text, lexerOpts) == "USE" {
+			diagnostics = append(diagnostics, newDiagnostic(query, &statementToken, DiagnosticClientDirective, `client directive "USE"`))
+		}
+		if i == 0 {
+			firstKind = Command(statement.Text, lexerOpts...)
+			continue
+		}
+		diagnostics = append(diagnostics, newDiagnostic(query, &statementToken, DiagnosticMultipleStatements, "multiple statements"))
+		if kind := Command(statement.Text, lexerOpts...); !mixed && isDDLAndDML(firstKind, kind) {
+			mixed = true
+			message := fmt.Sprintf("%s statement mixed with %s statement", kind, firstKind)
+			diagnostics = append(diagnostics, newDiagnostic(query, &statementToken, DiagnosticMixedDDLAndDML, message))
+		}
+	}
+	sortDiagnostics(diagnostics)
+	return diagnostics
+}
+
+// isDDLAndDML returns true if one of the kinds is DDL and the other one is DML
+func isDDLAndDML(a, b StatementKind) bool {
+	isDML := func(kind StatementKind) bool {
+		switch kind {
+		case StatementSelect, StatementInsert, StatementUpdate, StatementDelete, StatementMerge:
+			return true
+		}
+		return false
+	}
+	return (a == StatementDDL && isDML(b)) || (isDML(a) && b == StatementDDL)
+}
+
+// firstWord returns the uppercase value of the first value token of the statement
+func firstWord(statement string, lexerOpts []lexerOption) string {
+	lexer := New(statement, lexerOpts...)
+	for {
+		token := lexer.Scan()
+		if token.Type == EOF {
+			return ""
+		}
+		if isValueToken(token) {
+			return ToUpperASCII(token.Value)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package sqllexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckPrepare(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		lexerOpts []lexerOption
+		expected  []Diagnostic
+	}{
+		{
+			name:  "single statement",
+			input: "SELECT 'a;b' FROM t WHERE x = '\\c';",
+		},
+		{
+			name:  "multiple statements",
+			input: "SELECT 1; SELECT 2",
+			expected: []Diagnostic{
+				{Code: DiagnosticMultipleStatements, Message: "multiple statements", Start: 10, End: 18, Line: 1, Column: 11},
+			},
+		},
+		{
+			name:  "DDL mixed with DML",
+			input: "CREATE TABLE t (a int);\nINSERT INTO t VALUES (1)",
+			expected: []Diagnostic{
+				{Code: DiagnosticMultipleStatements, Message: "multiple statements", Start: 24, End: 48, Line: 2, Column: 1},
+				{Code: DiagnosticMixedDDLAndDML, Message: "INSERT statement mixed with DDL statement", Start: 24, End: 48, Line: 2, Column: 1},
+			},
+		},
+		{
+			name:      "psql metacommands",
+			input:     "\\c mydb\nSELECT 1",
+			lexerOpts: []lexerOption{WithDBMS(DBMSPostgres)},
+			expected: []Diagnostic{
+				{Code: DiagnosticClientDirective, Message: `client directive "\\c mydb"`, Start: 0, End: 7, Line: 1, Column: 1},
+			},
+		},
+		{
+			name:      "MySQL delimiter",
+			input:     "DELIMITER //\nCREATE PROCEDURE p() BEGIN SELECT 1; END //\nDELIMITER ;",
+			lexerOpts: []lexerOption{WithDBMS(DBMSMySQL)},
+			expected: []Diagnostic{
+				{Code: DiagnosticClientDirective, Message: `client directive "DELIMITER"`, Start: 0, End: 12, Line: 1, Column: 1},
+				{Code: DiagnosticClientDirective, Message: `client directive "DELIMITER"`, Start: 57, End: 68, Line: 3, Column: 1},
+			},
+		},
+		{
+			name:      "SQL Server USE and GO",
+			input:     "USE db\nGO\nSELECT 1",
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+			expected: []Diagnostic{
+				{Code: DiagnosticClientDirective, Message: `client directive "USE"`, Start: 0, End: 6, Line: 1, Column: 1},
+				{Code: DiagnosticClientDirective, Message: `client directive "GO"`, Start: 7, End: 9, Line: 2, Column: 1},
+				{Code: DiagnosticMultipleStatements, Message: "multiple statements", Start: 10, End: 18, Line: 3, Column: 1},
+			},
+		},
+		{
+			name:      "Oracle slash",
+			input:     "BEGIN NULL; END;\n/",
+			lexerOpts: []lexerOption{WithDBMS(DBMSOracle)},
+			expected: []Diagnostic{
+				{Code: DiagnosticClientDirective, Message: `client directive "/"`, Start: 17, End: 18, Line: 2, Column: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CheckPrepare(tt.input, tt.lexerOpts...))
+		})
+	}
+}
+
+func ExampleCheckPrepare() {
+	for _, diagnostic := range CheckPrepare("DROP TABLE tmp; SELECT * FROM users") {
+		fmt.Println(diagnostic)
+	}
+	// Output:
+	// 1:17: multiple statements
+	// 1:17: SELECT statement mixed with DDL statement
+}
@@ -0,0 +1,174 @@
+package sqllexer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// ProcessResult is the result of Process
+type ProcessResult struct {
+	// Obfuscated is the query obfuscated by the obfuscator, as returned by Obfuscator.Obfuscate
+	Obfuscated string `json:"obfuscated"`
+	// Normalized is the query obfuscated and normalized, as returned by ObfuscateAndNormalize
+	Normalized string             `json:"normalized"`
+	Metadata   *StatementMetadata `json:"metadata"`
+	// Fingerprint is the fingerprint of the query, as returned by Fingerprint
+	Fingerprint uint64 `json:"fingerprint"`
+}
+
+// Process returns the obfuscated query, the normalized query with its metadata and the fingerprint of the query.
+// The query is scanned once, each token being obfuscated, normalized and fingerprinted before the next one is scanned,
+// instead of once by Obfuscate, once by ObfuscateAndNormalize and once more by Fingerprint.
+func Process(query string, obfuscator *Obfuscator, normalizer *Normalizer, lexerOpts ...lexerOption) (result ProcessResult, err error) {
+	state := getNormalizerState(query, lexerOpts...)
+	defer putNormalizerState(state)
+	// the fingerprint is the hash of the query normalized by the fingerprint normalizer, in its own state
+	fingerprintState := getNormalizerState(query, lexerOpts...)
+	defer putNormalizerState(fingerprintState)
+	buffer := getObfuscatorBuffer()
+	obfuscatedSQL := (*buffer)[:0]
+	defer func() {
+		putObfuscatorBuffer(buffer, obfuscatedSQL)
+	}()
+
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = ProcessResult{}, fmt.Errorf("error processing SQL token: %v", r)
+		}
+	}()
+
+	// the fingerprint is timed as part of the normalization
+	timer, timed := newPhaseTimer(state.lexer.config.PhaseTimings)
+
+	metadata := normalizer.newMetadata()
+	fingerprintMetadata := fingerprintNormalizer.newMetadata()
+	normalizer.beginNormalization(state)
+	fingerprintNormalizer.beginNormalization(fingerprintState)
+
+	// the fingerprint token is a copy of the scanned token, its last value token is kept across tokens
+	// the same way the lexer keeps the one of the scanned token
+	var fingerprintToken Token
+	var lastValueToken, fingerprintLastValueToken *LastValueToken
+	// the whitespace of the obfuscated query is copied from the query between the tokens, which are scanned without it
+	base, end := state.lexer.baseOffset(), 0
+	summarizeInLists := normalizer.summarizesInLists(true)
+	inListStart := false
+	for {
+		var token *Token
+		inListLength := 0 // number of literals of the IN list scanned as a single token, if any
+		if inListStart {
+			token, inListLength = state.lexer.scanLiteralList()
+		}
+		if token == nil {
+			token = state.lexer.Scan()
+		}
+		last := fingerprintToken.lastValueToken
+		fingerprintToken = *token
+		fingerprintToken.lastValueToken = last
+		if timed {
+			timer.end(PhaseLex)
+		}
+
+		obfuscatedLength, normalizedLength := len(obfuscatedSQL), len(state.output)
+		normalizer.limitMetadata(state, obfuscatedLength+normalizedLength)
+		normalizer.collectInList(state, metadata, inListLength)
+		obfuscatedSQL = append(obfuscatedSQL, query[end:token.Start-base]...)
+		if inListLength > 0 {
+			// the obfuscated query keeps a placeholder for each literal of the list
+			obfuscatedSQL = state.lexer.appendObfuscatedLiteralList(obfuscatedSQL, query[token.Start-base:token.End-base])
+		}
+		end = token.End - base
+		obfuscator.ObfuscateTokenValue(token, lastValueToken, lexerOpts...)
+		if inListLength == 0 {
+			obfuscatedSQL = append(obfuscatedSQL, token.Value...)
+		}
+		if timed {
+			timer.end(PhaseObfuscate)
+		}
+		normalizer.normalizeNextToken(state, token, lastValueToken, &state.output, metadata, lexerOpts...)
+
+		fingerprintObfuscator.ObfuscateTokenValue(&fingerprintToken, fingerprintLastValueToken, lexerOpts...)
+		fingerprintNormalizer.normalizeNextToken(fingerprintState, &fingerprintToken, fingerprintLastValueToken, &fingerprintState.output, fingerprintMetadata, lexerOpts...)
+		if timed {
+			timer.end(PhaseNormalize)
+		}
+
+		if normalizer.exceedsMaxOutput(state, len(obfuscatedSQL)+len(state.output)) {
+			// the outputs end before the token, the fingerprint being the one of the statement up to it
+			obfuscatedSQL, state.output = obfuscatedSQL[:obfuscatedLength], state.output[:normalizedLength]
+			state.outputTruncated = true
+			break
+		}
+		if token.Type == EOF {
+			break
+		}
+		inListStart = summarizeInLists && isInListStart(token, lastValueToken)
+		if isValueToken(token) {
+			lastValueToken = token.getLastValueToken()
+			fingerprintLastValueToken = fingerprintToken.getLastValueToken()
+		}
+	}
+	normalizer.endNormalization(state, metadata)
+	if timed {
+		timer.report(OperationProcess)
+	}
+
+	metadata.Size = state.meta.size
+	normalizedSQL := normalizer.trimNormalizedSQL(string(state.output))
+	if normalizer.config.CollectOffsets {
+		metadata.Offsets = state.offsets.result(leadingSpaces(state.output), len(normalizedSQL))
+	}
+	return ProcessResult{
+		Obfuscated:  string(bytes.TrimSpace(obfuscatedSQL)),
+		Normalized:  normalizedSQL,
+		Metadata:    metadata,
+		Fingerprint: FingerprintNormalizedSQL(fingerprintNormalizer.trimNormalizedSQL(string(fingerprintState.output))),
+	}, nil
+}
+
+// Sanitize returns the result of Process, for integrations reporting queries to logs or traces.
+// The literals of a query which cannot be processed are never exposed: its normalized SQL is then
+// its obfuscated SQL, and its metadata is nil.
+func Sanitize(query string, obfuscator *Obfuscator, normalizer *Normalizer, lexerOpts ...lexerOption) ProcessResult {
+	result, err := Process(query, obfuscator, normalizer, lexerOpts...)
+	if err != nil {
+		obfuscated := obfuscator.Obfuscate(query, lexerOpts...)
+		result = ProcessResult{Obfuscated: obfuscated, Normalized: obfuscated, Fingerprint: Fingerprint(query, lexerOpts...)}
+	}
+	return result
+}
+
+// ProcessBatch processes the queries with Process across the given number of workers, GOMAXPROCS if it is not positive,
+// and returns their results in the order of the queries. The workers share the obfuscator, the normalizer
+// and the pooled lexers and buffers. The result of a query which fails is empty and its error is part of
+// the returned error.
+func ProcessBatch(queries []string, workers int, obfuscator *Obfuscator, normalizer *Normalizer, lexerOpts ...lexerOption) ([]ProcessResult, error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(queries))
+
+	results := make([]ProcessResult, len(queries))
+	errs := make([]error, len(queries))
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			// each worker takes the next query until there is none left
+			for i := int(next.Add(1) - 1); i < len(queries); i = int(next.Add(1) - 1) {
+				var err error
+				if results[i], err = Process(queries[i], obfuscator, normalizer, lexerOpts...); err != nil {
+					errs[i] = fmt.Errorf("query %d: %w", i, err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return results, errors.Join(errs...)
+}
@@ -0,0 +1,155 @@
+package sqllexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var processQueries = []string{
+	"SELECT * FROM users WHERE id = 1",
+	"  select u.id AS uid, o.total FROM users u JOIN orders o ON o.user_id = u.id WHERE o.total > 100.5 ORDER BY 2 DESC;  ",
+	"INSERT INTO logs (level, message) VALUES ('error', 'disk full'), ('info', 'started')",
+	"UPDATE \"Accounts\" SET balance = balance - 10 WHERE id IN (1, 2, 3) /* transfer */ AND name LIKE 'a\\_%' ESCAPE '\\'",
+	"WITH t AS (SELECT user1 FROM a2 WHERE x = $1) SELECT data->'key'->>0, true, NULL FROM t",
+	"CALL refresh_stats(42); -- nightly",
+	"SELECT * FROM t WHERE a IN ( -1,2 ,\n3) AND b NOT IN ('x, y', 'z') AND c IN (1, 'x')",
+	"SELECT 'unterminated",
+	"",
+}
+
+func TestProcessMatchesSeparatePasses(t *testing.T) {
+	tests := []struct {
+		name       string
+		obfuscator *Obfuscator
+		normalizer *Normalizer
+		lexerOpts  []lexerOption
+	}{
+		{
+			name:       "default",
+			obfuscator: NewObfuscator(),
+			normalizer: NewNormalizer(),
+		},
+		{
+			name:       "postgres with metadata",
+			obfuscator: NewObfuscator(WithReplaceDigits(true), WithKeepJsonPath(true)),
+			normalizer: NewNormalizer(
+				WithCollectTables(true),
+				WithCollectCommands(true),
+				WithCollectComments(true),
+				WithCollectProcedures(true),
+				WithCollectOrdering(true),
+				WithCollectOffsets(true),
+				WithCollectInLists(true),
+				WithUppercaseKeywords(true),
+				WithUnquoteSafeIdentifiers(true),
+				WithFoldIdentifierCase(IdentifierCaseAuto),
+			),
+			lexerOpts: []lexerOption{WithDBMS(DBMSPostgres)},
+		},
+		{
+			name:       "mysql",
+			obfuscator: NewObfuscator(WithReplaceBoolean(true), WithReplaceNull(true)),
+			normalizer: NewNormalizer(WithKeepSQLAlias(true), WithKeepTrailingSemicolon(true), WithCollectTables(true)),
+			lexerOpts:  []lexerOption{WithDBMS(DBMSMySQL)},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, query := range processQueries {
+			t.Run(tt.name+"/"+query, func(t *testing.T) {
+				result, err := Process(query, tt.obfuscator, tt.normalizer, tt.lexerOpts...)
+				assert.NoError(t, err)
+
+				normalized, metadata, err := ObfuscateAndNormalize(query, tt.obfuscator, tt.normalizer, tt.lexerOpts...)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.obfuscator.Obfuscate(query, tt.lexerOpts...), result.Obfuscated)
+				assert.Equal(t, normalized, result.Normalized)
+				assert.Equal(t, metadata, result.Metadata)
+				assert.Equal(t, Fingerprint(query, tt.lexerOpts...), result.Fingerprint)
+			})
+		}
+	}
+}
+
+func TestProcessBatch(t *testing.T) {
+	obfuscator := NewObfuscator()
+	normalizer := NewNormalizer(WithCollectTables(true), WithCollectCommands(true))
+	var queries []string
+	for i := 0; i < 50; i++ {
+		queries = append(queries, processQueries...)
+	}
+
+	for _, workers := range []int{0, 1, 4, 1000} {
+		t.Run(fmt.Sprint(workers), func(t *testing.T) {
+			results, err := ProcessBatch(queries, workers, obfuscator, normalizer)
+			assert.NoError(t, err)
+			assert.Len(t, results, len(queries))
+			for i, query := range queries {
+				expected, err := Process(query, obfuscator, normalizer)
+				assert.NoError(t, err)
+				assert.Equal(t, expected, results[i], query)
+			}
+		})
+	}
+
+	results, err := ProcessBatch(nil, 4, obfuscator, normalizer)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestSanitize(t *testing.T) {
+	obfuscator := NewObfuscator()
+	normalizer := NewNormalizer(WithCollectTables(true))
+	query := "SELECT * FROM users WHERE id = 1"
+	expected, err := Process(query, obfuscator, normalizer)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, Sanitize(query, obfuscator, normalizer))
+
+	// the query cannot be processed without a normalizer, it is only obfuscated
+	result := Sanitize(query, obfuscator, nil)
+	assert.Equal(t, ProcessResult{
+		Obfuscated:  "SELECT * FROM users WHERE id = ?",
+		Normalized:  "SELECT * FROM users WHERE id = ?",
+		Fingerprint: Fingerprint(query),
+	}, result)
+}
+
+func ExampleProcess() {
+	result, _ := Process("select id from users where name = 'alice';", NewObfuscator(), NewNormalizer(WithCollectTables(true)))
+	fmt.Println(result.Obfuscated)
+	fmt.Println(result.Normalized)
+	fmt.Println(result.Metadata.Tables)
+	fmt.Println(result.Fingerprint == Fingerprint("SELECT id FROM users WHERE name = 'bob'"))
+	// Output:
+	// select id from users where name = ?;
+	// select id from users where name = ?
+	// [users]
+	// true
+}
+
+func BenchmarkProcess(b *testing.B) {
+	obfuscator := NewObfuscator(WithReplaceDigits(true))
+	normalizer := NewNormalizer(WithCollectTables(true), WithCollectCommands(true), WithUppercaseKeywords(true))
+	query := processQueries[1] + processQueries[3]
+
+	b.Run("Fused", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := Process(query, obfuscator, normalizer); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("SeparatePasses", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			obfuscator.Obfuscate(query)
+			if _, _, err := ObfuscateAndNormalize(query, obfuscator, normalizer); err != nil {
+				b.Fatal(err)
+			}
+			Fingerprint(query)
+		}
+	})
+}
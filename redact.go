@@ -0,0 +1,86 @@
+package sqllexer
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+type redactConfig struct {
+	// LexerOptions are the options of the lexer of the SQL of the records, e.g. WithDBMS
+	LexerOptions []lexerOption `json:"-"`
+
+	// WholeLines specifies whether every record is SQL, instead of SQL being searched in the records
+	WholeLines bool `json:"whole_lines"`
+}
+
+type redactOption func(*redactConfig)
+
+func WithRedactLexerOptions(lexerOpts ...lexerOption) redactOption {
+	return func(c *redactConfig) {
+		c.LexerOptions = append(c.LexerOptions, lexerOpts...)
+	}
+}
+
+func WithRedactWholeLines(wholeLines bool) redactOption {
+	return func(c *redactConfig) {
+		c.WholeLines = wholeLines
+	}
+}
+
+// RedactLines reads newline-delimited log records from r and writes them to w with the literals of their SQL
+// obfuscated by the obfuscator, e.g. for log pipelines executing a filter process.
+// Each record is written as soon as it is read, in a single write, so records are not delayed by buffering.
+// The SQL of a record starts at its first command, e.g. SELECT, or WITH, and ends with the record,
+// unless WithRedactWholeLines is set. Records without SQL are written unchanged.
+// SQL quoted inside a record, e.g. a JSON string field, is not found, WithRedactWholeLines must be used instead.
+func RedactLines(r io.Reader, w io.Writer, obfuscator *Obfuscator, opts ...redactOption) error {
+	config := &redactConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			if _, writeErr := io.WriteString(w, config.redactLine(line, obfuscator)); writeErr != nil {
+				return writeErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// redactLine returns the record with the literals of its SQL obfuscated
+func (c *redactConfig) redactLine(line string, obfuscator *Obfuscator) string {
+	record := strings.TrimRight(line, "\r\n")
+	newline := line[len(record):]
+	start := 0
+	if !c.WholeLines {
+		start = sqlStart(record, c.LexerOptions)
+		if start < 0 {
+			return line
+		}
+	}
+	return record[:start] + obfuscator.Obfuscate(record[start:], c.LexerOptions...) + newline
+}
+
+// sqlStart returns the byte offset of the first command of the record, or -1 if it has none
+func sqlStart(record string, lexerOpts []lexerOption) int {
+	lexer := New(record, lexerOpts...)
+	for {
+		token := lexer.Scan()
+		switch token.Type {
+		case EOF:
+			return -1
+		case COMMAND, CTE_INDICATOR:
+			return token.Start
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package sqllexer
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     []redactOption
+		expected string
+	}{
+		{
+			name:     "sql in records",
+			input:    "2024-01-02 LOG: duration: 1 ms statement: SELECT * FROM users WHERE name = 'alice'\nno sql here\r\nERROR: UPDATE t SET a = 42",
+			expected: "2024-01-02 LOG: duration: 1 ms statement: SELECT * FROM users WHERE name = ?\nno sql here\r\nERROR: UPDATE t SET a = ?",
+		},
+		{
+			name:     "whole lines",
+			input:    "SELECT 1\n(SELECT 'a')\n",
+			opts:     []redactOption{WithRedactWholeLines(true)},
+			expected: "SELECT ?\n(SELECT ?)\n",
+		},
+		{
+			name:     "dbms",
+			input:    "query: SELECT $tag$ secret $tag$\n",
+			opts:     []redactOption{WithRedactLexerOptions(WithDBMS(DBMSPostgres))},
+			expected: "query: SELECT ?\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			err := RedactLines(strings.NewReader(tt.input), &output, NewObfuscator(), tt.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output.String())
+		})
+	}
+}
+
+func ExampleRedactLines() {
+	logs := "app=api msg=\"slow query\" SELECT * FROM users WHERE email = 'bob@example.com'\n"
+	_ = RedactLines(strings.NewReader(logs), os.Stdout, NewObfuscator())
+	// Output: app=api msg="slow query" SELECT * FROM users WHERE email = ?
+}
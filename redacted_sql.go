@@ -0,0 +1,39 @@
+package sqllexer
+
+// RedactedQuery is a query obfuscated only when it is formatted, e.g. by a logger emitting a log record,
+// so the query of a log record filtered out, e.g. by its level, is never obfuscated.
+// It is a fmt.Stringer, e.g. for zap.Stringer, a logr.Marshaler, and a slog.LogValuer.
+type RedactedQuery struct {
+	query      string
+	obfuscator *Obfuscator
+	lexerOpts  []lexerOption
+}
+
+var defaultRedactObfuscator = NewObfuscator()
+
+// RedactedSQL returns the query obfuscated by NewObfuscator() when it is formatted
+//
+//	logger.Debug("executing query", zap.Stringer("sql", sqllexer.RedactedSQL(query)))
+//	slog.Debug("executing query", "sql", sqllexer.RedactedSQL(query))
+func RedactedSQL(query string, lexerOpts ...lexerOption) RedactedQuery {
+	return defaultRedactObfuscator.Redacted(query, lexerOpts...)
+}
+
+// Redacted returns the query obfuscated by the obfuscator when it is formatted
+func (o *Obfuscator) Redacted(query string, lexerOpts ...lexerOption) RedactedQuery {
+	return RedactedQuery{query: query, obfuscator: o, lexerOpts: lexerOpts}
+}
+
+// String returns the obfuscated query
+func (q RedactedQuery) String() string {
+	if q.obfuscator == nil {
+		// the zero value has no query
+		return ""
+	}
+	return q.obfuscator.Obfuscate(q.query, q.lexerOpts...)
+}
+
+// MarshalLog returns the obfuscated query, it implements logr.Marshaler
+func (q RedactedQuery) MarshalLog() any {
+	return q.String()
+}
@@ -0,0 +1,10 @@
+//go:build go1.21
+
+package sqllexer
+
+import "log/slog"
+
+// LogValue returns the obfuscated query, it implements slog.LogValuer
+func (q RedactedQuery) LogValue() slog.Value {
+	return slog.StringValue(q.String())
+}
@@ -0,0 +1,27 @@
+//go:build go1.21
+
+package sqllexer
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactedSQLLogValue(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buffer, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	}))
+
+	logger.Info("query", "sql", RedactedSQL("SELECT * FROM users WHERE email = 'bob@example.com'"))
+	logger.Debug("filtered out", "sql", RedactedSQL("SELECT 1"))
+	assert.Equal(t, "level=INFO msg=query sql=\"SELECT * FROM users WHERE email = ?\"\n", buffer.String())
+}
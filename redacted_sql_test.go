@@ -0,0 +1,54 @@
+package sqllexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactedSQL(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		lexerOpts []lexerOption
+		expected  string
+	}{
+		{
+			name:     "literals",
+			input:    "SELECT * FROM users WHERE name = 'alice' AND age > 30",
+			expected: "SELECT * FROM users WHERE name = ? AND age > ?",
+		},
+		{
+			name:      "dollar quoted string",
+			input:     "SELECT $tag$secret$tag$",
+			lexerOpts: []lexerOption{WithDBMS(DBMSPostgres)},
+			expected:  "SELECT ?",
+		},
+		{
+			name:     "empty query",
+			input:    "",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redacted := RedactedSQL(tt.input, tt.lexerOpts...)
+			assert.Equal(t, tt.expected, redacted.String())
+			assert.Equal(t, tt.expected, redacted.MarshalLog())
+			assert.Equal(t, tt.expected, fmt.Sprint(redacted))
+		})
+	}
+}
+
+func TestObfuscatorRedacted(t *testing.T) {
+	obfuscator := NewObfuscator(WithReplaceDigits(true))
+	assert.Equal(t, "SELECT * FROM orders_? WHERE id = ?", obfuscator.Redacted("SELECT * FROM orders_2024 WHERE id = 7").String())
+	assert.Equal(t, "", RedactedQuery{}.String())
+}
+
+func ExampleRedactedSQL() {
+	fmt.Printf("executing %s\n", RedactedSQL("UPDATE users SET password = 'hunter2' WHERE id = 42"))
+	// Output: executing UPDATE users SET password = ? WHERE id = ?
+}
@@ -0,0 +1,211 @@
+package sqllexer
+
+import "strings"
+
+// identifierPart is a part of a dotted name, e.g. "public" and users of "public".users
+type identifierPart struct {
+	name  string // unquoted name
+	quote byte   // opening quote of the part, 0 if it is not quoted
+}
+
+// renaming is a name to rename and its new name
+type renaming struct {
+	from   []identifierPart
+	to     []identifierPart
+	prefix bool // true if from is only renamed when followed by other parts, e.g. old_schema.
+}
+
+// RenameIdentifiers returns the query with its identifiers renamed according to the mapping of old to new dotted
+// names. A name renames the identifiers starting with its parts, e.g. "users": "customers" renames users,
+// public.users is not renamed but users.id is renamed to customers.id, and a name ending with a dot only renames
+// the identifiers it qualifies, e.g. "old_schema.": "new_schema." renames old_schema.users to new_schema.users.
+// The longest matching name wins. Unquoted parts are matched case-insensitively and quoted parts exactly.
+//
+// Identifiers, quoted identifiers and function names are renamed, but not strings and comments.
+// New parts are quoted like the parts they replace, or with the quotes of the DBMS, e.g. backticks for MySQL,
+// when they would not mean the same unquoted, e.g. keywords or mixed case names for PostgreSQL.
+func RenameIdentifiers(query string, mapping map[string]string, lexerOpts ...lexerOption) string {
+	renamings := make([]renaming, 0, len(mapping))
+	for from, to := range mapping {
+		r := renaming{from: splitIdentifier(strings.TrimSuffix(from, ".")), to: splitIdentifier(strings.TrimSuffix(to, "."))}
+		r.prefix = strings.HasSuffix(from, ".")
+		if len(r.from) > 0 {
+			renamings = append(renamings, r)
+		}
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(query))
+	lexer := New(query, lexerOpts...)
+	dbms := lexer.config.DBMS
+	start, end := -1, -1 // span of the identifier tokens of the current name
+	flush := func() {
+		if start >= 0 {
+			builder.WriteString(renameIdentifier(query[start:end], renamings, dbms))
+			start, end = -1, -1
+		}
+	}
+	for {
+		token := lexer.Scan()
+		if token.Type == EOF {
+			flush()
+			return builder.String()
+		}
+		switch {
+		case token.Type == IDENT || token.Type == QUOTED_IDENT || token.Type == FUNCTION:
+			// adjacent tokens make a single name, e.g. "public".users
+			if token.Start != end {
+				flush()
+				start = token.Start
+			}
+			end = token.End
+		case token.Type == PUNCTUATION && token.Value == "." && token.Start == end:
+			// e.g. the dot of "public".users
+			end = token.End
+		default:
+			flush()
+			builder.WriteString(query[token.Start:token.End])
+		}
+	}
+}
+
+// renameIdentifier returns the identifier renamed by the longest matching renaming, or the identifier if none matches
+func renameIdentifier(ident string, renamings []renaming, dbms DBMSType) string {
+	parts := splitIdentifier(ident)
+	var best *renaming
+	for i := range renamings {
+		r := &renamings[i]
+		if len(r.from) > len(parts) || (r.prefix && len(r.from) == len(parts)) {
+			continue
+		}
+		if best != nil && len(r.from) <= len(best.from) {
+			continue
+		}
+		matches := true
+		for j, part := range r.from {
+			if !part.matches(parts[j]) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			best = r
+		}
+	}
+	if best == nil {
+		return ident
+	}
+
+	var builder strings.Builder
+	for i, part := range best.to {
+		if i > 0 {
+			builder.WriteByte('.')
+		}
+		// new parts are quoted like the parts they replace
+		quote := parts[min(i, len(best.from)-1)].quote
+		if quote == 0 && !isSafeUnquotedIdentifier(part.name, dbms) {
+			quote = identifierQuote(dbms)
+		}
+		builder.WriteString(quoteIdentifierPart(part.name, quote))
+	}
+	for _, part := range parts[len(best.from):] {
+		builder.WriteByte('.')
+		builder.WriteString(quoteIdentifierPart(part.name, part.quote))
+	}
+	if strings.HasSuffix(ident, ".") {
+		// e.g. users. of users.*
+		builder.WriteByte('.')
+	}
+	return builder.String()
+}
+
+func (p identifierPart) matches(other identifierPart) bool {
+	if p.quote == 0 && other.quote == 0 {
+		return strings.EqualFold(p.name, other.name)
+	}
+	return p.name == other.name
+}
+
+// splitIdentifier returns the parts of a dotted name, e.g. "public", users and [id] of "public".users.[id]
+func splitIdentifier(ident string) []identifierPart {
+	var parts []identifierPart
+	for i := 0; i < len(ident); {
+		var closing byte
+		switch ident[i] {
+		case '"', '`':
+			closing = ident[i]
+		case '[':
+			closing = ']'
+		default:
+			end := strings.IndexByte(ident[i:], '.')
+			if end < 0 {
+				end = len(ident) - i
+			}
+			parts = append(parts, identifierPart{name: ident[i : i+end]})
+			i += end + 1
+			continue
+		}
+		// quotes are escaped by doubling them
+		var name strings.Builder
+		j := i + 1
+		for ; j < len(ident); j++ {
+			if ident[j] == closing {
+				if j+1 < len(ident) && ident[j+1] == closing {
+					j++
+				} else {
+					break
+				}
+			}
+			name.WriteByte(ident[j])
+		}
+		parts = append(parts, identifierPart{name: name.String(), quote: ident[i]})
+		i = j + 2 // skip the closing quote and the dot
+	}
+	return parts
+}
+
+// nameEnd returns the index of the last token of the name starting at tokens[i]: adjacent identifiers, functions
+// and dots make a single name, e.g. "public".users, or public. and "Orders" as public."Orders" is lexed
+func nameEnd(tokens []Token, i int) int {
+	end := i
+	for end+1 < len(tokens) && tokens[end+1].Start == tokens[end].End && isNamePart(&tokens[end+1]) &&
+		tokens[end].Type != FUNCTION {
+		end++
+	}
+	return end
+}
+
+// isNamePart returns true if the token may be a part of a dotted name, or one of its dots
+func isNamePart(token *Token) bool {
+	switch token.Type {
+	case IDENT, QUOTED_IDENT, FUNCTION:
+		return true
+	case PUNCTUATION:
+		return token.Value == "."
+	}
+	return false
+}
+
+// identifierQuote returns the opening quote of the identifiers of the DBMS
+func identifierQuote(dbms DBMSType) byte {
+	switch dbms {
+	case DBMSMySQL:
+		return '`'
+	case DBMSSQLServer:
+		return '['
+	}
+	return '"'
+}
+
+// quoteIdentifierPart returns the name quoted with the opening quote, or the name if the quote is 0
+func quoteIdentifierPart(name string, quote byte) string {
+	if quote == 0 {
+		return name
+	}
+	closing := quote
+	if quote == '[' {
+		closing = ']'
+	}
+	escaped := strings.ReplaceAll(name, string(closing), string([]byte{closing, closing}))
+	return string(quote) + escaped + string(closing)
+}
@@ -0,0 +1,85 @@
+package sqllexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenameIdentifiers(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		mapping   map[string]string
+		lexerOpts []lexerOption
+		expected  string
+	}{
+		{
+			name:     "table",
+			input:    "SELECT users.id, u.name FROM users u JOIN public.users p ON p.id = u.id WHERE x = 'users' -- users",
+			mapping:  map[string]string{"users": "customers"},
+			expected: "SELECT customers.id, u.name FROM customers u JOIN public.users p ON p.id = u.id WHERE x = 'users' -- users",
+		},
+		{
+			name:     "schema prefix",
+			input:    "SELECT old_schema.fn(1), old_schema FROM old_schema.users, \"old_schema\".orders",
+			mapping:  map[string]string{"old_schema.": "new_schema."},
+			expected: "SELECT new_schema.fn(1), old_schema FROM new_schema.users, \"new_schema\".orders",
+		},
+		{
+			name:     "longest match",
+			input:    "SELECT * FROM s.users JOIN s.orders ON true",
+			mapping:  map[string]string{"s.": "t.", "s.users": "u.customers"},
+			expected: "SELECT * FROM u.customers JOIN t.orders ON true",
+		},
+		{
+			name:     "case",
+			input:    `SELECT * FROM USERS, "USERS", "users"`,
+			mapping:  map[string]string{"users": "customers"},
+			expected: `SELECT * FROM customers, "USERS", "customers"`,
+		},
+		{
+			name:     "wildcard",
+			input:    `SELECT users.*, "users".* FROM users`,
+			mapping:  map[string]string{"users": "customers"},
+			expected: `SELECT customers.*, "customers".* FROM customers`,
+		},
+		{
+			name:      "postgres quoting",
+			input:     "SELECT * FROM users",
+			mapping:   map[string]string{"users": "public.Users"},
+			lexerOpts: []lexerOption{WithDBMS(DBMSPostgres)},
+			expected:  `SELECT * FROM public."Users"`,
+		},
+		{
+			name:      "mysql quoting",
+			input:     "SELECT * FROM `users` JOIN orders",
+			mapping:   map[string]string{"users": "user list", "orders": "order"},
+			lexerOpts: []lexerOption{WithDBMS(DBMSMySQL)},
+			expected:  "SELECT * FROM `user list` JOIN `order`",
+		},
+		{
+			name:      "sql server quoting",
+			input:     "SELECT * FROM [dbo].[users] JOIN dbo.orders",
+			mapping:   map[string]string{"dbo.": "sales."},
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+			expected:  "SELECT * FROM [sales].[users] JOIN sales.orders",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RenameIdentifiers(tt.input, tt.mapping, tt.lexerOpts...))
+		})
+	}
+}
+
+func ExampleRenameIdentifiers() {
+	renamed := RenameIdentifiers(
+		"SELECT * FROM blue.users JOIN blue.orders ON orders.user_id = users.id",
+		map[string]string{"blue.": "green."},
+	)
+	fmt.Println(renamed)
+	// Output: SELECT * FROM green.users JOIN green.orders ON orders.user_id = users.id
+}
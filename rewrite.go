@@ -0,0 +1,27 @@
+package sqllexer
+
+import "strings"
+
+// Rewrite visits the tokens of the query, including spaces and comments, and returns the query made of the tokens
+// returned by fn for each of them: returning the token keeps it, returning other tokens replaces it, and returning
+// none drops it. Kept tokens are written with the bytes of the query, so a query whose tokens are all kept is
+// returned unchanged, while replacing tokens are written with their Value, their Type, Start and End being ignored.
+// Spaces are not added around replacing tokens, nor where tokens are dropped.
+func Rewrite(query string, fn func(Token) []Token, lexerOpts ...lexerOption) string {
+	var builder strings.Builder
+	builder.Grow(len(query))
+	lexer := New(query, lexerOpts...)
+	for {
+		token := lexer.Scan()
+		if token.Type == EOF {
+			return builder.String()
+		}
+		for _, rewritten := range fn(*token) {
+			if rewritten.Type == token.Type && rewritten.Value == token.Value && rewritten.Start == token.Start && rewritten.End == token.End {
+				builder.WriteString(query[token.Start:token.End])
+			} else {
+				builder.WriteString(rewritten.Value)
+			}
+		}
+	}
+}
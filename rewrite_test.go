@@ -0,0 +1,88 @@
+package sqllexer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		fn        func(Token) []Token
+		lexerOpts []lexerOption
+		expected  string
+	}{
+		{
+			name:     "identity",
+			input:    "SELECT  a, 'b''c' /* d */ FROM t -- e\nWHERE x = $1",
+			fn:       func(token Token) []Token { return []Token{token} },
+			expected: "SELECT  a, 'b''c' /* d */ FROM t -- e\nWHERE x = $1",
+		},
+		{
+			name:  "replace",
+			input: "select a from t",
+			fn: func(token Token) []Token {
+				if token.Type == COMMAND || token.Type == KEYWORD {
+					return []Token{{Type: token.Type, Value: strings.ToUpper(token.Value)}}
+				}
+				return []Token{token}
+			},
+			expected: "SELECT a FROM t",
+		},
+		{
+			name:  "drop",
+			input: "SELECT a /* comment */ FROM t",
+			fn: func(token Token) []Token {
+				if token.Type == MULTILINE_COMMENT {
+					return nil
+				}
+				return []Token{token}
+			},
+			expected: "SELECT a  FROM t",
+		},
+		{
+			name:  "expand",
+			input: "SELECT * FROM t",
+			fn: func(token Token) []Token {
+				if token.Type == WILDCARD {
+					return []Token{{Type: IDENT, Value: "a"}, {Type: PUNCTUATION, Value: ","}, {Type: SPACE, Value: " "}, {Type: IDENT, Value: "b"}}
+				}
+				return []Token{token}
+			},
+			expected: "SELECT a, b FROM t",
+		},
+		{
+			name:      "dbms",
+			input:     "SELECT `a` FROM t",
+			lexerOpts: []lexerOption{WithDBMS(DBMSMySQL)},
+			fn: func(token Token) []Token {
+				if token.Type == QUOTED_IDENT {
+					return []Token{{Type: QUOTED_IDENT, Value: `"a"`}}
+				}
+				return []Token{token}
+			},
+			expected: `SELECT "a" FROM t`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Rewrite(tt.input, tt.fn, tt.lexerOpts...))
+		})
+	}
+}
+
+func ExampleRewrite() {
+	rewritten := Rewrite("SELECT * FROM users WHERE id = 1", func(token Token) []Token {
+		if token.Type == NUMBER {
+			return []Token{{Type: OPERATOR, Value: "?"}}
+		}
+		return []Token{token}
+	})
+	fmt.Println(rewritten)
+	// Output: SELECT * FROM users WHERE id = ?
+}
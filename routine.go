@@ -0,0 +1,133 @@
+package sqllexer
+
+import "strings"
+
+// RoutineBody is the body of a routine declared by a CREATE FUNCTION, PROCEDURE or TRIGGER statement
+type RoutineBody struct {
+	Text     string `json:"text"`               // body, without its dollar quotes if any
+	Language string `json:"language,omitempty"` // language of the LANGUAGE clause, e.g. plpgsql, empty if none
+	Start    int    `json:"start"`              // byte offset of the body in the statement
+	End      int    `json:"end"`                // byte offset following the body in the statement
+}
+
+// routineBodyWords are the objects whose CREATE statement may declare a routine body
+var routineBodyWords = []string{"FUNCTION", "PROCEDURE", "PROC", "TRIGGER"}
+
+// ExtractRoutineBody returns the body of the routine declared by the CREATE FUNCTION, PROCEDURE or TRIGGER
+// statement, so that it can be lexed on its own. The body is either dollar quoted, e.g. AS $$ ... $$,
+// or a BEGIN ... END block. It returns false if the statement declares no routine or the routine has no body,
+// e.g. a PostgreSQL trigger executing a function.
+func ExtractRoutineBody(statement string, lexerOpts ...lexerOption) (RoutineBody, bool) {
+	lexer := New(statement, lexerOpts...)
+	var body RoutineBody
+	found := false
+	inRoutine := false
+	words := 0 // number of tokens preceding the routine word
+	parenDepth := 0
+	expectLanguage := false
+
+	for {
+		token := lexer.Scan()
+		if token.Type == EOF {
+			break
+		}
+		if !token.IsSignificant() {
+			continue
+		}
+		if token.Type == PUNCTUATION {
+			if token.Value == "(" {
+				parenDepth++
+			} else if token.Value == ")" && parenDepth > 0 {
+				parenDepth--
+			} else if token.Value == ";" && parenDepth == 0 {
+				break
+			}
+			continue
+		}
+
+		if !inRoutine {
+			// e.g. CREATE OR REPLACE DEFINER = admin FUNCTION
+			words++
+			if words == 1 && !isWord(token, "CREATE") || words > 8 {
+				return RoutineBody{}, false
+			}
+			inRoutine = isRoutineBodyWord(token)
+			continue
+		}
+		if parenDepth > 0 {
+			continue
+		}
+
+		switch {
+		case expectLanguage:
+			expectLanguage = false
+			body.Language = strings.Trim(token.Value, `'"`)
+		case isWord(token, "LANGUAGE"):
+			// the LANGUAGE clause may follow the body
+			expectLanguage = true
+		case found:
+		case token.Type == DOLLAR_QUOTED_STRING || token.Type == DOLLAR_QUOTED_FUNCTION:
+			tagLen := strings.IndexByte(token.Value[1:], '$') + 2
+			body.Start, body.End = token.Start+tagLen, token.End-tagLen
+			found = true
+		case isWord(token, "BEGIN"):
+			body.Start = token.Start // read before the lexer reuses the token
+			body.End = routineBlockEnd(lexer, statement)
+			found = true
+		}
+	}
+
+	if !found {
+		return RoutineBody{}, false
+	}
+	body.Text = statement[body.Start:body.End]
+	return body, true
+}
+
+// isRoutineBodyWord returns true if the token is one of the routineBodyWords
+func isRoutineBodyWord(token *Token) bool {
+	for _, word := range routineBodyWords {
+		if isWord(token, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// routineBlockEnd reads the BEGIN ... END block whose BEGIN was just scanned, and returns the byte offset
+// following its END, or following its last token if the block is not closed
+func routineBlockEnd(lexer *Lexer, statement string) int {
+	depth := 1
+	end := lexer.cursor
+	afterEnd := false // true after an END, e.g. END CASE closes a CASE rather than opening one
+	for {
+		token := lexer.Scan()
+		if token.Type == EOF {
+			return end
+		}
+		if !token.IsSignificant() {
+			continue
+		}
+		end = token.End
+
+		switch {
+		case isWord(token, "BEGIN"):
+			if !isTransactionBegin(statement, token.End) {
+				depth++
+			}
+		case isWord(token, "CASE"):
+			if !afterEnd {
+				depth++
+			}
+		case isWord(token, "END"):
+			// END IF, END LOOP, ... close control structures which are not counted
+			if !controlEndWords[nextWord(statement, token.End)] {
+				depth--
+			}
+			if depth == 0 {
+				return end
+			}
+		}
+		afterEnd = isWord(token, "END")
+	}
+}
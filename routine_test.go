@@ -0,0 +1,84 @@
+package sqllexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractRoutineBody(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		lexerOpts []lexerOption
+		text      string
+		language  string
+		ok        bool
+	}{
+		{
+			name:     "postgres dollar quoted",
+			input:    "CREATE OR REPLACE FUNCTION f(a int) RETURNS int AS $$ SELECT a + 1; $$ LANGUAGE sql;",
+			text:     " SELECT a + 1; ",
+			language: "sql",
+			ok:       true,
+		},
+		{
+			name:     "postgres tagged dollar quotes and leading language",
+			input:    "CREATE FUNCTION f() RETURNS trigger LANGUAGE 'plpgsql' AS $body$\nBEGIN\n  RETURN NEW;\nEND;\n$body$",
+			text:     "\nBEGIN\n  RETURN NEW;\nEND;\n",
+			language: "plpgsql",
+			ok:       true,
+		},
+		{
+			name:      "mysql procedure",
+			input:     "CREATE DEFINER = admin PROCEDURE p(IN n INT) BEGIN IF n > 0 THEN SELECT 1; END IF; CASE n WHEN 1 THEN SELECT 2; END CASE; END",
+			lexerOpts: []lexerOption{WithDBMS(DBMSMySQL)},
+			text:      "BEGIN IF n > 0 THEN SELECT 1; END IF; CASE n WHEN 1 THEN SELECT 2; END CASE; END",
+			ok:        true,
+		},
+		{
+			name:      "mysql trigger",
+			input:     "CREATE TRIGGER trg BEFORE INSERT ON t FOR EACH ROW BEGIN SET NEW.a = 1; END",
+			lexerOpts: []lexerOption{WithDBMS(DBMSMySQL)},
+			text:      "BEGIN SET NEW.a = 1; END",
+			ok:        true,
+		},
+		{
+			name:      "sql server nested blocks",
+			input:     "CREATE PROC p AS BEGIN BEGIN TRY BEGIN TRAN; SELECT CASE WHEN 1 = 1 THEN 1 END; COMMIT; END TRY BEGIN CATCH ROLLBACK; END CATCH END",
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+			text:      "BEGIN BEGIN TRY BEGIN TRAN; SELECT CASE WHEN 1 = 1 THEN 1 END; COMMIT; END TRY BEGIN CATCH ROLLBACK; END CATCH END",
+			ok:        true,
+		},
+		{
+			name:  "unterminated block",
+			input: "CREATE PROCEDURE p() BEGIN SELECT 1; ",
+			text:  "BEGIN SELECT 1;",
+			ok:    true,
+		},
+		{
+			name:  "postgres trigger without body",
+			input: "CREATE TRIGGER trg BEFORE INSERT ON t FOR EACH ROW EXECUTE FUNCTION f()",
+		},
+		{
+			name:  "not a routine",
+			input: "CREATE TABLE t (a int DEFAULT 1)",
+		},
+		{
+			name:  "not a create statement",
+			input: "SELECT $$ BEGIN $$",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, ok := ExtractRoutineBody(tt.input, tt.lexerOpts...)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.text, body.Text)
+			assert.Equal(t, tt.language, body.Language)
+			if ok {
+				assert.Equal(t, tt.input[body.Start:body.End], body.Text)
+			}
+		})
+	}
+}
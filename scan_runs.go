@@ -0,0 +1,27 @@
+//go:build !sqllexer_swar
+
+package sqllexer
+
+import "unicode/utf8"
+
+// skipSpaces moves the cursor past the run of spaces at the cursor
+func (s *Lexer) skipSpaces() {
+	for s.cursor < len(s.src) && isSpace(rune(s.src[s.cursor])) {
+		s.cursor++
+	}
+}
+
+// skipASCIIIdentifier moves the cursor past the run of ASCII identifier characters at the cursor,
+// recording the offsets of its digits relative to offset
+func (s *Lexer) skipASCIIIdentifier(offset int) {
+	for s.cursor < len(s.src) {
+		b := s.src[s.cursor]
+		if b >= utf8.RuneSelf || !isIdentifier(rune(b)) {
+			return
+		}
+		if isDigit(rune(b)) {
+			s.digits = append(s.digits, s.cursor-offset)
+		}
+		s.cursor++
+	}
+}
@@ -0,0 +1,94 @@
+//go:build sqllexer_swar
+
+package sqllexer
+
+import (
+	"math/bits"
+	"unicode/utf8"
+)
+
+// With the sqllexer_swar build tag, the runs of spaces and of ASCII identifier characters are scanned
+// eight bytes at a time, classifying the bytes of a 64-bit word at once (SIMD within a register).
+// This is faster on inputs with long runs, e.g. indented dumps, and portable to every architecture.
+
+const (
+	swarOnes  = 0x0101010101010101
+	swarHighs = 0x8080808080808080
+)
+
+// swarWord returns the eight bytes of s at i as a little-endian word, the first byte being the lowest
+func swarWord(s string, i int) uint64 {
+	_ = s[i+7]
+	return uint64(s[i]) | uint64(s[i+1])<<8 | uint64(s[i+2])<<16 | uint64(s[i+3])<<24 |
+		uint64(s[i+4])<<32 | uint64(s[i+5])<<40 | uint64(s[i+6])<<48 | uint64(s[i+7])<<56
+}
+
+// swarBetween returns the high bit of every byte of the word between lo and hi included, which are ASCII
+func swarBetween(word uint64, lo, hi byte) uint64 {
+	low := word &^ swarHighs
+	atLeastLo := low + swarOnes*uint64(0x80-lo)
+	aboveHi := low + swarOnes*uint64(0x7f-hi)
+	// bytes with their high bit set are not ASCII
+	return atLeastLo &^ aboveHi &^ word & swarHighs
+}
+
+// swarSpaces returns the high bit of every space byte of the word
+func swarSpaces(word uint64) uint64 {
+	return swarBetween(word, '\t', '\r') | swarBetween(word, ' ', ' ')
+}
+
+// swarIdentifiers returns the high bit of every ASCII identifier byte of the word:
+// !, # and $, . / and digits, @ and uppercase letters, _ and lowercase letters
+func swarIdentifiers(word uint64) uint64 {
+	return swarBetween(word, '!', '!') | swarBetween(word, '#', '$') | swarBetween(word, '.', '9') |
+		swarBetween(word, '@', 'Z') | swarBetween(word, '_', '_') | swarBetween(word, 'a', 'z')
+}
+
+// skipSpaces moves the cursor past the run of spaces at the cursor
+func (s *Lexer) skipSpaces() {
+	for s.cursor+8 <= len(s.src) {
+		if others := ^swarSpaces(swarWord(s.src, s.cursor)) & swarHighs; others != 0 {
+			s.cursor += bits.TrailingZeros64(others) / 8
+			return
+		}
+		s.cursor += 8
+	}
+	for s.cursor < len(s.src) && isSpace(rune(s.src[s.cursor])) {
+		s.cursor++
+	}
+}
+
+// skipASCIIIdentifier moves the cursor past the run of ASCII identifier characters at the cursor,
+// recording the offsets of its digits relative to offset
+func (s *Lexer) skipASCIIIdentifier(offset int) {
+	for s.cursor+8 <= len(s.src) {
+		word := swarWord(s.src, s.cursor)
+		n := 8
+		if others := ^swarIdentifiers(word) & swarHighs; others != 0 {
+			n = bits.TrailingZeros64(others) / 8
+		}
+		// the digits of the run, the bytes past the run being masked out
+		digits := swarBetween(word, '0', '9')
+		if n < 8 {
+			digits &= 1<<(n*8) - 1
+		}
+		for digits != 0 {
+			s.digits = append(s.digits, s.cursor+bits.TrailingZeros64(digits)/8-offset)
+			digits &= digits - 1
+		}
+		s.cursor += n
+		if n < 8 {
+			return
+		}
+	}
+	for s.cursor < len(s.src) {
+		b := s.src[s.cursor]
+		if b >= utf8.RuneSelf || !isIdentifier(rune(b)) {
+			return
+		}
+		if isDigit(rune(b)) {
+			s.digits = append(s.digits, s.cursor-offset)
+		}
+		s.cursor++
+	}
+}
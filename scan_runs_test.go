@@ -0,0 +1,47 @@
+package sqllexer
+
+import (
+	"math/rand"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestScanRuns checks the runs of spaces and identifier characters, with and without the sqllexer_swar build tag
+func TestScanRuns(t *testing.T) {
+	alphabet := []string{" ", "\t", "\n", "\r", "\f", "\v", "a", "Z", "_", "0", "9", ".", "$", "#", "@", "!", "?", "/",
+		"-", "(", "'", "\"", "`", "[", "\x00", "\x7f", "é", "日"}
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		var input []byte
+		for n := random.Intn(40); n > 0; n-- {
+			input = append(input, alphabet[random.Intn(len(alphabet))]...)
+		}
+		for start := 0; start < len(input); start++ {
+			src := string(input)
+
+			spaces := start
+			for spaces < len(src) && isSpace(rune(src[spaces])) {
+				spaces++
+			}
+			lexer := New(src)
+			lexer.cursor = start
+			lexer.skipSpaces()
+			assert.Equal(t, spaces, lexer.cursor, "spaces of %q at %d", src, start)
+
+			identifier, digits := start, []int(nil)
+			for identifier < len(src) && src[identifier] < utf8.RuneSelf && isIdentifier(rune(src[identifier])) {
+				if isDigit(rune(src[identifier])) {
+					digits = append(digits, identifier-1)
+				}
+				identifier++
+			}
+			lexer = New(src)
+			lexer.cursor = start
+			lexer.skipASCIIIdentifier(1)
+			assert.Equal(t, identifier, lexer.cursor, "identifier of %q at %d", src, start)
+			assert.Equal(t, digits, lexer.digits, "digits of %q at %d", src, start)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package sqllexer
+
+import "unicode/utf8"
+
+// SemanticTokenTypes is the legend of the token types of SemanticTokens, in the order of their indexes,
+// to be registered by language servers as the tokenTypes of their semantic tokens provider
+var SemanticTokenTypes = []string{"keyword", "string", "number", "parameter", "comment", "operator", "function"}
+
+// SemanticTokenModifiers is the legend of the token modifiers of SemanticTokens, in the order of their bits,
+// to be registered by language servers as the tokenModifiers of their semantic tokens provider.
+// Booleans and NULL are readonly, system variables, e.g. @@version, are defaultLibrary.
+var SemanticTokenModifiers = []string{"readonly", "defaultLibrary"}
+
+// semanticTokenTypeIndexes are the indexes in SemanticTokenTypes of the highlighting classes
+var semanticTokenTypeIndexes = map[highlightClass]uint32{
+	highlightKeyword:   0,
+	highlightString:    1,
+	highlightNumber:    2,
+	highlightParameter: 3,
+	highlightComment:   4,
+	highlightOperator:  5,
+	highlightFunction:  6,
+}
+
+const (
+	semanticModifierReadonly uint32 = 1 << iota
+	semanticModifierDefaultLibrary
+)
+
+// PositionEncoding is the encoding of the characters counted by LSP positions, as negotiated by the client and the server
+type PositionEncoding string
+
+const (
+	PositionEncodingUTF8  PositionEncoding = "utf-8"
+	PositionEncodingUTF16 PositionEncoding = "utf-16"
+	PositionEncodingUTF32 PositionEncoding = "utf-32"
+)
+
+type semanticTokensConfig struct {
+	// LexerOptions are the options of the lexer of the query, e.g. WithDBMS
+	LexerOptions []lexerOption `json:"-"`
+
+	// PositionEncoding is the encoding of the characters of the positions. Defaults to utf-16, the default of LSP
+	PositionEncoding PositionEncoding `json:"position_encoding,omitempty"`
+}
+
+type semanticTokensOption func(*semanticTokensConfig)
+
+func WithSemanticTokensLexerOptions(lexerOpts ...lexerOption) semanticTokensOption {
+	return func(c *semanticTokensConfig) {
+		c.LexerOptions = append(c.LexerOptions, lexerOpts...)
+	}
+}
+
+func WithSemanticTokensPositionEncoding(encoding PositionEncoding) semanticTokensOption {
+	return func(c *semanticTokensConfig) {
+		c.PositionEncoding = encoding
+	}
+}
+
+// SemanticTokens returns the LSP semantic tokens of the query, as the data of a textDocument/semanticTokens/full
+// response: 5 integers per token, the line and start character relative to the previous token, the length,
+// the index of the type in SemanticTokenTypes, and the bits of the modifiers in SemanticTokenModifiers.
+// Identifiers and spaces are not semantic tokens. Tokens spanning several lines, e.g. multiline comments,
+// are split into one token per line, as required by clients without multiline token support.
+func SemanticTokens(query string, opts ...semanticTokensOption) []uint32 {
+	config := &semanticTokensConfig{PositionEncoding: PositionEncodingUTF16}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	encoder := &semanticTokensEncoder{encoding: config.PositionEncoding}
+	lexer := New(query, config.LexerOptions...)
+	for {
+		token := lexer.Scan()
+		if token.Type == EOF {
+			return encoder.data
+		}
+		tokenType, ok := semanticTokenTypeIndexes[classifyHighlight(token)]
+		encoder.advance(query[token.Start:token.End], ok, tokenType, semanticTokenModifiers(token))
+	}
+}
+
+// semanticTokenModifiers returns the bits of the modifiers of the token
+func semanticTokenModifiers(token *Token) uint32 {
+	switch token.Type {
+	case BOOLEAN, NULL:
+		return semanticModifierReadonly
+	case SYSTEM_VARIABLE:
+		return semanticModifierDefaultLibrary
+	}
+	return 0
+}
+
+// semanticTokensEncoder delta encodes the semantic tokens of a query, tracking the position of the tokens it advances over
+type semanticTokensEncoder struct {
+	encoding PositionEncoding
+	data     []uint32
+	// line and character are the position of the next token
+	line, character uint32
+	// previousLine and previousCharacter are the position of the last encoded token
+	previousLine, previousCharacter uint32
+	// carriageReturn is true if the last character is a carriage return, which a line feed does not follow
+	carriageReturn bool
+}
+
+// advance advances over the text of a token, encoding each of its lines if it is a semantic token
+func (e *semanticTokensEncoder) advance(text string, semantic bool, tokenType, modifiers uint32) {
+	start, length := e.character, uint32(0)
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+		if r == '\n' || r == '\r' {
+			if semantic {
+				e.encode(start, length, tokenType, modifiers)
+			}
+			if !(r == '\n' && e.carriageReturn) {
+				e.line++
+			}
+			e.character, e.carriageReturn = 0, r == '\r'
+			start, length = 0, 0
+			continue
+		}
+		width := e.width(r, size)
+		e.character += width
+		length += width
+		e.carriageReturn = false
+	}
+	if semantic {
+		e.encode(start, length, tokenType, modifiers)
+	}
+}
+
+// encode appends the token starting at the character of the current line, unless it is empty
+func (e *semanticTokensEncoder) encode(character, length, tokenType, modifiers uint32) {
+	if length == 0 {
+		return
+	}
+	deltaLine, deltaCharacter := e.line-e.previousLine, character
+	if deltaLine == 0 {
+		deltaCharacter -= e.previousCharacter
+	}
+	e.data = append(e.data, deltaLine, deltaCharacter, length, tokenType, modifiers)
+	e.previousLine, e.previousCharacter = e.line, character
+}
+
+// width returns the number of characters of the rune of size bytes in the position encoding
+func (e *semanticTokensEncoder) width(r rune, size int) uint32 {
+	switch e.encoding {
+	case PositionEncodingUTF8:
+		return uint32(size)
+	case PositionEncodingUTF32:
+		return 1
+	}
+	if r > 0xFFFF {
+		// a surrogate pair
+		return 2
+	}
+	return 1
+}
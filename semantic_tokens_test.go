@@ -0,0 +1,101 @@
+package sqllexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSemanticTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     []semanticTokensOption
+		expected []uint32
+	}{
+		{
+			name:  "keywords, operators and parameters",
+			input: "SELECT * FROM users WHERE id = ? AND active = TRUE",
+			expected: []uint32{
+				0, 0, 6, 0, 0, // SELECT
+				0, 7, 1, 5, 0, // *
+				0, 2, 4, 0, 0, // FROM
+				0, 11, 5, 0, 0, // WHERE
+				0, 9, 1, 5, 0, // =
+				0, 2, 1, 3, 0, // ?
+				0, 2, 3, 0, 0, // AND
+				0, 11, 1, 5, 0, // =
+				0, 2, 4, 0, 1, // TRUE
+			},
+		},
+		{
+			name:  "functions, comments and positional parameters",
+			input: "SELECT count(*) FROM t -- x\nWHERE a = $1",
+			opts:  []semanticTokensOption{WithSemanticTokensLexerOptions(WithDBMS(DBMSPostgres))},
+			expected: []uint32{
+				0, 0, 6, 0, 0, // SELECT
+				0, 7, 5, 6, 0, // count
+				0, 6, 1, 5, 0, // *
+				0, 3, 4, 0, 0, // FROM
+				0, 7, 4, 4, 0, // -- x
+				1, 0, 5, 0, 0, // WHERE
+				0, 8, 1, 5, 0, // =
+				0, 2, 2, 3, 0, // $1
+			},
+		},
+		{
+			name:  "multiline tokens and CRLF line breaks",
+			input: "/* a\nb */ SELECT 'é😀', @@version\r\nFROM t",
+			expected: []uint32{
+				0, 0, 4, 4, 0, // /* a
+				1, 0, 4, 4, 0, // b */
+				0, 5, 6, 0, 0, // SELECT
+				0, 7, 5, 1, 0, // 'é😀'
+				0, 7, 9, 3, 2, // @@version
+				1, 0, 4, 0, 0, // FROM
+			},
+		},
+		{
+			name:  "utf-8 positions",
+			input: "SELECT 'é😀', 1",
+			opts:  []semanticTokensOption{WithSemanticTokensPositionEncoding(PositionEncodingUTF8)},
+			expected: []uint32{
+				0, 0, 6, 0, 0, // SELECT
+				0, 7, 8, 1, 0, // 'é😀'
+				0, 10, 1, 2, 0, // 1
+			},
+		},
+		{
+			name:  "utf-32 positions",
+			input: "SELECT 'é😀', 1",
+			opts:  []semanticTokensOption{WithSemanticTokensPositionEncoding(PositionEncodingUTF32)},
+			expected: []uint32{
+				0, 0, 6, 0, 0, // SELECT
+				0, 7, 4, 1, 0, // 'é😀'
+				0, 6, 1, 2, 0, // 1
+			},
+		},
+		{
+			name:     "identifiers only",
+			input:    "users",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SemanticTokens(tt.input, tt.opts...))
+		})
+	}
+}
+
+func ExampleSemanticTokens() {
+	data := SemanticTokens("SELECT name\nFROM users")
+	for i := 0; i < len(data); i += 5 {
+		fmt.Println(data[i:i+3], SemanticTokenTypes[data[i+3]])
+	}
+	// Output:
+	// [0 0 6] keyword
+	// [1 0 4] keyword
+}
@@ -0,0 +1,322 @@
+package sqllexer
+
+import "strings"
+
+// Statement is a statement of a SQL script
+type Statement struct {
+	Text  string `json:"text"`
+	Start int    `json:"start"` // byte offset of the statement in the script
+	End   int    `json:"end"`   // byte offset following the statement in the script
+}
+
+// Splitter divides a SQL script into its statements using the token stream, so that delimiters
+// inside strings, comments, quoted identifiers and dollar quoted bodies are ignored.
+// Besides semicolons outside of parentheses, the splitter understands:
+//   - BEGIN ... END blocks, CASE ... END expressions and, for Oracle, PL/SQL declarations and routines
+//   - SQL Server GO batch separators, and Oracle "/" lines terminating PL/SQL blocks
+//   - MySQL DELIMITER directives
+type Splitter struct {
+	lexerOpts []lexerOption
+	dbms      DBMSType
+}
+
+// NewSplitter returns a Splitter lexing scripts with the given lexer options
+func NewSplitter(lexerOpts ...lexerOption) *Splitter {
+	config := &LexerConfig{}
+	for _, opt := range lexerOpts {
+		opt(config)
+	}
+	return &Splitter{lexerOpts: lexerOpts, dbms: config.DBMS}
+}
+
+// transactionBeginWords are the words following a BEGIN that starts a transaction rather than a block
+var transactionBeginWords = map[string]bool{
+	"TRAN":        true,
+	"TRANSACTION": true,
+	"WORK":        true,
+	"DISTRIBUTED": true,
+	"ISOLATION":   true,
+	"READ":        true,
+	"NOT":         true,
+	"DEFERRABLE":  true,
+	"DEFERRED":    true,
+	"IMMEDIATE":   true,
+	"EXCLUSIVE":   true,
+}
+
+// controlEndWords are the words following an END that closes a control structure rather than a block
+var controlEndWords = map[string]bool{
+	"IF":     true,
+	"LOOP":   true,
+	"WHILE":  true,
+	"REPEAT": true,
+	"FOR":    true,
+}
+
+// routineWords are the objects whose Oracle CREATE statement declares a PL/SQL body
+var routineWords = map[string]bool{
+	"PROCEDURE": true,
+	"FUNCTION":  true,
+	"TRIGGER":   true,
+	"PACKAGE":   true,
+	"TYPE":      true,
+}
+
+// splitState is the state of the statement being read
+type splitState struct {
+	start       int    // byte offset of the first token of the statement, -1 if none
+	end         int    // byte offset following the last token of the statement
+	hasValue    bool   // true if the statement is not only made of comments
+	words       int    // number of words read in the statement
+	firstWord   string // first word of the statement
+	parenDepth  int
+	blockDepth  int  // depth of BEGIN ... END blocks and CASE ... END expressions
+	pendingEnd  bool // true after an END, until the next word tells what it closes
+	inRoutine   bool // true while reading an Oracle PL/SQL declaration or routine
+	routineDone bool // true once the body of the routine is closed
+}
+
+// Split returns the non-empty statements of the script, without their delimiters.
+// Comments preceding a statement belong to it.
+func (sp *Splitter) Split(script string) []Statement {
+	var statements []Statement
+	sp.split(script, nil, func(statement Statement, ok bool) {
+		if ok {
+			statements = append(statements, statement)
+		}
+	})
+	return statements
+}
+
+// split reads the statements of the script in a single pass. visit, if not nil, is called with each token
+// added to the statement being read and its byte offsets in the script. flush is called at the end of each
+// statement, ok being false if the statement is empty or only made of comments, in which case it is dropped.
+func (sp *Splitter) split(script string, visit func(token *Token, start, end int), flush func(statement Statement, ok bool)) {
+	state := splitState{start: -1}
+	delimiter := ";"
+
+	extend := func(token *Token, start, end int) {
+		state.extend(start, end, isValueToken(token))
+		if visit != nil {
+			visit(token, start, end)
+		}
+	}
+	finish := func() {
+		if state.start >= 0 || state.hasValue {
+			text := strings.TrimRight(script[state.start:state.end], " \t\r\n\f\v")
+			flush(Statement{Text: text, Start: state.start, End: state.start + len(text)}, state.hasValue)
+		}
+		state = splitState{start: -1}
+	}
+
+	offset := 0
+	lexer := New(script, sp.lexerOpts...)
+	// restart restarts lexing at pos, after a directive or a custom delimiter
+	restart := func(pos int) {
+		offset = pos
+		lexer = New(script[pos:], sp.lexerOpts...)
+	}
+
+	for {
+		token := lexer.Scan()
+		start, end := token.Start+offset, token.End+offset
+		if token.Type == EOF {
+			finish()
+			return
+		}
+		if token.Type == SPACE {
+			continue
+		}
+
+		if isValueToken(token) && isLineStart(script, start) {
+			if lineEnd, ok := sp.isSeparatorLine(script, token, end); ok {
+				finish()
+				restart(lineEnd)
+				continue
+			}
+			if newDelimiter, lineEnd, ok := sp.isDelimiterDirective(script, token, end); ok && !state.hasValue {
+				delimiter = newDelimiter
+				finish()
+				restart(lineEnd)
+				continue
+			}
+		}
+
+		if delimiter != ";" && token.Type != STRING && token.Type != INCOMPLETE_STRING && token.Type != QUOTED_IDENT &&
+			token.Type != COMMENT && token.Type != MULTILINE_COMMENT && token.Type != INCOMPLETE_COMMENT {
+			// a custom delimiter may be lexed within a token, e.g. END//
+			if i := strings.Index(script[start:min(len(script), end+len(delimiter)-1)], delimiter); i >= 0 {
+				if i > 0 {
+					extend(&Token{Type: token.Type, Value: script[start : start+i]}, start, start+i)
+				}
+				finish()
+				restart(start + i + len(delimiter))
+				continue
+			}
+			extend(token, start, end)
+			continue
+		}
+
+		if token.Type == PUNCTUATION && token.Value == ";" {
+			state.resolvePendingEnd("")
+			if state.parenDepth == 0 && state.blockDepth == 0 && (!state.inRoutine || state.routineDone) {
+				finish()
+				continue
+			}
+		}
+
+		sp.track(token, &state, script, offset)
+		extend(token, start, end)
+	}
+}
+
+// extend extends the statement to the token
+func (s *splitState) extend(start, end int, isValue bool) {
+	if s.start < 0 {
+		s.start = start
+	}
+	s.end = end
+	s.hasValue = s.hasValue || isValue
+}
+
+// resolvePendingEnd closes the block of a pending END, unless word tells it closes a control structure
+func (s *splitState) resolvePendingEnd(word string) {
+	if !s.pendingEnd {
+		return
+	}
+	s.pendingEnd = false
+	if controlEndWords[word] {
+		return
+	}
+	if s.blockDepth > 0 {
+		s.blockDepth--
+	}
+	if s.blockDepth == 0 && s.inRoutine {
+		s.routineDone = true
+	}
+}
+
+// track updates the parentheses and block depths of the statement
+func (sp *Splitter) track(token *Token, state *splitState, script string, offset int) {
+	switch token.Type {
+	case PUNCTUATION:
+		state.resolvePendingEnd("")
+		if token.Value == "(" {
+			state.parenDepth++
+		} else if token.Value == ")" && state.parenDepth > 0 {
+			state.parenDepth--
+		}
+		return
+	case COMMAND, KEYWORD, IDENT, FUNCTION, PROC_INDICATOR, CTE_INDICATOR, BOOLEAN, NULL:
+	default:
+		if isValueToken(token) {
+			state.resolvePendingEnd("")
+		}
+		return
+	}
+
+	word := ToUpperASCII(token.Value)
+	state.resolvePendingEnd(word)
+	state.words++
+	if state.words == 1 {
+		state.firstWord = word
+	}
+
+	if sp.dbms == DBMSOracle && !state.inRoutine {
+		// e.g. DECLARE ... BEGIN ... END, or CREATE OR REPLACE PROCEDURE ... IS ... BEGIN ... END
+		if word == "DECLARE" && state.words == 1 {
+			state.inRoutine = true
+		} else if routineWords[word] && state.firstWord == "CREATE" && state.words <= 5 {
+			state.inRoutine = true
+		}
+	}
+
+	switch word {
+	case "CASE":
+		state.blockDepth++
+	case "END":
+		state.pendingEnd = true
+	case "BEGIN":
+		if !isTransactionBegin(script, token.End+offset) {
+			state.blockDepth++
+		}
+	}
+}
+
+// isTransactionBegin returns true if the BEGIN ending at pos starts a transaction rather than a block
+func isTransactionBegin(script string, pos int) bool {
+	rest := strings.TrimLeft(script[pos:], " \t\r\n\f\v")
+	if rest == "" || rest[0] == ';' {
+		return true
+	}
+	return transactionBeginWords[nextWord(script, pos)]
+}
+
+// nextWord returns the upper cased word following pos, skipping spaces, or an empty string if none
+func nextWord(script string, pos int) string {
+	rest := strings.TrimLeft(script[pos:], " \t\r\n\f\v")
+	wordEnd := 0
+	for wordEnd < len(rest) && (isAsciiLetter(rune(rest[wordEnd])) || rest[wordEnd] == '_') {
+		wordEnd++
+	}
+	return ToUpperASCII(rest[:wordEnd])
+}
+
+// isLineStart returns true if only spaces precede pos on its line
+func isLineStart(script string, pos int) bool {
+	for i := pos - 1; i >= 0; i-- {
+		switch script[i] {
+		case '\n':
+			return true
+		case ' ', '\t', '\r', '\f', '\v':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// lineEnd returns the byte offset of the end of the line containing pos, past its newline
+func lineEnd(script string, pos int) int {
+	if i := strings.IndexByte(script[pos:], '\n'); i >= 0 {
+		return pos + i + 1
+	}
+	return len(script)
+}
+
+// isSeparatorLine returns true, and the end of the line, if the token is a SQL Server GO [count] batch separator
+// or an Oracle "/" terminating a PL/SQL block, alone on its line
+func (sp *Splitter) isSeparatorLine(script string, token *Token, end int) (int, bool) {
+	switch {
+	case sp.dbms == DBMSSQLServer && equalFoldASCII(token.Value, "GO"):
+	case sp.dbms == DBMSOracle && token.Value == "/":
+	default:
+		return 0, false
+	}
+	next := lineEnd(script, end)
+	rest := strings.TrimSpace(script[end:next])
+	for i := 0; i < len(rest); i++ {
+		// GO may be followed by a count
+		if sp.dbms != DBMSSQLServer || !isDigit(rune(rest[i])) {
+			return 0, false
+		}
+	}
+	return next, true
+}
+
+// isDelimiterDirective returns true, the new delimiter and the end of the line,
+// if the token starts a MySQL DELIMITER directive
+func (sp *Splitter) isDelimiterDirective(script string, token *Token, end int) (string, int, bool) {
+	if sp.dbms != DBMSMySQL && sp.dbms != "" {
+		return "", 0, false
+	}
+	if !equalFoldASCII(token.Value, "DELIMITER") {
+		return "", 0, false
+	}
+	next := lineEnd(script, end)
+	delimiter := strings.TrimSpace(script[end:next])
+	if delimiter == "" || strings.ContainsAny(delimiter, " \t") {
+		return "", 0, false
+	}
+	return delimiter, next, true
+}
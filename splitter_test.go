@@ -0,0 +1,96 @@
+package sqllexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitter(t *testing.T) {
+	tests := []struct {
+		name      string
+		script    string
+		expected  []string
+		lexerOpts []lexerOption
+	}{
+		{
+			name:     "semicolons",
+			script:   "SELECT 1; SELECT 2;\n\nSELECT 3",
+			expected: []string{"SELECT 1", "SELECT 2", "SELECT 3"},
+		},
+		{
+			name:     "empty statements and comments",
+			script:   ";; -- only a comment\n;  /* leading */ SELECT 1 ; -- trailing",
+			expected: []string{"/* leading */ SELECT 1"},
+		},
+		{
+			name:     "delimiters in strings, comments and identifiers",
+			script:   `SELECT 'a;b', "c;d" /* ; */ FROM t -- ;` + "\n; SELECT 2",
+			expected: []string{`SELECT 'a;b', "c;d" /* ; */ FROM t -- ;`, "SELECT 2"},
+		},
+		{
+			name:      "dollar quoted bodies",
+			script:    "CREATE FUNCTION f() RETURNS int AS $$ BEGIN RETURN 1; END; $$ LANGUAGE plpgsql; SELECT f();",
+			expected:  []string{"CREATE FUNCTION f() RETURNS int AS $$ BEGIN RETURN 1; END; $$ LANGUAGE plpgsql", "SELECT f()"},
+			lexerOpts: []lexerOption{WithDBMS(DBMSPostgres)},
+		},
+		{
+			name:     "transactions",
+			script:   "BEGIN; UPDATE t SET a = 1; COMMIT; BEGIN TRANSACTION; ROLLBACK;",
+			expected: []string{"BEGIN", "UPDATE t SET a = 1", "COMMIT", "BEGIN TRANSACTION", "ROLLBACK"},
+		},
+		{
+			name:     "begin end blocks",
+			script:   "CREATE PROCEDURE p() BEGIN IF x THEN SELECT 1; END IF; SELECT CASE WHEN a THEN 1 END FROM t; END; CALL p();",
+			expected: []string{"CREATE PROCEDURE p() BEGIN IF x THEN SELECT 1; END IF; SELECT CASE WHEN a THEN 1 END FROM t; END", "CALL p()"},
+		},
+		{
+			name:      "sql server go separators",
+			script:    "BEGIN TRY\n  SELECT 1;\nEND TRY\nBEGIN CATCH\n  SELECT 2;\nEND CATCH\nGO\nSELECT 3\ngo 2\nSELECT go FROM t",
+			expected:  []string{"BEGIN TRY\n  SELECT 1;\nEND TRY\nBEGIN CATCH\n  SELECT 2;\nEND CATCH", "SELECT 3", "SELECT go FROM t"},
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+		},
+		{
+			name:      "mysql delimiter directives",
+			script:    "DELIMITER //\nCREATE PROCEDURE p()\nBEGIN\n  SELECT 1;\nEND//\nDELIMITER ;\nCALL p();",
+			expected:  []string{"CREATE PROCEDURE p()\nBEGIN\n  SELECT 1;\nEND", "CALL p()"},
+			lexerOpts: []lexerOption{WithDBMS(DBMSMySQL)},
+		},
+		{
+			name:      "mysql custom delimiter within strings",
+			script:    "DELIMITER $$\nSELECT '$$' $$ SELECT 2$$",
+			expected:  []string{"SELECT '$$'", "SELECT 2"},
+			lexerOpts: []lexerOption{WithDBMS(DBMSMySQL)},
+		},
+		{
+			name:      "oracle pl/sql",
+			script:    "CREATE OR REPLACE PROCEDURE p IS\n  x NUMBER;\nBEGIN\n  x := 1;\nEND p;\n/\nDECLARE\n  y NUMBER;\nBEGIN\n  NULL;\nEND;\n/\nSELECT 1 FROM dual;",
+			expected:  []string{"CREATE OR REPLACE PROCEDURE p IS\n  x NUMBER;\nBEGIN\n  x := 1;\nEND p", "DECLARE\n  y NUMBER;\nBEGIN\n  NULL;\nEND", "SELECT 1 FROM dual"},
+			lexerOpts: []lexerOption{WithDBMS(DBMSOracle)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			statements := NewSplitter(test.lexerOpts...).Split(test.script)
+			texts := make([]string, 0, len(statements))
+			for _, statement := range statements {
+				assert.Equal(t, statement.Text, test.script[statement.Start:statement.End])
+				texts = append(texts, statement.Text)
+			}
+			assert.Equal(t, test.expected, texts)
+		})
+	}
+}
+
+func ExampleSplitter() {
+	script := "BEGIN;\nUPDATE accounts SET balance = 0 WHERE note = 'a;b';\nCOMMIT;"
+	for _, statement := range NewSplitter().Split(script) {
+		fmt.Println(statement.Start, statement.End, statement.Text)
+	}
+	// Output:
+	// 0 5 BEGIN
+	// 7 57 UPDATE accounts SET balance = 0 WHERE note = 'a;b'
+	// 59 65 COMMIT
+}
@@ -0,0 +1,227 @@
+package sqllexer
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// TraceContext is the W3C trace context propagated by a sqlcommenter comment
+type TraceContext struct {
+	TraceParent string `json:"traceparent"`
+	TraceState  string `json:"tracestate,omitempty"`
+	TraceID     string `json:"trace_id"`
+	SpanID      string `json:"span_id"`
+	Sampled     bool   `json:"sampled"`
+}
+
+// ParseSQLCommenter parses a sqlcommenter formatted comment, e.g.
+// /*action='run',traceparent='00-...-01'*/, into its key/value pairs.
+// Keys and values are URL decoded and escaped quotes in values are unescaped.
+// It returns false if the comment is not a well formed sqlcommenter comment.
+func ParseSQLCommenter(comment string) (map[string]string, bool) {
+	comment = strings.TrimSpace(comment)
+	if !strings.HasPrefix(comment, "/*") || !strings.HasSuffix(comment, "*/") || len(comment) < 4 {
+		return nil, false
+	}
+	body := strings.TrimSpace(comment[2 : len(comment)-2])
+	if body == "" {
+		return nil, false
+	}
+
+	tags := make(map[string]string)
+	for body != "" {
+		eq := strings.IndexByte(body, '=')
+		if eq <= 0 {
+			return nil, false
+		}
+		key, err := url.QueryUnescape(strings.TrimSpace(body[:eq]))
+		if err != nil || key == "" {
+			return nil, false
+		}
+		rest := strings.TrimLeft(body[eq+1:], " ")
+		if rest == "" || rest[0] != '\'' {
+			return nil, false
+		}
+
+		// find the closing quote, skipping escaped quotes
+		end := -1
+		for i := 1; i < len(rest); i++ {
+			if rest[i] == '\\' {
+				i++
+				continue
+			}
+			if rest[i] == '\'' {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			return nil, false
+		}
+		value, err := url.PathUnescape(strings.ReplaceAll(rest[1:end], `\'`, `'`))
+		if err != nil {
+			return nil, false
+		}
+		tags[key] = value
+
+		body = strings.TrimLeft(rest[end+1:], " ")
+		if body == "" {
+			break
+		}
+		if body[0] != ',' {
+			return nil, false
+		}
+		body = strings.TrimLeft(body[1:], " ")
+	}
+	return tags, true
+}
+
+// FormatSQLCommenter formats the key/value pairs as a sqlcommenter comment, e.g. /*action='run',route='%2Fusers'*/,
+// sorted by key. Keys and values are URL encoded, quotes and the */ closing the comment included.
+// It returns an empty string if there are no key/value pairs.
+func FormatSQLCommenter(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	builder.WriteString("/*")
+	for i, key := range keys {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteString(url.QueryEscape(key))
+		builder.WriteString("='")
+		builder.WriteString(url.PathEscape(tags[key]))
+		builder.WriteByte('\'')
+	}
+	builder.WriteString("*/")
+	return builder.String()
+}
+
+// AppendSQLCommenter returns the query with the sqlcommenter comment of the key/value pairs appended to its last
+// statement, after its last token but before its terminator and trailing comments, so a trailing line comment
+// cannot comment it out. The query is returned unchanged if there are no key/value pairs or no statement.
+func AppendSQLCommenter(query string, tags map[string]string, lexerOpts ...lexerOption) string {
+	comment := FormatSQLCommenter(tags)
+	if comment == "" {
+		return query
+	}
+	end := -1
+	lexer := New(query, lexerOpts...)
+	for {
+		token := lexer.Scan()
+		if token.Type == EOF {
+			break
+		}
+		if isValueToken(token) && !(token.Type == PUNCTUATION && token.Value == ";") {
+			end = token.End
+		}
+	}
+	if end < 0 {
+		return query
+	}
+	return query[:end] + " " + comment + query[end:]
+}
+
+// PrependSQLCommenter returns the query with the sqlcommenter comment of the key/value pairs prepended to its first
+// statement, after its leading comments, e.g. optimizer hints or license headers.
+// The query is returned unchanged if there are no key/value pairs or no statement.
+func PrependSQLCommenter(query string, tags map[string]string, lexerOpts ...lexerOption) string {
+	comment := FormatSQLCommenter(tags)
+	if comment == "" {
+		return query
+	}
+	lexer := New(query, lexerOpts...)
+	for {
+		token := lexer.Scan()
+		if token.Type == EOF {
+			return query
+		}
+		if isValueToken(token) {
+			return query[:token.Start] + comment + " " + query[token.Start:]
+		}
+	}
+}
+
+// SQLCommenterTags returns the key/value pairs of every sqlcommenter comment in the query.
+// When a key appears in several comments, the last one wins.
+func SQLCommenterTags(query string, lexerOpts ...lexerOption) map[string]string {
+	var tags map[string]string
+	for _, comment := range ExtractComments(query, lexerOpts...) {
+		if comment.Kind != CommentBlock {
+			continue
+		}
+		parsed, ok := ParseSQLCommenter(comment.Text)
+		if !ok {
+			continue
+		}
+		if tags == nil {
+			tags = make(map[string]string, len(parsed))
+		}
+		for key, value := range parsed {
+			tags[key] = value
+		}
+	}
+	return tags
+}
+
+// ExtractTraceContext returns the W3C trace context carried by the sqlcommenter comments of the query.
+// It returns false if the query has no valid traceparent.
+func ExtractTraceContext(query string, lexerOpts ...lexerOption) (TraceContext, bool) {
+	tags := SQLCommenterTags(query, lexerOpts...)
+	traceParent, ok := tags["traceparent"]
+	if !ok {
+		return TraceContext{}, false
+	}
+	return parseTraceParent(traceParent, tags["tracestate"])
+}
+
+// parseTraceParent parses a W3C traceparent header value: version-traceid-spanid-flags
+func parseTraceParent(traceParent, traceState string) (TraceContext, bool) {
+	parts := strings.Split(traceParent, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return TraceContext{}, false
+	}
+	if parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return TraceContext{}, false
+	}
+	for _, part := range parts[:4] {
+		if !isLowerHex(part) {
+			return TraceContext{}, false
+		}
+	}
+	if strings.Trim(parts[1], "0") == "" || strings.Trim(parts[2], "0") == "" {
+		return TraceContext{}, false
+	}
+	flags := hexValue(parts[3][1])
+	return TraceContext{
+		TraceParent: traceParent,
+		TraceState:  traceState,
+		TraceID:     parts[1],
+		SpanID:      parts[2],
+		Sampled:     flags&0x1 == 1,
+	}, true
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !(s[i] >= '0' && s[i] <= '9') && !(s[i] >= 'a' && s[i] <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func hexValue(c byte) byte {
+	if c >= 'a' {
+		return c - 'a' + 10
+	}
+	return c - '0'
+}
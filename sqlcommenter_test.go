@@ -0,0 +1,225 @@
+package sqllexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSQLCommenter(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected map[string]string
+		ok       bool
+	}{
+		{
+			input:    "/*action='%2Fparam*d',controller='index',framework='spring'*/",
+			expected: map[string]string{"action": "/param*d", "controller": "index", "framework": "spring"},
+			ok:       true,
+		},
+		{
+			input:    "/* route='%2Fpolls 1000', db_driver='psycopg2' */",
+			expected: map[string]string{"route": "/polls 1000", "db_driver": "psycopg2"},
+			ok:       true,
+		},
+		{
+			input:    `/*name='O\'Reilly',k%20ey='v'*/`,
+			expected: map[string]string{"name": "O'Reilly", "k ey": "v"},
+			ok:       true,
+		},
+		{
+			input: "/* just a regular comment */",
+			ok:    false,
+		},
+		{
+			input: "/*key='unterminated*/",
+			ok:    false,
+		},
+		{
+			input: "/*key=unquoted*/",
+			ok:    false,
+		},
+		{
+			input: "-- key='value'",
+			ok:    false,
+		},
+		{
+			input: "/**/",
+			ok:    false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			got, ok := ParseSQLCommenter(test.input)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.expected, got)
+		})
+	}
+}
+
+func TestExtractTraceContext(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected TraceContext
+		ok       bool
+	}{
+		{
+			input: "SELECT * FROM users /*traceparent='00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01',tracestate='congo%3Dt61rcWkgMzE'*/",
+			expected: TraceContext{
+				TraceParent: "00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01",
+				TraceState:  "congo=t61rcWkgMzE",
+				TraceID:     "5bd66ef5095369c7b0d1f8f4bd33716a",
+				SpanID:      "c532cb4098ac3dd2",
+				Sampled:     true,
+			},
+			ok: true,
+		},
+		{
+			input: "/*traceparent='00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-00'*/ SELECT 1",
+			expected: TraceContext{
+				TraceParent: "00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-00",
+				TraceID:     "5bd66ef5095369c7b0d1f8f4bd33716a",
+				SpanID:      "c532cb4098ac3dd2",
+			},
+			ok: true,
+		},
+		{
+			input: "SELECT 1 /*action='index'*/",
+			ok:    false,
+		},
+		{
+			input: "SELECT 1 /*traceparent='00-00000000000000000000000000000000-c532cb4098ac3dd2-01'*/",
+			ok:    false,
+		},
+		{
+			input: "SELECT 1 /*traceparent='00-5BD66EF5095369C7B0D1F8F4BD33716A-c532cb4098ac3dd2-01'*/",
+			ok:    false,
+		},
+		{
+			input: "SELECT '/*traceparent=''00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01''*/'",
+			ok:    false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			got, ok := ExtractTraceContext(test.input)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.expected, got)
+		})
+	}
+}
+
+func ExampleExtractTraceContext() {
+	query := "SELECT * FROM users /*controller='users',traceparent='00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01'*/"
+	fmt.Println(SQLCommenterTags(query)["controller"])
+	traceContext, ok := ExtractTraceContext(query)
+	fmt.Println(ok, traceContext.TraceID, traceContext.SpanID, traceContext.Sampled)
+	// Output:
+	// users
+	// true 5bd66ef5095369c7b0d1f8f4bd33716a c532cb4098ac3dd2 true
+}
+
+func TestFormatSQLCommenter(t *testing.T) {
+	tests := []struct {
+		input    map[string]string
+		expected string
+	}{
+		{
+			input:    nil,
+			expected: "",
+		},
+		{
+			input:    map[string]string{"route": "/polls 1000", "action": "run"},
+			expected: "/*action='run',route='%2Fpolls%201000'*/",
+		},
+		{
+			input:    map[string]string{"k ey": "O'Reilly */ DROP"},
+			expected: "/*k+ey='O%27Reilly%20%2A%2F%20DROP'*/",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			comment := FormatSQLCommenter(test.input)
+			assert.Equal(t, test.expected, comment)
+			if comment != "" {
+				parsed, ok := ParseSQLCommenter(comment)
+				assert.True(t, ok)
+				assert.Equal(t, test.input, parsed)
+			}
+		})
+	}
+}
+
+func TestAppendSQLCommenter(t *testing.T) {
+	tags := map[string]string{"app": "api"}
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    "SELECT * FROM users",
+			expected: "SELECT * FROM users /*app='api'*/",
+		},
+		{
+			input:    "SELECT * FROM users; -- trailing comment\n",
+			expected: "SELECT * FROM users /*app='api'*/; -- trailing comment\n",
+		},
+		{
+			input:    "BEGIN; UPDATE users SET a = 1;",
+			expected: "BEGIN; UPDATE users SET a = 1 /*app='api'*/;",
+		},
+		{
+			input:    "-- only a comment",
+			expected: "-- only a comment",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			assert.Equal(t, test.expected, AppendSQLCommenter(test.input, tags))
+		})
+	}
+}
+
+func TestPrependSQLCommenter(t *testing.T) {
+	tags := map[string]string{"app": "api"}
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    "SELECT * FROM users",
+			expected: "/*app='api'*/ SELECT * FROM users",
+		},
+		{
+			input:    "-- header\n/* license */ SELECT 1;",
+			expected: "-- header\n/* license */ /*app='api'*/ SELECT 1;",
+		},
+		{
+			input:    "",
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			assert.Equal(t, test.expected, PrependSQLCommenter(test.input, tags))
+		})
+	}
+}
+
+func ExampleAppendSQLCommenter() {
+	query := AppendSQLCommenter("SELECT * FROM users;", map[string]string{
+		"controller":  "users",
+		"traceparent": "00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01",
+	})
+	fmt.Println(query)
+	fmt.Println(SQLCommenterTags(query)["controller"])
+	// Output:
+	// SELECT * FROM users /*controller='users',traceparent='00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01'*/;
+	// users
+}
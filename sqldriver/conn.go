@@ -0,0 +1,231 @@
+package sqldriver
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+)
+
+// wrappedConn reports the statements of a connection. It implements every optional interface of database/sql,
+// falling back to the behavior of database/sql when the wrapped connection does not.
+type wrappedConn struct {
+	driver.Conn
+	config *config
+	// skipped is the statement of the last query the driver did not execute, returning driver.ErrSkip,
+	// reused when database/sql prepares the query instead
+	skipped *Statement
+}
+
+var (
+	_ driver.ConnPrepareContext = (*wrappedConn)(nil)
+	_ driver.ExecerContext      = (*wrappedConn)(nil)
+	_ driver.QueryerContext     = (*wrappedConn)(nil)
+	_ driver.ConnBeginTx        = (*wrappedConn)(nil)
+	_ driver.Pinger             = (*wrappedConn)(nil)
+	_ driver.SessionResetter    = (*wrappedConn)(nil)
+	_ driver.Validator          = (*wrappedConn)(nil)
+	_ driver.NamedValueChecker  = (*wrappedConn)(nil)
+)
+
+func (c *wrappedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *wrappedConn) PrepareContext(ctx context.Context, query string) (stmt driver.Stmt, err error) {
+	statement := c.statement(query)
+	c.config.run(ctx, OperationPrepare, statement, false, func(ctx context.Context) error {
+		if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+			stmt, err = preparer.PrepareContext(ctx, query)
+		} else {
+			stmt, err = c.Conn.Prepare(query)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newWrappedStmt(stmt, statement, c.config), nil
+}
+
+func (c *wrappedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (result driver.Result, err error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		// database/sql prepares the statement instead
+		return nil, driver.ErrSkip
+	}
+	statement := c.statement(query)
+	c.config.run(ctx, OperationExec, statement, false, func(ctx context.Context) error {
+		result, err = execer.ExecContext(ctx, query, args)
+		return err
+	})
+	c.skip(statement, err)
+	return result, err
+}
+
+func (c *wrappedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (rows driver.Rows, err error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		// database/sql prepares the statement instead
+		return nil, driver.ErrSkip
+	}
+	statement := c.statement(query)
+	c.config.run(ctx, OperationQuery, statement, false, func(ctx context.Context) error {
+		rows, err = queryer.QueryContext(ctx, query, args)
+		return err
+	})
+	c.skip(statement, err)
+	return rows, err
+}
+
+// statement returns the sanitized form of the query, the one of the query the driver just skipped if it is the same
+func (c *wrappedConn) statement(query string) *Statement {
+	statement := c.skipped
+	c.skipped = nil
+	if statement != nil && statement.Query == query {
+		return statement
+	}
+	return c.config.statement(query)
+}
+
+// skip keeps the statement if the driver did not execute it, so it is not sanitized again when it is prepared
+func (c *wrappedConn) skip(statement *Statement, err error) {
+	if err == driver.ErrSkip {
+		c.skipped = statement
+	}
+}
+
+func (c *wrappedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	if opts.Isolation != 0 || opts.ReadOnly {
+		return nil, errors.New("sqldriver: driver does not support transaction options")
+	}
+	return c.Conn.Begin() //nolint:staticcheck // fallback of drivers without BeginTx
+}
+
+func (c *wrappedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *wrappedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *wrappedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *wrappedConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	// database/sql converts the value with its default converter
+	return driver.ErrSkip
+}
+
+// wrappedStmt reports the executions of a prepared statement, whose sanitized form is computed when it is prepared
+type wrappedStmt struct {
+	driver.Stmt
+	statement *Statement
+	config    *config
+}
+
+var (
+	_ driver.StmtExecContext   = (*wrappedStmt)(nil)
+	_ driver.StmtQueryContext  = (*wrappedStmt)(nil)
+	_ driver.NamedValueChecker = (*checkerStmt)(nil)
+	_ driver.ColumnConverter   = (*converterStmt)(nil)
+	_ driver.NamedValueChecker = (*checkerConverterStmt)(nil)
+	_ driver.ColumnConverter   = (*checkerConverterStmt)(nil)
+)
+
+// checkerStmt, converterStmt and checkerConverterStmt are the wrapped statements forwarding the
+// driver.NamedValueChecker and driver.ColumnConverter interfaces of statements implementing them
+type checkerStmt struct {
+	*wrappedStmt
+	driver.NamedValueChecker
+}
+
+type converterStmt struct {
+	*wrappedStmt
+	converter driver.ColumnConverter
+}
+
+type checkerConverterStmt struct {
+	*converterStmt
+	driver.NamedValueChecker
+}
+
+func (s *converterStmt) ColumnConverter(index int) driver.ValueConverter {
+	return s.converter.ColumnConverter(index)
+}
+
+// newWrappedStmt wraps the prepared statement. The wrapper only implements the argument conversion interfaces
+// the statement implements, as database/sql falls back to the ones of the connection, then to its default
+// converter, for those the statement does not implement.
+func newWrappedStmt(stmt driver.Stmt, statement *Statement, config *config) driver.Stmt {
+	wrapped := &wrappedStmt{Stmt: stmt, statement: statement, config: config}
+	checker, isChecker := stmt.(driver.NamedValueChecker)
+	converter, isConverter := stmt.(driver.ColumnConverter)
+	switch {
+	case isChecker && isConverter:
+		return &checkerConverterStmt{converterStmt: &converterStmt{wrappedStmt: wrapped, converter: converter}, NamedValueChecker: checker}
+	case isChecker:
+		return &checkerStmt{wrappedStmt: wrapped, NamedValueChecker: checker}
+	case isConverter:
+		return &converterStmt{wrappedStmt: wrapped, converter: converter}
+	}
+	return wrapped
+}
+
+func (s *wrappedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (result driver.Result, err error) {
+	s.config.run(ctx, OperationExec, s.statement, true, func(ctx context.Context) error {
+		if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+			result, err = execer.ExecContext(ctx, args)
+			return err
+		}
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err == nil {
+			result, err = s.Stmt.Exec(values) //nolint:staticcheck // fallback of statements without ExecContext
+		}
+		return err
+	})
+	return result, err
+}
+
+func (s *wrappedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (rows driver.Rows, err error) {
+	s.config.run(ctx, OperationQuery, s.statement, true, func(ctx context.Context) error {
+		if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+			rows, err = queryer.QueryContext(ctx, args)
+			return err
+		}
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err == nil {
+			rows, err = s.Stmt.Query(values) //nolint:staticcheck // fallback of statements without QueryContext
+		}
+		return err
+	})
+	return rows, err
+}
+
+// namedValuesToValues converts the arguments for drivers without context support, which do not support names
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("sqldriver: driver does not support the use of named parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,216 @@
+// Package sqldriver wraps database/sql drivers to obfuscate, normalize and fingerprint the statements
+// they execute, and report them to callbacks, so applications get sanitized SQL for logging and metrics
+// without changing the code executing queries.
+//
+//	db := sql.OpenDB(sqldriver.WrapConnector(connector, sqldriver.WithAfter(func(ctx context.Context, event *sqldriver.Event) {
+//		log.Printf("%s %s took %s", event.Operation, event.Statement.NormalizedSQL, event.Duration)
+//	})))
+package sqldriver
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"time"
+
+	sqllexer "github.com/DataDog/go-sqllexer"
+)
+
+// Operation is the driver operation executing a statement
+type Operation string
+
+const (
+	OperationPrepare Operation = "prepare"
+	OperationExec    Operation = "exec"
+	OperationQuery   Operation = "query"
+)
+
+// Statement is the sanitized form of a query, computed once per query, or once per prepared statement
+type Statement struct {
+	// Query is the query as sent to the driver, literals included
+	Query string
+	// NormalizedSQL is the obfuscated and normalized query, safe to log
+	NormalizedSQL string
+	// Metadata is the metadata collected by the normalizer, nil if the query could not be normalized
+	Metadata *sqllexer.StatementMetadata
+	// Fingerprint is the fingerprint of the query, see sqllexer.Fingerprint
+	Fingerprint uint64
+}
+
+// Event is the execution of a statement by the driver
+type Event struct {
+	Operation Operation
+	Statement *Statement
+	// Prepared is true if the statement is executed by a prepared statement
+	Prepared bool
+	Start    time.Time
+	// Duration and Err are set once the driver returns. Err is driver.ErrSkip when the driver does not execute
+	// the statement this way, database/sql then preparing it, which is reported as other events
+	Duration time.Duration
+	Err      error
+}
+
+type config struct {
+	obfuscator *sqllexer.Obfuscator
+	normalizer *sqllexer.Normalizer
+	dbms       sqllexer.DBMSType
+	before     func(ctx context.Context, event *Event) context.Context
+	after      func(ctx context.Context, event *Event)
+}
+
+type Option func(*config)
+
+// WithObfuscator sets the obfuscator of the statements, NewObfuscator() by default
+func WithObfuscator(obfuscator *sqllexer.Obfuscator) Option {
+	return func(c *config) {
+		c.obfuscator = obfuscator
+	}
+}
+
+// WithNormalizer sets the normalizer of the statements, collecting tables and commands by default
+func WithNormalizer(normalizer *sqllexer.Normalizer) Option {
+	return func(c *config) {
+		c.normalizer = normalizer
+	}
+}
+
+// WithDBMS sets the database the statements are written for
+func WithDBMS(dbms sqllexer.DBMSType) Option {
+	return func(c *config) {
+		c.dbms = dbms
+	}
+}
+
+// WithBefore sets the callback called before the driver executes a statement.
+// The context it returns is the one passed to the driver and to the after callback, e.g. to start a span.
+func WithBefore(before func(ctx context.Context, event *Event) context.Context) Option {
+	return func(c *config) {
+		c.before = before
+	}
+}
+
+// WithAfter sets the callback called once the driver has executed a statement
+func WithAfter(after func(ctx context.Context, event *Event)) Option {
+	return func(c *config) {
+		c.after = after
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.obfuscator == nil {
+		c.obfuscator = sqllexer.NewObfuscator()
+	}
+	if c.normalizer == nil {
+		c.normalizer = sqllexer.NewNormalizer(sqllexer.WithCollectTables(true), sqllexer.WithCollectCommands(true))
+	}
+	return c
+}
+
+// statement returns the sanitized form of the query, or nil if there are no callbacks to report it to
+func (c *config) statement(query string) *Statement {
+	if c.before == nil && c.after == nil {
+		return nil
+	}
+	result := sqllexer.Sanitize(query, c.obfuscator, c.normalizer, sqllexer.WithDBMS(c.dbms))
+	return &Statement{
+		Query:         query,
+		NormalizedSQL: result.Normalized,
+		Metadata:      result.Metadata,
+		Fingerprint:   result.Fingerprint,
+	}
+}
+
+// run reports the execution of the statement by fn to the callbacks, after being called whenever before is
+func (c *config) run(ctx context.Context, operation Operation, statement *Statement, prepared bool, fn func(ctx context.Context) error) {
+	if c.before == nil && c.after == nil {
+		_ = fn(ctx)
+		return
+	}
+	event := &Event{Operation: operation, Statement: statement, Prepared: prepared, Start: time.Now()}
+	if c.before != nil {
+		ctx = c.before(ctx, event)
+	}
+	err := fn(ctx)
+	event.Duration, event.Err = time.Since(event.Start), err
+	if c.after != nil {
+		c.after(ctx, event)
+	}
+}
+
+// Wrap returns the driver with its statements reported to the callbacks of the options
+func Wrap(d driver.Driver, opts ...Option) driver.Driver {
+	return &wrappedDriver{Driver: d, config: newConfig(opts)}
+}
+
+// WrapConnector returns the connector with the statements of its connections reported to the callbacks of the options
+func WrapConnector(connector driver.Connector, opts ...Option) driver.Connector {
+	config := newConfig(opts)
+	return &wrappedConnector{Connector: connector, driver: &wrappedDriver{Driver: connector.Driver(), config: config}}
+}
+
+type wrappedDriver struct {
+	driver.Driver
+	config *config
+}
+
+func (d *wrappedDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedConn{Conn: conn, config: d.config}, nil
+}
+
+func (d *wrappedDriver) OpenConnector(name string) (driver.Connector, error) {
+	if driverContext, ok := d.Driver.(driver.DriverContext); ok {
+		connector, err := driverContext.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		return &wrappedConnector{Connector: connector, driver: d}, nil
+	}
+	return &dsnConnector{name: name, driver: d}, nil
+}
+
+type wrappedConnector struct {
+	driver.Connector
+	driver *wrappedDriver
+}
+
+func (c *wrappedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedConn{Conn: conn, config: c.driver.config}, nil
+}
+
+func (c *wrappedConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// Close closes the wrapped connector if it is closable, as database/sql does when the DB is closed
+func (c *wrappedConnector) Close() error {
+	if closer, ok := c.Connector.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// dsnConnector is the connector of drivers without one, as created by database/sql
+type dsnConnector struct {
+	name   string
+	driver *wrappedDriver
+}
+
+func (c *dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.name)
+}
+
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
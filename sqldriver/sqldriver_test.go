@@ -0,0 +1,379 @@
+package sqldriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	sqllexer "github.com/DataDog/go-sqllexer"
+	"github.com/stretchr/testify/assert"
+)
+
+var errFake = errors.New("fake error")
+
+// fakeDriver is a driver whose connections execute nothing, and fail queries containing "fail"
+type fakeDriver struct {
+	legacy bool // true if its connections and statements only implement the required interfaces
+}
+
+func (d fakeDriver) Open(string) (driver.Conn, error) {
+	if d.legacy {
+		return &legacyConn{}, nil
+	}
+	return &fakeConn{}, nil
+}
+
+type fakeConnector struct {
+	driver fakeDriver
+}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open("")
+}
+
+func (c fakeConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type legacyConn struct{}
+
+func (c *legacyConn) Prepare(query string) (driver.Stmt, error) {
+	if strings.Contains(query, "fail") {
+		return nil, errFake
+	}
+	return &legacyStmt{}, nil
+}
+
+func (c *legacyConn) Close() error {
+	return nil
+}
+
+func (c *legacyConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+type legacyStmt struct{}
+
+func (s *legacyStmt) Close() error {
+	return nil
+}
+
+func (s *legacyStmt) NumInput() int {
+	return -1
+}
+
+func (s *legacyStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func (s *legacyStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &fakeRows{}, nil
+}
+
+type fakeConn struct {
+	legacyConn
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if strings.Contains(query, "fail") {
+		return nil, errFake
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if strings.Contains(query, "fail") {
+		return nil, errFake
+	}
+	return &fakeRows{}, nil
+}
+
+// skipConn is a connection and its connector, whose fast paths return driver.ErrSkip, as drivers do
+// for queries they cannot execute without preparing them, e.g. queries with arguments
+type skipConn struct {
+	legacyConn
+}
+
+func (c *skipConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *skipConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *skipConn) Connect(context.Context) (driver.Conn, error) {
+	return c, nil
+}
+
+func (c *skipConn) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+// converterConn is a connector and its connection, whose statements convert their arguments with their own driver.ColumnConverter
+type converterConn struct {
+	legacyConn
+	values []driver.Value // the arguments of the last execution
+}
+
+func (c *converterConn) Prepare(string) (driver.Stmt, error) {
+	return &pointStmt{conn: c}, nil
+}
+
+func (c *converterConn) Connect(context.Context) (driver.Conn, error) {
+	return c, nil
+}
+
+func (c *converterConn) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type pointStmt struct {
+	legacyStmt
+	conn *converterConn
+}
+
+func (s *pointStmt) NumInput() int {
+	return 1
+}
+
+func (s *pointStmt) Exec(values []driver.Value) (driver.Result, error) {
+	s.conn.values = values
+	return driver.RowsAffected(1), nil
+}
+
+func (s *pointStmt) ColumnConverter(int) driver.ValueConverter {
+	return pointConverter{}
+}
+
+// point is an argument type only supported by the pointConverter
+type point struct {
+	x, y int
+}
+
+type pointConverter struct{}
+
+func (pointConverter) ConvertValue(value any) (driver.Value, error) {
+	if p, ok := value.(point); ok {
+		return fmt.Sprintf("%d,%d", p.x, p.y), nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(value)
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error {
+	return nil
+}
+
+func (fakeTx) Rollback() error {
+	return nil
+}
+
+type fakeRows struct{}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"id"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next([]driver.Value) error {
+	return io.EOF
+}
+
+// recorder records the events reported to the callbacks
+type recorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *recorder) options() []Option {
+	return []Option{
+		WithBefore(func(ctx context.Context, event *Event) context.Context {
+			return context.WithValue(ctx, r, event.Operation)
+		}),
+		WithAfter(func(ctx context.Context, event *Event) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if ctx.Value(r) == event.Operation {
+				r.events = append(r.events, *event)
+			}
+		}),
+	}
+}
+
+func TestWrapConnector(t *testing.T) {
+	query := "UPDATE users SET name = 'alice' WHERE id = 1"
+	expected := &Statement{
+		Query:         query,
+		NormalizedSQL: "UPDATE users SET name = ? WHERE id = ?",
+		Metadata:      &sqllexer.StatementMetadata{Size: 11, Tables: []string{"users"}, Comments: []string{}, Commands: []string{"UPDATE"}, Procedures: []string{}, StatementKind: sqllexer.StatementUpdate},
+		Fingerprint:   sqllexer.Fingerprint(query),
+	}
+
+	t.Run("context interfaces", func(t *testing.T) {
+		r := &recorder{}
+		db := sql.OpenDB(WrapConnector(fakeConnector{}, r.options()...))
+		defer db.Close()
+
+		_, err := db.Exec(query)
+		assert.NoError(t, err)
+		rows, err := db.Query("SELECT id FROM users WHERE name = 'bob'")
+		assert.NoError(t, err)
+		assert.NoError(t, rows.Close())
+		_, err = db.Exec("DELETE FROM fail WHERE id = 2")
+		assert.ErrorIs(t, err, errFake)
+
+		if assert.Len(t, r.events, 3) {
+			assert.Equal(t, OperationExec, r.events[0].Operation)
+			assert.Equal(t, expected, r.events[0].Statement)
+			assert.False(t, r.events[0].Prepared)
+			assert.NoError(t, r.events[0].Err)
+			assert.Equal(t, OperationQuery, r.events[1].Operation)
+			assert.Equal(t, "SELECT id FROM users WHERE name = ?", r.events[1].Statement.NormalizedSQL)
+			assert.Equal(t, OperationExec, r.events[2].Operation)
+			assert.ErrorIs(t, r.events[2].Err, errFake)
+		}
+	})
+
+	t.Run("legacy interfaces", func(t *testing.T) {
+		r := &recorder{}
+		db := sql.OpenDB(WrapConnector(fakeConnector{driver: fakeDriver{legacy: true}}, r.options()...))
+		defer db.Close()
+
+		// database/sql prepares the statement of drivers without ExecerContext
+		_, err := db.Exec(query)
+		assert.NoError(t, err)
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+		assert.NoError(t, tx.Commit())
+
+		if assert.Len(t, r.events, 2) {
+			assert.Equal(t, OperationPrepare, r.events[0].Operation)
+			assert.Equal(t, expected, r.events[0].Statement)
+			assert.Equal(t, OperationExec, r.events[1].Operation)
+			assert.True(t, r.events[1].Prepared)
+			// the statement is computed once, when it is prepared
+			assert.Same(t, r.events[0].Statement, r.events[1].Statement)
+		}
+	})
+
+	t.Run("prepared statements", func(t *testing.T) {
+		r := &recorder{}
+		db := sql.OpenDB(WrapConnector(fakeConnector{}, r.options()...))
+		defer db.Close()
+
+		stmt, err := db.Prepare("SELECT * FROM users WHERE id = ?")
+		assert.NoError(t, err)
+		for i := 0; i < 2; i++ {
+			rows, err := stmt.Query(i)
+			assert.NoError(t, err)
+			assert.NoError(t, rows.Close())
+		}
+		assert.NoError(t, stmt.Close())
+		_, err = db.Prepare("SELECT * FROM fail")
+		assert.ErrorIs(t, err, errFake)
+
+		if assert.Len(t, r.events, 4) {
+			assert.Equal(t, OperationPrepare, r.events[0].Operation)
+			assert.Equal(t, OperationQuery, r.events[1].Operation)
+			assert.Equal(t, OperationQuery, r.events[2].Operation)
+			assert.Same(t, r.events[0].Statement, r.events[2].Statement)
+			assert.Equal(t, OperationPrepare, r.events[3].Operation)
+			assert.ErrorIs(t, r.events[3].Err, errFake)
+		}
+	})
+}
+
+func TestWrapSkip(t *testing.T) {
+	var before, after []Event
+	db := sql.OpenDB(WrapConnector(&skipConn{},
+		WithBefore(func(ctx context.Context, event *Event) context.Context {
+			before = append(before, *event)
+			return ctx
+		}),
+		WithAfter(func(ctx context.Context, event *Event) {
+			after = append(after, *event)
+		})))
+	defer db.Close()
+
+	// database/sql prepares the statements the driver skips
+	_, err := db.Exec("DELETE FROM users WHERE id = ?", 1)
+	assert.NoError(t, err)
+	rows, err := db.Query("SELECT * FROM users WHERE id = ?", 1)
+	assert.NoError(t, err)
+	assert.NoError(t, rows.Close())
+
+	// every before is followed by its after
+	assert.Len(t, before, 6)
+	if assert.Len(t, after, 6) {
+		for i, operation := range []Operation{OperationExec, OperationPrepare, OperationExec, OperationQuery, OperationPrepare, OperationQuery} {
+			assert.Equal(t, operation, before[i].Operation)
+			assert.Equal(t, operation, after[i].Operation)
+		}
+		assert.ErrorIs(t, after[0].Err, driver.ErrSkip)
+		assert.NoError(t, after[2].Err)
+		assert.ErrorIs(t, after[3].Err, driver.ErrSkip)
+		// the statement is sanitized once, when the driver skips it
+		assert.Same(t, after[0].Statement, after[1].Statement)
+		assert.Same(t, after[3].Statement, after[4].Statement)
+	}
+}
+
+func TestWrapColumnConverter(t *testing.T) {
+	conn := &converterConn{}
+	r := &recorder{}
+	db := sql.OpenDB(WrapConnector(conn, r.options()...))
+	defer db.Close()
+
+	stmt, err := db.Prepare("INSERT INTO points VALUES (?)")
+	assert.NoError(t, err)
+	defer stmt.Close()
+	// the arguments are converted by the converter of the statement, not by the default converter
+	_, err = stmt.Exec(point{1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []driver.Value{"1,2"}, conn.values)
+	assert.Len(t, r.events, 2)
+}
+
+func TestWrap(t *testing.T) {
+	r := &recorder{}
+	sql.Register("sqldriver-fake", Wrap(fakeDriver{}, append(r.options(), WithDBMS(sqllexer.DBMSPostgres))...))
+	db, err := sql.Open("sqldriver-fake", "")
+	assert.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec("SELECT $tag$secret$tag$ FROM users WHERE id = $1", 1)
+	assert.NoError(t, err)
+	if assert.Len(t, r.events, 1) {
+		assert.Equal(t, "SELECT ? FROM users WHERE id = $1", r.events[0].Statement.NormalizedSQL)
+	}
+}
+
+func TestWithoutCallbacks(t *testing.T) {
+	db := sql.OpenDB(WrapConnector(fakeConnector{}))
+	defer db.Close()
+
+	_, err := db.Exec("DELETE FROM fail")
+	assert.ErrorIs(t, err, errFake)
+	assert.NoError(t, db.Ping())
+}
+
+func ExampleWrapConnector() {
+	db := sql.OpenDB(WrapConnector(fakeConnector{}, WithAfter(func(ctx context.Context, event *Event) {
+		fmt.Println(event.Operation, event.Statement.NormalizedSQL, event.Statement.Metadata.Tables)
+	})))
+	defer db.Close()
+
+	_, _ = db.Exec("INSERT INTO orders (id, total) VALUES (1, 9.99)")
+	// Output: exec INSERT INTO orders ( id, total ) VALUES ( ? ) [orders]
+}
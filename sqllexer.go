@@ -2,6 +2,7 @@ package sqllexer
 
 import (
 	"strings"
+	"sync"
 	"unicode/utf8"
 )
 
@@ -10,39 +11,48 @@ type TokenType int
 const (
 	ERROR TokenType = iota
 	EOF
-	SPACE                  // space or newline
-	STRING                 // string literal
-	INCOMPLETE_STRING      // incomplete string literal so that we can obfuscate it, e.g. 'abc
-	NUMBER                 // number literal
-	IDENT                  // identifier
-	QUOTED_IDENT           // quoted identifier
-	OPERATOR               // operator
-	WILDCARD               // wildcard *
-	COMMENT                // comment
-	MULTILINE_COMMENT      // multiline comment
-	PUNCTUATION            // punctuation
-	DOLLAR_QUOTED_FUNCTION // dollar quoted function
-	DOLLAR_QUOTED_STRING   // dollar quoted string
-	POSITIONAL_PARAMETER   // numbered parameter
-	BIND_PARAMETER         // bind parameter
-	FUNCTION               // function
-	SYSTEM_VARIABLE        // system variable
-	UNKNOWN                // unknown token
-	COMMAND                // SQL commands like SELECT, INSERT
-	KEYWORD                // Other SQL keywords
-	JSON_OP                // JSON operators
-	BOOLEAN                // boolean literal
-	NULL                   // null literal
-	PROC_INDICATOR         // procedure indicator
-	CTE_INDICATOR          // CTE indicator
-	ALIAS_INDICATOR        // alias indicator
+	SPACE                   // space or newline
+	STRING                  // string literal
+	INCOMPLETE_STRING       // incomplete string literal so that we can obfuscate it, e.g. 'abc
+	NUMBER                  // number literal
+	IDENT                   // identifier
+	QUOTED_IDENT            // quoted identifier
+	OPERATOR                // operator
+	WILDCARD                // wildcard *
+	COMMENT                 // comment
+	MULTILINE_COMMENT       // multiline comment
+	PUNCTUATION             // punctuation
+	DOLLAR_QUOTED_FUNCTION  // dollar quoted function
+	DOLLAR_QUOTED_STRING    // dollar quoted string
+	POSITIONAL_PARAMETER    // positional parameter, e.g. $1
+	BIND_PARAMETER          // bind parameter
+	FUNCTION                // function
+	SYSTEM_VARIABLE         // system variable
+	UNKNOWN                 // unknown token
+	COMMAND                 // SQL commands like SELECT, INSERT
+	KEYWORD                 // Other SQL keywords
+	JSON_OP                 // JSON operators
+	BOOLEAN                 // boolean literal
+	NULL                    // null literal
+	PROC_INDICATOR          // procedure indicator
+	CTE_INDICATOR           // CTE indicator
+	ALIAS_INDICATOR         // alias indicator
+	TEMPLATE                // template expression, e.g. {{ .Table }}
+	INCOMPLETE_COMMENT      // incomplete multiline comment so that we can obfuscate it, e.g. /* abc
+	INCOMPLETE_QUOTED_IDENT // incomplete quoted identifier so that we can obfuscate it, e.g. "abc
+	PLACEHOLDER             // question mark placeholder, e.g. ? or ?1, only lexed WithQuestionMarkPlaceholders
 )
 
 // Token represents a SQL token with its type and value.
 type Token struct {
 	Type             TokenType
 	Value            string
+	Start            int            // byte offset of the token in the input
+	End              int            // byte offset following the token in the input
+	Line             int            // line of the token, starting at 1, only set WithStartPosition
+	Column           int            // column of the token in runes, starting at 1, only set WithStartPosition
 	isTableIndicator bool           // true if the token is a table indicator
+	isTemporaryTable bool           // true if the token names a temporary table
 	digits           []int          // private - only used by replaceDigits
 	quotes           []int          // private - only used by trimQuotes
 	lastValueToken   LastValueToken // private - internal state
@@ -62,8 +72,93 @@ func (t *Token) getLastValueToken() *LastValueToken {
 	return &t.lastValueToken
 }
 
+// IsTemporaryTable returns true if the token names a temporary table: a SQL Server #name or ##name,
+// a PostgreSQL pg_temp.name, or the table of CREATE TEMPORARY TABLE name or CREATE TEMP TABLE name.
+// The later references of a table created temporary by its name only are not recognized.
+func (t *Token) IsTemporaryTable() bool {
+	return t.isTemporaryTable
+}
+
+// SystemVariable returns the scope and the name of a SYSTEM_VARIABLE token, e.g. GLOBAL and sql_mode
+// for @@global.sql_mode. The scope is uppercase, and empty if the variable has none, e.g. @@VERSION.
+// It returns empty strings for the other tokens, and for the tokens lexed WithOffsetsOnly.
+func (t *Token) SystemVariable() (scope, name string) {
+	if t.Type != SYSTEM_VARIABLE || len(t.Value) < 2 {
+		return "", ""
+	}
+	name = t.Value[2:]
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		if scope = systemVariableScope(name[:i]); scope != "" {
+			name = name[i+1:]
+		}
+	}
+	return scope, name
+}
+
 type LexerConfig struct {
 	DBMS DBMSType `json:"dbms,omitempty"`
+
+	// Hardened guarantees that Scan never panics and always advances, returning ERROR tokens instead,
+	// for inputs which may be hostile
+	Hardened bool `json:"hardened"`
+
+	// TemplateDelimiters are the delimiters of the template expressions lexed as TEMPLATE tokens
+	TemplateDelimiters []TemplateDelimiter `json:"template_delimiters,omitempty"`
+
+	// OffsetsOnly leaves the values of the tokens empty, only their types and offsets are set.
+	// The value of a token is then read with Lexer.ValueAt.
+	OffsetsOnly bool `json:"offsets_only"`
+
+	// CompoundKeywords scans the phrases of keywords, e.g. GROUP BY or IS NOT NULL, as single tokens
+	CompoundKeywords bool `json:"compound_keywords"`
+
+	// SkipWhitespace skips the whitespace between the tokens instead of returning it as SPACE tokens
+	SkipWhitespace bool `json:"skip_whitespace"`
+
+	// QuestionMarkIdentifiers scans the question marks following an identifier as part of it, e.g. a?b,
+	// as the lexer used to, instead of as placeholders
+	QuestionMarkIdentifiers bool `json:"question_mark_identifiers"`
+
+	// QuestionMarkPlaceholders lexes the question marks as PLACEHOLDER tokens instead of operators
+	QuestionMarkPlaceholders bool `json:"question_mark_placeholders"`
+
+	// NoDoubledQuoteEscapes ends the strings at a doubled quote, e.g. 'it''s' is lexed as 'it' and 's',
+	// as the lexer used to, instead of reading the doubled quote as an escaped quote
+	NoDoubledQuoteEscapes bool `json:"no_doubled_quote_escapes"`
+
+	// SplitQualifiedIdentifiers lexes the parts of the qualified identifiers and their dots as separate tokens,
+	// e.g. users.name as users, . and name
+	SplitQualifiedIdentifiers bool `json:"split_qualified_identifiers"`
+
+	// NoFunctions lexes the names followed by a parenthesis as identifiers or keywords instead of functions
+	NoFunctions bool `json:"no_functions"`
+
+	// StartPosition is the position of the input in the document it is extracted from, e.g. a migration file.
+	// The offsets of the tokens are then offsets in the document, and their lines and columns are set.
+	StartPosition *Position `json:"start_position,omitempty"`
+
+	// PhaseTimings is called with the time spent in each phase of the operations processing a query
+	PhaseTimings PhaseTimings `json:"-"`
+}
+
+// Position is a position in a document
+type Position struct {
+	Offset int `json:"offset"` // byte offset, starting at 0
+	Line   int `json:"line"`   // line, starting at 1
+	Column int `json:"column"` // column in runes, starting at 1
+}
+
+// TemplateDelimiter delimits the template expressions of templated SQL, e.g. {{ and }} for Go templates.
+// If Close is empty, the expression is Open followed by a name, e.g. :param.
+type TemplateDelimiter struct {
+	Open  string `json:"open"`
+	Close string `json:"close,omitempty"`
+}
+
+// DefaultTemplateDelimiters are the delimiters of Go templates, {{ .Table }}, and of shell-like variables, ${var}
+var DefaultTemplateDelimiters = []TemplateDelimiter{
+	{Open: "{{", Close: "}}"},
+	{Open: "${", Close: "}"},
 }
 
 type lexerOption func(*LexerConfig)
@@ -75,11 +170,109 @@ func WithDBMS(dbms DBMSType) lexerOption {
 	}
 }
 
-type trieNode struct {
-	children         map[rune]*trieNode
-	isEnd            bool
-	tokenType        TokenType
-	isTableIndicator bool
+// WithHardened sets the hardened mode of the lexer. In hardened mode, every token but EOF advances the cursor
+// by at least one byte, so scanning an input takes at most len(input)+1 calls to Scan, EOF is only returned
+// at the end of the input, and the lexer never panics. The bytes which cannot be scanned, e.g. NUL bytes,
+// and the token being scanned when the lexer would panic, are returned as ERROR tokens of a single character.
+func WithHardened(hardened bool) lexerOption {
+	return func(c *LexerConfig) {
+		c.Hardened = hardened
+	}
+}
+
+// WithOffsetsOnly leaves the values of the tokens empty, for the callers which only need their types and offsets,
+// e.g. to fingerprint or classify queries. Use Lexer.ValueAt to read the value of a token.
+// The normalizer and the obfuscator read the values of the tokens, so it is not an option for them.
+func WithOffsetsOnly(offsetsOnly bool) lexerOption {
+	return func(c *LexerConfig) {
+		c.OffsetsOnly = offsetsOnly
+	}
+}
+
+// WithCompoundKeywords scans the phrases of keywords, e.g. GROUP BY, INSERT INTO, LEFT OUTER JOIN or IS NOT NULL,
+// as single tokens, whose value is the phrase as written. A phrase is a COMMAND if one of its keywords is,
+// a KEYWORD otherwise. The obfuscator and the normalizer scan the keywords one by one regardless.
+func WithCompoundKeywords(compoundKeywords bool) lexerOption {
+	return func(c *LexerConfig) {
+		c.CompoundKeywords = compoundKeywords
+	}
+}
+
+// WithSkipWhitespace skips the whitespace while scanning, so no SPACE token is returned. It is cheaper than
+// filtering the SPACE tokens out, as they are never built. The whitespace is still in the input between the tokens.
+func WithSkipWhitespace(skipWhitespace bool) lexerOption {
+	return func(c *LexerConfig) {
+		c.SkipWhitespace = skipWhitespace
+	}
+}
+
+// WithQuestionMarkIdentifiers scans the question marks following an identifier as part of it, e.g. a?b or name?,
+// as the previous versions of the lexer did. It is needed to lex SQL obfuscated WithReplaceDigits, whose
+// identifiers hold the placeholders of their digits, e.g. users? for users1. By default, a question mark
+// is a placeholder, and a?b is lexed as a, ? and b.
+func WithQuestionMarkIdentifiers(questionMarkIdentifiers bool) lexerOption {
+	return func(c *LexerConfig) {
+		c.QuestionMarkIdentifiers = questionMarkIdentifiers
+	}
+}
+
+// WithQuestionMarkPlaceholders lexes the question marks as PLACEHOLDER tokens, followed by their number
+// if they are numbered, e.g. ?1 in SQLite, for the queries whose question marks are bind parameters.
+// By default, a question mark is an OPERATOR token, as it is also the PostgreSQL JSON operator.
+// The JSON operators ?| and ?& are still JSON_OP tokens.
+func WithQuestionMarkPlaceholders(questionMarkPlaceholders bool) lexerOption {
+	return func(c *LexerConfig) {
+		c.QuestionMarkPlaceholders = questionMarkPlaceholders
+	}
+}
+
+// WithNoDoubledQuoteEscapes ends the strings at a doubled quote as the previous versions of the lexer did,
+// so a string holding a doubled quote is lexed as two adjacent strings. By default, a doubled quote in a string is the
+// standard SQL escape of a quote, and the string ends at the first quote which is not doubled.
+func WithNoDoubledQuoteEscapes(noDoubledQuoteEscapes bool) lexerOption {
+	return func(c *LexerConfig) {
+		c.NoDoubledQuoteEscapes = noDoubledQuoteEscapes
+	}
+}
+
+// WithSplitQualifiedIdentifiers lexes the parts of the qualified identifiers as separate tokens, the dots
+// between them being PUNCTUATION tokens, e.g. users.name as users, . and name, and "public"."users" as
+// "public", . and "users". By default, a qualified identifier is a single token.
+func WithSplitQualifiedIdentifiers(splitQualifiedIdentifiers bool) lexerOption {
+	return func(c *LexerConfig) {
+		c.SplitQualifiedIdentifiers = splitQualifiedIdentifiers
+	}
+}
+
+// WithNoFunctions disables the classification of the function calls: the names followed by a parenthesis,
+// e.g. count(*), are lexed as identifiers, and the keywords, e.g. LEFT(name, 2), as keywords.
+// By default, a name followed by a parenthesis is a FUNCTION, unless it is a keyword reserved in the dialect,
+// e.g. VALUES(1), and the built-in functions, e.g. count (*), may have whitespace before the parenthesis.
+func WithNoFunctions(noFunctions bool) lexerOption {
+	return func(c *LexerConfig) {
+		c.NoFunctions = noFunctions
+	}
+}
+
+// WithStartPosition sets the position of the input in the document it is extracted from, e.g. a migration file,
+// a heredoc or a templated source, so the positions of the tokens are in the coordinates of the document:
+// their offsets are shifted by offset, and their lines and columns are set, the input starting at line and column.
+// The offsets of the tokens then no longer index the input, Lexer.ValueAt must be used to read their values.
+// Validate supports it, the other functions slicing their input with the offsets of the tokens do not.
+func WithStartPosition(offset, line, column int) lexerOption {
+	return func(c *LexerConfig) {
+		c.StartPosition = &Position{Offset: offset, Line: line, Column: column}
+	}
+}
+
+// WithTemplateDelimiters lexes the template expressions delimited by the delimiters as TEMPLATE tokens,
+// so templated SQL can be tokenized before it is rendered, e.g. WithTemplateDelimiters(DefaultTemplateDelimiters...).
+// Template expressions are detected everywhere but inside strings, comments and quoted identifiers.
+// An expression which is not closed extends to the end of the input.
+func WithTemplateDelimiters(delimiters ...TemplateDelimiter) lexerOption {
+	return func(c *LexerConfig) {
+		c.TemplateDelimiters = delimiters
+	}
 }
 
 // SQL Lexer inspired from Rob Pike's talk on Lexical Scanning in Go
@@ -87,27 +280,195 @@ type Lexer struct {
 	src              string // the input src string
 	cursor           int    // the current position of the cursor
 	start            int    // the start position of the current token
-	config           *LexerConfig
-	token            *Token
-	digits           []int // Indexes of digits in the token
-	quotes           []int // Indexes of quotes in the token
-	isTableIndicator bool  // true if the token is a table indicator
+	config           LexerConfig
+	token            Token
+	position         Position // position of the byte at positionOffset, tracked WithStartPosition
+	positionOffset   int
+	dialect          dialectFeature // the features of the DBMS, resolved from the config
+	afterOperand     bool           // true if the last value token ends an operand, a sign following it is an operator
+	temporaryTable   int            // 1 after TEMPORARY, 2 after TEMPORARY TABLE, whose following name is a temporary table
+	digits           []int          // Indexes of digits in the token
+	quotes           []int          // Indexes of quotes in the token
+	isTableIndicator bool           // true if the token is a table indicator
 }
 
 func New(input string, opts ...lexerOption) *Lexer {
-	lexer := &Lexer{
+	lexer := &Lexer{}
+	lexer.reset(input, opts...)
+	return lexer
+}
+
+var lexerPool = sync.Pool{
+	New: func() any {
+		return &Lexer{}
+	},
+}
+
+// GetLexer returns a lexer of the input from a pool, reusing the lexers released with PutLexer,
+// so processing many queries does not allocate a lexer per query
+func GetLexer(input string, opts ...lexerOption) *Lexer {
+	lexer := lexerPool.Get().(*Lexer)
+	lexer.reset(input, opts...)
+	return lexer
+}
+
+// PutLexer releases the lexer to the pool. Neither the lexer nor its last token may be used afterwards.
+func PutLexer(lexer *Lexer) {
+	// do not retain the input while the lexer is pooled
+	lexer.reset("")
+	lexerPool.Put(lexer)
+}
+
+// reset prepares the lexer to scan a new input, reusing its buffers
+func (s *Lexer) reset(input string, opts ...lexerOption) {
+	*s = Lexer{
 		src:    input,
-		config: &LexerConfig{},
-		token:  &Token{},
+		digits: s.digits[:0],
+		quotes: s.quotes[:0],
 	}
 	for _, opt := range opts {
-		opt(lexer.config)
+		opt(&s.config)
+	}
+	if s.config.StartPosition != nil {
+		s.position = *s.config.StartPosition
+	}
+	s.dialect = dialectFeatures(s.config.DBMS)
+}
+
+// baseOffset returns the offset of the input in the document it is extracted from, 0 without WithStartPosition
+func (s *Lexer) baseOffset() int {
+	if s.config.StartPosition != nil {
+		return s.config.StartPosition.Offset
+	}
+	return 0
+}
+
+// advancePosition moves the tracked position to the byte offset of the input
+func (s *Lexer) advancePosition(offset int) {
+	for s.positionOffset < offset {
+		b := s.src[s.positionOffset]
+		switch {
+		case b == '\n':
+			s.position.Line++
+			s.position.Column = 1
+			s.positionOffset++
+		case b < utf8.RuneSelf:
+			s.position.Column++
+			s.positionOffset++
+		default:
+			_, size := utf8.DecodeRuneInString(s.src[s.positionOffset:])
+			s.position.Column++
+			s.positionOffset += size
+		}
 	}
-	return lexer
+}
+
+// ValueAt returns the value of the token, which is empty if the lexer is created WithOffsetsOnly
+func (s *Lexer) ValueAt(token Token) string {
+	base := s.baseOffset()
+	return s.src[token.Start-base : token.End-base]
 }
 
 // Scan scans the next token and returns it.
+// Scanning an input is linear in its length: every token but EOF consumes the input it is scanned from,
+// which is only read again to look a few runes past it. An unterminated string, quoted identifier, comment
+// or dollar-quoted string extends to the end of the input, so it is scanned once.
 func (s *Lexer) Scan() *Token {
+	if s.config.SkipWhitespace {
+		s.skipSpaces()
+		s.start = s.cursor
+	}
+	if s.config.Hardened {
+		return s.scanHardened()
+	}
+	start := s.cursor
+	token := s.scan()
+	if s.cursor == start && token.Type != EOF {
+		// a token which consumes nothing would be scanned again forever, its character is an error instead
+		return s.emitHardenedError(start)
+	}
+	return token
+}
+
+// ScanEach scans the tokens of the input up to EOF, excluded, calling fn with each of them by value,
+// and stops early if fn returns false. The tokens are copies which do not escape to the heap,
+// so scanning does not allocate, while the token returned by Scan is reused by the next call.
+func (s *Lexer) ScanEach(fn func(Token) bool) {
+	for token := s.Scan(); token.Type != EOF; token = s.Scan() {
+		if !fn(*token) {
+			return
+		}
+	}
+}
+
+// Mark is the state of a lexer between two tokens, to which it is reset with ResetTo
+type Mark struct {
+	cursor         int
+	lastValueToken LastValueToken
+	position       Position
+	positionOffset int
+	afterOperand   bool
+	temporaryTable int
+}
+
+// Mark returns the state of the lexer, so the tokens scanned after it can be scanned again after ResetTo,
+// e.g. to look ahead without buffering the tokens
+func (s *Lexer) Mark() Mark {
+	return Mark{
+		cursor:         s.cursor,
+		lastValueToken: s.token.lastValueToken,
+		position:       s.position,
+		positionOffset: s.positionOffset,
+		afterOperand:   s.afterOperand,
+		temporaryTable: s.temporaryTable,
+	}
+}
+
+// ResetTo resets the lexer to the mark returned by its Mark, the next token scanned being the one which followed it.
+// The token returned by the last Scan is not restored.
+func (s *Lexer) ResetTo(mark Mark) {
+	s.cursor, s.start = mark.cursor, mark.cursor
+	s.token.lastValueToken = mark.lastValueToken
+	s.position, s.positionOffset = mark.position, mark.positionOffset
+	s.afterOperand, s.temporaryTable = mark.afterOperand, mark.temporaryTable
+	s.digits, s.quotes, s.isTableIndicator = s.digits[:0], s.quotes[:0], false
+}
+
+// scanHardened scans the next token, returning an ERROR token instead of panicking or not advancing the cursor
+func (s *Lexer) scanHardened() (token *Token) {
+	start := s.cursor
+	defer func() {
+		if r := recover(); r != nil {
+			token = s.emitHardenedError(start)
+		}
+	}()
+	token = s.scan()
+	if token.Type == EOF {
+		if start < len(s.src) {
+			// a NUL byte, which is scanned as the end of the input
+			return s.emitHardenedError(start)
+		}
+	} else if base := s.baseOffset(); token.End-base <= start || token.Start-base != start {
+		return s.emitHardenedError(start)
+	}
+	return token
+}
+
+// emitHardenedError emits the character at start as an ERROR token, so the scan makes progress
+func (s *Lexer) emitHardenedError(start int) *Token {
+	_, size := utf8.DecodeRuneInString(s.src[start:])
+	s.start, s.cursor = start, start+size
+	s.digits, s.quotes, s.isTableIndicator = s.digits[:0], s.quotes[:0], false
+	return s.emit(ERROR)
+}
+
+// scan scans the next token
+func (s *Lexer) scan() *Token {
+	if len(s.config.TemplateDelimiters) > 0 {
+		if token := s.scanTemplate(); token != nil {
+			return token
+		}
+	}
 	ch := s.peek()
 	switch {
 	case isSpace(ch):
@@ -125,8 +486,9 @@ func (s *Lexer) Scan() *Token {
 	case isLeadingSign(ch):
 		// if the leading sign is followed by a digit, then it's a number
 		// although this is not strictly true, it's good enough for our purposes
+		// unless it follows an operand, e.g. 1-1 or (a)-1, where it is a binary operator
 		nextCh := s.lookAhead(1)
-		if isDigit(nextCh) || nextCh == '.' {
+		if !s.afterOperand && (isDigit(nextCh) || nextCh == '.' && isDigit(s.lookAhead(2))) {
 			return s.scanNumberWithLeadingSign()
 		}
 		return s.scanOperator(ch)
@@ -139,24 +501,24 @@ func (s *Lexer) Scan() *Token {
 			// if the dollar sign is followed by a digit, then it's a numbered parameter
 			return s.scanPositionalParameter()
 		}
-		if s.config.DBMS == DBMSSQLServer && isLetter(s.lookAhead(1)) {
+		if s.dialect&dialectDollarIdentifiers != 0 && isLetter(s.lookAhead(1)) {
 			return s.scanIdentifier(ch)
 		}
 		return s.scanDollarQuotedString()
 	case ch == ':':
-		if s.config.DBMS == DBMSOracle && isAlphaNumeric(s.lookAhead(1)) {
+		if s.dialect&dialectColonBindParameters != 0 && isAlphaNumeric(s.lookAhead(1)) {
 			return s.scanBindParameter()
 		}
 		return s.scanOperator(ch)
 	case ch == '`':
-		if s.config.DBMS == DBMSMySQL {
+		if s.dialect&dialectBacktickQuotes != 0 {
 			return s.scanDoubleQuotedIdentifier('`')
 		}
 		return s.scanUnknown() // backtick is only valid in mysql
 	case ch == '#':
-		if s.config.DBMS == DBMSSQLServer {
+		if s.dialect&dialectHashIdentifiers != 0 {
 			return s.scanIdentifier(ch)
-		} else if s.config.DBMS == DBMSMySQL {
+		} else if s.dialect&dialectHashComments != 0 {
 			// MySQL treats # as a comment
 			return s.scanSingleLineComment(ch)
 		}
@@ -171,7 +533,7 @@ func (s *Lexer) Scan() *Token {
 			return s.emit(JSON_OP)
 		}
 		if isAlphaNumeric(s.lookAhead(1)) {
-			if s.config.DBMS == DBMSSnowflake {
+			if s.dialect&dialectAtIdentifiers != 0 {
 				return s.scanIdentifier(ch)
 			}
 			return s.scanBindParameter()
@@ -185,7 +547,7 @@ func (s *Lexer) Scan() *Token {
 	case isOperator(ch):
 		return s.scanOperator(ch)
 	case isPunctuation(ch):
-		if ch == '[' && s.config.DBMS == DBMSSQLServer {
+		if ch == '[' && s.dialect&dialectBracketQuotes != 0 {
 			return s.scanDoubleQuotedIdentifier('[')
 		}
 		return s.scanPunctuation()
@@ -242,18 +604,6 @@ func (s *Lexer) next() rune {
 	return s.nextBy(1)
 }
 
-func (s *Lexer) matchAt(match []rune) bool {
-	if s.cursor+len(match) > len(s.src) {
-		return false
-	}
-	for i, ch := range match {
-		if s.src[s.cursor+i] != byte(ch) {
-			return false
-		}
-	}
-	return true
-}
-
 func (s *Lexer) scanNumberWithLeadingSign() *Token {
 	s.start = s.cursor
 	ch := s.next() // consume the leading sign
@@ -268,11 +618,14 @@ func (s *Lexer) scanNumber(ch rune) *Token {
 func (s *Lexer) scanNumberic(ch rune) *Token {
 	s.start = s.cursor
 	if ch == '0' {
-		nextCh := s.lookAhead(1)
-		if nextCh == 'x' || nextCh == 'X' {
-			return s.scanHexNumber()
-		} else if nextCh >= '0' && nextCh <= '7' {
-			return s.scanOctalNumber()
+		// a prefix without digits, e.g. 0x, is the number 0 followed by an identifier
+		switch nextCh := s.lookAhead(1); {
+		case (nextCh == 'x' || nextCh == 'X') && isHexDigit(s.lookAhead(2)):
+			return s.scanPrefixedNumber(isHexDigit)
+		case (nextCh == 'o' || nextCh == 'O') && s.dialect&dialectOctalLiterals != 0 && isOctalDigit(s.lookAhead(2)):
+			return s.scanPrefixedNumber(isOctalDigit)
+		case (nextCh == 'b' || nextCh == 'B') && s.dialect&dialectBinaryLiterals != 0 && isBinaryDigit(s.lookAhead(2)):
+			return s.scanPrefixedNumber(isBinaryDigit)
 		}
 	}
 
@@ -280,34 +633,44 @@ func (s *Lexer) scanNumberic(ch rune) *Token {
 	return s.scanDecimalNumber(ch)
 }
 
+// scanDecimalNumber scans the digits of a decimal number, with a single decimal point and an exponent.
+// The number ends before a second decimal point, e.g. 1.2 in 1.2.3, and before an exponent without digits,
+// e.g. 1 in 1e or 1e+, the rest of the input being scanned as the next tokens.
 func (s *Lexer) scanDecimalNumber(ch rune) *Token {
-	// scan digits
-	for isDigit(ch) || ch == '.' || isExpontent(ch) {
-		if isExpontent(ch) {
+	decimalPoint := false
+	for {
+		switch {
+		case isDigit(ch):
+			ch = s.next()
+		case ch == '.' && !decimalPoint && s.lookAhead(1) != '.':
+			// the range operator of 1..2 is not a decimal point
+			decimalPoint = true
 			ch = s.next()
-			if isLeadingSign(ch) {
+		case isExpontent(ch):
+			n := 1 // the length of the exponent marker, with its sign
+			if isLeadingSign(s.lookAhead(1)) {
+				n = 2
+			}
+			if !isDigit(s.lookAhead(n)) {
+				return s.emit(NUMBER)
+			}
+			for ch = s.nextBy(n); isDigit(ch); {
 				ch = s.next()
 			}
-		} else {
-			ch = s.next()
+			return s.emit(NUMBER)
+		default:
+			return s.emit(NUMBER)
 		}
 	}
-	return s.emit(NUMBER)
-}
-
-func (s *Lexer) scanHexNumber() *Token {
-	ch := s.nextBy(2) // consume 0x or 0X
-
-	for isDigit(ch) || ('a' <= ch && ch <= 'f') || ('A' <= ch && ch <= 'F') {
-		ch = s.next()
-	}
-	return s.emit(NUMBER)
 }
 
-func (s *Lexer) scanOctalNumber() *Token {
-	ch := s.nextBy(2) // consume the leading 0 and number
+// scanPrefixedNumber scans a number prefixed with its base, e.g. 0x1F, 0o17 or 0b101, up to its last digit:
+// the characters following it, e.g. GHI in 0x123GHI, are scanned as the next tokens.
+// The value of the number is not computed, so it cannot overflow, whatever its length.
+func (s *Lexer) scanPrefixedNumber(isBaseDigit func(rune) bool) *Token {
+	ch := s.nextBy(2) // consume the 0 and the base
 
-	for '0' <= ch && ch <= '7' {
+	for isBaseDigit(ch) {
 		ch = s.next()
 	}
 	return s.emit(NUMBER)
@@ -315,23 +678,29 @@ func (s *Lexer) scanOctalNumber() *Token {
 
 func (s *Lexer) scanString() *Token {
 	s.start = s.cursor
+	// LIKE...ESCAPE clause accepts only one character, backslash included
+	backslashEscapes := !equalFoldASCII(s.token.lastValueToken.Value, "ESCAPE")
 	escaped := false
 
-	for ch := s.next(); !isEOF(ch); ch = s.next() {
-		if escaped {
+	// the quotes and backslashes are ASCII, so the string is scanned byte by byte
+	for s.cursor++; s.cursor < len(s.src); s.cursor++ {
+		switch b := s.src[s.cursor]; {
+		case b == 0:
+			// a NUL byte is read as EOF
+			return s.emit(INCOMPLETE_STRING)
+		case escaped:
 			// encountered an escape character
 			// reset the escaped flag and continue
 			escaped = false
-			continue
-		}
-
-		if ch == '\\' && !strings.EqualFold(s.token.lastValueToken.Value, "ESCAPE") { // LIKE...ESCAPE clause accepts only one character, backslash included
+		case b == '\\' && backslashEscapes:
 			escaped = true
-			continue
-		}
-
-		if ch == '\'' {
-			s.next() // consume the closing quote
+		case b == '\'':
+			if s.cursor+1 < len(s.src) && s.src[s.cursor+1] == '\'' && !s.config.NoDoubledQuoteEscapes {
+				// a doubled quote is an escaped quote, e.g. 'it''s'
+				s.cursor++
+				continue
+			}
+			s.cursor++ // consume the closing quote
 			return s.emit(STRING)
 		}
 	}
@@ -341,73 +710,112 @@ func (s *Lexer) scanString() *Token {
 
 func (s *Lexer) scanIdentifier(ch rune) *Token {
 	s.start = s.cursor
-	node := keywordRoot
-	pos := s.cursor
-
 	offset := s.start // offset is used to calculate the indexes of digits in the token value
 
-	// If first character is Unicode, skip trie lookup
+	// If first character is Unicode, skip keyword lookup
 	if ch > 127 {
-		for isIdentifier(ch) {
-			if isDigit(ch) {
-				s.digits = append(s.digits, s.cursor-offset)
-			}
-			ch = s.nextBy(utf8.RuneLen(ch))
-		}
+		s.scanIdentifierChars(offset)
 		if s.start == s.cursor {
 			return s.scanUnknown()
 		}
 		return s.emit(IDENT)
 	}
 
-	// ASCII characters - try keyword matching
-	for isAsciiLetter(ch) || ch == '_' {
-		// Convert to uppercase for case-insensitive matching
-		upperCh := ch
-		if ch >= 'a' && ch <= 'z' {
-			upperCh -= 32
-		}
-
-		// Try to follow trie path
-		if next, exists := node.children[upperCh]; exists {
-			node = next
-			pos = s.cursor
-			ch = s.next()
-		} else {
-			// No more matches possible in trie
-			// Reset node for next potential keyword
-			// and continue scanning identifier
-			node = keywordRoot
-			ch = s.next()
-			break
-		}
+	// ASCII characters - the run of letters and underscores may be a keyword
+	for s.cursor < len(s.src) && (isAsciiLetter(rune(s.src[s.cursor])) || s.src[s.cursor] == '_') {
+		s.cursor++
 	}
 
-	// If we found a complete keyword and next char is whitespace
-	if node.isEnd && (isPunctuation(ch) || isSpace(ch) || isEOF(ch)) {
-		s.cursor = pos + 1 // Include the last matched character
-		s.isTableIndicator = node.isTableIndicator
-		return s.emit(node.tokenType)
+	// A keyword is followed by a punctuation, a space or the end of the input, which are ASCII
+	ch = 0
+	if s.cursor < len(s.src) {
+		ch = rune(s.src[s.cursor])
 	}
-
-	// Continue scanning identifier if no keyword match
-	for isIdentifier(ch) {
-		if isDigit(ch) {
-			s.digits = append(s.digits, s.cursor-offset)
+	// the parts following a dot are names, e.g. order in t.order
+	afterDot := s.config.SplitQualifiedIdentifiers && s.start > 0 && s.src[s.start-1] == '.'
+	if (isPunctuation(ch) || isSpace(ch) || isEOF(ch)) && !afterDot {
+		if kw := lookupKeyword(s.src[s.start:s.cursor]); kw != nil {
+			if s.isFunctionKeyword(kw) && s.isFunctionCall(false) {
+				return s.emit(FUNCTION)
+			}
+			if s.config.CompoundKeywords {
+				if compound := s.scanCompoundKeyword(kw); compound != nil {
+					s.isTableIndicator = compound.isTableIndicator
+					return s.emit(compound.tokenType)
+				}
+			}
+			s.isTableIndicator = kw.isTableIndicator
+			token := s.emit(kw.tokenType)
+			// share the value of the keyword instead of retaining the input, if it has the same case
+			if token.Value == kw.value {
+				token.Value = kw.value
+			} else if token.Value == kw.lower {
+				token.Value = kw.lower
+			}
+			return token
 		}
-		ch = s.nextBy(utf8.RuneLen(ch))
 	}
 
+	// Continue scanning identifier if no keyword match
+	ch = s.scanIdentifierChars(offset)
+
 	if s.start == s.cursor {
 		return s.scanUnknown()
 	}
 
-	if ch == '(' {
+	if s.isFunctionCall(ch != '(' && isBuiltinFunction(s.src[s.start:s.cursor])) {
 		return s.emit(FUNCTION)
 	}
 	return s.emit(IDENT)
 }
 
+// scanIdentifierChars advances the cursor past the identifier characters, recording the indexes of the digits
+// relative to offset, and returns the character following them
+func (s *Lexer) scanIdentifierChars(offset int) rune {
+	if s.config.SplitQualifiedIdentifiers {
+		return s.scanIdentifierPart(offset)
+	}
+	for s.cursor < len(s.src) {
+		// Fast path for ASCII
+		s.skipASCIIIdentifier(offset)
+		if s.cursor == len(s.src) {
+			break
+		}
+		if b := s.src[s.cursor]; b < utf8.RuneSelf {
+			if b == '?' && s.config.QuestionMarkIdentifiers {
+				s.cursor++
+				continue
+			}
+			return rune(b)
+		}
+		// Slow path for non-ASCII
+		r, size := utf8.DecodeRuneInString(s.src[s.cursor:])
+		if !isIdentifier(r) {
+			return r
+		}
+		s.cursor += size
+	}
+	return 0
+}
+
+// scanIdentifierPart is scanIdentifierChars stopping at the dots, WithSplitQualifiedIdentifiers
+func (s *Lexer) scanIdentifierPart(offset int) rune {
+	for s.cursor < len(s.src) {
+		r, size := rune(s.src[s.cursor]), 1
+		if r >= utf8.RuneSelf {
+			r, size = utf8.DecodeRuneInString(s.src[s.cursor:])
+		}
+		if r == '.' || !(isIdentifier(r) || r == '?' && s.config.QuestionMarkIdentifiers) {
+			return r
+		}
+		if isDigit(r) {
+			s.digits = append(s.digits, s.cursor-offset)
+		}
+		s.cursor += size
+	}
+	return 0
+}
+
 func (s *Lexer) scanDoubleQuotedIdentifier(delimiter rune) *Token {
 	closingDelimiter := delimiter
 	if delimiter == '[' {
@@ -425,8 +833,14 @@ func (s *Lexer) scanDoubleQuotedIdentifier(delimiter rune) *Token {
 		// e.g. sqlserver [foo].[bar]
 		if ch == closingDelimiter {
 			s.quotes = append(s.quotes, s.cursor-offset)
-			specialCase := []rune{closingDelimiter, '.', delimiter}
-			if s.matchAt([]rune(specialCase)) {
+			// a doubled closing quote escapes it, e.g. "say ""hi""" or [a]]b]
+			// the first quote is trimmed, the second one is part of the name
+			if s.cursor+1 < len(s.src) && rune(s.src[s.cursor+1]) == closingDelimiter {
+				ch = s.nextBy(2)
+				continue
+			}
+			if s.cursor+2 < len(s.src) && s.src[s.cursor+1] == '.' && rune(s.src[s.cursor+2]) == delimiter &&
+				!s.config.SplitQualifiedIdentifiers {
 				s.quotes = append(s.quotes, s.cursor+2-offset)
 				ch = s.nextBy(3) // consume the "."
 				continue
@@ -435,7 +849,7 @@ func (s *Lexer) scanDoubleQuotedIdentifier(delimiter rune) *Token {
 		}
 		if isEOF(ch) {
 			s.quotes = nil // if we hit EOF, we clear the quotes
-			return s.emit(ERROR)
+			return s.emit(INCOMPLETE_QUOTED_IDENT)
 		}
 		if isDigit(ch) {
 			s.digits = append(s.digits, s.cursor-offset)
@@ -447,12 +861,10 @@ func (s *Lexer) scanDoubleQuotedIdentifier(delimiter rune) *Token {
 }
 
 func (s *Lexer) scanWhitespace() *Token {
-	// scan whitespace, tab, newline, carriage return
+	// scan whitespace, tab, newline, carriage return, which are all ASCII
 	s.start = s.cursor
-	ch := s.next()
-	for isSpace(ch) {
-		ch = s.next()
-	}
+	s.cursor++
+	s.skipSpaces()
 	return s.emit(SPACE)
 }
 
@@ -491,6 +903,12 @@ func (s *Lexer) scanOperator(lastCh rune) *Token {
 			s.next()
 			return s.emit(JSON_OP) // ?&
 		}
+		if s.config.QuestionMarkPlaceholders {
+			for isDigit(ch) {
+				ch = s.next()
+			}
+			return s.emit(PLACEHOLDER)
+		}
 	case '<':
 		if ch == '@' {
 			s.next()
@@ -498,49 +916,80 @@ func (s *Lexer) scanOperator(lastCh rune) *Token {
 		}
 	}
 
-	for isOperator(ch) && !(lastCh == '=' && (ch == '?' || ch == '@')) {
-		// hack: we don't want to treat "=?" as an single operator
-		lastCh = ch
-		ch = s.next()
-	}
-
+	// the longest operator of the dialect, e.g. >= in >=-1 or = in =?
+	s.cursor = s.start + s.operatorLength(s.start)
 	return s.emit(OPERATOR)
 }
 
 func (s *Lexer) scanWildcard() *Token {
 	s.start = s.cursor
+	if s.afterOperand && s.isMultiplication() {
+		// e.g. a*b, or *= WithDBMS(DBMSSQLServer)
+		return s.scanOperator('*')
+	}
 	s.next()
 	return s.emit(WILDCARD)
 }
 
+// isMultiplication checks if the * at the cursor, which follows an operand, is followed by another one.
+// It is a wildcard if it follows a qualifier, e.g. t.*, or ends a select list, e.g. SELECT TOP 10 * FROM t.
+func (s *Lexer) isMultiplication() bool {
+	if s.cursor > 0 && s.src[s.cursor-1] == '.' {
+		return false
+	}
+	next := s.cursor + 1
+	for next < len(s.src) && isSpace(rune(s.src[next])) {
+		next++
+	}
+	if next == len(s.src) {
+		return false
+	}
+	switch ch := s.src[next]; {
+	case ch == ',' || ch == ')' || ch == ';' || ch == 0:
+		return false
+	case isAsciiLetter(rune(ch)):
+		end := next
+		for end < len(s.src) && s.src[end] < utf8.RuneSelf && isIdentifier(rune(s.src[end])) {
+			end++
+		}
+		return !isWildcardEndWord(s.src[next:end])
+	}
+	return true
+}
+
 func (s *Lexer) scanSingleLineComment(ch rune) *Token {
 	s.start = s.cursor
 	if ch == '#' {
-		ch = s.next() // consume the opening #
+		s.cursor++ // consume the opening #
 	} else {
-		ch = s.nextBy(2) // consume the opening dashes
+		s.cursor += 2 // consume the opening dashes
 	}
-	for ch != '\n' && !isEOF(ch) {
-		ch = s.next()
+	// the comment ends at the end of the line, or at a NUL byte, which is read as EOF
+	for s.cursor < len(s.src) && s.src[s.cursor] != '\n' && s.src[s.cursor] != 0 {
+		s.cursor++
 	}
 	return s.emit(COMMENT)
 }
 
 func (s *Lexer) scanMultiLineComment() *Token {
 	s.start = s.cursor
-	ch := s.nextBy(2) // consume the opening slash and asterisk
-	for {
-		if ch == '*' && s.lookAhead(1) == '/' {
-			s.nextBy(2) // consume the closing asterisk and slash
-			break
-		}
-		if isEOF(ch) {
-			// encountered EOF before closing comment
-			// this usually happens when the comment is truncated
-			return s.emit(ERROR)
-		}
-		ch = s.next()
+	s.cursor += 2 // consume the opening slash and asterisk
+	body := s.src[s.cursor:]
+	end := strings.Index(body, "*/")
+	if end < 0 {
+		end = len(body)
+	}
+	if nul := strings.IndexByte(body[:end], 0); nul >= 0 {
+		// a NUL byte is read as EOF
+		end = nul
 	}
+	if end == len(body) || body[end] == 0 {
+		// encountered EOF before closing comment
+		// this usually happens when the comment is truncated
+		s.cursor += end
+		return s.emit(INCOMPLETE_COMMENT)
+	}
+	s.cursor += end + 2 // consume the closing asterisk and slash
 	return s.emit(MULTILINE_COMMENT)
 }
 
@@ -552,25 +1001,23 @@ func (s *Lexer) scanPunctuation() *Token {
 
 func (s *Lexer) scanDollarQuotedString() *Token {
 	s.start = s.cursor
-	ch := s.next() // consume the dollar sign
-	tagStart := s.cursor
-
-	for s.cursor < len(s.src) && ch != '$' {
-		ch = s.next()
+	// the tag runs up to the next dollar sign, compared in place without decoding the runes
+	tagEnd := strings.IndexByte(s.src[s.cursor+1:], '$')
+	if tagEnd < 0 {
+		s.cursor = len(s.src)
+		return s.emit(ERROR)
 	}
-	s.next()                            // consume the closing dollar sign of the tag
-	tag := s.src[tagStart-1 : s.cursor] // include the opening and closing dollar sign e.g. $tag$
+	s.cursor += tagEnd + 2         // consume the opening dollar sign, the tag and the closing dollar sign
+	tag := s.src[s.start:s.cursor] // include the opening and closing dollar sign e.g. $tag$
 
-	for s.cursor < len(s.src) {
-		if s.matchAt([]rune(tag)) {
-			s.nextBy(len(tag)) // consume the closing tag
-			if tag == "$func$" {
-				return s.emit(DOLLAR_QUOTED_FUNCTION)
-			}
-			return s.emit(DOLLAR_QUOTED_STRING)
+	if end := strings.Index(s.src[s.cursor:], tag); end >= 0 {
+		s.cursor += end + len(tag) // consume the closing tag
+		if tag == "$func$" {
+			return s.emit(DOLLAR_QUOTED_FUNCTION)
 		}
-		s.next()
+		return s.emit(DOLLAR_QUOTED_STRING)
 	}
+	s.cursor = len(s.src)
 	return s.emit(ERROR)
 }
 
@@ -598,6 +1045,37 @@ func (s *Lexer) scanBindParameter() *Token {
 	return s.emit(BIND_PARAMETER)
 }
 
+// scanTemplate scans the template expression at the cursor, or returns nil if there is none
+func (s *Lexer) scanTemplate() *Token {
+	rest := s.src[s.cursor:]
+	for _, delimiter := range s.config.TemplateDelimiters {
+		if delimiter.Open == "" || !strings.HasPrefix(rest, delimiter.Open) {
+			continue
+		}
+		end := len(rest)
+		if delimiter.Close == "" {
+			// the name following the opening delimiter
+			end = len(delimiter.Open)
+			for end < len(rest) {
+				r, size := utf8.DecodeRuneInString(rest[end:])
+				if !isAlphaNumeric(r) {
+					break
+				}
+				end += size
+			}
+			if end == len(delimiter.Open) {
+				continue
+			}
+		} else if i := strings.Index(rest[len(delimiter.Open):], delimiter.Close); i >= 0 {
+			end = len(delimiter.Open) + i + len(delimiter.Close)
+		}
+		s.start = s.cursor
+		s.cursor += end
+		return s.emit(TEMPLATE)
+	}
+	return nil
+}
+
 func (s *Lexer) scanSystemVariable() *Token {
 	s.start = s.cursor
 	ch := s.nextBy(2) // consume @@
@@ -608,9 +1086,58 @@ func (s *Lexer) scanSystemVariable() *Token {
 	for isAlphaNumeric(ch) {
 		ch = s.next()
 	}
+	// the variable may follow its scope, e.g. @@GLOBAL.sql_mode
+	if ch == '.' && isAlphaNumeric(s.lookAhead(1)) && systemVariableScope(s.src[s.start+2:s.cursor]) != "" {
+		ch = s.next()
+		for isAlphaNumeric(ch) {
+			ch = s.next()
+		}
+	}
 	return s.emit(SYSTEM_VARIABLE)
 }
 
+// trackTemporaryTable tracks the keywords of TEMPORARY TABLE, and reports whether the value token names
+// a temporary table
+func (s *Lexer) trackTemporaryTable(t TokenType, value string) bool {
+	state := s.temporaryTable
+	s.temporaryTable = 0
+	switch t {
+	case KEYWORD:
+		switch {
+		case equalFoldASCII(value, "TEMPORARY"):
+			s.temporaryTable = 1
+		case state == 1 && equalFoldASCII(value, "TABLE"):
+			s.temporaryTable = 2
+		case state == 2 && (equalFoldASCII(value, "IF") || equalFoldASCII(value, "NOT") || equalFoldASCII(value, "EXISTS")):
+			// e.g. CREATE TEMPORARY TABLE IF NOT EXISTS name
+			s.temporaryTable = 2
+		}
+	case IDENT, FUNCTION:
+		if equalFoldASCII(value, "TEMP") {
+			// TEMP is not a keyword
+			s.temporaryTable = 1
+			return false
+		}
+		return state == 2 || isTemporaryTableName(value)
+	case QUOTED_IDENT:
+		return state == 2
+	}
+	return false
+}
+
+// systemVariableScopes are the scopes of the MySQL system variables, e.g. GLOBAL in @@GLOBAL.sql_mode
+var systemVariableScopes = []string{"GLOBAL", "SESSION", "LOCAL", "PERSIST", "PERSIST_ONLY"}
+
+// systemVariableScope returns the uppercase scope matching the word, or "" if it is not a scope
+func systemVariableScope(word string) string {
+	for _, scope := range systemVariableScopes {
+		if equalFoldASCII(word, scope) {
+			return scope
+		}
+	}
+	return ""
+}
+
 func (s *Lexer) scanUnknown() *Token {
 	// When we see an unknown token, we advance the cursor until we see something that looks like a token boundary.
 	s.start = s.cursor
@@ -620,34 +1147,59 @@ func (s *Lexer) scanUnknown() *Token {
 
 // Modify emit function to use positions and maintain links
 func (s *Lexer) emit(t TokenType) *Token {
-	tok := s.token
-	lastValueToken := tok.lastValueToken
+	tok := &s.token
 
-	// Zero other fields
-	*tok = Token{
-		Type:             t,
-		Value:            s.src[s.start:s.cursor],
-		isTableIndicator: s.isTableIndicator,
-		lastValueToken:   lastValueToken,
+	// Set every field but lastValueToken, which is kept across tokens
+	tok.Type = t
+	switch {
+	case s.config.OffsetsOnly:
+		tok.Value = ""
+	case t == OPERATOR || t == PUNCTUATION || t == WILDCARD || t == JSON_OP:
+		tok.Value = internSymbol(s.src[s.start:s.cursor])
+	default:
+		tok.Value = s.src[s.start:s.cursor]
 	}
-
-	if len(s.digits) > 0 {
-		tok.digits = s.digits
-	} else {
-		tok.digits = nil
+	tok.Start = s.start
+	tok.End = s.cursor
+	tok.Line, tok.Column = 0, 0
+	if s.config.StartPosition != nil {
+		s.advancePosition(s.start)
+		tok.Start += s.config.StartPosition.Offset
+		tok.End += s.config.StartPosition.Offset
+		tok.Line, tok.Column = s.position.Line, s.position.Column
 	}
-
-	if len(s.quotes) > 0 {
-		tok.quotes = s.quotes
+	tok.isTableIndicator = s.isTableIndicator
+	if t != SPACE && t != COMMENT && t != MULTILINE_COMMENT && t != INCOMPLETE_COMMENT {
+		s.afterOperand = isOperandEnd(t, s.src[s.start:s.cursor])
+		tok.isTemporaryTable = s.trackTemporaryTable(t, s.src[s.start:s.cursor])
 	} else {
-		tok.quotes = nil
+		tok.isTemporaryTable = false
 	}
 
+	// the indexes are handed to the token, the next tokens append to the rest of the buffers,
+	// so copies of the token, e.g. those of ScanEach, keep their indexes
+	tok.digits, s.digits = detachIndexes(s.digits)
+	tok.quotes, s.quotes = detachIndexes(s.quotes)
+
 	// Reset lexer state
 	s.start = s.cursor
-	s.digits = nil
-	s.quotes = nil
 	s.isTableIndicator = false
 
 	return tok
 }
+
+// indexBufferSize is the capacity of the buffers the lexer records the indexes of digits and quotes in
+const indexBufferSize = 256
+
+// detachIndexes returns the indexes recorded in the buffer for a token, which the lexer no longer writes to,
+// and the buffer for the next tokens: the rest of it, or a new one when little of it is left
+func detachIndexes(buffer []int) (indexes []int, rest []int) {
+	if len(buffer) == 0 {
+		return nil, buffer
+	}
+	indexes = buffer[:len(buffer):len(buffer)]
+	if rest = buffer[len(buffer):]; cap(rest) < indexBufferSize/16 {
+		rest = make([]int, 0, indexBufferSize)
+	}
+	return indexes, rest
+}
@@ -3,6 +3,7 @@ package sqllexer
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -83,6 +84,11 @@ GROUP BY sales.product_key;
 		{"Large", LargeQuery},
 		{"Complex", ComplexQuery},
 		{"SuperLarge", fmt.Sprintf(superLargeQuery, 1)},
+		{"DollarQuoted", "CREATE FUNCTION f() RETURNS void AS $body$ " + strings.Repeat("UPDATE t SET a = a + 1; ", 40) + "$body$ LANGUAGE sql"},
+		// the same statement with ASCII and with non-ASCII identifiers and strings, the cost of decoding UTF-8
+		{"ASCII", strings.Repeat("SELECT prenom, namae FROM utilisateurs WHERE ville = 'Zurich' AND deja_vu > 1;\n", 20)},
+		{"Unicode", strings.Repeat("SELECT prénom, 名前 FROM utilisateurs WHERE ville = 'Zürich' AND déjà_vu > 1;\n", 20)},
+		{"Indented", strings.Repeat("INSERT INTO customer_order_history_archive\n                (customer_identifier, order_reference_number)\n        VALUES\n                (1234567890, 'ORD0000000001');\n", 20)},
 	}
 
 	for _, bm := range benchmarks {
@@ -101,3 +107,42 @@ GROUP BY sales.product_key;
 		})
 	}
 }
+
+// BenchmarkLexerAdversarial lexes inputs whose tokens may run to the end of the input, at two sizes:
+// the time per byte is the same at both, the scan being linear in the length of the input
+func BenchmarkLexerAdversarial(b *testing.B) {
+	text := func(n int) string {
+		return strings.Repeat("abc def 123 ", n/12)
+	}
+	benchmarks := []struct {
+		name  string
+		query func(n int) string
+		opts  []lexerOption
+	}{
+		{"LoneDollar", func(n int) string { return "SELECT $" + text(n) }, nil},
+		{"DollarTags", func(n int) string { return strings.Repeat("$a ", n/3) }, nil},
+		{"UnterminatedDollarQuote", func(n int) string { return "SELECT $tag$" + text(n) }, nil},
+		{"UnterminatedString", func(n int) string { return "SELECT '" + text(n) }, nil},
+		{"UnterminatedQuotedIdent", func(n int) string { return `SELECT "` + text(n) }, nil},
+		{"UnterminatedComment", func(n int) string { return "SELECT /*" + text(n) }, nil},
+		{"Quotes", func(n int) string { return strings.Repeat(`'"`, n/2) }, nil},
+		{"CommentOpenings", func(n int) string { return strings.Repeat("/*", n/2) }, nil},
+		{"UnterminatedTemplate", func(n int) string { return "SELECT {{" + text(n) }, []lexerOption{WithTemplateDelimiters(DefaultTemplateDelimiters...)}},
+		{"TemplateOpenings", func(n int) string { return strings.Repeat("{{ ", n/3) }, []lexerOption{WithTemplateDelimiters(DefaultTemplateDelimiters...)}},
+	}
+
+	for _, bm := range benchmarks {
+		for _, size := range []int{64 << 10, 1 << 20} {
+			query := bm.query(size)
+			b.Run(bm.name+"/"+strconv.Itoa(len(query)), func(b *testing.B) {
+				b.SetBytes(int64(len(query)))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					lexer := New(query, bm.opts...)
+					for token := lexer.Scan(); token.Type != EOF; token = lexer.Scan() {
+					}
+				}
+			})
+		}
+	}
+}
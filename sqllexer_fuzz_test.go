@@ -50,6 +50,29 @@ func FuzzObfuscatorAndNormalizer(f *testing.F) {
 	})
 }
 
+func FuzzHardenedLexer(f *testing.F) {
+	addComplexTestCases(f)
+	addObfuscationTestCases(f)
+
+	f.Fuzz(func(t *testing.T, input string, dbmsType string) {
+		lexer := New(input, WithDBMS(DBMSType(dbmsType)), WithHardened(true))
+		end := 0
+		for {
+			token := lexer.Scan()
+			if token.Type == EOF {
+				if token.Start != len(input) {
+					t.Errorf("EOF at %d before the end of the input of %d bytes", token.Start, len(input))
+				}
+				return
+			}
+			if token.Start != end || token.End <= token.Start {
+				t.Fatalf("token %d-%d does not advance from %d", token.Start, token.End, end)
+			}
+			end = token.End
+		}
+	})
+}
+
 func addComplexTestCases(f *testing.F) {
 	// PostgreSQL specific patterns
 	postgresPatterns := []string{
@@ -2,7 +2,12 @@ package sqllexer
 
 import (
 	"fmt"
+	"math/rand"
+	"strings"
 	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
 )
 
 // TokenSpec is a simplified token specification for testing
@@ -715,6 +720,71 @@ here */`,
 				{NUMBER, "1"},
 			},
 		},
+		{
+			name:  "Quoted identifier with doubled quotes",
+			input: `SELECT "say ""hi""" FROM "a""b"."c"`,
+			expected: []TokenSpec{
+				{COMMAND, "SELECT"},
+				{SPACE, " "},
+				{QUOTED_IDENT, `"say ""hi"""`},
+				{SPACE, " "},
+				{KEYWORD, "FROM"},
+				{SPACE, " "},
+				{QUOTED_IDENT, `"a""b"."c"`},
+			},
+		},
+		{
+			name:  "Backtick quoted identifier with doubled backticks",
+			input: "SELECT `a``b` FROM `t`",
+			expected: []TokenSpec{
+				{COMMAND, "SELECT"},
+				{SPACE, " "},
+				{QUOTED_IDENT, "`a``b`"},
+				{SPACE, " "},
+				{KEYWORD, "FROM"},
+				{SPACE, " "},
+				{QUOTED_IDENT, "`t`"},
+			},
+			lexerOpts: []lexerOption{WithDBMS(DBMSMySQL)},
+		},
+		{
+			name:  "Bracket quoted identifier with doubled closing brackets",
+			input: "SELECT [a]]b] FROM [t]",
+			expected: []TokenSpec{
+				{COMMAND, "SELECT"},
+				{SPACE, " "},
+				{QUOTED_IDENT, "[a]]b]"},
+				{SPACE, " "},
+				{KEYWORD, "FROM"},
+				{SPACE, " "},
+				{QUOTED_IDENT, "[t]"},
+			},
+			lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)},
+		},
+		{
+			name:  "Incomplete multiline comment",
+			input: "SELECT 1 /* truncated",
+			expected: []TokenSpec{
+				{COMMAND, "SELECT"},
+				{SPACE, " "},
+				{NUMBER, "1"},
+				{SPACE, " "},
+				{INCOMPLETE_COMMENT, "/* truncated"},
+			},
+		},
+		{
+			name:  "Incomplete quoted identifier",
+			input: `SELECT * FROM "say ""hi`,
+			expected: []TokenSpec{
+				{COMMAND, "SELECT"},
+				{SPACE, " "},
+				{WILDCARD, "*"},
+				{SPACE, " "},
+				{KEYWORD, "FROM"},
+				{SPACE, " "},
+				{INCOMPLETE_QUOTED_IDENT, `"say ""hi`},
+			},
+		},
 		{
 			name:  "Tokenize function",
 			input: "SELECT count(*) FROM users",
@@ -998,6 +1068,15 @@ here */`,
 				{IDENT, "c"},
 			},
 		},
+		{
+			name:  "whitespace with form feed and vertical tab",
+			input: "SELECT\f\v\r\n\t1",
+			expected: []TokenSpec{
+				{COMMAND, "SELECT"},
+				{SPACE, "\f\v\r\n\t"},
+				{NUMBER, "1"},
+			},
+		},
 		{
 			name:  "mysql comment",
 			input: "#1",
@@ -1041,6 +1120,769 @@ here */`,
 	}
 }
 
+func TestLexerTokenPositions(t *testing.T) {
+	input := "SELECT 'é', \"ü\" FROM t -- c"
+	lexer := New(input)
+	end := 0
+	for {
+		token := lexer.Scan()
+		assert.Equal(t, end, token.Start)
+		if token.Type == EOF {
+			assert.Equal(t, len(input), token.Start)
+			assert.Equal(t, len(input), token.End)
+			break
+		}
+		assert.Equal(t, token.Value, input[token.Start:token.End])
+		end = token.End
+	}
+}
+
+func TestLexerDollarSignsDoNotAllocate(t *testing.T) {
+	input := "SELECT $1, $2 FROM t WHERE a = $tag$x$tag$ AND b = $$y$$ AND c = $func$SELECT 1$func$ AND d = $unterminated"
+	lexer := New(input, WithDBMS(DBMSPostgres))
+	allocs := testing.AllocsPerRun(100, func() {
+		lexer.reset(input, WithDBMS(DBMSPostgres))
+		for token := lexer.Scan(); token.Type != EOF; token = lexer.Scan() {
+		}
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestLexerInternsValues(t *testing.T) {
+	input := "SELECT a, (b) FROM t WHERE x >= 1 AND y::int <> 2 OR z -> 'k' Or select"
+	scan := func() []Token {
+		// a copy of the input, so the values are interned only if they do not point into it
+		lexer := New(strings.Clone(input), WithDBMS(DBMSPostgres))
+		var tokens []Token
+		for token := lexer.Scan(); token.Type != EOF; token = lexer.Scan() {
+			tokens = append(tokens, *token)
+		}
+		return tokens
+	}
+
+	first, second := scan(), scan()
+	assert.Equal(t, len(first), len(second))
+	for i := range first {
+		interned := unsafe.StringData(first[i].Value) == unsafe.StringData(second[i].Value)
+		switch first[i].Type {
+		case COMMAND, KEYWORD, OPERATOR, PUNCTUATION, JSON_OP:
+			// keywords are interned in uppercase and lowercase only, not in mixed case
+			assert.Equal(t, first[i].Value != "Or", interned, first[i].Value)
+		default:
+			assert.False(t, interned, first[i].Value)
+		}
+	}
+}
+
+func TestLexerStartPosition(t *testing.T) {
+	input := "SELECT 'é',\n  x\r\nFROM t"
+	expected := []Token{
+		{Type: COMMAND, Value: "SELECT", Start: 100, End: 106, Line: 5, Column: 3},
+		{Type: SPACE, Value: " ", Start: 106, End: 107, Line: 5, Column: 9},
+		{Type: STRING, Value: "'é'", Start: 107, End: 111, Line: 5, Column: 10},
+		{Type: PUNCTUATION, Value: ",", Start: 111, End: 112, Line: 5, Column: 13},
+		{Type: SPACE, Value: "\n  ", Start: 112, End: 115, Line: 5, Column: 14},
+		{Type: IDENT, Value: "x", Start: 115, End: 116, Line: 6, Column: 3},
+		{Type: SPACE, Value: "\r\n", Start: 116, End: 118, Line: 6, Column: 4},
+		{Type: KEYWORD, Value: "FROM", Start: 118, End: 122, Line: 7, Column: 1},
+		{Type: SPACE, Value: " ", Start: 122, End: 123, Line: 7, Column: 5},
+		{Type: IDENT, Value: "t", Start: 123, End: 124, Line: 7, Column: 6},
+		{Type: EOF, Value: "", Start: 124, End: 124, Line: 7, Column: 7},
+	}
+
+	for _, hardened := range []bool{false, true} {
+		lexer := New(input, WithStartPosition(100, 5, 3), WithHardened(hardened))
+		for _, want := range expected {
+			token := lexer.Scan()
+			assert.Equal(t, want, Token{
+				Type: token.Type, Value: token.Value, Start: token.Start, End: token.End, Line: token.Line, Column: token.Column,
+			})
+			assert.Equal(t, want.Value, lexer.ValueAt(*token))
+		}
+	}
+}
+
+func TestLexerOffsetsOnly(t *testing.T) {
+	input := "SELECT 'é', \"ü\" FROM t -- c"
+	reference := New(input)
+	lexer := New(input, WithOffsetsOnly(true))
+	for {
+		want := *reference.Scan()
+		got := *lexer.Scan()
+		assert.Equal(t, want.Type, got.Type)
+		assert.Equal(t, want.Start, got.Start)
+		assert.Equal(t, want.End, got.End)
+		assert.Empty(t, got.Value)
+		assert.Equal(t, want.Value, lexer.ValueAt(got))
+		if got.Type == EOF {
+			break
+		}
+	}
+}
+
+func TestLexerSkipWhitespace(t *testing.T) {
+	input := "  SELECT a ,\tb\nFROM t -- c\n WHERE {{x}} = $1  "
+	for _, hardened := range []bool{false, true} {
+		t.Run(fmt.Sprint(hardened), func(t *testing.T) {
+			opts := []lexerOption{WithDBMS(DBMSPostgres), WithHardened(hardened), WithTemplateDelimiters(DefaultTemplateDelimiters...)}
+			reference := New(input, opts...)
+			lexer := New(input, append(opts, WithSkipWhitespace(true))...)
+			for {
+				want := *reference.Scan()
+				if want.Type == SPACE {
+					continue
+				}
+				got := *lexer.Scan()
+				assert.Equal(t, want.Type, got.Type)
+				assert.Equal(t, want.Value, got.Value)
+				assert.Equal(t, want.Start, got.Start)
+				assert.Equal(t, want.End, got.End)
+				if got.Type == EOF {
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestLexerScanEach(t *testing.T) {
+	input := "SELECT a, 'b' FROM t WHERE c = $1 -- d"
+	var expected []Token
+	lexer := New(input)
+	for token := lexer.Scan(); token.Type != EOF; token = lexer.Scan() {
+		expected = append(expected, *token)
+	}
+
+	var tokens []Token
+	New(input).ScanEach(func(token Token) bool {
+		tokens = append(tokens, token)
+		return true
+	})
+	assert.Equal(t, expected, tokens)
+
+	// the scan stops when fn returns false
+	tokens = tokens[:0]
+	New(input).ScanEach(func(token Token) bool {
+		tokens = append(tokens, token)
+		return token.Type != IDENT
+	})
+	assert.Equal(t, expected[:3], tokens)
+
+	// the tokens are delivered by value, without allocating
+	var values int
+	allocs := testing.AllocsPerRun(100, func() {
+		lexer := GetLexer(input)
+		lexer.ScanEach(func(token Token) bool {
+			if isValueToken(&token) {
+				values++
+			}
+			return true
+		})
+		PutLexer(lexer)
+	})
+	assert.Zero(t, allocs)
+	assert.Positive(t, values)
+}
+
+func TestLexerTokensKeepIndexes(t *testing.T) {
+	// the tokens held at once keep the indexes of their digits and quotes, which later tokens do not overwrite
+	var tokens []Token
+	New(`SELECT "ab", "c", users12, t3 FROM ` + strings.Repeat("x1, ", 100) + "y2").ScanEach(func(token Token) bool {
+		if token.Type == QUOTED_IDENT || token.Type == IDENT {
+			tokens = append(tokens, token)
+		}
+		return true
+	})
+	assert.Len(t, tokens, 105)
+	assert.Equal(t, "ab", trimQuotes(&tokens[0]))
+	assert.Equal(t, "c", trimQuotes(&tokens[1]))
+	assert.Equal(t, "users?", replaceDigits(&tokens[2], "?"))
+	assert.Equal(t, "t?", replaceDigits(&tokens[3], "?"))
+	for _, token := range tokens[4:104] {
+		assert.Equal(t, "x?", replaceDigits(&token, "?"))
+	}
+	assert.Equal(t, "y?", replaceDigits(&tokens[104], "?"))
+}
+
+func TestLexerMark(t *testing.T) {
+	input := "SELECT count(*) FROM t\nWHERE name LIKE 'a\\_%' ESCAPE '\\' AND id = 1"
+	scan := func(lexer *Lexer, n int) []Token {
+		var tokens []Token
+		for i := 0; i < n; i++ {
+			token := lexer.Scan()
+			tokens = append(tokens, *token)
+			if isValueToken(token) {
+				token.getLastValueToken()
+			}
+		}
+		return tokens
+	}
+
+	reference := scan(New(input, WithStartPosition(100, 5, 3)), 30)
+	for i := 0; i < 25; i++ {
+		lexer := New(input, WithStartPosition(100, 5, 3))
+		scan(lexer, i)
+		mark := lexer.Mark()
+		// scan speculatively, then again from the mark
+		assert.Equal(t, reference[i:i+5], scan(lexer, 5))
+		lexer.ResetTo(mark)
+		assert.Equal(t, reference[i:], scan(lexer, 30-i))
+	}
+}
+
+func TestLexerSigns(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []TokenSpec
+	}{
+		{"1-1", []TokenSpec{{NUMBER, "1"}, {OPERATOR, "-"}, {NUMBER, "1"}}},
+		{"a+-1", []TokenSpec{{IDENT, "a"}, {OPERATOR, "+"}, {NUMBER, "-1"}}},
+		{"(a)-1", []TokenSpec{{PUNCTUATION, "("}, {IDENT, "a"}, {PUNCTUATION, ")"}, {OPERATOR, "-"}, {NUMBER, "1"}}},
+		{"'a' -1", []TokenSpec{{STRING, "'a'"}, {OPERATOR, "-"}, {NUMBER, "1"}}},
+		{"$1 /* c */ -1", []TokenSpec{{POSITIONAL_PARAMETER, "$1"}, {MULTILINE_COMMENT, "/* c */"}, {OPERATOR, "-"}, {NUMBER, "1"}}},
+		{"id=-1", []TokenSpec{{IDENT, "id"}, {OPERATOR, "="}, {NUMBER, "-1"}}},
+		{"x<>-1.5", []TokenSpec{{IDENT, "x"}, {OPERATOR, "<>"}, {NUMBER, "-1.5"}}},
+		{"f(-1,+2)", []TokenSpec{{FUNCTION, "f"}, {PUNCTUATION, "("}, {NUMBER, "-1"}, {PUNCTUATION, ","}, {NUMBER, "+2"}, {PUNCTUATION, ")"}}},
+		{"SELECT -1", []TokenSpec{{COMMAND, "SELECT"}, {NUMBER, "-1"}}},
+		{"a BETWEEN -1 AND -2", []TokenSpec{{IDENT, "a"}, {KEYWORD, "BETWEEN"}, {NUMBER, "-1"}, {KEYWORD, "AND"}, {NUMBER, "-2"}}},
+		{"CASE WHEN a THEN -1 ELSE -2 END -1", []TokenSpec{
+			{KEYWORD, "CASE"}, {IDENT, "WHEN"}, {IDENT, "a"}, {IDENT, "THEN"}, {NUMBER, "-1"},
+			{KEYWORD, "ELSE"}, {NUMBER, "-2"}, {KEYWORD, "END"}, {OPERATOR, "-"}, {NUMBER, "1"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			lexer := New(tt.input, WithSkipWhitespace(true))
+			var tokens []TokenSpec
+			for token := lexer.Scan(); token.Type != EOF; token = lexer.Scan() {
+				tokens = append(tokens, TokenSpec{token.Type, token.Value})
+			}
+			assert.Equal(t, tt.expected, tokens)
+		})
+	}
+}
+
+func TestLexerNumbers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []TokenSpec
+	}{
+		{"1.5", []TokenSpec{{NUMBER, "1.5"}}},
+		{"1.", []TokenSpec{{NUMBER, "1."}}},
+		{"-.5", []TokenSpec{{NUMBER, "-.5"}}},
+		{"1.5e10", []TokenSpec{{NUMBER, "1.5e10"}}},
+		{"1.e-5", []TokenSpec{{NUMBER, "1.e-5"}}},
+		{"2E+3", []TokenSpec{{NUMBER, "2E+3"}}},
+		{"0x1aF", []TokenSpec{{NUMBER, "0x1aF"}}},
+		{"0123.45", []TokenSpec{{NUMBER, "0123.45"}}},
+		// a second decimal point ends the number
+		{"1.2.3", []TokenSpec{{NUMBER, "1.2"}, {PUNCTUATION, "."}, {NUMBER, "3"}}},
+		{"1..2", []TokenSpec{{NUMBER, "1"}, {PUNCTUATION, "."}, {PUNCTUATION, "."}, {NUMBER, "2"}}},
+		// an exponent or a hex prefix without digits is not part of the number
+		{"1e", []TokenSpec{{NUMBER, "1"}, {IDENT, "e"}}},
+		{"1e+", []TokenSpec{{NUMBER, "1"}, {IDENT, "e"}, {OPERATOR, "+"}}},
+		{"1.5E-x", []TokenSpec{{NUMBER, "1.5"}, {IDENT, "E"}, {OPERATOR, "-"}, {IDENT, "x"}}},
+		{"0x", []TokenSpec{{NUMBER, "0"}, {IDENT, "x"}}},
+		{"0xg", []TokenSpec{{NUMBER, "0"}, {IDENT, "xg"}}},
+		{"-.x", []TokenSpec{{OPERATOR, "-"}, {PUNCTUATION, "."}, {IDENT, "x"}}},
+		{"12abc", []TokenSpec{{NUMBER, "12"}, {IDENT, "abc"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			lexer := New(tt.input)
+			var tokens []TokenSpec
+			for token := lexer.Scan(); token.Type != EOF; token = lexer.Scan() {
+				tokens = append(tokens, TokenSpec{token.Type, token.Value})
+			}
+			assert.Equal(t, tt.expected, tokens)
+		})
+	}
+}
+
+func TestLexerPrefixedNumbers(t *testing.T) {
+	tests := []struct {
+		input    string
+		dbms     DBMSType
+		expected []TokenSpec
+	}{
+		{"0x123GHI", DBMSPostgres, []TokenSpec{{NUMBER, "0x123"}, {IDENT, "GHI"}}},
+		{"0xFFFFFFFFFFFFFFFFFFFFFFFF", DBMSMySQL, []TokenSpec{{NUMBER, "0xFFFFFFFFFFFFFFFFFFFFFFFF"}}},
+		{"0o17", DBMSPostgres, []TokenSpec{{NUMBER, "0o17"}}},
+		{"0o178", DBMSPostgres, []TokenSpec{{NUMBER, "0o17"}, {NUMBER, "8"}}},
+		{"0o17", DBMSMySQL, []TokenSpec{{NUMBER, "0"}, {IDENT, "o17"}}},
+		{"0b101", DBMSMySQL, []TokenSpec{{NUMBER, "0b101"}}},
+		{"0B1012", DBMSPostgres, []TokenSpec{{NUMBER, "0B101"}, {NUMBER, "2"}}},
+		{"0b101", DBMSSQLServer, []TokenSpec{{NUMBER, "0"}, {IDENT, "b101"}}},
+		{"0b2", DBMSMySQL, []TokenSpec{{NUMBER, "0"}, {IDENT, "b2"}}},
+		{"0o17 + 0b1", "", []TokenSpec{{NUMBER, "0o17"}, {SPACE, " "}, {OPERATOR, "+"}, {SPACE, " "}, {NUMBER, "0b1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			lexer := New(tt.input, WithDBMS(tt.dbms))
+			var tokens []TokenSpec
+			for token := lexer.Scan(); token.Type != EOF; token = lexer.Scan() {
+				tokens = append(tokens, TokenSpec{token.Type, token.Value})
+			}
+			assert.Equal(t, tt.expected, tokens)
+		})
+	}
+}
+
+func TestLexerDoubledQuotes(t *testing.T) {
+	tests := []struct {
+		input    string
+		opts     []lexerOption
+		expected []TokenSpec
+	}{
+		{"'it''s here'", nil, []TokenSpec{{STRING, "'it''s here'"}}},
+		{"''''", nil, []TokenSpec{{STRING, "''''"}}},
+		{"'', 'a'", nil, []TokenSpec{{STRING, "''"}, {PUNCTUATION, ","}, {SPACE, " "}, {STRING, "'a'"}}},
+		{"'a\\'''b'", nil, []TokenSpec{{STRING, "'a\\'''b'"}}},
+		{"'it''", nil, []TokenSpec{{INCOMPLETE_STRING, "'it''"}}},
+		{"'it''s'", []lexerOption{WithNoDoubledQuoteEscapes(true)}, []TokenSpec{{STRING, "'it'"}, {STRING, "'s'"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			lexer := New(tt.input, tt.opts...)
+			var tokens []TokenSpec
+			for token := lexer.Scan(); token.Type != EOF; token = lexer.Scan() {
+				tokens = append(tokens, TokenSpec{token.Type, token.Value})
+			}
+			assert.Equal(t, tt.expected, tokens)
+		})
+	}
+}
+
+func TestLexerSystemVariables(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []TokenSpec
+		scope    string
+		name     string
+	}{
+		{"@@VERSION", []TokenSpec{{SYSTEM_VARIABLE, "@@VERSION"}}, "", "VERSION"},
+		{"@@GLOBAL.sql_mode", []TokenSpec{{SYSTEM_VARIABLE, "@@GLOBAL.sql_mode"}}, "GLOBAL", "sql_mode"},
+		{"@@session.time_zone", []TokenSpec{{SYSTEM_VARIABLE, "@@session.time_zone"}}, "SESSION", "time_zone"},
+		{"@@persist_only.max_connections", []TokenSpec{{SYSTEM_VARIABLE, "@@persist_only.max_connections"}}, "PERSIST_ONLY", "max_connections"},
+		{"@@GLOBAL.", []TokenSpec{{SYSTEM_VARIABLE, "@@GLOBAL"}, {PUNCTUATION, "."}}, "", "GLOBAL"},
+		{"@@a.b", []TokenSpec{{SYSTEM_VARIABLE, "@@a"}, {PUNCTUATION, "."}, {IDENT, "b"}}, "", "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			lexer := New(tt.input, WithDBMS(DBMSMySQL))
+			var tokens []TokenSpec
+			for token := lexer.Scan(); token.Type != EOF; token = lexer.Scan() {
+				tokens = append(tokens, TokenSpec{token.Type, token.Value})
+			}
+			assert.Equal(t, tt.expected, tokens)
+
+			token := New(tt.input, WithDBMS(DBMSMySQL)).Scan()
+			scope, name := token.SystemVariable()
+			assert.Equal(t, tt.scope, scope)
+			assert.Equal(t, tt.name, name)
+		})
+	}
+}
+
+func TestLexerTemporaryTables(t *testing.T) {
+	tests := []struct {
+		input    string
+		dbms     DBMSType
+		expected []string
+	}{
+		{"CREATE TEMPORARY TABLE IF NOT EXISTS t (a INT)", DBMSMySQL, []string{"t"}},
+		{"CREATE TEMP TABLE \"t\" AS SELECT a FROM u", DBMSPostgres, []string{"\"t\""}},
+		{"SELECT * FROM #t JOIN ##g ON #t.id = ##g.id", DBMSSQLServer, []string{"#t", "##g", "#t.id", "##g.id"}},
+		{"SELECT * FROM pg_temp.t, pg_temp", DBMSPostgres, []string{"pg_temp.t"}},
+		{"SELECT temp FROM t", "", nil},
+		{"CREATE TABLE t (temp INT)", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			lexer := New(tt.input, WithDBMS(tt.dbms))
+			var temporary []string
+			for token := lexer.Scan(); token.Type != EOF; token = lexer.Scan() {
+				if token.IsTemporaryTable() {
+					temporary = append(temporary, token.Value)
+				}
+			}
+			assert.Equal(t, tt.expected, temporary)
+		})
+	}
+}
+
+func TestLexerSplitQualifiedIdentifiers(t *testing.T) {
+	tests := []struct {
+		input    string
+		dbms     DBMSType
+		expected []TokenSpec
+	}{
+		{"users.name", "", []TokenSpec{{IDENT, "users"}, {PUNCTUATION, "."}, {IDENT, "name"}}},
+		{"t.order", "", []TokenSpec{{IDENT, "t"}, {PUNCTUATION, "."}, {IDENT, "order"}}},
+		{"t.*", "", []TokenSpec{{IDENT, "t"}, {PUNCTUATION, "."}, {WILDCARD, "*"}}},
+		{"dbo.fn(1)", "", []TokenSpec{{IDENT, "dbo"}, {PUNCTUATION, "."}, {FUNCTION, "fn"}, {PUNCTUATION, "("}, {NUMBER, "1"}, {PUNCTUATION, ")"}}},
+		{`"public"."users"`, "", []TokenSpec{{QUOTED_IDENT, `"public"`}, {PUNCTUATION, "."}, {QUOTED_IDENT, `"users"`}}},
+		{"[dbo].[t]", DBMSSQLServer, []TokenSpec{{QUOTED_IDENT, "[dbo]"}, {PUNCTUATION, "."}, {QUOTED_IDENT, "[t]"}}},
+		{"é.ü1", "", []TokenSpec{{IDENT, "é"}, {PUNCTUATION, "."}, {IDENT, "ü1"}}},
+		{"1.5", "", []TokenSpec{{NUMBER, "1.5"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			lexer := New(tt.input, WithDBMS(tt.dbms), WithSplitQualifiedIdentifiers(true))
+			var tokens []TokenSpec
+			for token := lexer.Scan(); token.Type != EOF; token = lexer.Scan() {
+				tokens = append(tokens, TokenSpec{token.Type, token.Value})
+			}
+			assert.Equal(t, tt.expected, tokens)
+		})
+	}
+}
+
+func TestLexerWildcards(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []TokenSpec
+	}{
+		{"SELECT * FROM t", []TokenSpec{{COMMAND, "SELECT"}, {WILDCARD, "*"}, {KEYWORD, "FROM"}, {IDENT, "t"}}},
+		{"SELECT t.*, count(*)", []TokenSpec{
+			{COMMAND, "SELECT"}, {IDENT, "t."}, {WILDCARD, "*"}, {PUNCTUATION, ","},
+			{FUNCTION, "count"}, {PUNCTUATION, "("}, {WILDCARD, "*"}, {PUNCTUATION, ")"},
+		}},
+		{"SELECT a*b, 2 * (c)", []TokenSpec{
+			{COMMAND, "SELECT"}, {IDENT, "a"}, {OPERATOR, "*"}, {IDENT, "b"}, {PUNCTUATION, ","},
+			{NUMBER, "2"}, {OPERATOR, "*"}, {PUNCTUATION, "("}, {IDENT, "c"}, {PUNCTUATION, ")"},
+		}},
+		{"SELECT TOP 10 * FROM t", []TokenSpec{{COMMAND, "SELECT"}, {KEYWORD, "TOP"}, {NUMBER, "10"}, {WILDCARD, "*"}, {KEYWORD, "FROM"}, {IDENT, "t"}}},
+		{"SELECT DISTINCT ON (a) * FROM t", []TokenSpec{
+			{COMMAND, "SELECT"}, {KEYWORD, "DISTINCT"}, {KEYWORD, "ON"}, {PUNCTUATION, "("}, {IDENT, "a"}, {PUNCTUATION, ")"},
+			{WILDCARD, "*"}, {KEYWORD, "FROM"}, {IDENT, "t"},
+		}},
+		{"SELECT a * from_date", []TokenSpec{{COMMAND, "SELECT"}, {IDENT, "a"}, {OPERATOR, "*"}, {IDENT, "from_date"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			lexer := New(tt.input, WithSkipWhitespace(true))
+			var tokens []TokenSpec
+			for token := lexer.Scan(); token.Type != EOF; token = lexer.Scan() {
+				tokens = append(tokens, TokenSpec{token.Type, token.Value})
+			}
+			assert.Equal(t, tt.expected, tokens)
+		})
+	}
+}
+
+func TestLexerQuestionMarks(t *testing.T) {
+	tests := []struct {
+		input                   string
+		questionMarkIdentifiers bool
+		expected                []TokenSpec
+	}{
+		{"a?b", false, []TokenSpec{{IDENT, "a"}, {OPERATOR, "?"}, {IDENT, "b"}}},
+		{"name?", false, []TokenSpec{{IDENT, "name"}, {OPERATOR, "?"}}},
+		{"name=?", false, []TokenSpec{{IDENT, "name"}, {OPERATOR, "="}, {OPERATOR, "?"}}},
+		{"t?.c?", false, []TokenSpec{{IDENT, "t"}, {OPERATOR, "?"}, {PUNCTUATION, "."}, {IDENT, "c"}, {OPERATOR, "?"}}},
+		{"a?b", true, []TokenSpec{{IDENT, "a?b"}}},
+		{"t?.c? = ?", true, []TokenSpec{{IDENT, "t?.c?"}, {OPERATOR, "="}, {OPERATOR, "?"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			lexer := New(tt.input, WithQuestionMarkIdentifiers(tt.questionMarkIdentifiers), WithSkipWhitespace(true))
+			var tokens []TokenSpec
+			for token := lexer.Scan(); token.Type != EOF; token = lexer.Scan() {
+				tokens = append(tokens, TokenSpec{token.Type, token.Value})
+			}
+			assert.Equal(t, tt.expected, tokens)
+		})
+	}
+}
+
+func TestLexerPlaceholders(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []TokenSpec
+	}{
+		{"a=?", []TokenSpec{{IDENT, "a"}, {OPERATOR, "="}, {PLACEHOLDER, "?"}}},
+		{"a IN (?1,?2)", []TokenSpec{{IDENT, "a"}, {KEYWORD, "IN"}, {PUNCTUATION, "("}, {PLACEHOLDER, "?1"}, {PUNCTUATION, ","}, {PLACEHOLDER, "?2"}, {PUNCTUATION, ")"}}},
+		{"?-1", []TokenSpec{{PLACEHOLDER, "?"}, {OPERATOR, "-"}, {NUMBER, "1"}}},
+		{"a ?| b", []TokenSpec{{IDENT, "a"}, {JSON_OP, "?|"}, {IDENT, "b"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			lexer := New(tt.input, WithQuestionMarkPlaceholders(true), WithSkipWhitespace(true))
+			var tokens []TokenSpec
+			for token := lexer.Scan(); token.Type != EOF; token = lexer.Scan() {
+				tokens = append(tokens, TokenSpec{token.Type, token.Value})
+			}
+			assert.Equal(t, tt.expected, tokens)
+		})
+	}
+
+	// the obfuscator and the normalizer keep the placeholders
+	obfuscated := NewObfuscator().Obfuscate("SELECT a FROM t WHERE b = ? AND c = 1", WithQuestionMarkPlaceholders(true))
+	assert.Equal(t, "SELECT a FROM t WHERE b = ? AND c = ?", obfuscated)
+	normalized, _, err := NewNormalizer().Normalize("SELECT a FROM t WHERE b IN (?, ?)", WithQuestionMarkPlaceholders(true))
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a FROM t WHERE b IN ( ? )", normalized)
+}
+
+func TestGetLexer(t *testing.T) {
+	scan := func(lexer *Lexer) []TokenSpec {
+		var tokens []TokenSpec
+		for token := lexer.Scan(); token.Type != EOF; token = lexer.Scan() {
+			tokens = append(tokens, TokenSpec{token.Type, token.Value})
+		}
+		return tokens
+	}
+
+	lexer := GetLexer("SELECT [a1] FROM t", WithDBMS(DBMSSQLServer))
+	assert.Equal(t, []TokenSpec{
+		{COMMAND, "SELECT"},
+		{SPACE, " "},
+		{QUOTED_IDENT, "[a1]"},
+		{SPACE, " "},
+		{KEYWORD, "FROM"},
+		{SPACE, " "},
+		{IDENT, "t"},
+	}, scan(lexer))
+	PutLexer(lexer)
+
+	// a pooled lexer does not keep the options nor the state of its previous input
+	lexer = GetLexer("SELECT [a1]")
+	assert.Equal(t, LexerConfig{}, lexer.config)
+	assert.Equal(t, []TokenSpec{
+		{COMMAND, "SELECT"},
+		{SPACE, " "},
+		{PUNCTUATION, "["},
+		{IDENT, "a1"},
+		{PUNCTUATION, "]"},
+	}, scan(lexer))
+	PutLexer(lexer)
+}
+
+func TestLexerHardened(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []TokenSpec
+	}{
+		{
+			name:  "NUL byte",
+			input: "SELECT \x00 1",
+			expected: []TokenSpec{
+				{COMMAND, "SELECT"},
+				{SPACE, " "},
+				{ERROR, "\x00"},
+				{SPACE, " "},
+				{NUMBER, "1"},
+			},
+		},
+		{
+			name:  "NUL byte in a string",
+			input: "'a\x00b'",
+			expected: []TokenSpec{
+				{INCOMPLETE_STRING, "'a"},
+				{ERROR, "\x00"},
+				{IDENT, "b"},
+				{INCOMPLETE_STRING, "'"},
+			},
+		},
+		{
+			name:  "invalid UTF-8",
+			input: "SELECT \xff\xfe",
+			expected: []TokenSpec{
+				{COMMAND, "SELECT"},
+				{SPACE, " "},
+				{UNKNOWN, "\xff"},
+				{UNKNOWN, "\xfe"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lexer := New(tt.input, WithHardened(true))
+			var tokens []TokenSpec
+			for token := lexer.Scan(); token.Type != EOF; token = lexer.Scan() {
+				tokens = append(tokens, TokenSpec{token.Type, token.Value})
+			}
+			assert.Equal(t, tt.expected, tokens)
+		})
+	}
+}
+
+func TestLexerTemplate(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		delimiters []TemplateDelimiter
+		expected   []TokenSpec
+	}{
+		{
+			name:       "go template",
+			input:      "SELECT * FROM {{ .Table }} WHERE id = {{.ID}}",
+			delimiters: DefaultTemplateDelimiters,
+			expected: []TokenSpec{
+				{COMMAND, "SELECT"},
+				{SPACE, " "},
+				{WILDCARD, "*"},
+				{SPACE, " "},
+				{KEYWORD, "FROM"},
+				{SPACE, " "},
+				{TEMPLATE, "{{ .Table }}"},
+				{SPACE, " "},
+				{KEYWORD, "WHERE"},
+				{SPACE, " "},
+				{IDENT, "id"},
+				{SPACE, " "},
+				{OPERATOR, "="},
+				{SPACE, " "},
+				{TEMPLATE, "{{.ID}}"},
+			},
+		},
+		{
+			name:       "shell variable",
+			input:      "DELETE FROM ${schema}.logs",
+			delimiters: DefaultTemplateDelimiters,
+			expected: []TokenSpec{
+				{COMMAND, "DELETE"},
+				{SPACE, " "},
+				{KEYWORD, "FROM"},
+				{SPACE, " "},
+				{TEMPLATE, "${schema}"},
+				{PUNCTUATION, "."},
+				{IDENT, "logs"},
+			},
+		},
+		{
+			name:       "named parameter, not a cast",
+			input:      "SELECT a::text FROM t WHERE b = :b",
+			delimiters: []TemplateDelimiter{{Open: ":"}},
+			expected: []TokenSpec{
+				{COMMAND, "SELECT"},
+				{SPACE, " "},
+				{IDENT, "a"},
+				{OPERATOR, "::"},
+				{IDENT, "text"},
+				{SPACE, " "},
+				{KEYWORD, "FROM"},
+				{SPACE, " "},
+				{IDENT, "t"},
+				{SPACE, " "},
+				{KEYWORD, "WHERE"},
+				{SPACE, " "},
+				{IDENT, "b"},
+				{SPACE, " "},
+				{OPERATOR, "="},
+				{SPACE, " "},
+				{TEMPLATE, ":b"},
+			},
+		},
+		{
+			name:       "inside a string",
+			input:      "SELECT '{{ .Name }}'",
+			delimiters: DefaultTemplateDelimiters,
+			expected: []TokenSpec{
+				{COMMAND, "SELECT"},
+				{SPACE, " "},
+				{STRING, "'{{ .Name }}'"},
+			},
+		},
+		{
+			name:       "unterminated",
+			input:      "SELECT {{ .Name",
+			delimiters: DefaultTemplateDelimiters,
+			expected: []TokenSpec{
+				{COMMAND, "SELECT"},
+				{SPACE, " "},
+				{TEMPLATE, "{{ .Name"},
+			},
+		},
+		{
+			name:  "disabled",
+			input: "{{a}}",
+			expected: []TokenSpec{
+				{PUNCTUATION, "{"},
+				{PUNCTUATION, "{"},
+				{IDENT, "a"},
+				{PUNCTUATION, "}"},
+				{PUNCTUATION, "}"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lexer := New(tt.input, WithTemplateDelimiters(tt.delimiters...))
+			var tokens []TokenSpec
+			for token := lexer.Scan(); token.Type != EOF; token = lexer.Scan() {
+				tokens = append(tokens, TokenSpec{token.Type, token.Value})
+			}
+			assert.Equal(t, tt.expected, tokens)
+		})
+	}
+}
+
+// hostileFragments are the fragments of the random inputs of the hardened lexer, mostly unterminated tokens
+var hostileFragments = []string{
+	"'", "\"", "`", "$", "$$", "$a$", "/*", "*/", "--", "#", "@", "@@", ":", "::", "[", "]", "{", "}", "(", ")",
+	"\\", "\x00", "\xff", "\xe2\x82", "é", "e", "E", "1", ".", "0x", "-", "+", "?", "N'", "x'", "b'",
+	" ", "\n", "SELECT", "FROM", "a",
+}
+
+func TestLexerHardenedProperties(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	dbmsTypes := []DBMSType{"", DBMSPostgres, DBMSMySQL, DBMSSQLServer, DBMSOracle, DBMSSnowflake}
+	for i := 0; i < 20000; i++ {
+		var builder strings.Builder
+		for n := random.Intn(20); n >= 0; n-- {
+			builder.WriteString(hostileFragments[random.Intn(len(hostileFragments))])
+		}
+		input := builder.String()
+		dbms := dbmsTypes[random.Intn(len(dbmsTypes))]
+
+		lexer := New(input, WithDBMS(dbms), WithHardened(true))
+		end := 0
+		for scans := 1; ; scans++ {
+			token := lexer.Scan()
+			if token.Type == EOF {
+				if !assert.Equal(t, len(input), token.Start, "EOF before the end of %q for %s", input, dbms) {
+					return
+				}
+				break
+			}
+			if !assert.Equal(t, end, token.Start, "token not following the previous one in %q for %s", input, dbms) ||
+				!assert.Greater(t, token.End, token.Start, "token not advancing in %q for %s", input, dbms) ||
+				!assert.LessOrEqual(t, scans, len(input), "too many scans of %q for %s", input, dbms) {
+				return
+			}
+			end = token.End
+		}
+	}
+}
+
+func TestLexerHardenedLongInputs(t *testing.T) {
+	// each Scan advances, so the number of scans of inputs of repeated unterminated tokens is bounded by their length
+	for _, fragment := range hostileFragments {
+		input := strings.Repeat(fragment, 10000)
+		lexer := New(input, WithHardened(true))
+		scans := 1
+		for lexer.Scan().Type != EOF {
+			scans++
+		}
+		assert.LessOrEqual(t, scans, len(input)+1, "fragment %q", fragment)
+	}
+}
+
 func TestLexerIdentifierWithDigits(t *testing.T) {
 	tests := []struct {
 		input          string
@@ -1178,7 +2020,7 @@ func TestLexerIdentifierWithQuotes(t *testing.T) {
 			expectedTokens: []TokenSpec{
 				{COMMAND, "SELECT"},
 				{SPACE, " "},
-				{ERROR, `"fóo"."`},
+				{INCOMPLETE_QUOTED_IDENT, `"fóo"."`},
 			},
 			expectedQuotes: [][]int{},
 		},
@@ -1327,3 +2169,20 @@ func ExampleLexer() {
 		fmt.Println(token)
 	}
 }
+
+func ExampleLexer_Mark() {
+	lexer := New("SELECT my_udf (a, b) FROM t", WithSkipWhitespace(true))
+	for token := lexer.Scan(); token.Type != EOF; token = lexer.Scan() {
+		if token.Type != IDENT {
+			continue
+		}
+		// an identifier followed by a parenthesis is a function call, the parenthesis is scanned again
+		name := token.Value
+		mark := lexer.Mark()
+		if next := lexer.Scan(); next.Value == "(" {
+			fmt.Println("function", name)
+		}
+		lexer.ResetTo(mark)
+	}
+	// Output: function my_udf
+}
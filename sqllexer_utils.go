@@ -1,8 +1,10 @@
 package sqllexer
 
 import (
+	"fmt"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 type DBMSType string
@@ -36,6 +38,47 @@ func getDBMSFromAlias(alias DBMSType) DBMSType {
 	return alias
 }
 
+// dbmsNames maps the lowercase names of the database/sql drivers and of the connection URL schemes to their DBMS
+var dbmsNames = map[string]DBMSType{
+	"postgres":   DBMSPostgres,
+	"postgresql": DBMSPostgres,
+	"pgsql":      DBMSPostgres,
+	"pgx":        DBMSPostgres,
+	"pq":         DBMSPostgres,
+	"mysql":      DBMSMySQL,
+	"mariadb":    DBMSMySQL,
+	"sqlserver":  DBMSSQLServer,
+	"sql-server": DBMSSQLServer,
+	"mssql":      DBMSSQLServer,
+	"azuresql":   DBMSSQLServer,
+	"oracle":     DBMSOracle,
+	"godror":     DBMSOracle,
+	"goracle":    DBMSOracle,
+	"oci8":       DBMSOracle,
+	"snowflake":  DBMSSnowflake,
+}
+
+// ParseDBMS returns the DBMS of a database/sql driver name, e.g. pgx, godror or sqlserver, or of a connection URL,
+// e.g. postgres://user@host/db, jdbc:sqlserver://host or postgresql+psycopg2://host. The name is case insensitive,
+// and a versioned driver, e.g. pgx/v5, is the driver. It returns an error if the DBMS is not known.
+func ParseDBMS(name string) (DBMSType, error) {
+	key := ToLowerASCII(strings.TrimSpace(name))
+	key = strings.TrimPrefix(key, "jdbc:")
+	if i := strings.Index(key, "://"); i >= 0 {
+		key = key[:i]
+	}
+	// e.g. the driver of postgresql+psycopg2 or the version of pgx/v5
+	if i := strings.IndexAny(key, "+/"); i >= 0 {
+		key = key[:i]
+	}
+	if dbms, ok := dbmsNames[key]; ok {
+		return dbms, nil
+	}
+	return "", fmt.Errorf("unknown DBMS %q", name)
+}
+
+//go:generate go run gen_dialects.go
+
 var commands = []string{
 	"SELECT",
 	"INSERT",
@@ -179,45 +222,109 @@ var (
 	}
 )
 
-// buildCombinedTrie combines all types of SQL keywords into a single trie
-// This trie is used for efficient case-insensitive keyword matching during lexing
-func buildCombinedTrie() *trieNode {
-	root := &trieNode{children: make(map[rune]*trieNode)}
-
-	// Add all types of keywords
-	addToTrie(root, commands, COMMAND, false)
-	addToTrie(root, keywords, KEYWORD, false)
-	addToTrie(root, tableIndicatorCommands, COMMAND, true)
-	addToTrie(root, tableIndicatorKeywords, KEYWORD, true)
-	addToTrie(root, booleanValues, BOOLEAN, false)
-	addToTrie(root, nullValues, NULL, false)
-	addToTrie(root, procedureNames, PROC_INDICATOR, false)
-	addToTrie(root, ctes, CTE_INDICATOR, false)
-	addToTrie(root, alias, ALIAS_INDICATOR, false)
-
-	return root
-}
-
-func addToTrie(root *trieNode, words []string, tokenType TokenType, isTableIndicator bool) {
-	for _, word := range words {
-		node := root
-		// Convert to uppercase for case-insensitive matching
-		for _, ch := range strings.ToUpper(word) {
-			if next, exists := node.children[ch]; exists {
-				node = next
-			} else {
-				next = &trieNode{children: make(map[rune]*trieNode)}
-				node.children[ch] = next
-				node = next
-			}
+//go:generate go run gen_keywords.go
+
+// keyword is a keyword of the lexer, as looked up by the generated lookupKeyword
+type keyword struct {
+	value            string // the keyword in uppercase
+	lower            string // the keyword in lowercase
+	tokenType        TokenType
+	isTableIndicator bool
+}
+
+// asciiSymbols holds every ASCII character, the values of single character symbols are sliced from it
+var asciiSymbols = func() string {
+	symbols := make([]byte, utf8.RuneSelf)
+	for i := range symbols {
+		symbols[i] = byte(i)
+	}
+	return string(symbols)
+}()
+
+// symbols are the operators of several characters whose values are interned
+var symbols = func() map[string]string {
+	symbols := make(map[string]string)
+	for _, symbol := range []string{
+		"<=", ">=", "<>", "!=", "==", "||", "&&", "::", ":=", "=>", "<<", ">>", "**",
+		"->", "->>", "#>", "#>>", "#-", "?|", "?&", "<@", "@>",
+	} {
+		symbols[symbol] = symbol
+	}
+	return symbols
+}()
+
+// internSymbol returns the value of an operator or a punctuation from a static table if it is a common one,
+// so the values of the tokens share their memory instead of retaining the input
+func internSymbol(value string) string {
+	if len(value) == 1 && value[0] < utf8.RuneSelf {
+		return asciiSymbols[value[0] : value[0]+1]
+	}
+	if symbol, ok := symbols[value]; ok {
+		return symbol
+	}
+	return value
+}
+
+// isKeyword checks if an ASCII word is one of the keywords of the lexer, case-insensitively
+func isKeyword(word string) bool {
+	return lookupKeyword(word) != nil
+}
+
+// ToUpperASCII returns s with the ASCII letters a-z mapped to upper case.
+// Unlike strings.ToUpper, it is independent of Unicode case mappings, e.g. the Turkish dotless "ı"
+// is not mapped to "I", and it never changes the bytes of multi-byte characters.
+// s is returned as is, without allocating, if it has no lowercase ASCII letter.
+func ToUpperASCII(s string) string {
+	return toggleCaseASCII(s, 'a', 'z')
+}
+
+// ToLowerASCII returns s with the ASCII letters A-Z mapped to lower case.
+// Like ToUpperASCII, it is independent of Unicode case mappings and never changes multi-byte characters.
+func ToLowerASCII(s string) string {
+	return toggleCaseASCII(s, 'A', 'Z')
+}
+
+// toggleCaseASCII toggles the case of the ASCII letters of s in the range [from, to]
+func toggleCaseASCII(s string, from, to byte) string {
+	i := 0
+	for i < len(s) && (s[i] < from || s[i] > to) {
+		i++
+	}
+	if i == len(s) {
+		return s
+	}
+	var mapped strings.Builder
+	mapped.Grow(len(s))
+	mapped.WriteString(s[:i])
+	for ; i < len(s); i++ {
+		c := s[i]
+		if c >= from && c <= to {
+			c ^= 'a' - 'A'
 		}
-		node.isEnd = true
-		node.tokenType = tokenType
-		node.isTableIndicator = isTableIndicator
+		mapped.WriteByte(c)
 	}
+	return mapped.String()
 }
 
-var keywordRoot = buildCombinedTrie()
+// equalFoldASCII reports whether s and t are equal under ASCII case folding
+func equalFoldASCII(s, t string) bool {
+	if len(s) != len(t) {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		a, b := s[i], t[i]
+		if a >= 'a' && a <= 'z' {
+			a -= 'a' - 'A'
+		}
+		if b >= 'a' && b <= 'z' {
+			b -= 'a' - 'A'
+		}
+		if a != b {
+			return false
+		}
+	}
+	return true
+}
 
 // TODO: Optimize these functions to work with rune positions instead of string operations
 // They are currently used by obfuscator and normalizer, which we'll optimize later
@@ -277,9 +384,104 @@ func trimQuotes(token *Token) string {
 	return trimmedToken.String()
 }
 
+// QualifiedParts returns the unquoted parts of the qualified name of an IDENT, QUOTED_IDENT or FUNCTION token,
+// e.g. public and users table for "public"."users table", or db, schema and table for db.schema.table.
+// It returns nil for the other tokens, and for the tokens lexed WithOffsetsOnly.
+func (t *Token) QualifiedParts() []string {
+	if t.Type != IDENT && t.Type != QUOTED_IDENT && t.Type != FUNCTION {
+		return nil
+	}
+	var parts []string
+	for value := t.Value; value != ""; value = strings.TrimPrefix(value, ".") {
+		var part string
+		if value[0] == '"' || value[0] == '`' || value[0] == '[' {
+			part, value = unquoteIdentifierPart(value)
+		} else {
+			end := strings.IndexByte(value, '.')
+			if end < 0 {
+				end = len(value)
+			}
+			part, value = value[:end], value[end:]
+		}
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// unquoteIdentifierPart returns the unquoted quoted identifier starting the value, its doubled closing quotes
+// unescaped, and the rest of the value
+func unquoteIdentifierPart(value string) (part, rest string) {
+	closing := value[0]
+	if closing == '[' {
+		closing = ']'
+	}
+	escaped := false
+	for i := 1; i < len(value); i++ {
+		if value[i] != closing {
+			continue
+		}
+		if i+1 < len(value) && value[i+1] == closing {
+			escaped = true
+			i++
+			continue
+		}
+		part = value[1:i]
+		if escaped {
+			part = strings.ReplaceAll(part, string([]byte{closing, closing}), string(closing))
+		}
+		return part, value[i+1:]
+	}
+	// an incomplete quoted identifier
+	return value[1:], ""
+}
+
+// character classes of the ASCII characters, as bits of charClasses
+const (
+	charSpace       uint8 = 1 << iota // space, tab, newline, carriage return, form feed and vertical tab
+	charDigit                         // 0-9
+	charLetter                        // a-z, A-Z and underscore
+	charOperator                      // operator characters, see isOperator
+	charPunctuation                   // punctuation characters, see isPunctuation
+	charIdentifier                    // characters of identifiers: letters, digits and . $ # / @ !
+)
+
+// charClasses are the classes of the bytes, replacing chains of comparisons in the hot loops of the lexer.
+// Only ASCII characters have classes, non-ASCII letters are classified with the unicode package.
+var charClasses = func() (classes [256]uint8) {
+	for _, ch := range " \t\n\r\f\v" {
+		classes[ch] |= charSpace
+	}
+	for ch := '0'; ch <= '9'; ch++ {
+		classes[ch] |= charDigit | charIdentifier
+	}
+	for ch := 'a'; ch <= 'z'; ch++ {
+		classes[ch] |= charLetter | charIdentifier
+		classes[ch-'a'+'A'] |= charLetter | charIdentifier
+	}
+	classes['_'] |= charLetter | charIdentifier
+	for _, ch := range "+-*/=<>!&|^%~?@:#" {
+		classes[ch] |= charOperator
+	}
+	for _, ch := range "(),;.:[]{}" {
+		classes[ch] |= charPunctuation
+	}
+	for _, ch := range ".$#/@!" {
+		classes[ch] |= charIdentifier
+	}
+	return classes
+}()
+
+// charClass returns the classes of an ASCII character, and no class for other characters
+func charClass(ch rune) uint8 {
+	if ch < 0 || ch >= 128 {
+		return 0
+	}
+	return charClasses[ch]
+}
+
 // isDigit checks if a rune is a digit (0-9)
 func isDigit(ch rune) bool {
-	return ch >= '0' && ch <= '9'
+	return charClass(ch)&charDigit != 0
 }
 
 // isLeadingDigit checks if a rune is + or -
@@ -292,9 +494,24 @@ func isExpontent(ch rune) bool {
 	return ch == 'e' || ch == 'E'
 }
 
-// isSpace checks if a rune is a space or newline
+// isHexDigit checks if a rune is a hexadecimal digit (0-9, a-f, A-F)
+func isHexDigit(ch rune) bool {
+	return isDigit(ch) || ('a' <= ch && ch <= 'f') || ('A' <= ch && ch <= 'F')
+}
+
+// isOctalDigit checks if a rune is an octal digit (0-7)
+func isOctalDigit(ch rune) bool {
+	return '0' <= ch && ch <= '7'
+}
+
+// isBinaryDigit checks if a rune is a binary digit (0 or 1)
+func isBinaryDigit(ch rune) bool {
+	return ch == '0' || ch == '1'
+}
+
+// isSpace checks if a rune is a space, tab, newline, carriage return, form feed or vertical tab
 func isSpace(ch rune) bool {
-	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
+	return charClass(ch)&charSpace != 0
 }
 
 // isAsciiLetter checks if a rune is an ASCII letter (a-z or A-Z)
@@ -304,8 +521,7 @@ func isAsciiLetter(ch rune) bool {
 
 // isLetter checks if a rune is an ASCII letter (a-z or A-Z) or unicode letter
 func isLetter(ch rune) bool {
-	return isAsciiLetter(ch) || ch == '_' ||
-		(ch > 127 && unicode.IsLetter(ch))
+	return charClass(ch)&charLetter != 0 || (ch > 127 && unicode.IsLetter(ch))
 }
 
 // isAlphaNumeric checks if a rune is an ASCII letter (a-z or A-Z), digit (0-9), or unicode number
@@ -326,9 +542,7 @@ func isSingleQuote(ch rune) bool {
 
 // isOperator checks if a rune is an operator
 func isOperator(ch rune) bool {
-	return ch == '+' || ch == '-' || ch == '*' || ch == '/' || ch == '=' || ch == '<' || ch == '>' ||
-		ch == '!' || ch == '&' || ch == '|' || ch == '^' || ch == '%' || ch == '~' || ch == '?' ||
-		ch == '@' || ch == ':' || ch == '#'
+	return charClass(ch)&charOperator != 0
 }
 
 // isWildcard checks if a rune is a wildcard (*)
@@ -348,8 +562,7 @@ func isMultiLineComment(ch rune, nextCh rune) bool {
 
 // isPunctuation checks if a rune is a punctuation character
 func isPunctuation(ch rune) bool {
-	return ch == '(' || ch == ')' || ch == ',' || ch == ';' || ch == '.' || ch == ':' ||
-		ch == '[' || ch == ']' || ch == '{' || ch == '}'
+	return charClass(ch)&charPunctuation != 0
 }
 
 // isEOF checks if a rune is EOF (end of file)
@@ -359,11 +572,112 @@ func isEOF(ch rune) bool {
 
 // isIdentifier checks if a rune is an identifier
 func isIdentifier(ch rune) bool {
-	return ch == '.' || ch == '?' || ch == '$' || ch == '#' || ch == '/' || ch == '@' || ch == '!' || isLetter(ch) || isDigit(ch)
+	return charClass(ch)&charIdentifier != 0 || (ch > 127 && unicode.IsLetter(ch))
+}
+
+// bytesPerToken and bytesPerValueToken are the average lengths of the tokens of typical queries,
+// spaces included, measured on the queries of the tests
+const (
+	bytesPerToken      = 2
+	bytesPerValueToken = 4
+)
+
+// estimateTokens returns the expected number of tokens of an input of n bytes,
+// to preallocate the slices of tokens without growing them while scanning
+func estimateTokens(n int) int {
+	return n/bytesPerToken + 1
+}
+
+// estimateValueTokens returns the expected number of value tokens of an input of n bytes
+func estimateValueTokens(n int) int {
+	return n/bytesPerValueToken + 1
 }
 
 // isValueToken checks if a token is a value token
 // A value token is a token that is not a space, comment, or EOF
 func isValueToken(token *Token) bool {
-	return token.Type != EOF && token.Type != SPACE && token.Type != COMMENT && token.Type != MULTILINE_COMMENT
+	return token.IsSignificant()
+}
+
+// IsSignificant returns true if the token is part of the statement: it is not a space, a comment or EOF
+func (t *Token) IsSignificant() bool {
+	return t.Type != EOF && t.Type != SPACE && !t.IsComment()
+}
+
+// IsComment returns true if the token is a comment, incomplete comments included
+func (t *Token) IsComment() bool {
+	return t.Type == COMMENT || t.Type == MULTILINE_COMMENT || t.Type == INCOMPLETE_COMMENT
+}
+
+// IsLiteral returns true if the token is a literal: a number, a string, incomplete strings included, a boolean or NULL
+func (t *Token) IsLiteral() bool {
+	switch t.Type {
+	case NUMBER, STRING, INCOMPLETE_STRING, DOLLAR_QUOTED_STRING, BOOLEAN, NULL:
+		return true
+	}
+	return false
+}
+
+// IsParameter returns true if the token is a bind parameter, e.g. :name, @name or $1, or a placeholder.
+// The ? placeholders are OPERATOR tokens, as ? is also an operator, e.g. of the PostgreSQL JSON,
+// unless they are lexed WithQuestionMarkPlaceholders.
+func (t *Token) IsParameter() bool {
+	return t.Type == POSITIONAL_PARAMETER || t.Type == BIND_PARAMETER || t.Type == PLACEHOLDER
+}
+
+// IsValue returns true if the token stands for a value of the statement: a literal or a bind parameter
+func (t *Token) IsValue() bool {
+	return t.IsLiteral() || t.IsParameter()
+}
+
+// isTemporaryTableName reports whether the identifier names a temporary table by itself,
+// e.g. #orders or ##orders in SQL Server, or pg_temp.orders in PostgreSQL
+func isTemporaryTableName(name string) bool {
+	if name[0] == '#' {
+		return true
+	}
+	return len(name) > len("pg_temp.") && equalFoldASCII(name[:len("pg_temp.")], "pg_temp.")
+}
+
+// isOperandEnd checks if a value token of the type and value ends an operand, so a sign following it
+// is a binary operator, e.g. the minus of 1-1 or (a)-1, and not the sign of a number
+func isOperandEnd(tokenType TokenType, value string) bool {
+	switch tokenType {
+	case NUMBER, STRING, INCOMPLETE_STRING, QUOTED_IDENT, DOLLAR_QUOTED_STRING, DOLLAR_QUOTED_FUNCTION,
+		POSITIONAL_PARAMETER, BIND_PARAMETER, PLACEHOLDER, SYSTEM_VARIABLE, BOOLEAN, NULL, TEMPLATE:
+		return true
+	case IDENT:
+		// the words scanned as identifiers that precede an operand, e.g. CASE WHEN -1
+		return !isOperandWord(value)
+	case KEYWORD:
+		// the end of a CASE expression
+		return equalFoldASCII(value, "END")
+	case PUNCTUATION:
+		return value == ")" || value == "]"
+	}
+	return false
+}
+
+// wildcardEndWords are the words following the wildcard of a select list, e.g. SELECT * EXCEPT (a)
+var wildcardEndWords = []string{"FROM", "INTO", "EXCEPT", "EXCLUDE", "REPLACE", "RENAME"}
+
+func isWildcardEndWord(value string) bool {
+	for _, word := range wildcardEndWords {
+		if equalFoldASCII(value, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// operandWords are the words preceding an operand which are not scanned as keywords
+var operandWords = []string{"WHEN", "THEN", "RETURN", "OFFSET", "FETCH", "ILIKE", "DIV", "MOD", "XOR", "INTERVAL"}
+
+func isOperandWord(value string) bool {
+	for _, word := range operandWords {
+		if equalFoldASCII(value, word) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,142 @@
+package sqllexerpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative sqllexer.proto
+
+import (
+	sqllexer "github.com/DataDog/go-sqllexer"
+)
+
+// FromToken returns the protobuf message of the token
+func FromToken(token *sqllexer.Token) *Token {
+	return &Token{
+		Type:  TokenType(token.Type),
+		Value: token.Value,
+		Start: int64(token.Start),
+		End:   int64(token.End),
+	}
+}
+
+// ToToken returns the token of the protobuf message
+func (t *Token) ToToken() sqllexer.Token {
+	return sqllexer.Token{
+		Type:  sqllexer.TokenType(t.GetType()),
+		Value: t.GetValue(),
+		Start: int(t.GetStart()),
+		End:   int(t.GetEnd()),
+	}
+}
+
+// NewTokenStream returns the tokens of the query lexed for the DBMS
+func NewTokenStream(query string, dbms sqllexer.DBMSType) *TokenStream {
+	stream := &TokenStream{Dbms: string(dbms)}
+	lexer := sqllexer.New(query, sqllexer.WithDBMS(dbms))
+	for token := lexer.Scan(); token.Type != sqllexer.EOF; token = lexer.Scan() {
+		stream.Tokens = append(stream.Tokens, FromToken(token))
+	}
+	return stream
+}
+
+// FromStatementMetadata returns the protobuf message of the metadata, nil if the metadata is nil
+func FromStatementMetadata(metadata *sqllexer.StatementMetadata) *StatementMetadata {
+	if metadata == nil {
+		return nil
+	}
+	message := &StatementMetadata{
+		Size:          int64(metadata.Size),
+		Tables:        metadata.Tables,
+		Comments:      metadata.Comments,
+		Commands:      metadata.Commands,
+		Procedures:    metadata.Procedures,
+		Columns:       metadata.Columns,
+		StatementKind: string(metadata.StatementKind),
+		TableAliases:  metadata.TableAliases,
+		Truncated:     metadata.Truncated,
+	}
+	for _, join := range metadata.Joins {
+		message.Joins = append(message.Joins, &Join{Type: join.Type, Left: join.Left, Right: join.Right})
+	}
+	for _, offset := range metadata.Offsets {
+		message.Offsets = append(message.Offsets, &OffsetMapping{
+			NormalizedStart: int64(offset.NormalizedStart),
+			NormalizedEnd:   int64(offset.NormalizedEnd),
+			OriginalStart:   int64(offset.OriginalStart),
+			OriginalEnd:     int64(offset.OriginalEnd),
+		})
+	}
+	message.GroupBy = fromOrderingExpressions(metadata.GroupBy)
+	message.OrderBy = fromOrderingExpressions(metadata.OrderBy)
+	return message
+}
+
+func fromOrderingExpressions(expressions []sqllexer.OrderingExpression) []*OrderingExpression {
+	var messages []*OrderingExpression
+	for _, expression := range expressions {
+		messages = append(messages, &OrderingExpression{
+			Expression: expression.Expression,
+			Ordinal:    int64(expression.Ordinal),
+			Descending: expression.Descending,
+		})
+	}
+	return messages
+}
+
+// ToStatementMetadata returns the metadata of the protobuf message, nil if the message is nil.
+// The lists which the normalizer always sets are empty instead of nil, as they are in its metadata.
+func (m *StatementMetadata) ToStatementMetadata() *sqllexer.StatementMetadata {
+	if m == nil {
+		return nil
+	}
+	metadata := &sqllexer.StatementMetadata{
+		Size:          int(m.Size),
+		Tables:        nonNil(m.Tables),
+		Comments:      nonNil(m.Comments),
+		Commands:      nonNil(m.Commands),
+		Procedures:    nonNil(m.Procedures),
+		Columns:       m.Columns,
+		StatementKind: sqllexer.StatementKind(m.StatementKind),
+		TableAliases:  m.TableAliases,
+		Truncated:     m.Truncated,
+	}
+	for _, join := range m.Joins {
+		metadata.Joins = append(metadata.Joins, sqllexer.Join{Type: join.GetType(), Left: join.GetLeft(), Right: join.GetRight()})
+	}
+	for _, offset := range m.Offsets {
+		metadata.Offsets = append(metadata.Offsets, sqllexer.OffsetMapping{
+			NormalizedStart: int(offset.GetNormalizedStart()),
+			NormalizedEnd:   int(offset.GetNormalizedEnd()),
+			OriginalStart:   int(offset.GetOriginalStart()),
+			OriginalEnd:     int(offset.GetOriginalEnd()),
+		})
+	}
+	metadata.GroupBy = toOrderingExpressions(m.GroupBy)
+	metadata.OrderBy = toOrderingExpressions(m.OrderBy)
+	return metadata
+}
+
+func toOrderingExpressions(messages []*OrderingExpression) []sqllexer.OrderingExpression {
+	var expressions []sqllexer.OrderingExpression
+	for _, message := range messages {
+		expressions = append(expressions, sqllexer.OrderingExpression{
+			Expression: message.GetExpression(),
+			Ordinal:    int(message.GetOrdinal()),
+			Descending: message.GetDescending(),
+		})
+	}
+	return expressions
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// ObfuscateAndNormalize obfuscates and normalizes the query for the DBMS, see sqllexer.ObfuscateAndNormalize
+func ObfuscateAndNormalize(query string, obfuscator *sqllexer.Obfuscator, normalizer *sqllexer.Normalizer, dbms sqllexer.DBMSType) (*ObfuscationResult, error) {
+	sql, metadata, err := sqllexer.ObfuscateAndNormalize(query, obfuscator, normalizer, sqllexer.WithDBMS(dbms))
+	if err != nil {
+		return nil, err
+	}
+	return &ObfuscationResult{Sql: sql, Metadata: FromStatementMetadata(metadata)}, nil
+}
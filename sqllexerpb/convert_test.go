@@ -0,0 +1,101 @@
+package sqllexerpb
+
+import (
+	"fmt"
+	"testing"
+
+	sqllexer "github.com/DataDog/go-sqllexer"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTokenTypes(t *testing.T) {
+	// the values of the enum are the ones of sqllexer.TokenType
+	assert.Len(t, TokenType_name, int(sqllexer.PLACEHOLDER)+1)
+	assert.Equal(t, TokenType_TOKEN_TYPE_COMMAND, TokenType(sqllexer.COMMAND))
+	assert.Equal(t, TokenType_TOKEN_TYPE_ALIAS_INDICATOR, TokenType(sqllexer.ALIAS_INDICATOR))
+	assert.Equal(t, TokenType_TOKEN_TYPE_TEMPLATE, TokenType(sqllexer.TEMPLATE))
+	assert.Equal(t, TokenType_TOKEN_TYPE_PLACEHOLDER, TokenType(sqllexer.PLACEHOLDER))
+}
+
+func TestNewTokenStream(t *testing.T) {
+	stream := NewTokenStream("SELECT $1", sqllexer.DBMSPostgres)
+	expected := &TokenStream{
+		Dbms: "postgresql",
+		Tokens: []*Token{
+			{Type: TokenType_TOKEN_TYPE_COMMAND, Value: "SELECT", Start: 0, End: 6},
+			{Type: TokenType_TOKEN_TYPE_SPACE, Value: " ", Start: 6, End: 7},
+			{Type: TokenType_TOKEN_TYPE_POSITIONAL_PARAMETER, Value: "$1", Start: 7, End: 9},
+		},
+	}
+	assert.True(t, proto.Equal(expected, stream), "got %v", stream)
+	assert.Equal(t, sqllexer.Token{Type: sqllexer.POSITIONAL_PARAMETER, Value: "$1", Start: 7, End: 9}, stream.Tokens[2].ToToken())
+}
+
+func TestStatementMetadata(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "joins, aliases and offsets",
+			input: "SELECT u.name FROM users u JOIN orders o ON o.user_id = u.id",
+		},
+		{
+			name:  "comments",
+			input: "/* trace */ DELETE FROM sessions",
+		},
+	}
+
+	normalizer := sqllexer.NewNormalizer(
+		sqllexer.WithCollectTables(true),
+		sqllexer.WithCollectCommands(true),
+		sqllexer.WithCollectComments(true),
+		sqllexer.WithCollectProcedures(true),
+		sqllexer.WithCollectOffsets(true),
+	)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ObfuscateAndNormalize(tt.input, sqllexer.NewObfuscator(), normalizer, sqllexer.DBMSPostgres)
+			assert.NoError(t, err)
+			_, metadata, err := sqllexer.ObfuscateAndNormalize(tt.input, sqllexer.NewObfuscator(), normalizer, sqllexer.WithDBMS(sqllexer.DBMSPostgres))
+			assert.NoError(t, err)
+
+			// the metadata survives the encoding
+			data, err := proto.Marshal(result)
+			assert.NoError(t, err)
+			decoded := &ObfuscationResult{}
+			assert.NoError(t, proto.Unmarshal(data, decoded))
+			assert.Equal(t, metadata, decoded.GetMetadata().ToStatementMetadata())
+		})
+	}
+}
+
+func TestStatementMetadataOrdering(t *testing.T) {
+	normalizer := sqllexer.NewNormalizer(sqllexer.WithCollectOrdering(true))
+	_, metadata, err := normalizer.Normalize("SELECT a, count(*) FROM t GROUP BY a ORDER BY 2 DESC")
+	assert.NoError(t, err)
+
+	decoded := &StatementMetadata{}
+	data, err := proto.Marshal(FromStatementMetadata(metadata))
+	assert.NoError(t, err)
+	assert.NoError(t, proto.Unmarshal(data, decoded))
+	assert.Equal(t, metadata.GroupBy, decoded.ToStatementMetadata().GroupBy)
+	assert.Equal(t, []sqllexer.OrderingExpression{{Expression: "2", Ordinal: 2, Descending: true}}, decoded.ToStatementMetadata().OrderBy)
+}
+
+func TestNilStatementMetadata(t *testing.T) {
+	assert.Nil(t, FromStatementMetadata(nil))
+	var message *StatementMetadata
+	assert.Nil(t, message.ToStatementMetadata())
+}
+
+func ExampleObfuscateAndNormalize() {
+	normalizer := sqllexer.NewNormalizer(sqllexer.WithCollectTables(true))
+	result, err := ObfuscateAndNormalize("SELECT * FROM users WHERE id = 42", sqllexer.NewObfuscator(), normalizer, sqllexer.DBMSMySQL)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(result.GetSql(), result.GetMetadata().GetTables())
+	// Output: SELECT * FROM users WHERE id = ? [users]
+}
@@ -0,0 +1,15 @@
+module github.com/DataDog/go-sqllexer/sqllexerpb
+
+go 1.21
+
+require (
+	github.com/DataDog/go-sqllexer v0.1.6
+	github.com/stretchr/testify v1.8.4
+	google.golang.org/protobuf v1.34.2
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,998 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: sqllexer.proto
+
+// The tokens and metadata produced by github.com/DataDog/go-sqllexer, for consumers written in other languages.
+
+package sqllexerpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// TokenType is the type of a token. Its values are the ones of sqllexer.TokenType.
+type TokenType int32
+
+const (
+	TokenType_TOKEN_TYPE_ERROR                   TokenType = 0
+	TokenType_TOKEN_TYPE_EOF                     TokenType = 1
+	TokenType_TOKEN_TYPE_SPACE                   TokenType = 2
+	TokenType_TOKEN_TYPE_STRING                  TokenType = 3
+	TokenType_TOKEN_TYPE_INCOMPLETE_STRING       TokenType = 4
+	TokenType_TOKEN_TYPE_NUMBER                  TokenType = 5
+	TokenType_TOKEN_TYPE_IDENT                   TokenType = 6
+	TokenType_TOKEN_TYPE_QUOTED_IDENT            TokenType = 7
+	TokenType_TOKEN_TYPE_OPERATOR                TokenType = 8
+	TokenType_TOKEN_TYPE_WILDCARD                TokenType = 9
+	TokenType_TOKEN_TYPE_COMMENT                 TokenType = 10
+	TokenType_TOKEN_TYPE_MULTILINE_COMMENT       TokenType = 11
+	TokenType_TOKEN_TYPE_PUNCTUATION             TokenType = 12
+	TokenType_TOKEN_TYPE_DOLLAR_QUOTED_FUNCTION  TokenType = 13
+	TokenType_TOKEN_TYPE_DOLLAR_QUOTED_STRING    TokenType = 14
+	TokenType_TOKEN_TYPE_POSITIONAL_PARAMETER    TokenType = 15
+	TokenType_TOKEN_TYPE_BIND_PARAMETER          TokenType = 16
+	TokenType_TOKEN_TYPE_FUNCTION                TokenType = 17
+	TokenType_TOKEN_TYPE_SYSTEM_VARIABLE         TokenType = 18
+	TokenType_TOKEN_TYPE_UNKNOWN                 TokenType = 19
+	TokenType_TOKEN_TYPE_COMMAND                 TokenType = 20
+	TokenType_TOKEN_TYPE_KEYWORD                 TokenType = 21
+	TokenType_TOKEN_TYPE_JSON_OP                 TokenType = 22
+	TokenType_TOKEN_TYPE_BOOLEAN                 TokenType = 23
+	TokenType_TOKEN_TYPE_NULL                    TokenType = 24
+	TokenType_TOKEN_TYPE_PROC_INDICATOR          TokenType = 25
+	TokenType_TOKEN_TYPE_CTE_INDICATOR           TokenType = 26
+	TokenType_TOKEN_TYPE_ALIAS_INDICATOR         TokenType = 27
+	TokenType_TOKEN_TYPE_TEMPLATE                TokenType = 28
+	TokenType_TOKEN_TYPE_INCOMPLETE_COMMENT      TokenType = 29
+	TokenType_TOKEN_TYPE_INCOMPLETE_QUOTED_IDENT TokenType = 30
+	TokenType_TOKEN_TYPE_PLACEHOLDER             TokenType = 31
+)
+
+// Enum value maps for TokenType.
+var (
+	TokenType_name = map[int32]string{
+		0:  "TOKEN_TYPE_ERROR",
+		1:  "TOKEN_TYPE_EOF",
+		2:  "TOKEN_TYPE_SPACE",
+		3:  "TOKEN_TYPE_STRING",
+		4:  "TOKEN_TYPE_INCOMPLETE_STRING",
+		5:  "TOKEN_TYPE_NUMBER",
+		6:  "TOKEN_TYPE_IDENT",
+		7:  "TOKEN_TYPE_QUOTED_IDENT",
+		8:  "TOKEN_TYPE_OPERATOR",
+		9:  "TOKEN_TYPE_WILDCARD",
+		10: "TOKEN_TYPE_COMMENT",
+		11: "TOKEN_TYPE_MULTILINE_COMMENT",
+		12: "TOKEN_TYPE_PUNCTUATION",
+		13: "TOKEN_TYPE_DOLLAR_QUOTED_FUNCTION",
+		14: "TOKEN_TYPE_DOLLAR_QUOTED_STRING",
+		15: "TOKEN_TYPE_POSITIONAL_PARAMETER",
+		16: "TOKEN_TYPE_BIND_PARAMETER",
+		17: "TOKEN_TYPE_FUNCTION",
+		18: "TOKEN_TYPE_SYSTEM_VARIABLE",
+		19: "TOKEN_TYPE_UNKNOWN",
+		20: "TOKEN_TYPE_COMMAND",
+		21: "TOKEN_TYPE_KEYWORD",
+		22: "TOKEN_TYPE_JSON_OP",
+		23: "TOKEN_TYPE_BOOLEAN",
+		24: "TOKEN_TYPE_NULL",
+		25: "TOKEN_TYPE_PROC_INDICATOR",
+		26: "TOKEN_TYPE_CTE_INDICATOR",
+		27: "TOKEN_TYPE_ALIAS_INDICATOR",
+		28: "TOKEN_TYPE_TEMPLATE",
+		29: "TOKEN_TYPE_INCOMPLETE_COMMENT",
+		30: "TOKEN_TYPE_INCOMPLETE_QUOTED_IDENT",
+		31: "TOKEN_TYPE_PLACEHOLDER",
+	}
+	TokenType_value = map[string]int32{
+		"TOKEN_TYPE_ERROR":                   0,
+		"TOKEN_TYPE_EOF":                     1,
+		"TOKEN_TYPE_SPACE":                   2,
+		"TOKEN_TYPE_STRING":                  3,
+		"TOKEN_TYPE_INCOMPLETE_STRING":       4,
+		"TOKEN_TYPE_NUMBER":                  5,
+		"TOKEN_TYPE_IDENT":                   6,
+		"TOKEN_TYPE_QUOTED_IDENT":            7,
+		"TOKEN_TYPE_OPERATOR":                8,
+		"TOKEN_TYPE_WILDCARD":                9,
+		"TOKEN_TYPE_COMMENT":                 10,
+		"TOKEN_TYPE_MULTILINE_COMMENT":       11,
+		"TOKEN_TYPE_PUNCTUATION":             12,
+		"TOKEN_TYPE_DOLLAR_QUOTED_FUNCTION":  13,
+		"TOKEN_TYPE_DOLLAR_QUOTED_STRING":    14,
+		"TOKEN_TYPE_POSITIONAL_PARAMETER":    15,
+		"TOKEN_TYPE_BIND_PARAMETER":          16,
+		"TOKEN_TYPE_FUNCTION":                17,
+		"TOKEN_TYPE_SYSTEM_VARIABLE":         18,
+		"TOKEN_TYPE_UNKNOWN":                 19,
+		"TOKEN_TYPE_COMMAND":                 20,
+		"TOKEN_TYPE_KEYWORD":                 21,
+		"TOKEN_TYPE_JSON_OP":                 22,
+		"TOKEN_TYPE_BOOLEAN":                 23,
+		"TOKEN_TYPE_NULL":                    24,
+		"TOKEN_TYPE_PROC_INDICATOR":          25,
+		"TOKEN_TYPE_CTE_INDICATOR":           26,
+		"TOKEN_TYPE_ALIAS_INDICATOR":         27,
+		"TOKEN_TYPE_TEMPLATE":                28,
+		"TOKEN_TYPE_INCOMPLETE_COMMENT":      29,
+		"TOKEN_TYPE_INCOMPLETE_QUOTED_IDENT": 30,
+		"TOKEN_TYPE_PLACEHOLDER":             31,
+	}
+)
+
+func (x TokenType) Enum() *TokenType {
+	p := new(TokenType)
+	*p = x
+	return p
+}
+
+func (x TokenType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TokenType) Descriptor() protoreflect.EnumDescriptor {
+	return file_sqllexer_proto_enumTypes[0].Descriptor()
+}
+
+func (TokenType) Type() protoreflect.EnumType {
+	return &file_sqllexer_proto_enumTypes[0]
+}
+
+func (x TokenType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TokenType.Descriptor instead.
+func (TokenType) EnumDescriptor() ([]byte, []int) {
+	return file_sqllexer_proto_rawDescGZIP(), []int{0}
+}
+
+// Token is a token of a query
+type Token struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type  TokenType `protobuf:"varint,1,opt,name=type,proto3,enum=datadog.sqllexer.v1.TokenType" json:"type,omitempty"`
+	Value string    `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// start is the byte offset of the token in the query
+	Start int64 `protobuf:"varint,3,opt,name=start,proto3" json:"start,omitempty"`
+	// end is the byte offset following the token in the query
+	End int64 `protobuf:"varint,4,opt,name=end,proto3" json:"end,omitempty"`
+}
+
+func (x *Token) Reset() {
+	*x = Token{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sqllexer_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Token) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Token) ProtoMessage() {}
+
+func (x *Token) ProtoReflect() protoreflect.Message {
+	mi := &file_sqllexer_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Token.ProtoReflect.Descriptor instead.
+func (*Token) Descriptor() ([]byte, []int) {
+	return file_sqllexer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Token) GetType() TokenType {
+	if x != nil {
+		return x.Type
+	}
+	return TokenType_TOKEN_TYPE_ERROR
+}
+
+func (x *Token) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Token) GetStart() int64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *Token) GetEnd() int64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+// TokenStream is the tokens of a query, EOF excluded
+type TokenStream struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// dbms is the database the query is written for, empty for the generic dialect
+	Dbms   string   `protobuf:"bytes,1,opt,name=dbms,proto3" json:"dbms,omitempty"`
+	Tokens []*Token `protobuf:"bytes,2,rep,name=tokens,proto3" json:"tokens,omitempty"`
+}
+
+func (x *TokenStream) Reset() {
+	*x = TokenStream{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sqllexer_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TokenStream) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenStream) ProtoMessage() {}
+
+func (x *TokenStream) ProtoReflect() protoreflect.Message {
+	mi := &file_sqllexer_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenStream.ProtoReflect.Descriptor instead.
+func (*TokenStream) Descriptor() ([]byte, []int) {
+	return file_sqllexer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TokenStream) GetDbms() string {
+	if x != nil {
+		return x.Dbms
+	}
+	return ""
+}
+
+func (x *TokenStream) GetTokens() []*Token {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+// Join is a join between two tables
+type Join struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Left string `protobuf:"bytes,2,opt,name=left,proto3" json:"left,omitempty"`
+	// right is empty when the joined relation is a subquery
+	Right string `protobuf:"bytes,3,opt,name=right,proto3" json:"right,omitempty"`
+}
+
+func (x *Join) Reset() {
+	*x = Join{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sqllexer_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Join) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Join) ProtoMessage() {}
+
+func (x *Join) ProtoReflect() protoreflect.Message {
+	mi := &file_sqllexer_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Join.ProtoReflect.Descriptor instead.
+func (*Join) Descriptor() ([]byte, []int) {
+	return file_sqllexer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Join) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Join) GetLeft() string {
+	if x != nil {
+		return x.Left
+	}
+	return ""
+}
+
+func (x *Join) GetRight() string {
+	if x != nil {
+		return x.Right
+	}
+	return ""
+}
+
+// OffsetMapping maps a range of the normalized SQL to the range of the original SQL it was produced from
+type OffsetMapping struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NormalizedStart int64 `protobuf:"varint,1,opt,name=normalized_start,json=normalizedStart,proto3" json:"normalized_start,omitempty"`
+	NormalizedEnd   int64 `protobuf:"varint,2,opt,name=normalized_end,json=normalizedEnd,proto3" json:"normalized_end,omitempty"`
+	OriginalStart   int64 `protobuf:"varint,3,opt,name=original_start,json=originalStart,proto3" json:"original_start,omitempty"`
+	OriginalEnd     int64 `protobuf:"varint,4,opt,name=original_end,json=originalEnd,proto3" json:"original_end,omitempty"`
+}
+
+func (x *OffsetMapping) Reset() {
+	*x = OffsetMapping{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sqllexer_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OffsetMapping) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OffsetMapping) ProtoMessage() {}
+
+func (x *OffsetMapping) ProtoReflect() protoreflect.Message {
+	mi := &file_sqllexer_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OffsetMapping.ProtoReflect.Descriptor instead.
+func (*OffsetMapping) Descriptor() ([]byte, []int) {
+	return file_sqllexer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *OffsetMapping) GetNormalizedStart() int64 {
+	if x != nil {
+		return x.NormalizedStart
+	}
+	return 0
+}
+
+func (x *OffsetMapping) GetNormalizedEnd() int64 {
+	if x != nil {
+		return x.NormalizedEnd
+	}
+	return 0
+}
+
+func (x *OffsetMapping) GetOriginalStart() int64 {
+	if x != nil {
+		return x.OriginalStart
+	}
+	return 0
+}
+
+func (x *OffsetMapping) GetOriginalEnd() int64 {
+	if x != nil {
+		return x.OriginalEnd
+	}
+	return 0
+}
+
+// OrderingExpression is an expression of a GROUP BY or ORDER BY clause
+type OrderingExpression struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Expression string `protobuf:"bytes,1,opt,name=expression,proto3" json:"expression,omitempty"`
+	// ordinal is the position of the select list item the expression references, 0 if it is not an ordinal
+	Ordinal    int64 `protobuf:"varint,2,opt,name=ordinal,proto3" json:"ordinal,omitempty"`
+	Descending bool  `protobuf:"varint,3,opt,name=descending,proto3" json:"descending,omitempty"`
+}
+
+func (x *OrderingExpression) Reset() {
+	*x = OrderingExpression{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sqllexer_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OrderingExpression) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderingExpression) ProtoMessage() {}
+
+func (x *OrderingExpression) ProtoReflect() protoreflect.Message {
+	mi := &file_sqllexer_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderingExpression.ProtoReflect.Descriptor instead.
+func (*OrderingExpression) Descriptor() ([]byte, []int) {
+	return file_sqllexer_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *OrderingExpression) GetExpression() string {
+	if x != nil {
+		return x.Expression
+	}
+	return ""
+}
+
+func (x *OrderingExpression) GetOrdinal() int64 {
+	if x != nil {
+		return x.Ordinal
+	}
+	return 0
+}
+
+func (x *OrderingExpression) GetDescending() bool {
+	if x != nil {
+		return x.Descending
+	}
+	return false
+}
+
+// StatementMetadata is the metadata collected by the normalizer
+type StatementMetadata struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Size          int64                 `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	Tables        []string              `protobuf:"bytes,2,rep,name=tables,proto3" json:"tables,omitempty"`
+	Comments      []string              `protobuf:"bytes,3,rep,name=comments,proto3" json:"comments,omitempty"`
+	Commands      []string              `protobuf:"bytes,4,rep,name=commands,proto3" json:"commands,omitempty"`
+	Procedures    []string              `protobuf:"bytes,5,rep,name=procedures,proto3" json:"procedures,omitempty"`
+	Columns       []string              `protobuf:"bytes,6,rep,name=columns,proto3" json:"columns,omitempty"`
+	StatementKind string                `protobuf:"bytes,7,opt,name=statement_kind,json=statementKind,proto3" json:"statement_kind,omitempty"`
+	TableAliases  map[string]string     `protobuf:"bytes,8,rep,name=table_aliases,json=tableAliases,proto3" json:"table_aliases,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Joins         []*Join               `protobuf:"bytes,9,rep,name=joins,proto3" json:"joins,omitempty"`
+	Truncated     bool                  `protobuf:"varint,10,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	Offsets       []*OffsetMapping      `protobuf:"bytes,11,rep,name=offsets,proto3" json:"offsets,omitempty"`
+	GroupBy       []*OrderingExpression `protobuf:"bytes,12,rep,name=group_by,json=groupBy,proto3" json:"group_by,omitempty"`
+	OrderBy       []*OrderingExpression `protobuf:"bytes,13,rep,name=order_by,json=orderBy,proto3" json:"order_by,omitempty"`
+}
+
+func (x *StatementMetadata) Reset() {
+	*x = StatementMetadata{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sqllexer_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StatementMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatementMetadata) ProtoMessage() {}
+
+func (x *StatementMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_sqllexer_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatementMetadata.ProtoReflect.Descriptor instead.
+func (*StatementMetadata) Descriptor() ([]byte, []int) {
+	return file_sqllexer_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StatementMetadata) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *StatementMetadata) GetTables() []string {
+	if x != nil {
+		return x.Tables
+	}
+	return nil
+}
+
+func (x *StatementMetadata) GetComments() []string {
+	if x != nil {
+		return x.Comments
+	}
+	return nil
+}
+
+func (x *StatementMetadata) GetCommands() []string {
+	if x != nil {
+		return x.Commands
+	}
+	return nil
+}
+
+func (x *StatementMetadata) GetProcedures() []string {
+	if x != nil {
+		return x.Procedures
+	}
+	return nil
+}
+
+func (x *StatementMetadata) GetColumns() []string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *StatementMetadata) GetStatementKind() string {
+	if x != nil {
+		return x.StatementKind
+	}
+	return ""
+}
+
+func (x *StatementMetadata) GetTableAliases() map[string]string {
+	if x != nil {
+		return x.TableAliases
+	}
+	return nil
+}
+
+func (x *StatementMetadata) GetJoins() []*Join {
+	if x != nil {
+		return x.Joins
+	}
+	return nil
+}
+
+func (x *StatementMetadata) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+func (x *StatementMetadata) GetOffsets() []*OffsetMapping {
+	if x != nil {
+		return x.Offsets
+	}
+	return nil
+}
+
+func (x *StatementMetadata) GetGroupBy() []*OrderingExpression {
+	if x != nil {
+		return x.GroupBy
+	}
+	return nil
+}
+
+func (x *StatementMetadata) GetOrderBy() []*OrderingExpression {
+	if x != nil {
+		return x.OrderBy
+	}
+	return nil
+}
+
+// ObfuscationResult is the result of obfuscating and normalizing a query
+type ObfuscationResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// sql is the obfuscated and normalized query
+	Sql      string             `protobuf:"bytes,1,opt,name=sql,proto3" json:"sql,omitempty"`
+	Metadata *StatementMetadata `protobuf:"bytes,2,opt,name=metadata,proto3" json:"metadata,omitempty"`
+}
+
+func (x *ObfuscationResult) Reset() {
+	*x = ObfuscationResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sqllexer_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ObfuscationResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ObfuscationResult) ProtoMessage() {}
+
+func (x *ObfuscationResult) ProtoReflect() protoreflect.Message {
+	mi := &file_sqllexer_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ObfuscationResult.ProtoReflect.Descriptor instead.
+func (*ObfuscationResult) Descriptor() ([]byte, []int) {
+	return file_sqllexer_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ObfuscationResult) GetSql() string {
+	if x != nil {
+		return x.Sql
+	}
+	return ""
+}
+
+func (x *ObfuscationResult) GetMetadata() *StatementMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+var File_sqllexer_proto protoreflect.FileDescriptor
+
+var file_sqllexer_proto_rawDesc = []byte{
+	0x0a, 0x0e, 0x73, 0x71, 0x6c, 0x6c, 0x65, 0x78, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x13, 0x64, 0x61, 0x74, 0x61, 0x64, 0x6f, 0x67, 0x2e, 0x73, 0x71, 0x6c, 0x6c, 0x65, 0x78,
+	0x65, 0x72, 0x2e, 0x76, 0x31, 0x22, 0x79, 0x0a, 0x05, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x32,
+	0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1e, 0x2e, 0x64,
+	0x61, 0x74, 0x61, 0x64, 0x6f, 0x67, 0x2e, 0x73, 0x71, 0x6c, 0x6c, 0x65, 0x78, 0x65, 0x72, 0x2e,
+	0x76, 0x31, 0x2e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79,
+	0x70, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x10,
+	0x0a, 0x03, 0x65, 0x6e, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x65, 0x6e, 0x64,
+	0x22, 0x55, 0x0a, 0x0b, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12,
+	0x12, 0x0a, 0x04, 0x64, 0x62, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64,
+	0x62, 0x6d, 0x73, 0x12, 0x32, 0x0a, 0x06, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x64, 0x6f, 0x67, 0x2e, 0x73, 0x71,
+	0x6c, 0x6c, 0x65, 0x78, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52,
+	0x06, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x22, 0x44, 0x0a, 0x04, 0x4a, 0x6f, 0x69, 0x6e, 0x12,
+	0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74,
+	0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x65, 0x66, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6c, 0x65, 0x66, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x69, 0x67, 0x68, 0x74,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x72, 0x69, 0x67, 0x68, 0x74, 0x22, 0xab, 0x01,
+	0x0a, 0x0d, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x12,
+	0x29, 0x0a, 0x10, 0x6e, 0x6f, 0x72, 0x6d, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x5f, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x6e, 0x6f, 0x72, 0x6d, 0x61,
+	0x6c, 0x69, 0x7a, 0x65, 0x64, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x25, 0x0a, 0x0e, 0x6e, 0x6f,
+	0x72, 0x6d, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x5f, 0x65, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0d, 0x6e, 0x6f, 0x72, 0x6d, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x45, 0x6e,
+	0x64, 0x12, 0x25, 0x0a, 0x0e, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x5f, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x6f, 0x72, 0x69, 0x67, 0x69,
+	0x6e, 0x61, 0x6c, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x6f, 0x72, 0x69, 0x67,
+	0x69, 0x6e, 0x61, 0x6c, 0x5f, 0x65, 0x6e, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b,
+	0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x45, 0x6e, 0x64, 0x22, 0x6e, 0x0a, 0x12, 0x4f,
+	0x72, 0x64, 0x65, 0x72, 0x69, 0x6e, 0x67, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x1e, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x65, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x18, 0x0a, 0x07, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x07, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x6c, 0x12, 0x1e, 0x0a, 0x0a, 0x64,
+	0x65, 0x73, 0x63, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x0a, 0x64, 0x65, 0x73, 0x63, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x22, 0x8d, 0x05, 0x0a, 0x11,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74,
+	0x61, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x12, 0x1a, 0x0a,
+	0x08, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x08, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x64, 0x75,
+	0x72, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x72, 0x6f, 0x63, 0x65,
+	0x64, 0x75, 0x72, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73,
+	0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x12,
+	0x25, 0x0a, 0x0e, 0x73, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x6b, 0x69, 0x6e,
+	0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x73, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65,
+	0x6e, 0x74, 0x4b, 0x69, 0x6e, 0x64, 0x12, 0x5d, 0x0a, 0x0d, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x5f,
+	0x61, 0x6c, 0x69, 0x61, 0x73, 0x65, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x38, 0x2e,
+	0x64, 0x61, 0x74, 0x61, 0x64, 0x6f, 0x67, 0x2e, 0x73, 0x71, 0x6c, 0x6c, 0x65, 0x78, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x4d, 0x65, 0x74,
+	0x61, 0x64, 0x61, 0x74, 0x61, 0x2e, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x41, 0x6c, 0x69, 0x61, 0x73,
+	0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0c, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x41, 0x6c,
+	0x69, 0x61, 0x73, 0x65, 0x73, 0x12, 0x2f, 0x0a, 0x05, 0x6a, 0x6f, 0x69, 0x6e, 0x73, 0x18, 0x09,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x64, 0x6f, 0x67, 0x2e, 0x73,
+	0x71, 0x6c, 0x6c, 0x65, 0x78, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4a, 0x6f, 0x69, 0x6e, 0x52,
+	0x05, 0x6a, 0x6f, 0x69, 0x6e, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61,
+	0x74, 0x65, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x74, 0x72, 0x75, 0x6e, 0x63,
+	0x61, 0x74, 0x65, 0x64, 0x12, 0x3c, 0x0a, 0x07, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x73, 0x18,
+	0x0b, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x64, 0x6f, 0x67, 0x2e,
+	0x73, 0x71, 0x6c, 0x6c, 0x65, 0x78, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x66, 0x66, 0x73,
+	0x65, 0x74, 0x4d, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x52, 0x07, 0x6f, 0x66, 0x66, 0x73, 0x65,
+	0x74, 0x73, 0x12, 0x42, 0x0a, 0x08, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x5f, 0x62, 0x79, 0x18, 0x0c,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x64, 0x6f, 0x67, 0x2e, 0x73,
+	0x71, 0x6c, 0x6c, 0x65, 0x78, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x72, 0x64, 0x65, 0x72,
+	0x69, 0x6e, 0x67, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x67,
+	0x72, 0x6f, 0x75, 0x70, 0x42, 0x79, 0x12, 0x42, 0x0a, 0x08, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x5f,
+	0x62, 0x79, 0x18, 0x0d, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x64,
+	0x6f, 0x67, 0x2e, 0x73, 0x71, 0x6c, 0x6c, 0x65, 0x78, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4f,
+	0x72, 0x64, 0x65, 0x72, 0x69, 0x6e, 0x67, 0x45, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x52, 0x07, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x42, 0x79, 0x1a, 0x3f, 0x0a, 0x11, 0x54, 0x61,
+	0x62, 0x6c, 0x65, 0x41, 0x6c, 0x69, 0x61, 0x73, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x69, 0x0a, 0x11, 0x4f,
+	0x62, 0x66, 0x75, 0x73, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x12, 0x10, 0x0a, 0x03, 0x73, 0x71, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73,
+	0x71, 0x6c, 0x12, 0x42, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x64, 0x6f, 0x67, 0x2e, 0x73,
+	0x71, 0x6c, 0x6c, 0x65, 0x78, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x6d, 0x65, 0x6e, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x52, 0x08, 0x6d, 0x65,
+	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x2a, 0x89, 0x07, 0x0a, 0x09, 0x54, 0x6f, 0x6b, 0x65, 0x6e,
+	0x54, 0x79, 0x70, 0x65, 0x12, 0x14, 0x0a, 0x10, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x54, 0x59,
+	0x50, 0x45, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x00, 0x12, 0x12, 0x0a, 0x0e, 0x54, 0x4f,
+	0x4b, 0x45, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x45, 0x4f, 0x46, 0x10, 0x01, 0x12, 0x14,
+	0x0a, 0x10, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x53, 0x50, 0x41,
+	0x43, 0x45, 0x10, 0x02, 0x12, 0x15, 0x0a, 0x11, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x54, 0x59,
+	0x50, 0x45, 0x5f, 0x53, 0x54, 0x52, 0x49, 0x4e, 0x47, 0x10, 0x03, 0x12, 0x20, 0x0a, 0x1c, 0x54,
+	0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x49, 0x4e, 0x43, 0x4f, 0x4d, 0x50,
+	0x4c, 0x45, 0x54, 0x45, 0x5f, 0x53, 0x54, 0x52, 0x49, 0x4e, 0x47, 0x10, 0x04, 0x12, 0x15, 0x0a,
+	0x11, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4e, 0x55, 0x4d, 0x42,
+	0x45, 0x52, 0x10, 0x05, 0x12, 0x14, 0x0a, 0x10, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x54, 0x59,
+	0x50, 0x45, 0x5f, 0x49, 0x44, 0x45, 0x4e, 0x54, 0x10, 0x06, 0x12, 0x1b, 0x0a, 0x17, 0x54, 0x4f,
+	0x4b, 0x45, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x51, 0x55, 0x4f, 0x54, 0x45, 0x44, 0x5f,
+	0x49, 0x44, 0x45, 0x4e, 0x54, 0x10, 0x07, 0x12, 0x17, 0x0a, 0x13, 0x54, 0x4f, 0x4b, 0x45, 0x4e,
+	0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4f, 0x50, 0x45, 0x52, 0x41, 0x54, 0x4f, 0x52, 0x10, 0x08,
+	0x12, 0x17, 0x0a, 0x13, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x57,
+	0x49, 0x4c, 0x44, 0x43, 0x41, 0x52, 0x44, 0x10, 0x09, 0x12, 0x16, 0x0a, 0x12, 0x54, 0x4f, 0x4b,
+	0x45, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x43, 0x4f, 0x4d, 0x4d, 0x45, 0x4e, 0x54, 0x10,
+	0x0a, 0x12, 0x20, 0x0a, 0x1c, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f,
+	0x4d, 0x55, 0x4c, 0x54, 0x49, 0x4c, 0x49, 0x4e, 0x45, 0x5f, 0x43, 0x4f, 0x4d, 0x4d, 0x45, 0x4e,
+	0x54, 0x10, 0x0b, 0x12, 0x1a, 0x0a, 0x16, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x54, 0x59, 0x50,
+	0x45, 0x5f, 0x50, 0x55, 0x4e, 0x43, 0x54, 0x55, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x10, 0x0c, 0x12,
+	0x25, 0x0a, 0x21, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x44, 0x4f,
+	0x4c, 0x4c, 0x41, 0x52, 0x5f, 0x51, 0x55, 0x4f, 0x54, 0x45, 0x44, 0x5f, 0x46, 0x55, 0x4e, 0x43,
+	0x54, 0x49, 0x4f, 0x4e, 0x10, 0x0d, 0x12, 0x23, 0x0a, 0x1f, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f,
+	0x54, 0x59, 0x50, 0x45, 0x5f, 0x44, 0x4f, 0x4c, 0x4c, 0x41, 0x52, 0x5f, 0x51, 0x55, 0x4f, 0x54,
+	0x45, 0x44, 0x5f, 0x53, 0x54, 0x52, 0x49, 0x4e, 0x47, 0x10, 0x0e, 0x12, 0x23, 0x0a, 0x1f, 0x54,
+	0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x50, 0x4f, 0x53, 0x49, 0x54, 0x49,
+	0x4f, 0x4e, 0x41, 0x4c, 0x5f, 0x50, 0x41, 0x52, 0x41, 0x4d, 0x45, 0x54, 0x45, 0x52, 0x10, 0x0f,
+	0x12, 0x1d, 0x0a, 0x19, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x42,
+	0x49, 0x4e, 0x44, 0x5f, 0x50, 0x41, 0x52, 0x41, 0x4d, 0x45, 0x54, 0x45, 0x52, 0x10, 0x10, 0x12,
+	0x17, 0x0a, 0x13, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x46, 0x55,
+	0x4e, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x10, 0x11, 0x12, 0x1e, 0x0a, 0x1a, 0x54, 0x4f, 0x4b, 0x45,
+	0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x53, 0x59, 0x53, 0x54, 0x45, 0x4d, 0x5f, 0x56, 0x41,
+	0x52, 0x49, 0x41, 0x42, 0x4c, 0x45, 0x10, 0x12, 0x12, 0x16, 0x0a, 0x12, 0x54, 0x4f, 0x4b, 0x45,
+	0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x13,
+	0x12, 0x16, 0x0a, 0x12, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x43,
+	0x4f, 0x4d, 0x4d, 0x41, 0x4e, 0x44, 0x10, 0x14, 0x12, 0x16, 0x0a, 0x12, 0x54, 0x4f, 0x4b, 0x45,
+	0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4b, 0x45, 0x59, 0x57, 0x4f, 0x52, 0x44, 0x10, 0x15,
+	0x12, 0x16, 0x0a, 0x12, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4a,
+	0x53, 0x4f, 0x4e, 0x5f, 0x4f, 0x50, 0x10, 0x16, 0x12, 0x16, 0x0a, 0x12, 0x54, 0x4f, 0x4b, 0x45,
+	0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x42, 0x4f, 0x4f, 0x4c, 0x45, 0x41, 0x4e, 0x10, 0x17,
+	0x12, 0x13, 0x0a, 0x0f, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4e,
+	0x55, 0x4c, 0x4c, 0x10, 0x18, 0x12, 0x1d, 0x0a, 0x19, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x54,
+	0x59, 0x50, 0x45, 0x5f, 0x50, 0x52, 0x4f, 0x43, 0x5f, 0x49, 0x4e, 0x44, 0x49, 0x43, 0x41, 0x54,
+	0x4f, 0x52, 0x10, 0x19, 0x12, 0x1c, 0x0a, 0x18, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x54, 0x59,
+	0x50, 0x45, 0x5f, 0x43, 0x54, 0x45, 0x5f, 0x49, 0x4e, 0x44, 0x49, 0x43, 0x41, 0x54, 0x4f, 0x52,
+	0x10, 0x1a, 0x12, 0x1e, 0x0a, 0x1a, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45,
+	0x5f, 0x41, 0x4c, 0x49, 0x41, 0x53, 0x5f, 0x49, 0x4e, 0x44, 0x49, 0x43, 0x41, 0x54, 0x4f, 0x52,
+	0x10, 0x1b, 0x12, 0x17, 0x0a, 0x13, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45,
+	0x5f, 0x54, 0x45, 0x4d, 0x50, 0x4c, 0x41, 0x54, 0x45, 0x10, 0x1c, 0x12, 0x21, 0x0a, 0x1d, 0x54,
+	0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x49, 0x4e, 0x43, 0x4f, 0x4d, 0x50,
+	0x4c, 0x45, 0x54, 0x45, 0x5f, 0x43, 0x4f, 0x4d, 0x4d, 0x45, 0x4e, 0x54, 0x10, 0x1d, 0x12, 0x26,
+	0x0a, 0x22, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x49, 0x4e, 0x43,
+	0x4f, 0x4d, 0x50, 0x4c, 0x45, 0x54, 0x45, 0x5f, 0x51, 0x55, 0x4f, 0x54, 0x45, 0x44, 0x5f, 0x49,
+	0x44, 0x45, 0x4e, 0x54, 0x10, 0x1e, 0x12, 0x1a, 0x0a, 0x16, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f,
+	0x54, 0x59, 0x50, 0x45, 0x5f, 0x50, 0x4c, 0x41, 0x43, 0x45, 0x48, 0x4f, 0x4c, 0x44, 0x45, 0x52,
+	0x10, 0x1f, 0x42, 0x2b, 0x5a, 0x29, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2f, 0x44, 0x61, 0x74, 0x61, 0x44, 0x6f, 0x67, 0x2f, 0x67, 0x6f, 0x2d, 0x73, 0x71, 0x6c, 0x6c,
+	0x65, 0x78, 0x65, 0x72, 0x2f, 0x73, 0x71, 0x6c, 0x6c, 0x65, 0x78, 0x65, 0x72, 0x70, 0x62, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_sqllexer_proto_rawDescOnce sync.Once
+	file_sqllexer_proto_rawDescData = file_sqllexer_proto_rawDesc
+)
+
+func file_sqllexer_proto_rawDescGZIP() []byte {
+	file_sqllexer_proto_rawDescOnce.Do(func() {
+		file_sqllexer_proto_rawDescData = protoimpl.X.CompressGZIP(file_sqllexer_proto_rawDescData)
+	})
+	return file_sqllexer_proto_rawDescData
+}
+
+var file_sqllexer_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_sqllexer_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_sqllexer_proto_goTypes = []any{
+	(TokenType)(0),             // 0: datadog.sqllexer.v1.TokenType
+	(*Token)(nil),              // 1: datadog.sqllexer.v1.Token
+	(*TokenStream)(nil),        // 2: datadog.sqllexer.v1.TokenStream
+	(*Join)(nil),               // 3: datadog.sqllexer.v1.Join
+	(*OffsetMapping)(nil),      // 4: datadog.sqllexer.v1.OffsetMapping
+	(*OrderingExpression)(nil), // 5: datadog.sqllexer.v1.OrderingExpression
+	(*StatementMetadata)(nil),  // 6: datadog.sqllexer.v1.StatementMetadata
+	(*ObfuscationResult)(nil),  // 7: datadog.sqllexer.v1.ObfuscationResult
+	nil,                        // 8: datadog.sqllexer.v1.StatementMetadata.TableAliasesEntry
+}
+var file_sqllexer_proto_depIdxs = []int32{
+	0, // 0: datadog.sqllexer.v1.Token.type:type_name -> datadog.sqllexer.v1.TokenType
+	1, // 1: datadog.sqllexer.v1.TokenStream.tokens:type_name -> datadog.sqllexer.v1.Token
+	8, // 2: datadog.sqllexer.v1.StatementMetadata.table_aliases:type_name -> datadog.sqllexer.v1.StatementMetadata.TableAliasesEntry
+	3, // 3: datadog.sqllexer.v1.StatementMetadata.joins:type_name -> datadog.sqllexer.v1.Join
+	4, // 4: datadog.sqllexer.v1.StatementMetadata.offsets:type_name -> datadog.sqllexer.v1.OffsetMapping
+	5, // 5: datadog.sqllexer.v1.StatementMetadata.group_by:type_name -> datadog.sqllexer.v1.OrderingExpression
+	5, // 6: datadog.sqllexer.v1.StatementMetadata.order_by:type_name -> datadog.sqllexer.v1.OrderingExpression
+	6, // 7: datadog.sqllexer.v1.ObfuscationResult.metadata:type_name -> datadog.sqllexer.v1.StatementMetadata
+	8, // [8:8] is the sub-list for method output_type
+	8, // [8:8] is the sub-list for method input_type
+	8, // [8:8] is the sub-list for extension type_name
+	8, // [8:8] is the sub-list for extension extendee
+	0, // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_sqllexer_proto_init() }
+func file_sqllexer_proto_init() {
+	if File_sqllexer_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_sqllexer_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*Token); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sqllexer_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*TokenStream); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sqllexer_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*Join); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sqllexer_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*OffsetMapping); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sqllexer_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*OrderingExpression); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sqllexer_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*StatementMetadata); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sqllexer_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*ObfuscationResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_sqllexer_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_sqllexer_proto_goTypes,
+		DependencyIndexes: file_sqllexer_proto_depIdxs,
+		EnumInfos:         file_sqllexer_proto_enumTypes,
+		MessageInfos:      file_sqllexer_proto_msgTypes,
+	}.Build()
+	File_sqllexer_proto = out.File
+	file_sqllexer_proto_rawDesc = nil
+	file_sqllexer_proto_goTypes = nil
+	file_sqllexer_proto_depIdxs = nil
+}
@@ -0,0 +1,229 @@
+// Package sqllexertest runs conformance corpora against the lexer, obfuscator and normalizer,
+// so dialect contributors and users of custom option sets can check the engine handles their queries as expected.
+//
+// A corpus is a list of fixtures, e.g. loaded from JSON files:
+//
+//	[
+//	  {
+//	    "name": "select with literal",
+//	    "dbms": "postgresql",
+//	    "input": "SELECT * FROM users WHERE id = 1",
+//	    "tokens": [{"type": "COMMAND", "value": "SELECT"}, {"type": "WILDCARD", "value": "*"}, ...],
+//	    "obfuscated": "SELECT * FROM users WHERE id = ?",
+//	    "normalized": "SELECT * FROM users WHERE id = ?"
+//	  }
+//	]
+package sqllexertest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"testing"
+
+	sqllexer "github.com/DataDog/go-sqllexer"
+)
+
+// Fixture is a query and the expected results of the engine. Empty expectations are not checked.
+type Fixture struct {
+	Name  string            `json:"name,omitempty"`
+	DBMS  sqllexer.DBMSType `json:"dbms,omitempty"`
+	Input string            `json:"input"`
+
+	// Tokens are the expected tokens of the input, spaces excluded
+	Tokens []Token `json:"tokens,omitempty"`
+
+	// Obfuscated is the expected output of the obfuscator
+	Obfuscated string `json:"obfuscated,omitempty"`
+
+	// Normalized is the expected output of the obfuscator and the normalizer, see sqllexer.ObfuscateAndNormalize
+	Normalized string `json:"normalized,omitempty"`
+}
+
+// Token is an expected token. Its type is the name of the token type constant, e.g. COMMAND or QUOTED_IDENT.
+type Token struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// tokenTypeNames are the names of the token type constants
+var tokenTypeNames = map[sqllexer.TokenType]string{
+	sqllexer.ERROR:                   "ERROR",
+	sqllexer.EOF:                     "EOF",
+	sqllexer.SPACE:                   "SPACE",
+	sqllexer.STRING:                  "STRING",
+	sqllexer.INCOMPLETE_STRING:       "INCOMPLETE_STRING",
+	sqllexer.NUMBER:                  "NUMBER",
+	sqllexer.IDENT:                   "IDENT",
+	sqllexer.QUOTED_IDENT:            "QUOTED_IDENT",
+	sqllexer.OPERATOR:                "OPERATOR",
+	sqllexer.WILDCARD:                "WILDCARD",
+	sqllexer.COMMENT:                 "COMMENT",
+	sqllexer.MULTILINE_COMMENT:       "MULTILINE_COMMENT",
+	sqllexer.PUNCTUATION:             "PUNCTUATION",
+	sqllexer.DOLLAR_QUOTED_FUNCTION:  "DOLLAR_QUOTED_FUNCTION",
+	sqllexer.DOLLAR_QUOTED_STRING:    "DOLLAR_QUOTED_STRING",
+	sqllexer.POSITIONAL_PARAMETER:    "POSITIONAL_PARAMETER",
+	sqllexer.BIND_PARAMETER:          "BIND_PARAMETER",
+	sqllexer.FUNCTION:                "FUNCTION",
+	sqllexer.SYSTEM_VARIABLE:         "SYSTEM_VARIABLE",
+	sqllexer.UNKNOWN:                 "UNKNOWN",
+	sqllexer.COMMAND:                 "COMMAND",
+	sqllexer.KEYWORD:                 "KEYWORD",
+	sqllexer.JSON_OP:                 "JSON_OP",
+	sqllexer.BOOLEAN:                 "BOOLEAN",
+	sqllexer.NULL:                    "NULL",
+	sqllexer.PROC_INDICATOR:          "PROC_INDICATOR",
+	sqllexer.CTE_INDICATOR:           "CTE_INDICATOR",
+	sqllexer.ALIAS_INDICATOR:         "ALIAS_INDICATOR",
+	sqllexer.TEMPLATE:                "TEMPLATE",
+	sqllexer.INCOMPLETE_COMMENT:      "INCOMPLETE_COMMENT",
+	sqllexer.INCOMPLETE_QUOTED_IDENT: "INCOMPLETE_QUOTED_IDENT",
+	sqllexer.PLACEHOLDER:             "PLACEHOLDER",
+}
+
+// TokenTypeName returns the name of the token type constant, e.g. COMMAND
+func TokenTypeName(tokenType sqllexer.TokenType) string {
+	if name, ok := tokenTypeNames[tokenType]; ok {
+		return name
+	}
+	return strconv.Itoa(int(tokenType))
+}
+
+type config struct {
+	obfuscator *sqllexer.Obfuscator
+	normalizer *sqllexer.Normalizer
+	hardened   bool
+}
+
+type Option func(*config)
+
+// WithObfuscator sets the obfuscator checked against the expected outputs, NewObfuscator() by default
+func WithObfuscator(obfuscator *sqllexer.Obfuscator) Option {
+	return func(c *config) {
+		c.obfuscator = obfuscator
+	}
+}
+
+// WithNormalizer sets the normalizer checked against the expected normalized outputs, NewNormalizer() by default
+func WithNormalizer(normalizer *sqllexer.Normalizer) Option {
+	return func(c *config) {
+		c.normalizer = normalizer
+	}
+}
+
+// WithHardened sets the hardened mode of the lexer, see sqllexer.WithHardened
+func WithHardened(hardened bool) Option {
+	return func(c *config) {
+		c.hardened = hardened
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.obfuscator == nil {
+		c.obfuscator = sqllexer.NewObfuscator()
+	}
+	if c.normalizer == nil {
+		c.normalizer = sqllexer.NewNormalizer()
+	}
+	return c
+}
+
+// Check returns an error describing every expectation of the fixture the engine does not meet, or nil
+func Check(fixture Fixture, opts ...Option) error {
+	c := newConfig(opts)
+	dbms, hardened := sqllexer.WithDBMS(fixture.DBMS), sqllexer.WithHardened(c.hardened)
+
+	var errs []error
+	if len(fixture.Tokens) > 0 {
+		var tokens []Token
+		lexer := sqllexer.New(fixture.Input, dbms, hardened)
+		for token := lexer.Scan(); token.Type != sqllexer.EOF; token = lexer.Scan() {
+			if token.Type != sqllexer.SPACE {
+				tokens = append(tokens, Token{Type: TokenTypeName(token.Type), Value: token.Value})
+			}
+		}
+		if err := compareTokens(fixture.Tokens, tokens); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if fixture.Obfuscated != "" {
+		if got := c.obfuscator.Obfuscate(fixture.Input, dbms, hardened); got != fixture.Obfuscated {
+			errs = append(errs, fmt.Errorf("obfuscated: got %q, want %q", got, fixture.Obfuscated))
+		}
+	}
+	if fixture.Normalized != "" {
+		got, _, err := sqllexer.ObfuscateAndNormalize(fixture.Input, c.obfuscator, c.normalizer, dbms, hardened)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("normalized: %w", err))
+		} else if got != fixture.Normalized {
+			errs = append(errs, fmt.Errorf("normalized: got %q, want %q", got, fixture.Normalized))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// compareTokens returns an error describing the first difference between the expected and actual tokens, or nil
+func compareTokens(expected, actual []Token) error {
+	for i := 0; i < len(expected) || i < len(actual); i++ {
+		switch {
+		case i >= len(actual):
+			return fmt.Errorf("tokens: missing token %d %s %q", i, expected[i].Type, expected[i].Value)
+		case i >= len(expected):
+			return fmt.Errorf("tokens: unexpected token %d %s %q", i, actual[i].Type, actual[i].Value)
+		case expected[i] != actual[i]:
+			return fmt.Errorf("tokens: got token %d %s %q, want %s %q", i, actual[i].Type, actual[i].Value, expected[i].Type, expected[i].Value)
+		}
+	}
+	return nil
+}
+
+// Run checks every fixture of the corpus in a subtest named after the fixture
+func Run(t *testing.T, fixtures []Fixture, opts ...Option) {
+	t.Helper()
+	for i, fixture := range fixtures {
+		name := fixture.Name
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+		t.Run(name, func(t *testing.T) {
+			if err := Check(fixture, opts...); err != nil {
+				t.Errorf("%s\n%s", fixture.Input, err)
+			}
+		})
+	}
+}
+
+// Load reads the fixtures of the JSON files of the file system matching the pattern, see fs.Glob.
+// Each file holds an array of fixtures, whose names default to the file name followed by their index.
+func Load(fsys fs.FS, pattern string) ([]Fixture, error) {
+	paths, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+	var fixtures []Fixture
+	for _, p := range paths {
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, err
+		}
+		var file []Fixture
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("sqllexertest: %s: %w", p, err)
+		}
+		for i := range file {
+			if file[i].Name == "" {
+				file[i].Name = path.Base(p) + "#" + strconv.Itoa(i)
+			}
+		}
+		fixtures = append(fixtures, file...)
+	}
+	return fixtures, nil
+}
@@ -0,0 +1,137 @@
+package sqllexertest
+
+import (
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	sqllexer "github.com/DataDog/go-sqllexer"
+	"github.com/stretchr/testify/assert"
+)
+
+var corpus = fstest.MapFS{
+	"corpus/postgresql.json": {Data: []byte(`[
+		{
+			"name": "dollar quoted string",
+			"dbms": "postgresql",
+			"input": "SELECT $tag$secret$tag$ FROM users WHERE id = $1",
+			"tokens": [
+				{"type": "COMMAND", "value": "SELECT"},
+				{"type": "DOLLAR_QUOTED_STRING", "value": "$tag$secret$tag$"},
+				{"type": "KEYWORD", "value": "FROM"},
+				{"type": "IDENT", "value": "users"},
+				{"type": "KEYWORD", "value": "WHERE"},
+				{"type": "IDENT", "value": "id"},
+				{"type": "OPERATOR", "value": "="},
+				{"type": "POSITIONAL_PARAMETER", "value": "$1"}
+			],
+			"obfuscated": "SELECT ? FROM users WHERE id = $1",
+			"normalized": "SELECT ? FROM users WHERE id = $1"
+		}
+	]`)},
+	"corpus/mysql.json": {Data: []byte(`[
+		{
+			"dbms": "mysql",
+			"input": "SELECT ` + "`name`" + ` FROM users # comment",
+			"normalized": "SELECT name FROM users"
+		}
+	]`)},
+	"invalid/invalid.json": {Data: []byte(`{"input": "SELECT 1"}`)},
+}
+
+func TestLoad(t *testing.T) {
+	fixtures, err := Load(corpus, "corpus/*.json")
+	assert.NoError(t, err)
+	if assert.Len(t, fixtures, 2) {
+		assert.Equal(t, "mysql.json#0", fixtures[0].Name)
+		assert.Equal(t, sqllexer.DBMSMySQL, fixtures[0].DBMS)
+		assert.Equal(t, "dollar quoted string", fixtures[1].Name)
+		assert.Len(t, fixtures[1].Tokens, 8)
+	}
+
+	_, err = Load(corpus, "invalid/*.json")
+	assert.ErrorContains(t, err, "invalid/invalid.json")
+}
+
+func TestRun(t *testing.T) {
+	fixtures, err := Load(corpus, "corpus/*.json")
+	assert.NoError(t, err)
+	Run(t, fixtures)
+	Run(t, fixtures, WithHardened(true))
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		fixture  Fixture
+		opts     []Option
+		expected string
+	}{
+		{
+			name:    "met expectations",
+			fixture: Fixture{Input: "SELECT 1", Tokens: []Token{{"COMMAND", "SELECT"}, {"NUMBER", "1"}}, Obfuscated: "SELECT ?"},
+		},
+		{
+			name:     "different token",
+			fixture:  Fixture{Input: "SELECT a", Tokens: []Token{{"COMMAND", "SELECT"}, {"FUNCTION", "a"}}},
+			expected: `tokens: got token 1 IDENT "a", want FUNCTION "a"`,
+		},
+		{
+			name:     "missing token",
+			fixture:  Fixture{Input: "SELECT", Tokens: []Token{{"COMMAND", "SELECT"}, {"NUMBER", "1"}}},
+			expected: `tokens: missing token 1 NUMBER "1"`,
+		},
+		{
+			name:     "unexpected token",
+			fixture:  Fixture{Input: "SELECT 1", Tokens: []Token{{"COMMAND", "SELECT"}}},
+			expected: `tokens: unexpected token 1 NUMBER "1"`,
+		},
+		{
+			name:     "several expectations",
+			fixture:  Fixture{Input: "SELECT 42", Obfuscated: "SELECT 42", Normalized: "SELECT 42"},
+			expected: "obfuscated: got \"SELECT ?\", want \"SELECT 42\"\nnormalized: got \"SELECT ?\", want \"SELECT 42\"",
+		},
+		{
+			name:    "custom obfuscator",
+			fixture: Fixture{Input: "SELECT * FROM orders_2024", Obfuscated: "SELECT * FROM orders_?"},
+			opts:    []Option{WithObfuscator(sqllexer.NewObfuscator(sqllexer.WithReplaceDigits(true)))},
+		},
+		{
+			name:    "custom normalizer",
+			fixture: Fixture{Input: "select 1;", Normalized: "SELECT ?;"},
+			opts:    []Option{WithNormalizer(sqllexer.NewNormalizer(sqllexer.WithUppercaseKeywords(true), sqllexer.WithKeepTrailingSemicolon(true)))},
+		},
+		{
+			name:    "hardened lexer",
+			fixture: Fixture{Input: "SELECT \x00", Tokens: []Token{{"COMMAND", "SELECT"}, {"ERROR", "\x00"}}},
+			opts:    []Option{WithHardened(true)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(tt.fixture, tt.opts...)
+			if tt.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTokenTypeName(t *testing.T) {
+	assert.Equal(t, "QUOTED_IDENT", TokenTypeName(sqllexer.QUOTED_IDENT))
+	assert.Equal(t, "100", TokenTypeName(sqllexer.TokenType(100)))
+}
+
+func ExampleCheck() {
+	err := Check(Fixture{
+		DBMS:       sqllexer.DBMSSQLServer,
+		Input:      "SELECT [name] FROM users WHERE id = 1",
+		Tokens:     []Token{{"COMMAND", "SELECT"}, {"IDENT", "[name]"}},
+		Obfuscated: "SELECT [name] FROM users WHERE id = ?",
+	})
+	fmt.Println(err)
+	// Output: tokens: got token 1 QUOTED_IDENT "[name]", want IDENT "[name]"
+}
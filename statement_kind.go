@@ -0,0 +1,166 @@
+package sqllexer
+
+// StatementKind is the category of a SQL statement
+type StatementKind string
+
+const (
+	StatementSelect  StatementKind = "SELECT"
+	StatementInsert  StatementKind = "INSERT"
+	StatementUpdate  StatementKind = "UPDATE"
+	StatementDelete  StatementKind = "DELETE"
+	StatementMerge   StatementKind = "MERGE"
+	StatementDDL     StatementKind = "DDL"     // data definition, e.g. CREATE, ALTER, DROP, TRUNCATE
+	StatementDCL     StatementKind = "DCL"     // data control, e.g. GRANT, REVOKE
+	StatementTCL     StatementKind = "TCL"     // transaction control, e.g. BEGIN, COMMIT, ROLLBACK
+	StatementUtility StatementKind = "UTILITY" // everything else, e.g. EXPLAIN, USE, SET, procedure calls and blocks
+	StatementUnknown StatementKind = "UNKNOWN" // empty or unrecognized statement
+)
+
+var statementKinds = map[string]StatementKind{
+	"SELECT":    StatementSelect,
+	"VALUES":    StatementSelect,
+	"INSERT":    StatementInsert,
+	"REPLACE":   StatementInsert, // MySQL
+	"UPSERT":    StatementInsert,
+	"UPDATE":    StatementUpdate,
+	"DELETE":    StatementDelete,
+	"MERGE":     StatementMerge,
+	"CREATE":    StatementDDL,
+	"ALTER":     StatementDDL,
+	"DROP":      StatementDDL,
+	"TRUNCATE":  StatementDDL,
+	"RENAME":    StatementDDL,
+	"COMMENT":   StatementDDL,
+	"GRANT":     StatementDCL,
+	"REVOKE":    StatementDCL,
+	"DENY":      StatementDCL, // SQL Server
+	"BEGIN":     StatementTCL,
+	"START":     StatementTCL,
+	"COMMIT":    StatementTCL,
+	"ROLLBACK":  StatementTCL,
+	"SAVEPOINT": StatementTCL,
+	"RELEASE":   StatementTCL,
+	"END":       StatementTCL, // PostgreSQL
+	"ABORT":     StatementTCL, // PostgreSQL
+}
+
+// Command returns the kind of the first statement in the query.
+// Leading comments and parentheses are skipped, and the kind of statements prefixed
+// with a CTE (WITH ...) or EXPLAIN is the kind of the statement that follows the prefix.
+func Command(query string, lexerOpts ...lexerOption) StatementKind {
+	lexer := New(query, lexerOpts...)
+	classifier := statementClassifier{dbms: lexer.config.DBMS}
+	for {
+		token := lexer.Scan()
+		if classifier.classify(token) || token.Type == EOF {
+			break
+		}
+	}
+	return classifier.result()
+}
+
+// statementClassifier determines the kind of a statement from its tokens
+type statementClassifier struct {
+	dbms         DBMSType
+	kind         StatementKind
+	depth        int  // parentheses depth
+	inPrefix     bool // true after WITH or EXPLAIN, until the statement they prefix
+	prefixDepth  int  // parentheses depth of the WITH or EXPLAIN prefix
+	explain      bool // true if the statement is prefixed with EXPLAIN
+	pendingBegin bool // true after a SQL Server BEGIN, which is either a transaction or a block
+}
+
+// classify consumes the next token of the statement and returns true once the kind is determined
+func (c *statementClassifier) classify(token *Token) bool {
+	if c.kind != "" && !c.pendingBegin {
+		return true
+	}
+
+	switch token.Type {
+	case SPACE, COMMENT, MULTILINE_COMMENT, EOF:
+		return false
+	case PUNCTUATION:
+		if token.Value == "(" {
+			c.depth++
+		} else if token.Value == ")" {
+			c.depth--
+		}
+		return false
+	case COMMAND, KEYWORD, IDENT, FUNCTION, PROC_INDICATOR, CTE_INDICATOR:
+	default:
+		if c.pendingBegin {
+			c.pendingBegin = false
+			c.kind = StatementUtility
+			return true
+		}
+		if !c.inPrefix {
+			c.kind = StatementUnknown
+			return true
+		}
+		return false
+	}
+
+	if c.inPrefix && !c.pendingBegin && c.depth > c.prefixDepth {
+		// skip CTE bodies and EXPLAIN options
+		return false
+	}
+
+	word := ToUpperASCII(token.Value)
+
+	if c.pendingBegin {
+		// SQL Server BEGIN TRAN[SACTION] starts a transaction, any other BEGIN starts a block
+		c.pendingBegin = false
+		if word == "TRAN" || word == "TRANSACTION" || word == "DISTRIBUTED" {
+			c.kind = StatementTCL
+		} else {
+			c.kind = StatementUtility
+		}
+		return true
+	}
+
+	if c.inPrefix {
+		switch kind := statementKinds[word]; kind {
+		case StatementSelect, StatementInsert, StatementUpdate, StatementDelete, StatementMerge:
+			c.kind = kind
+			return true
+		}
+		return false
+	}
+
+	switch word {
+	case "WITH", "EXPLAIN":
+		c.inPrefix = true
+		c.prefixDepth = c.depth
+		c.explain = c.explain || word == "EXPLAIN"
+		return false
+	case "BEGIN":
+		switch c.dbms {
+		case DBMSOracle:
+			// PL/SQL anonymous block
+			c.kind = StatementUtility
+			return true
+		case DBMSSQLServer:
+			c.kind = StatementTCL
+			c.pendingBegin = true
+			return false
+		}
+	}
+
+	if kind, ok := statementKinds[word]; ok {
+		c.kind = kind
+	} else {
+		c.kind = StatementUtility
+	}
+	return true
+}
+
+// result returns the kind of the statement classified so far
+func (c *statementClassifier) result() StatementKind {
+	if c.kind != "" {
+		return c.kind
+	}
+	if c.explain {
+		return StatementUtility
+	}
+	return StatementUnknown
+}
@@ -0,0 +1,75 @@
+package sqllexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  StatementKind
+		lexerOpts []lexerOption
+	}{
+		{input: "SELECT * FROM users", expected: StatementSelect},
+		{input: "  /* leading */ -- comment\n select 1", expected: StatementSelect},
+		{input: "(SELECT id FROM a) UNION (SELECT id FROM b)", expected: StatementSelect},
+		{input: "INSERT INTO users (id) VALUES (1)", expected: StatementInsert},
+		{input: "REPLACE INTO users (id) VALUES (1)", expected: StatementInsert, lexerOpts: []lexerOption{WithDBMS(DBMSMySQL)}},
+		{input: "UPDATE users SET name = 'x'", expected: StatementUpdate},
+		{input: "DELETE FROM users", expected: StatementDelete},
+		{input: "MERGE INTO t USING s ON t.id = s.id WHEN MATCHED THEN DELETE", expected: StatementMerge},
+		{input: "WITH recent AS (SELECT * FROM orders WHERE id > 1) SELECT * FROM recent", expected: StatementSelect},
+		{input: "WITH moved AS (DELETE FROM a RETURNING *) INSERT INTO b SELECT * FROM moved", expected: StatementInsert},
+		{input: "WITH RECURSIVE t(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM t) UPDATE x SET y = 1", expected: StatementUpdate},
+		{input: "EXPLAIN ANALYZE SELECT * FROM users", expected: StatementSelect},
+		{input: "EXPLAIN (FORMAT JSON) DELETE FROM users", expected: StatementDelete},
+		{input: "EXPLAIN PLAN FOR UPDATE users SET a = 1", expected: StatementUpdate, lexerOpts: []lexerOption{WithDBMS(DBMSOracle)}},
+		{input: "EXPLAIN", expected: StatementUtility},
+		{input: "CREATE TABLE users (id int)", expected: StatementDDL},
+		{input: "ALTER TABLE users ADD COLUMN name text", expected: StatementDDL},
+		{input: "DROP TABLE IF EXISTS users", expected: StatementDDL},
+		{input: "TRUNCATE TABLE users", expected: StatementDDL},
+		{input: "GRANT SELECT ON users TO bob", expected: StatementDCL},
+		{input: "REVOKE ALL ON users FROM bob", expected: StatementDCL},
+		{input: "BEGIN", expected: StatementTCL},
+		{input: "START TRANSACTION", expected: StatementTCL},
+		{input: "COMMIT", expected: StatementTCL},
+		{input: "ROLLBACK TO SAVEPOINT a", expected: StatementTCL},
+		{input: "BEGIN TRANSACTION", expected: StatementTCL, lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)}},
+		{input: "BEGIN TRY SELECT 1 END TRY", expected: StatementUtility, lexerOpts: []lexerOption{WithDBMS(DBMSSQLServer)}},
+		{input: "BEGIN dbms_output.put_line('x'); END;", expected: StatementUtility, lexerOpts: []lexerOption{WithDBMS(DBMSOracle)}},
+		{input: "USE mydb", expected: StatementUtility},
+		{input: "SET search_path TO public", expected: StatementUtility},
+		{input: "EXEC my_proc", expected: StatementUtility},
+		{input: "CALL my_proc(1)", expected: StatementUtility},
+		{input: "", expected: StatementUnknown},
+		{input: "-- only a comment", expected: StatementUnknown},
+		{input: "42", expected: StatementUnknown},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			assert.Equal(t, test.expected, Command(test.input, test.lexerOpts...))
+		})
+	}
+}
+
+func TestNormalizerStatementKind(t *testing.T) {
+	normalizer := NewNormalizer(WithCollectCommands(true))
+	_, statementMetadata, err := normalizer.Normalize("WITH recent AS (SELECT * FROM orders) DELETE FROM users WHERE id IN (SELECT user_id FROM recent)")
+	assert.NoError(t, err)
+	assert.Equal(t, StatementDelete, statementMetadata.StatementKind)
+
+	normalizer = NewNormalizer(WithCollectCommands(false))
+	_, statementMetadata, err = normalizer.Normalize("SELECT 1")
+	assert.NoError(t, err)
+	assert.Equal(t, StatementKind(""), statementMetadata.StatementKind)
+}
+
+func ExampleCommand() {
+	fmt.Println(Command("/* app */ WITH t AS (SELECT 1) INSERT INTO users SELECT * FROM t"))
+	// Output: INSERT
+}
@@ -0,0 +1,70 @@
+package sqllexer
+
+import "strings"
+
+type stripCommentsConfig struct {
+	// LexerOptions are the options of the lexer of the query, e.g. WithDBMS to lex dialect specific comments such as MySQL "#"
+	LexerOptions []lexerOption `json:"-"`
+
+	// KeepHints specifies whether optimizer hints (/*+ ... */) should be kept
+	KeepHints bool `json:"keep_hints"`
+
+	// KeepVersionedComments specifies whether MySQL versioned comments (/*! ... */) should be kept
+	KeepVersionedComments bool `json:"keep_versioned_comments"`
+}
+
+type stripCommentsOption func(*stripCommentsConfig)
+
+func WithStripCommentsLexerOptions(lexerOpts ...lexerOption) stripCommentsOption {
+	return func(c *stripCommentsConfig) {
+		c.LexerOptions = append(c.LexerOptions, lexerOpts...)
+	}
+}
+
+func WithKeepHints(keepHints bool) stripCommentsOption {
+	return func(c *stripCommentsConfig) {
+		c.KeepHints = keepHints
+	}
+}
+
+func WithKeepVersionedComments(keepVersionedComments bool) stripCommentsOption {
+	return func(c *stripCommentsConfig) {
+		c.KeepVersionedComments = keepVersionedComments
+	}
+}
+
+// StripComments removes the line and block comments of the query and leaves the rest of it byte-for-byte untouched.
+// A single space is inserted where removing a comment would otherwise glue two tokens together.
+func StripComments(query string, opts ...stripCommentsOption) string {
+	config := &stripCommentsConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(query))
+	lexer := New(query, config.LexerOptions...)
+	pendingSeparator := false
+	for {
+		token := lexer.Scan()
+		if token.Type == EOF {
+			break
+		}
+		if (token.Type == COMMENT || token.Type == MULTILINE_COMMENT) && !config.keepComment(token.Value) {
+			// only separate tokens that would otherwise be glued together
+			pendingSeparator = builder.Len() > 0 && !isSpace(rune(query[token.Start-1]))
+			continue
+		}
+		if pendingSeparator && token.Type != SPACE {
+			builder.WriteByte(' ')
+		}
+		pendingSeparator = false
+		builder.WriteString(token.Value)
+	}
+	return builder.String()
+}
+
+func (c *stripCommentsConfig) keepComment(comment string) bool {
+	return (c.KeepHints && strings.HasPrefix(comment, "/*+")) ||
+		(c.KeepVersionedComments && strings.HasPrefix(comment, "/*!"))
+}
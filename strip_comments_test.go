@@ -0,0 +1,62 @@
+package sqllexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripComments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		opts     []stripCommentsOption
+	}{
+		{
+			input:    "SELECT * FROM users",
+			expected: "SELECT * FROM users",
+		},
+		{
+			input:    "/* leading */ SELECT  *\n\tFROM users -- trailing\nWHERE id = 1",
+			expected: " SELECT  *\n\tFROM users \nWHERE id = 1",
+		},
+		{
+			input:    "SELECT 1/* glued */FROM dual",
+			expected: "SELECT 1 FROM dual",
+		},
+		{
+			input:    "SELECT '-- not a comment', \"/* nor this */\" FROM t",
+			expected: "SELECT '-- not a comment', \"/* nor this */\" FROM t",
+		},
+		{
+			input:    "SELECT /*+ INDEX(users idx) */ * FROM users /* c */",
+			expected: "SELECT  * FROM users ",
+		},
+		{
+			input:    "SELECT /*+ INDEX(users idx) */ * FROM users /* c */",
+			expected: "SELECT /*+ INDEX(users idx) */ * FROM users ",
+			opts:     []stripCommentsOption{WithKeepHints(true)},
+		},
+		{
+			input:    "SELECT /*!40001 SQL_NO_CACHE */ * FROM users # c",
+			expected: "SELECT /*!40001 SQL_NO_CACHE */ * FROM users ",
+			opts:     []stripCommentsOption{WithKeepVersionedComments(true), WithStripCommentsLexerOptions(WithDBMS(DBMSMySQL))},
+		},
+		{
+			input:    "SELECT /*!40001 SQL_NO_CACHE */ * FROM users",
+			expected: "SELECT  * FROM users",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			assert.Equal(t, test.expected, StripComments(test.input, test.opts...))
+		})
+	}
+}
+
+func ExampleStripComments() {
+	fmt.Println(StripComments("SELECT /*+ FULL(u) */ * FROM users u -- all users", WithKeepHints(true)))
+	// Output: SELECT /*+ FULL(u) */ * FROM users u
+}
@@ -0,0 +1,197 @@
+package sqllexer
+
+import "strings"
+
+type tenantConfig struct {
+	// LexerOptions are the options of the lexer of the query, e.g. WithDBMS, whose DBMS quotes the table names
+	LexerOptions []lexerOption `json:"-"`
+
+	// Prefix is prepended to the name of every table, e.g. t42_
+	Prefix string `json:"prefix,omitempty"`
+
+	// Suffix is appended to the name of every table, e.g. _t42
+	Suffix string `json:"suffix,omitempty"`
+}
+
+type tenantOption func(*tenantConfig)
+
+func WithTenantLexerOptions(lexerOpts ...lexerOption) tenantOption {
+	return func(c *tenantConfig) {
+		c.LexerOptions = append(c.LexerOptions, lexerOpts...)
+	}
+}
+
+func WithTenantPrefix(prefix string) tenantOption {
+	return func(c *tenantConfig) {
+		c.Prefix = prefix
+	}
+}
+
+func WithTenantSuffix(suffix string) tenantOption {
+	return func(c *tenantConfig) {
+		c.Suffix = suffix
+	}
+}
+
+// RewriteTenantTables returns the query with the prefix and suffix added to the name of every table it references,
+// e.g. orders becomes t42_orders with WithTenantPrefix("t42_"), so tenants sharing a database use their own tables.
+// Tables are the identifiers following FROM, JOIN, UPDATE, INTO, TABLE and USING, and commas of FROM clauses,
+// as collected by the normalizer. CTE names, aliases, columns and table functions are left untouched,
+// but the qualifiers naming the tables are renamed with them, e.g. orders.id becomes t42_orders.id.
+// Only the table name is changed, not its schema, e.g. sales.orders becomes sales.t42_orders.
+// Quoted names stay quoted, and unquoted names are quoted with the quotes of the DBMS if the new name needs it.
+func RewriteTenantTables(query string, opts ...tenantOption) string {
+	config := &tenantConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	tokens := make([]Token, 0, estimateTokens(len(query)))
+	lexer := New(query, config.LexerOptions...)
+	dbms := lexer.config.DBMS
+	for {
+		token := lexer.Scan()
+		if token.Type == EOF {
+			break
+		}
+		tokens = append(tokens, *token)
+	}
+
+	// the names are collected first, so the columns qualified by a table renamed after them are renamed too
+	var names []tenantName
+	var tables [][]identifierPart
+	state := &metadataState{ctes: make(map[string]bool)}
+	var lastValueToken *LastValueToken
+	var functionParentheses []bool // whether each enclosing parenthesis holds the arguments of a function
+	for i := 0; i < len(tokens); i++ {
+		token := &tokens[i]
+		state.trackClause(token, lastValueToken)
+		state.trackCTEs(token)
+		switch {
+		case token.Type == PUNCTUATION && token.Value == "(":
+			functionParentheses = append(functionParentheses, lastValueToken != nil && lastValueToken.Type == FUNCTION)
+		case token.Type == PUNCTUATION && token.Value == ")" && len(functionParentheses) > 0:
+			functionParentheses = functionParentheses[:len(functionParentheses)-1]
+		}
+
+		if isNamePart(token) && token.Type != PUNCTUATION {
+			end := nameEnd(tokens, i)
+			text := query[token.Start:tokens[end].End]
+			name := tenantName{start: token.Start, end: tokens[end].End, parts: splitIdentifier(text), dotted: strings.HasSuffix(text, ".")}
+			switch {
+			case len(name.parts) == 0 || (tokens[end].Type == FUNCTION && !isColumnListTable(lastValueToken)):
+				// e.g. a function or a table function
+			case isCTEName(state, lastValueToken):
+				state.ctes[name.parts[len(name.parts)-1].name] = true
+			case isTablePosition(state.clause, lastValueToken) && !isNonTableWord(text) &&
+				!state.ctes[name.parts[len(name.parts)-1].name] && !(len(functionParentheses) > 0 && functionParentheses[len(functionParentheses)-1]):
+				name.table = true
+				names = append(names, name)
+				tables = append(tables, name.parts)
+			case len(name.parts) > 1 || name.dotted:
+				// e.g. a column qualified by its table
+				names = append(names, name)
+			}
+			lastValueToken = tokens[end].getLastValueToken()
+			i = end
+			continue
+		}
+
+		if isValueToken(token) {
+			lastValueToken = token.getLastValueToken()
+		}
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(query) + len(names)*(len(config.Prefix)+len(config.Suffix)))
+	end := 0
+	for _, name := range names {
+		index := len(name.parts) - 1 // index of the table part of the name
+		if !name.table {
+			if index = name.tableQualifier(tables); index < 0 {
+				continue
+			}
+		}
+		builder.WriteString(query[end:name.start])
+		builder.WriteString(config.rename(name, index, dbms))
+		end = name.end
+	}
+	builder.WriteString(query[end:])
+	return builder.String()
+}
+
+// tenantName is a table name of the query, or a name qualified by a table
+type tenantName struct {
+	start, end int
+	parts      []identifierPart
+	dotted     bool // true if the name ends with a dot, e.g. orders. of orders.*
+	table      bool // true if the name is a table, false if it may be qualified by one
+}
+
+// tableQualifier returns the index of the part of the name naming one of the tables, as the qualifier of a column,
+// e.g. orders of orders.id or sales.orders.id, or -1 if its qualifier names none of them
+func (n *tenantName) tableQualifier(tables [][]identifierPart) int {
+	qualifier := n.parts
+	if !n.dotted {
+		qualifier = qualifier[:len(qualifier)-1]
+	}
+	for _, table := range tables {
+		// the qualifier and the table may omit the schema, e.g. orders.id of sales.orders
+		matches := true
+		for i := 1; i <= min(len(qualifier), len(table)); i++ {
+			if !qualifier[len(qualifier)-i].matches(table[len(table)-i]) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return len(qualifier) - 1
+		}
+	}
+	return -1
+}
+
+// isColumnListTable returns true if a function following lastValueToken is a table followed by its columns,
+// e.g. INSERT INTO orders(id) or CREATE TABLE orders(id int)
+func isColumnListTable(lastValueToken *LastValueToken) bool {
+	return lastValueToken != nil && lastValueToken.Type == KEYWORD &&
+		(equalFoldASCII(lastValueToken.Value, "INTO") || equalFoldASCII(lastValueToken.Value, "TABLE"))
+}
+
+// rename returns the name with the prefix and suffix added to its part at index
+func (c *tenantConfig) rename(name tenantName, index int, dbms DBMSType) string {
+	var builder strings.Builder
+	for i, part := range name.parts {
+		if i > 0 {
+			builder.WriteByte('.')
+		}
+		if i != index {
+			builder.WriteString(quoteIdentifierPart(part.name, part.quote))
+			continue
+		}
+		renamed := c.Prefix + part.name + c.Suffix
+		quote := part.quote
+		if quote == 0 && !isPlainIdentifier(renamed) {
+			quote = identifierQuote(dbms)
+		}
+		builder.WriteString(quoteIdentifierPart(renamed, quote))
+	}
+	if name.dotted {
+		builder.WriteByte('.')
+	}
+	return builder.String()
+}
+
+// isPlainIdentifier returns true if the name is lexed as an identifier when unquoted,
+// i.e. it is made of letters, digits and underscores, does not start with a digit and is not a keyword
+func isPlainIdentifier(name string) bool {
+	if name == "" || isDigit(rune(name[0])) || isKeyword(name) {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		ch := rune(name[i])
+		if !isAsciiLetter(ch) && !isDigit(ch) && ch != '_' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package sqllexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewriteTenantTables(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     []tenantOption
+		expected string
+	}{
+		{
+			name:     "select",
+			input:    "SELECT o.id, users.name FROM orders o JOIN users ON users.id = o.user_id, sales.items WHERE o.id = 'orders'",
+			opts:     []tenantOption{WithTenantPrefix("t42_")},
+			expected: "SELECT o.id, t42_users.name FROM t42_orders o JOIN t42_users ON t42_users.id = o.user_id, sales.items WHERE o.id = 'orders'",
+		},
+		{
+			name:     "comma joins and schemas",
+			input:    "SELECT * FROM sales.orders, \"sales\".items, users",
+			opts:     []tenantOption{WithTenantPrefix("t42_")},
+			expected: "SELECT * FROM sales.t42_orders, \"sales\".t42_items, t42_users",
+		},
+		{
+			name:     "ctes and subqueries",
+			input:    "WITH recent AS (SELECT * FROM orders) SELECT * FROM recent WHERE EXISTS (SELECT 1 FROM refunds)",
+			opts:     []tenantOption{WithTenantPrefix("t42_")},
+			expected: "WITH recent AS (SELECT * FROM t42_orders) SELECT * FROM recent WHERE EXISTS (SELECT 1 FROM t42_refunds)",
+		},
+		{
+			name:     "functions",
+			input:    "SELECT EXTRACT(YEAR FROM created_at) FROM orders, generate_series(1, 3) g",
+			opts:     []tenantOption{WithTenantPrefix("t42_")},
+			expected: "SELECT EXTRACT(YEAR FROM created_at) FROM t42_orders, generate_series(1, 3) g",
+		},
+		{
+			name:     "insert",
+			input:    "INSERT INTO orders(id, total) SELECT id, total FROM carts",
+			opts:     []tenantOption{WithTenantPrefix("t42_")},
+			expected: "INSERT INTO t42_orders(id, total) SELECT id, total FROM t42_carts",
+		},
+		{
+			name:     "update and delete",
+			input:    "UPDATE orders SET paid = true FROM payments WHERE payments.id = orders.payment_id; DELETE FROM carts USING users WHERE carts.user_id = users.id",
+			opts:     []tenantOption{WithTenantSuffix("_t42")},
+			expected: "UPDATE orders_t42 SET paid = true FROM payments_t42 WHERE payments_t42.id = orders_t42.payment_id; DELETE FROM carts_t42 USING users_t42 WHERE carts_t42.user_id = users_t42.id",
+		},
+		{
+			name:     "quoted",
+			input:    `SELECT * FROM "Orders"`,
+			opts:     []tenantOption{WithTenantPrefix("t42_")},
+			expected: `SELECT * FROM "t42_Orders"`,
+		},
+		{
+			name:     "mixed quotes",
+			input:    `SELECT * FROM public."Orders" o, "x"."y".z JOIN a."b" ON true`,
+			opts:     []tenantOption{WithTenantPrefix("t42_")},
+			expected: `SELECT * FROM public."t42_Orders" o, "x"."y".t42_z JOIN a."t42_b" ON true`,
+		},
+		{
+			name:     "qualified columns",
+			input:    `SELECT orders.id, sales.orders.total, "orders".*, o.id, orders FROM sales.orders JOIN items o ON o.order_id = orders.id`,
+			opts:     []tenantOption{WithTenantPrefix("t42_")},
+			expected: `SELECT t42_orders.id, sales.t42_orders.total, "t42_orders".*, o.id, orders FROM sales.t42_orders JOIN t42_items o ON o.order_id = t42_orders.id`,
+		},
+		{
+			name:     "mysql quoting",
+			input:    "SELECT * FROM orders JOIN `users` ON true",
+			opts:     []tenantOption{WithTenantPrefix("tenant-42."), WithTenantLexerOptions(WithDBMS(DBMSMySQL))},
+			expected: "SELECT * FROM `tenant-42.orders` JOIN `tenant-42.users` ON true",
+		},
+		{
+			name:     "sql server quoting",
+			input:    "SELECT * FROM [dbo].[orders] JOIN users ON 1 = 1",
+			opts:     []tenantOption{WithTenantSuffix("$42"), WithTenantLexerOptions(WithDBMS(DBMSSQLServer))},
+			expected: "SELECT * FROM [dbo].[orders$42] JOIN [users$42] ON 1 = 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RewriteTenantTables(tt.input, tt.opts...))
+		})
+	}
+}
+
+func ExampleRewriteTenantTables() {
+	rewritten := RewriteTenantTables(
+		"SELECT o.id FROM orders o JOIN customers c ON c.id = o.customer_id",
+		WithTenantPrefix("t42_"),
+	)
+	fmt.Println(rewritten)
+	// Output: SELECT o.id FROM t42_orders o JOIN t42_customers c ON c.id = o.customer_id
+}
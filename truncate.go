@@ -0,0 +1,84 @@
+package sqllexer
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type truncateConfig struct {
+	// LexerOptions are the options of the lexer of the query, e.g. WithDBMS
+	LexerOptions []lexerOption `json:"-"`
+
+	// Marker is appended to truncated queries, before the number of omitted tokens. Defaults to "…", and may be empty
+	Marker string `json:"marker,omitempty"`
+}
+
+type truncateOption func(*truncateConfig)
+
+func WithTruncateLexerOptions(lexerOpts ...lexerOption) truncateOption {
+	return func(c *truncateConfig) {
+		c.LexerOptions = append(c.LexerOptions, lexerOpts...)
+	}
+}
+
+func WithTruncateMarker(marker string) truncateOption {
+	return func(c *truncateConfig) {
+		c.Marker = marker
+	}
+}
+
+const defaultTruncateMarker = "…"
+
+// TruncateQuery returns the query truncated to at most n characters for display, e.g. in tables of a UI,
+// followed by the marker and the number of omitted tokens, e.g. "SELECT * FROM users … (4 more tokens)".
+// The query is cut at a token boundary, so it is never cut inside a multi-byte character, a string literal,
+// an identifier or a comment. Spaces and comments are not counted as omitted tokens.
+// Queries of at most n characters are returned unchanged.
+func TruncateQuery(query string, n int, opts ...truncateOption) string {
+	if utf8.RuneCountInString(query) <= n {
+		return query
+	}
+	config := &truncateConfig{Marker: defaultTruncateMarker}
+	for _, opt := range opts {
+		opt(config)
+	}
+	cut, length, omitted := 0, 0, 0
+	truncated := false
+	lexer := New(query, config.LexerOptions...)
+	for {
+		token := lexer.Scan()
+		if token.Type == EOF {
+			break
+		}
+		if !truncated {
+			length += utf8.RuneCountInString(query[token.Start:token.End])
+			if length <= n {
+				cut = token.End
+				continue
+			}
+			truncated = true
+		}
+		if isValueToken(token) {
+			omitted++
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString(strings.TrimRight(query[:cut], " \t\r\n"))
+	if builder.Len() > 0 {
+		builder.WriteByte(' ')
+	}
+	if config.Marker != "" {
+		builder.WriteString(config.Marker)
+		builder.WriteByte(' ')
+	}
+	builder.WriteByte('(')
+	builder.WriteString(strconv.Itoa(omitted))
+	if omitted == 1 {
+		builder.WriteString(" more token)")
+	} else {
+		builder.WriteString(" more tokens)")
+	}
+	return builder.String()
+}
@@ -0,0 +1,87 @@
+package sqllexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		n        int
+		opts     []truncateOption
+		expected string
+	}{
+		{
+			name:     "short query",
+			input:    "SELECT * FROM users",
+			n:        19,
+			expected: "SELECT * FROM users",
+		},
+		{
+			name:     "token boundary",
+			input:    "SELECT * FROM users WHERE id = 1",
+			n:        16,
+			expected: "SELECT * FROM … (5 more tokens)",
+		},
+		{
+			name:     "string literal",
+			input:    "SELECT * FROM users WHERE name = 'a long name'",
+			n:        40,
+			expected: "SELECT * FROM users WHERE name = … (1 more token)",
+		},
+		{
+			name:     "multi-byte characters",
+			input:    "SELECT 'héhé', 'été'",
+			n:        16,
+			expected: "SELECT 'héhé', … (1 more token)",
+		},
+		{
+			name:     "comments are not counted",
+			input:    "SELECT a /* comment */ FROM t",
+			n:        10,
+			expected: "SELECT a … (2 more tokens)",
+		},
+		{
+			name:     "first token too long",
+			input:    "SELECT 1",
+			n:        3,
+			expected: "… (2 more tokens)",
+		},
+		{
+			name:     "marker",
+			input:    "SELECT * FROM users",
+			n:        10,
+			opts:     []truncateOption{WithTruncateMarker("[...]")},
+			expected: "SELECT * [...] (2 more tokens)",
+		},
+		{
+			name:     "empty marker",
+			input:    "SELECT * FROM users",
+			n:        10,
+			opts:     []truncateOption{WithTruncateMarker("")},
+			expected: "SELECT * (2 more tokens)",
+		},
+		{
+			name:     "dbms",
+			input:    "SELECT $tag$ a b c $tag$ FROM t",
+			n:        20,
+			opts:     []truncateOption{WithTruncateLexerOptions(WithDBMS(DBMSPostgres))},
+			expected: "SELECT … (3 more tokens)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, TruncateQuery(tt.input, tt.n, tt.opts...))
+		})
+	}
+}
+
+func ExampleTruncateQuery() {
+	fmt.Println(TruncateQuery("SELECT id, name, email FROM users WHERE id IN (1, 2, 3)", 30))
+	// Output: SELECT id, name, email FROM … (11 more tokens)
+}
@@ -0,0 +1,131 @@
+package sqllexer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// DiagnosticCode identifies the problem reported by a diagnostic
+type DiagnosticCode string
+
+const (
+	DiagnosticUnterminatedString      DiagnosticCode = "unterminated_string"
+	DiagnosticUnterminatedComment     DiagnosticCode = "unterminated_comment"
+	DiagnosticUnterminatedDollarQuote DiagnosticCode = "unterminated_dollar_quote"
+	DiagnosticUnterminatedIdentifier  DiagnosticCode = "unterminated_quoted_identifier"
+	DiagnosticUnclosedBracket         DiagnosticCode = "unclosed_bracket"   // (, [ or { without its closing bracket
+	DiagnosticUnexpectedBracket       DiagnosticCode = "unexpected_bracket" // ), ] or } without its opening bracket
+	DiagnosticInvalidToken            DiagnosticCode = "invalid_token"      // e.g. @@ without a variable name
+	DiagnosticUnknownToken            DiagnosticCode = "unknown_token"
+	DiagnosticUnexpectedEnd           DiagnosticCode = "unexpected_end" // a NUL byte, which ends the lexing of the query
+)
+
+// Diagnostic is a structural problem of a query
+type Diagnostic struct {
+	Code    DiagnosticCode `json:"code"`
+	Message string         `json:"message"`
+	Start   int            `json:"start"`  // byte offset of the offending token in the query
+	End     int            `json:"end"`    // byte offset following the offending token in the query
+	Line    int            `json:"line"`   // line of the offending token, starting at 1
+	Column  int            `json:"column"` // column of the offending token in runes, starting at 1
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%d:%d: %s", d.Line, d.Column, d.Message)
+}
+
+// closingBrackets are the closing brackets of the opening brackets
+var closingBrackets = map[string]string{
+	"(": ")",
+	"[": "]",
+	"{": "}",
+}
+
+// Validate checks the structure of the query, without parsing it, and returns its problems in order of position:
+// unterminated strings, comments, dollar quoted strings and quoted identifiers, unbalanced parentheses,
+// brackets and braces, unknown tokens, and NUL bytes, past which the query is not checked.
+// A query without problems has no diagnostics.
+func Validate(query string, lexerOpts ...lexerOption) []Diagnostic {
+	var diagnostics []Diagnostic
+	var brackets []Token // opening brackets waiting for their closing bracket
+	lexer := New(query, lexerOpts...)
+	for {
+		token := lexer.Scan()
+		if token.Type == EOF {
+			if lexer.cursor < len(query) {
+				// the rest of the query, which is not checked, is the offending token
+				rest := *token
+				rest.End = token.Start + len(query) - lexer.cursor
+				diagnostics = append(diagnostics, newDiagnostic(query, &rest, DiagnosticUnexpectedEnd, "unexpected NUL byte, the rest of the query is ignored"))
+			}
+			break
+		}
+		switch token.Type {
+		case INCOMPLETE_STRING:
+			diagnostics = append(diagnostics, newDiagnostic(query, token, DiagnosticUnterminatedString, "unterminated string literal"))
+		case INCOMPLETE_COMMENT:
+			diagnostics = append(diagnostics, newDiagnostic(query, token, DiagnosticUnterminatedComment, "unterminated multiline comment"))
+		case INCOMPLETE_QUOTED_IDENT:
+			diagnostics = append(diagnostics, newDiagnostic(query, token, DiagnosticUnterminatedIdentifier, "unterminated quoted identifier"))
+		case ERROR:
+			code, message := tokenError(token.Value)
+			diagnostics = append(diagnostics, newDiagnostic(query, token, code, message))
+		case UNKNOWN:
+			diagnostics = append(diagnostics, newDiagnostic(query, token, DiagnosticUnknownToken, fmt.Sprintf("unknown token %q", token.Value)))
+		case PUNCTUATION:
+			switch token.Value {
+			case "(", "[", "{":
+				brackets = append(brackets, *token)
+			case ")", "]", "}":
+				if len(brackets) == 0 || closingBrackets[brackets[len(brackets)-1].Value] != token.Value {
+					diagnostics = append(diagnostics, newDiagnostic(query, token, DiagnosticUnexpectedBracket, fmt.Sprintf("unexpected %q", token.Value)))
+				} else {
+					brackets = brackets[:len(brackets)-1]
+				}
+			}
+		}
+	}
+	for i := range brackets {
+		message := fmt.Sprintf("%q is never closed", brackets[i].Value)
+		diagnostics = append(diagnostics, newDiagnostic(query, &brackets[i], DiagnosticUnclosedBracket, message))
+	}
+	// unclosed brackets are only known at the end of the query
+	sortDiagnostics(diagnostics)
+	return diagnostics
+}
+
+// sortDiagnostics sorts the diagnostics in order of position, keeping the order of diagnostics at the same position
+func sortDiagnostics(diagnostics []Diagnostic) {
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return diagnostics[i].Start < diagnostics[j].Start
+	})
+}
+
+// tokenError returns the problem of an ERROR token, from its opening characters
+func tokenError(value string) (DiagnosticCode, string) {
+	switch {
+	case strings.HasPrefix(value, "$"):
+		return DiagnosticUnterminatedDollarQuote, "unterminated dollar quoted string"
+	}
+	return DiagnosticInvalidToken, fmt.Sprintf("invalid token %q", value)
+}
+
+func newDiagnostic(query string, token *Token, code DiagnosticCode, message string) Diagnostic {
+	diagnostic := Diagnostic{
+		Code:    code,
+		Message: message,
+		Start:   token.Start,
+		End:     token.End,
+		Line:    token.Line,
+		Column:  token.Column,
+	}
+	if token.Line == 0 {
+		// the lexer did not track the positions of the tokens, which are offsets in the query
+		lineStart := strings.LastIndexByte(query[:token.Start], '\n') + 1
+		diagnostic.Line = strings.Count(query[:token.Start], "\n") + 1
+		diagnostic.Column = utf8.RuneCountInString(query[lineStart:token.Start]) + 1
+	}
+	return diagnostic
+}
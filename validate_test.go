@@ -0,0 +1,115 @@
+package sqllexer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		lexerOpts []lexerOption
+		expected  []Diagnostic
+	}{
+		{
+			name:  "valid",
+			input: "SELECT a[1], '(' FROM t WHERE b IN (1, (2)) /* ) */ -- (",
+		},
+		{
+			name:  "unterminated string",
+			input: "SELECT * FROM t\nWHERE name = 'abc",
+			expected: []Diagnostic{
+				{Code: DiagnosticUnterminatedString, Message: "unterminated string literal", Start: 29, End: 33, Line: 2, Column: 14},
+			},
+		},
+		{
+			name:  "unterminated comment",
+			input: "SELECT 1 /* truncated",
+			expected: []Diagnostic{
+				{Code: DiagnosticUnterminatedComment, Message: "unterminated multiline comment", Start: 9, End: 21, Line: 1, Column: 10},
+			},
+		},
+		{
+			name:  "nul byte",
+			input: "SELECT 1\x00 (((",
+			expected: []Diagnostic{
+				{Code: DiagnosticUnexpectedEnd, Message: "unexpected NUL byte, the rest of the query is ignored", Start: 8, End: 13, Line: 1, Column: 9},
+			},
+		},
+		{
+			name:  "nul byte in string",
+			input: "SELECT (\n'a\x00b')",
+			expected: []Diagnostic{
+				{Code: DiagnosticUnclosedBracket, Message: `"(" is never closed`, Start: 7, End: 8, Line: 1, Column: 8},
+				{Code: DiagnosticUnterminatedString, Message: "unterminated string literal", Start: 9, End: 11, Line: 2, Column: 1},
+				{Code: DiagnosticUnexpectedEnd, Message: "unexpected NUL byte, the rest of the query is ignored", Start: 11, End: 15, Line: 2, Column: 3},
+			},
+		},
+		{
+			name:      "unterminated dollar quote",
+			input:     "SELECT $tag$ body",
+			lexerOpts: []lexerOption{WithDBMS(DBMSPostgres)},
+			expected: []Diagnostic{
+				{Code: DiagnosticUnterminatedDollarQuote, Message: "unterminated dollar quoted string", Start: 7, End: 17, Line: 1, Column: 8},
+			},
+		},
+		{
+			name:      "embedded in a document",
+			input:     "SELECT 1\nFROM t WHERE (a = 'é' OR 'b",
+			lexerOpts: []lexerOption{WithStartPosition(120, 7, 5)},
+			expected: []Diagnostic{
+				{Code: DiagnosticUnclosedBracket, Message: `"(" is never closed`, Start: 142, End: 143, Line: 8, Column: 14},
+				{Code: DiagnosticUnterminatedString, Message: "unterminated string literal", Start: 155, End: 157, Line: 8, Column: 26},
+			},
+		},
+		{
+			name:  "unterminated quoted identifier",
+			input: `SELECT "name FROM t`,
+			expected: []Diagnostic{
+				{Code: DiagnosticUnterminatedIdentifier, Message: "unterminated quoted identifier", Start: 7, End: 19, Line: 1, Column: 8},
+			},
+		},
+		{
+			name:  "unbalanced parentheses",
+			input: "SELECT (a FROM t WHERE b IN (1, 2))) AND c = (1",
+			expected: []Diagnostic{
+				{Code: DiagnosticUnexpectedBracket, Message: `unexpected ")"`, Start: 35, End: 36, Line: 1, Column: 36},
+				{Code: DiagnosticUnclosedBracket, Message: `"(" is never closed`, Start: 45, End: 46, Line: 1, Column: 46},
+			},
+		},
+		{
+			name:  "mismatched brackets",
+			input: "SELECT a[1) FROM t",
+			expected: []Diagnostic{
+				{Code: DiagnosticUnclosedBracket, Message: `"[" is never closed`, Start: 8, End: 9, Line: 1, Column: 9},
+				{Code: DiagnosticUnexpectedBracket, Message: `unexpected ")"`, Start: 10, End: 11, Line: 1, Column: 11},
+			},
+		},
+		{
+			name:  "unknown tokens",
+			input: "SELECT é, `a` FROM t",
+			expected: []Diagnostic{
+				{Code: DiagnosticUnknownToken, Message: "unknown token \"`\"", Start: 11, End: 12, Line: 1, Column: 11},
+				{Code: DiagnosticUnknownToken, Message: "unknown token \"`\"", Start: 13, End: 14, Line: 1, Column: 13},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, Validate(test.input, test.lexerOpts...))
+		})
+	}
+}
+
+func ExampleValidate() {
+	for _, diagnostic := range Validate("SELECT *\nFROM users\nWHERE (name = 'abc") {
+		fmt.Println(diagnostic)
+	}
+	// Output:
+	// 3:7: "(" is never closed
+	// 3:15: unterminated string literal
+}